@@ -0,0 +1,132 @@
+package kafkaroutingconnector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+// Prefixes of the paths of the fields a route matches.
+const (
+	bodyPrefix       = "body."
+	attributesPrefix = "attributes."
+)
+
+// Config configures the kafkarouting connector.
+type Config struct {
+	// Routes are tried in order, the first one matching a log record routes it.
+	Routes []RouteConfig `mapstructure:"routes"`
+	// DefaultPipelines receive the log records no route matches and those of the routes without pipelines. Log
+	// records without a pipeline are dropped.
+	DefaultPipelines []pipeline.ID `mapstructure:"default_pipelines"`
+	// HECMetadata names the attributes receiving the index, source and sourcetype overrides of the routes. It must
+	// match the otel_attrs_to_hec_metadata setting of the splunk_hec exporter, and has the same defaults.
+	HECMetadata HECMetadata `mapstructure:"otel_attrs_to_hec_metadata"`
+}
+
+// RouteConfig matches log records by the topic and headers of their Kafka record and by their fields, and sends
+// them to pipelines, possibly overriding their HEC metadata. The regular expressions must match whole values.
+type RouteConfig struct {
+	// Topic is a regular expression matching the topic of the record.
+	Topic string `mapstructure:"topic"`
+	// Headers map header names to regular expressions matching their value.
+	Headers map[string]string `mapstructure:"headers"`
+	// Fields map paths of body fields, e.g. body.level, or attributes, e.g. attributes.tenant, to regular
+	// expressions matching their value.
+	Fields map[string]string `mapstructure:"fields"`
+
+	// Pipelines receive the matching log records, the default pipelines when empty.
+	Pipelines []pipeline.ID `mapstructure:"pipelines"`
+	// Index, SourceType and Source override the HEC metadata of the matching log records.
+	Index      string `mapstructure:"index"`
+	SourceType string `mapstructure:"sourcetype"`
+	Source     string `mapstructure:"source"`
+}
+
+// HECMetadata maps HEC metadata to attribute names.
+type HECMetadata struct {
+	Source     string `mapstructure:"source"`
+	SourceType string `mapstructure:"sourcetype"`
+	Index      string `mapstructure:"index"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		HECMetadata: HECMetadata{
+			Source:     "com.splunk.source",
+			SourceType: "com.splunk.sourcetype",
+			Index:      "com.splunk.index",
+		},
+	}
+}
+
+// Validate checks the conditions and destinations of the routes.
+func (cfg *Config) Validate() error {
+	m := cfg.HECMetadata
+	if m.Source == "" || m.SourceType == "" || m.Index == "" {
+		return errors.New("otel_attrs_to_hec_metadata: source, sourcetype and index are required")
+	}
+	if len(cfg.Routes) == 0 {
+		return errors.New("routes: at least one route is required")
+	}
+	for i, r := range cfg.Routes {
+		if _, err := compileRoute(r); err != nil {
+			return fmt.Errorf("routes[%d]: %w", i, err)
+		}
+		if len(r.Pipelines) > 0 {
+			continue
+		}
+		if r.Index == "" && r.SourceType == "" && r.Source == "" {
+			return fmt.Errorf("routes[%d]: pipelines, index, sourcetype or source is required", i)
+		}
+		if len(cfg.DefaultPipelines) == 0 {
+			return fmt.Errorf("routes[%d]: pipelines: required without default_pipelines", i)
+		}
+	}
+	return nil
+}
+
+// conditions are the compiled conditions of a route.
+type conditions struct {
+	topic   *regexp.Regexp
+	headers map[string]*regexp.Regexp
+	fields  map[string]*regexp.Regexp
+}
+
+func compileRoute(r RouteConfig) (conditions, error) {
+	c := conditions{headers: map[string]*regexp.Regexp{}, fields: map[string]*regexp.Regexp{}}
+	if r.Topic == "" && len(r.Headers) == 0 && len(r.Fields) == 0 {
+		return c, errors.New("topic, headers or fields is required")
+	}
+	var err error
+	if r.Topic != "" {
+		if c.topic, err = compile(r.Topic); err != nil {
+			return c, fmt.Errorf("topic: %w", err)
+		}
+	}
+	for name, expr := range r.Headers {
+		if c.headers[name], err = compile(expr); err != nil {
+			return c, fmt.Errorf("headers: %s: %w", name, err)
+		}
+	}
+	for path, expr := range r.Fields {
+		if (!strings.HasPrefix(path, bodyPrefix) || path == bodyPrefix) && (!strings.HasPrefix(path, attributesPrefix) || path == attributesPrefix) {
+			return c, fmt.Errorf("fields: %q is not a path under body. or attributes., e.g. body.level", path)
+		}
+		if c.fields[path], err = compile(expr); err != nil {
+			return c, fmt.Errorf("fields: %s: %w", path, err)
+		}
+	}
+	return c, nil
+}
+
+// compile compiles a regular expression matching whole values.
+func compile(expr string) (*regexp.Regexp, error) {
+	if _, err := regexp.Compile(expr); err != nil {
+		return nil, err
+	}
+	return regexp.MustCompile(`^(?:` + expr + `)$`), nil
+}
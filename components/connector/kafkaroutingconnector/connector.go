@@ -0,0 +1,216 @@
+// Package kafkaroutingconnector implements the kafkarouting connector, which routes the log records of a logs
+// pipeline to other logs pipelines by the topic and headers of the Kafka record they were read from and by their
+// fields, and can override their index, sourcetype and source. A single receiver subscribed to many topics, e.g.
+// with a regular expression, can then feed many Splunk destinations.
+package kafkaroutingconnector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+// unrouted is the group of the log records no route matches.
+const unrouted = -1
+
+type route struct {
+	conditions
+	// next receives the matching log records, nil to drop them.
+	next consumer.Logs
+	// attributes are the HEC metadata overrides, by attribute name.
+	attributes map[string]string
+}
+
+type routingConnector struct {
+	component.StartFunc
+	component.ShutdownFunc
+
+	routes []route
+	// next receives the log records no route matches, nil to drop them.
+	next consumer.Logs
+}
+
+var _ connector.Logs = (*routingConnector)(nil)
+
+func newRoutingConnector(cfg *Config, router connector.LogsRouterAndConsumer) (*routingConnector, error) {
+	c := &routingConnector{}
+	if len(cfg.DefaultPipelines) > 0 {
+		var err error
+		if c.next, err = router.Consumer(cfg.DefaultPipelines...); err != nil {
+			return nil, fmt.Errorf("default_pipelines: %w", err)
+		}
+	}
+	for i, rc := range cfg.Routes {
+		conds, err := compileRoute(rc)
+		if err != nil {
+			return nil, fmt.Errorf("routes[%d]: %w", i, err)
+		}
+		r := route{conditions: conds, next: c.next, attributes: map[string]string{}}
+		if len(rc.Pipelines) > 0 {
+			if r.next, err = router.Consumer(rc.Pipelines...); err != nil {
+				return nil, fmt.Errorf("routes[%d]: pipelines: %w", i, err)
+			}
+		}
+		for attr, v := range map[string]string{
+			cfg.HECMetadata.Index:      rc.Index,
+			cfg.HECMetadata.SourceType: rc.SourceType,
+			cfg.HECMetadata.Source:     rc.Source,
+		} {
+			if v != "" {
+				r.attributes[attr] = v
+			}
+		}
+		c.routes = append(c.routes, r)
+	}
+	return c, nil
+}
+
+func (c *routingConnector) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+// ConsumeLogs groups the log records by route and sends every group to the pipelines of its route.
+func (c *routingConnector) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	// The topic and headers are those of the record of the request, only fields differ between log records.
+	candidates := make([]int, 0, len(c.routes))
+	for i, r := range c.routes {
+		if r.matchesRecord(ctx) {
+			candidates = append(candidates, i)
+		}
+	}
+	var groups []int
+	seen := map[int]bool{}
+	forEachLogRecord(ld, func(lr plog.LogRecord) {
+		g := c.group(candidates, lr)
+		groups = append(groups, g)
+		seen[g] = true
+	})
+	if len(seen) == 1 {
+		return c.send(ctx, groups[0], ld)
+	}
+
+	var errs []error
+	for g := range seen {
+		part := plog.NewLogs()
+		ld.CopyTo(part)
+		i := 0
+		part.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+			rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+				sl.LogRecords().RemoveIf(func(plog.LogRecord) bool {
+					i++
+					return groups[i-1] != g
+				})
+				return sl.LogRecords().Len() == 0
+			})
+			return rl.ScopeLogs().Len() == 0
+		})
+		errs = append(errs, c.send(ctx, g, part))
+	}
+	return errors.Join(errs...)
+}
+
+// group returns the first of the candidate routes matching the fields of a log record, unrouted when none does.
+func (c *routingConnector) group(candidates []int, lr plog.LogRecord) int {
+	for _, i := range candidates {
+		if c.routes[i].matchesFields(lr) {
+			return i
+		}
+	}
+	return unrouted
+}
+
+// send applies the overrides of a route to log records and sends them to its pipelines.
+func (c *routingConnector) send(ctx context.Context, g int, ld plog.Logs) error {
+	next := c.next
+	if g != unrouted {
+		r := c.routes[g]
+		next = r.next
+		if len(r.attributes) > 0 {
+			forEachLogRecord(ld, func(lr plog.LogRecord) {
+				for attr, v := range r.attributes {
+					lr.Attributes().PutStr(attr, v)
+				}
+			})
+		}
+	}
+	if next == nil || ld.LogRecordCount() == 0 {
+		return nil
+	}
+	return next.ConsumeLogs(ctx, ld)
+}
+
+func forEachLogRecord(ld plog.Logs, f func(plog.LogRecord)) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				f(lrs.At(k))
+			}
+		}
+	}
+}
+
+// matchesRecord reports whether the topic and headers of the Kafka record of the request match.
+func (r route) matchesRecord(ctx context.Context) bool {
+	if r.topic != nil && !r.topic.MatchString(kafkarecord.FromContext(ctx).Topic) {
+		return false
+	}
+	for name, re := range r.headers {
+		v, ok := kafkarecord.Header(ctx, name)
+		if !ok || !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesFields reports whether the fields of a log record match.
+func (r route) matchesFields(lr plog.LogRecord) bool {
+	for path, re := range r.fields {
+		v, ok := field(lr, path)
+		if !ok || !re.MatchString(v.AsString()) {
+			return false
+		}
+	}
+	return true
+}
+
+// field returns the value at a path, a dotted path into the body or the attributes of a log record.
+func field(lr plog.LogRecord, path string) (pcommon.Value, bool) {
+	if rest, ok := strings.CutPrefix(path, bodyPrefix); ok {
+		if lr.Body().Type() != pcommon.ValueTypeMap {
+			return pcommon.Value{}, false
+		}
+		return lookup(lr.Body().Map(), rest)
+	}
+	return lookup(lr.Attributes(), strings.TrimPrefix(path, attributesPrefix))
+}
+
+// lookup returns the value at a dotted path in a map. Keys often contain dots themselves, e.g. kafka.topic, so the
+// longest key matching a prefix of the path is tried first.
+func lookup(m pcommon.Map, path string) (pcommon.Value, bool) {
+	if v, ok := m.Get(path); ok {
+		return v, true
+	}
+	for i := strings.LastIndexByte(path, '.'); i > 0; i = strings.LastIndexByte(path[:i], '.') {
+		v, ok := m.Get(path[:i])
+		if !ok || v.Type() != pcommon.ValueTypeMap {
+			continue
+		}
+		if v, ok := lookup(v.Map(), path[i+1:]); ok {
+			return v, true
+		}
+	}
+	return pcommon.Value{}, false
+}
@@ -0,0 +1,190 @@
+package kafkaroutingconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/connector/connectortest"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+var (
+	appLogs      = pipeline.NewIDWithName(pipeline.SignalLogs, "app")
+	securityLogs = pipeline.NewIDWithName(pipeline.SignalLogs, "security")
+	defaultLogs  = pipeline.NewIDWithName(pipeline.SignalLogs, "default")
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	assert.EqualError(t, cfg.Validate(), "routes: at least one route is required")
+
+	valid := func() *Config {
+		c := createDefaultConfig()
+		c.Routes = []RouteConfig{{Topic: "app-.*", Pipelines: []pipeline.ID{appLogs}}}
+		return c
+	}
+	require.NoError(t, valid().Validate())
+	for _, tt := range []struct {
+		modify func(*Config)
+		err    string
+	}{
+		{modify: func(c *Config) { c.Routes[0].Topic = "" }, err: "routes[0]: topic, headers or fields is required"},
+		{modify: func(c *Config) { c.Routes[0].Topic = "app-(" }, err: "routes[0]: topic: error parsing regexp"},
+		{modify: func(c *Config) { c.Routes[0].Headers = map[string]string{"tenant": "["} }, err: "routes[0]: headers: tenant: error parsing regexp"},
+		{modify: func(c *Config) { c.Routes[0].Fields = map[string]string{"level": "error"} }, err: `routes[0]: fields: "level" is not a path under body. or attributes.`},
+		{modify: func(c *Config) { c.Routes[0].Fields = map[string]string{"body.": "error"} }, err: `routes[0]: fields: "body." is not a path under body. or attributes.`},
+		{modify: func(c *Config) { c.Routes[0].Pipelines = nil }, err: "routes[0]: pipelines, index, sourcetype or source is required"},
+		{modify: func(c *Config) { c.Routes[0].Pipelines, c.Routes[0].Index = nil, "app" }, err: "routes[0]: pipelines: required without default_pipelines"},
+		{modify: func(c *Config) { c.HECMetadata.Index = "" }, err: "otel_attrs_to_hec_metadata: source, sourcetype and index are required"},
+	} {
+		c := valid()
+		tt.modify(c)
+		assert.ErrorContains(t, c.Validate(), tt.err)
+	}
+
+	c := valid()
+	c.Routes[0].Pipelines, c.Routes[0].Index = nil, "app"
+	c.DefaultPipelines = []pipeline.ID{defaultLogs}
+	require.NoError(t, c.Validate())
+}
+
+// recordContext returns the context of a request holding a Kafka record of a topic with headers.
+func recordContext(topic string, headers map[string]string) context.Context {
+	md := map[string][]string{"kafka.topic": {topic}}
+	for k, v := range headers {
+		md[k] = []string{v}
+	}
+	return client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(md)})
+}
+
+func newTestConnector(t *testing.T, cfg *Config) (connector.Logs, map[pipeline.ID]*consumertest.LogsSink) {
+	require.NoError(t, cfg.Validate())
+	sinks := map[pipeline.ID]*consumertest.LogsSink{}
+	consumers := map[pipeline.ID]consumer.Logs{}
+	for _, id := range []pipeline.ID{appLogs, securityLogs, defaultLogs} {
+		sinks[id] = new(consumertest.LogsSink)
+		consumers[id] = sinks[id]
+	}
+	c, err := NewFactory().CreateLogsToLogs(context.Background(), connectortest.NewNopSettings(componentType), cfg, connector.NewLogsRouter(consumers))
+	require.NoError(t, err)
+	require.NoError(t, c.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, c.Shutdown(context.Background())) })
+	return c, sinks
+}
+
+// bodies returns the bodies and the attributes of the log records a sink received.
+func bodies(sink *consumertest.LogsSink) (bodies []any, attrs []map[string]any) {
+	for _, ld := range sink.AllLogs() {
+		rls := ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			sls := rls.At(i).ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				lrs := sls.At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					bodies = append(bodies, lrs.At(k).Body().AsRaw())
+					attrs = append(attrs, lrs.At(k).Attributes().AsRaw())
+				}
+			}
+		}
+	}
+	return bodies, attrs
+}
+
+func testLogs(bodies ...map[string]any) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("host.name", "collector-1")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, b := range bodies {
+		lr := lrs.AppendEmpty()
+		_ = lr.Body().SetEmptyMap().FromRaw(b)
+		lr.Attributes().PutStr("kafka.topic", "ignored")
+	}
+	return ld
+}
+
+func TestRouteByTopicAndHeaders(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Routes = []RouteConfig{
+		{Topic: "audit-.*", Headers: map[string]string{"tenant": "acme|globex"}, Pipelines: []pipeline.ID{securityLogs}, Index: "security"},
+		{Topic: "app-.*", Pipelines: []pipeline.ID{appLogs}},
+		{Topic: "audit-.*", Index: "audit", SourceType: "audit:json"},
+	}
+	cfg.DefaultPipelines = []pipeline.ID{defaultLogs}
+	c, sinks := newTestConnector(t, cfg)
+
+	for _, tt := range []struct {
+		topic   string
+		headers map[string]string
+		sink    pipeline.ID
+		attrs   map[string]any
+	}{
+		{topic: "audit-logins", headers: map[string]string{"tenant": "acme"}, sink: securityLogs, attrs: map[string]any{"com.splunk.index": "security"}},
+		{topic: "app-web", sink: appLogs, attrs: map[string]any{}},
+		// The whole topic must match.
+		{topic: "my-app-web", sink: defaultLogs, attrs: map[string]any{}},
+		{topic: "audit-logins", headers: map[string]string{"tenant": "initech"}, sink: defaultLogs, attrs: map[string]any{"com.splunk.index": "audit", "com.splunk.sourcetype": "audit:json"}},
+		{topic: "audit-logins", sink: defaultLogs, attrs: map[string]any{"com.splunk.index": "audit", "com.splunk.sourcetype": "audit:json"}},
+	} {
+		for _, sink := range sinks {
+			sink.Reset()
+		}
+		require.NoError(t, c.ConsumeLogs(recordContext(tt.topic, tt.headers), testLogs(map[string]any{"message": "hello"})))
+		for id, sink := range sinks {
+			if id != tt.sink {
+				assert.Zero(t, sink.LogRecordCount(), "%s to %s", tt.topic, id)
+				continue
+			}
+			got, attrs := bodies(sink)
+			assert.Equal(t, []any{map[string]any{"message": "hello"}}, got, tt.topic)
+			tt.attrs["kafka.topic"] = "ignored"
+			assert.Equal(t, []map[string]any{tt.attrs}, attrs, tt.topic)
+		}
+	}
+}
+
+func TestRouteByFields(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Routes = []RouteConfig{
+		{Fields: map[string]string{"body.level": "ERROR|FATAL"}, Pipelines: []pipeline.ID{securityLogs}},
+		{Fields: map[string]string{"body.service.name": "web", "attributes.kafka.topic": "ignored"}, Pipelines: []pipeline.ID{appLogs}},
+	}
+	c, sinks := newTestConnector(t, cfg)
+
+	ld := testLogs(
+		map[string]any{"level": "ERROR", "id": 1},
+		map[string]any{"level": "INFO", "service": map[string]any{"name": "web"}, "id": 2},
+		map[string]any{"level": "INFO", "id": 3},
+		map[string]any{"level": "FATAL", "id": 4},
+	)
+	require.NoError(t, c.ConsumeLogs(context.Background(), ld))
+
+	got, _ := bodies(sinks[securityLogs])
+	assert.Equal(t, []any{
+		map[string]any{"level": "ERROR", "id": int64(1)},
+		map[string]any{"level": "FATAL", "id": int64(4)},
+	}, got)
+	got, _ = bodies(sinks[appLogs])
+	assert.Equal(t, []any{map[string]any{"level": "INFO", "service": map[string]any{"name": "web"}, "id": int64(2)}}, got)
+	// Without default pipelines, the unmatched record is dropped.
+	assert.Zero(t, sinks[defaultLogs].LogRecordCount())
+	// The groups keep their resource.
+	assert.Equal(t, map[string]any{"host.name": "collector-1"}, sinks[securityLogs].AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().AsRaw())
+}
+
+func TestUnknownPipeline(t *testing.T) {
+	cfg := createDefaultConfig()
+	cfg.Routes = []RouteConfig{{Topic: "app-.*", Pipelines: []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalLogs, "missing")}}}
+	router := connector.NewLogsRouter(map[pipeline.ID]consumer.Logs{appLogs: consumertest.NewNop()})
+	_, err := NewFactory().CreateLogsToLogs(context.Background(), connectortest.NewNopSettings(componentType), cfg, router)
+	assert.ErrorContains(t, err, "routes[0]: pipelines:")
+}
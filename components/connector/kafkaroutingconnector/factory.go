@@ -0,0 +1,29 @@
+package kafkaroutingconnector
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/connector"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+var componentType = component.MustNewType("kafkarouting")
+
+// NewFactory returns the factory of the kafkarouting connector.
+func NewFactory() connector.Factory {
+	return connector.NewFactory(
+		componentType,
+		func() component.Config { return createDefaultConfig() },
+		connector.WithLogsToLogs(createLogsToLogs, component.StabilityLevelAlpha),
+	)
+}
+
+func createLogsToLogs(_ context.Context, _ connector.Settings, cfg component.Config, next consumer.Logs) (connector.Logs, error) {
+	router, ok := next.(connector.LogsRouterAndConsumer)
+	if !ok {
+		return nil, errors.New("the kafkarouting connector requires a logs router")
+	}
+	return newRoutingConnector(cfg.(*Config), router)
+}
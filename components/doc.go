@@ -0,0 +1,4 @@
+// Package components holds the OpenTelemetry Collector components built into the SOC4Kafka distribution, one
+// package per component under receiver, processor, exporter, extension and connector. Each package exposes a
+// NewFactory function and is listed in distribution/builder-config.yaml.
+package components
@@ -0,0 +1,53 @@
+package schemaregistryencodingextension
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/avro"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/schemaregistry"
+)
+
+type avroDecoder struct {
+	schema *avro.Schema
+}
+
+// newAvroDecoder parses an Avro schema, with the named types of the schemas it references.
+func newAvroDecoder(ctx context.Context, registry *schemaregistry.Client, schema *schemaregistry.Schema) (*avroDecoder, error) {
+	names := avro.NewNames()
+	if err := parseAvroReferences(ctx, registry, schema.References, names, map[schemaregistry.Reference]bool{}); err != nil {
+		return nil, err
+	}
+	s, err := avro.Parse(schema.Schema, names)
+	if err != nil {
+		return nil, err
+	}
+	return &avroDecoder{schema: s}, nil
+}
+
+// parseAvroReferences parses the referenced schemas, their own references first, adding their types to names.
+// Schemas referenced several times are parsed once.
+func parseAvroReferences(ctx context.Context, registry *schemaregistry.Client, refs []schemaregistry.Reference, names *avro.Names, parsed map[schemaregistry.Reference]bool) error {
+	for _, ref := range refs {
+		key := schemaregistry.Reference{Subject: ref.Subject, Version: ref.Version}
+		if parsed[key] {
+			continue
+		}
+		parsed[key] = true
+		s, err := registry.SchemaByReference(ctx, ref)
+		if err != nil {
+			return err
+		}
+		if err := parseAvroReferences(ctx, registry, s.References, names, parsed); err != nil {
+			return err
+		}
+		if _, err := avro.Parse(s.Schema, names); err != nil {
+			return fmt.Errorf("reference %s: %w", ref.Name, err)
+		}
+	}
+	return nil
+}
+
+func (d *avroDecoder) decode(value []byte) (any, error) {
+	return d.schema.Decode(value)
+}
@@ -0,0 +1,57 @@
+package schemaregistryencodingextension
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+// Config configures the schema_registry_encoding extension.
+type Config struct {
+	// URL is the base URL of the Schema Registry, e.g. https://schema-registry:8081.
+	URL string `mapstructure:"url"`
+	// Username and Password authenticate with the registry with basic auth, the basic.auth.user.info setting of
+	// the Confluent converters.
+	Username string              `mapstructure:"username"`
+	Password configopaque.String `mapstructure:"password"`
+	// TLS configures the connection to the registry.
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+	// Timeout bounds every request to the registry.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RawFallback turns the messages that fail to decode into records with the raw message as body, instead of
+	// failing them.
+	RawFallback bool `mapstructure:"raw_fallback"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		TLS:         configtls.NewDefaultClientConfig(),
+		Timeout:     10 * time.Second,
+		RawFallback: true,
+	}
+}
+
+// Validate checks the registry URL and the timeout.
+func (cfg *Config) Validate() error {
+	if cfg.URL == "" {
+		return errors.New("url: required")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("url: %q is not an http or https URL", cfg.URL)
+	}
+	if cfg.Password != "" && cfg.Username == "" {
+		return errors.New("password: set without username")
+	}
+	if cfg.Timeout <= 0 {
+		return errors.New("timeout: must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package schemaregistryencodingextension implements the schema_registry_encoding extension, an encoding of the
+// Kafka receiver decoding messages serialized with a Confluent Schema Registry, the equivalent of the
+// value.converter and value.converter.schema.registry.url settings of Splunk Connect for Kafka. Avro, Protobuf and
+// JSON Schema messages become log records with a structured body, which the splunk_hec exporter sends as JSON
+// events.
+package schemaregistryencodingextension
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/schemaregistry"
+)
+
+var (
+	_ extension.Extension = (*schemaRegistryExtension)(nil)
+	_ plog.Unmarshaler    = (*schemaRegistryExtension)(nil)
+)
+
+const (
+	// ErrorAttribute and SchemaIDAttribute are set on the records of the messages that fail to decode, with the
+	// error and the schema ID of the message when it is in the wire format.
+	ErrorAttribute    = "schema_registry.error"
+	SchemaIDAttribute = "schema_registry.schema_id"
+
+	// The reasons of undecodable messages, the reason attribute of the undecodable records counter.
+	reasonWireFormat = "wire_format"
+	reasonSchema     = "schema"
+	reasonValue      = "value"
+)
+
+// decoder decodes the values serialized with one schema.
+type decoder interface {
+	decode(value []byte) (any, error)
+}
+
+type schemaRegistryExtension struct {
+	cfg       *Config
+	transport *http.Transport
+	registry  *schemaregistry.Client
+	// undecodable counts the messages that fail to decode, by reason.
+	undecodable metric.Int64Counter
+
+	mu       sync.Mutex
+	decoders map[int]decoder
+}
+
+func newSchemaRegistryExtension(cfg *Config, set component.TelemetrySettings) (*schemaRegistryExtension, error) {
+	undecodable, err := set.MeterProvider.Meter(scopeName).Int64Counter(
+		"schema_registry_encoding_undecodable_records",
+		metric.WithDescription("Number of Kafka messages the schema_registry_encoding extension failed to decode."),
+		metric.WithUnit("{record}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaRegistryExtension{cfg: cfg, undecodable: undecodable, decoders: map[int]decoder{}}, nil
+}
+
+func (e *schemaRegistryExtension) Start(ctx context.Context, _ component.Host) error {
+	tlsConfig, err := e.cfg.TLS.LoadTLSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("loading the TLS configuration: %w", err)
+	}
+	e.transport = &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}
+	httpClient := &http.Client{Transport: e.transport, Timeout: e.cfg.Timeout}
+	e.registry = schemaregistry.NewClient(e.cfg.URL, httpClient, e.cfg.Username, string(e.cfg.Password))
+	return nil
+}
+
+func (e *schemaRegistryExtension) Shutdown(context.Context) error {
+	if e.transport != nil {
+		e.transport.CloseIdleConnections()
+	}
+	return nil
+}
+
+// UnmarshalLogs decodes a message in the Confluent wire format into a log record. With raw_fallback, a message
+// failing to decode becomes a record with the message as body and the error as attribute instead of an error.
+func (e *schemaRegistryExtension) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+
+	id, reason, err := e.decode(buf, lr)
+	if err == nil {
+		return ld, nil
+	}
+	e.undecodable.Add(context.Background(), 1, metric.WithAttributes(attribute.String("reason", reason)))
+	if !e.cfg.RawFallback {
+		return plog.Logs{}, err
+	}
+	if utf8.Valid(buf) {
+		lr.Body().SetStr(string(buf))
+	} else {
+		lr.Body().SetEmptyBytes().FromRaw(buf)
+	}
+	lr.Attributes().PutStr(ErrorAttribute, err.Error())
+	if reason != reasonWireFormat {
+		lr.Attributes().PutInt(SchemaIDAttribute, int64(id))
+	}
+	return ld, nil
+}
+
+// decode decodes a message into the body of a record. On failure, it returns the reason and the schema ID of the
+// message, when it is in the wire format.
+func (e *schemaRegistryExtension) decode(buf []byte, lr plog.LogRecord) (int, string, error) {
+	id, value, err := schemaregistry.SplitWireFormat(buf)
+	if err != nil {
+		return 0, reasonWireFormat, err
+	}
+	dec, err := e.decoder(id)
+	if err != nil {
+		return id, reasonSchema, err
+	}
+	body, err := dec.decode(value)
+	if err == nil {
+		err = lr.Body().FromRaw(body)
+	}
+	if err != nil {
+		return id, reasonValue, fmt.Errorf("decoding with schema %d: %w", id, err)
+	}
+	return id, "", nil
+}
+
+// decoder returns the decoder of the schema id, fetching the schema on first use.
+func (e *schemaRegistryExtension) decoder(id int) (decoder, error) {
+	e.mu.Lock()
+	dec, ok := e.decoders[id]
+	e.mu.Unlock()
+	if ok {
+		return dec, nil
+	}
+
+	ctx := context.Background()
+	schema, err := e.registry.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch schema.Type {
+	case schemaregistry.Avro:
+		dec, err = newAvroDecoder(ctx, e.registry, schema)
+	case schemaregistry.Protobuf:
+		dec, err = newProtobufDecoder(ctx, e.registry, schema)
+	case schemaregistry.JSON:
+		dec, err = newJSONDecoder(schema.Schema)
+	default:
+		return nil, fmt.Errorf("schema %d: schema type %s is not supported", id, schema.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	e.mu.Lock()
+	e.decoders[id] = dec
+	e.mu.Unlock()
+	return dec, nil
+}
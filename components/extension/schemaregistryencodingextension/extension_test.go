@@ -0,0 +1,295 @@
+package schemaregistryencodingextension
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	assert.EqualError(t, cfg.Validate(), "url: required")
+
+	cfg.URL = "https://schema-registry:8081"
+	require.NoError(t, cfg.Validate())
+	for _, tt := range []struct {
+		modify func(*Config)
+		err    string
+	}{
+		{modify: func(c *Config) { c.URL = "schema-registry:8081" }, err: `url: "schema-registry:8081" is not an http or https URL`},
+		{modify: func(c *Config) { c.Password = "secret" }, err: "password: set without username"},
+		{modify: func(c *Config) { c.Timeout = 0 }, err: "timeout: must be positive"},
+	} {
+		c := *cfg
+		tt.modify(&c)
+		assert.EqualError(t, c.Validate(), tt.err)
+	}
+}
+
+// registryStandIn serves the schemas of a Schema Registry over TLS with basic auth.
+func registryStandIn(t *testing.T, schemas map[string]string) (*httptest.Server, string) {
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "sr" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s, ok := schemas[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(s))
+	}))
+	t.Cleanup(registry.Close)
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: registry.Certificate().Raw}), 0o600))
+	return registry, ca
+}
+
+func wireFormat(id uint32, value []byte) []byte {
+	return append(binary.BigEndian.AppendUint32([]byte{0}, id), value...)
+}
+
+func TestUnmarshalLogs(t *testing.T) {
+	registry, ca := registryStandIn(t, map[string]string{
+		"/schemas/ids/1": `{"schema":"{\"type\":\"record\",\"name\":\"Order\",\"namespace\":\"shop\",\"fields\":[` +
+			`{\"name\":\"id\",\"type\":\"long\"},{\"name\":\"customer\",\"type\":\"Customer\"},` +
+			`{\"name\":\"note\",\"type\":[\"null\",\"string\"]}]}",` +
+			`"references":[{"name":"shop.Customer","subject":"customer-value","version":1}]}`,
+		"/subjects/customer-value/versions/1": `{"subject":"customer-value","version":1,"id":2,` +
+			`"schema":"{\"type\":\"record\",\"name\":\"Customer\",\"namespace\":\"shop\",\"fields\":[{\"name\":\"name\",\"type\":\"string\"}]}"}`,
+	})
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.URL = registry.URL
+	cfg.Username = "sr"
+	cfg.Password = "secret"
+	cfg.TLS.CAFile = ca
+	cfg.RawFallback = false
+	require.NoError(t, cfg.Validate())
+	ext, err := factory.Create(context.Background(), extensiontest.NewNopSettings(componentType), cfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+	e := ext.(*schemaRegistryExtension)
+
+	// id 42, customer "ann", note "rush": zig-zag varints and length-prefixed strings.
+	value := []byte{84, 6, 'a', 'n', 'n', 2, 8, 'r', 'u', 's', 'h'}
+	before := time.Now()
+	ld, err := e.UnmarshalLogs(wireFormat(1, value))
+	require.NoError(t, err)
+	require.Equal(t, 1, ld.LogRecordCount())
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, map[string]any{
+		"id":       int64(42),
+		"customer": map[string]any{"name": "ann"},
+		"note":     "rush",
+	}, lr.Body().AsRaw())
+	assert.False(t, lr.ObservedTimestamp().AsTime().Before(before.Truncate(time.Microsecond)))
+
+	// The schema is fetched once.
+	registry.Close()
+	_, err = e.UnmarshalLogs(wireFormat(1, []byte{2, 0, 0}))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		msg []byte
+		err string
+	}{
+		{msg: []byte(`{"id":42}`), err: "not in the Confluent wire format: no magic byte"},
+		{msg: wireFormat(1, []byte{84, 6, 'a'}), err: "decoding with schema 1: shop.Order.customer: shop.Customer.name: unexpected end of data"},
+		{msg: wireFormat(3, nil), err: "schema 3:"},
+	} {
+		_, err := e.UnmarshalLogs(tt.msg)
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
+
+func TestUnsupportedSchemaType(t *testing.T) {
+	registry, ca := registryStandIn(t, map[string]string{"/schemas/ids/3": `{"schemaType":"XML","schema":"<schema/>"}`})
+	cfg := createDefaultConfig()
+	cfg.URL, cfg.Username, cfg.Password = registry.URL, "sr", "secret"
+	cfg.TLS.CAFile = ca
+	cfg.RawFallback = false
+	e, err := newSchemaRegistryExtension(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	_, err = e.UnmarshalLogs(wireFormat(3, nil))
+	assert.EqualError(t, err, "schema 3: schema type XML is not supported")
+
+	cfg.Password = "wrong"
+	e, err = newSchemaRegistryExtension(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	_, err = e.UnmarshalLogs(wireFormat(3, nil))
+	assert.EqualError(t, err, "schema 3: registry responded 401 Unauthorized")
+}
+
+// schemaResponse returns the registry response of a schema of a type.
+func schemaResponse(t *testing.T, schemaType, schema string, refs ...map[string]any) string {
+	data, err := json.Marshal(map[string]any{"schemaType": schemaType, "schema": schema, "references": refs})
+	require.NoError(t, err)
+	return string(data)
+}
+
+// startExtension starts the extension with the registry stand-in serving schemas.
+func startExtension(t *testing.T, schemas map[string]string, set component.TelemetrySettings, rawFallback bool) *schemaRegistryExtension {
+	registry, ca := registryStandIn(t, schemas)
+	cfg := createDefaultConfig()
+	cfg.URL, cfg.Username, cfg.Password = registry.URL, "sr", "secret"
+	cfg.TLS.CAFile = ca
+	cfg.RawFallback = rawFallback
+	e, err := newSchemaRegistryExtension(cfg, set)
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, e.Shutdown(context.Background())) })
+	return e
+}
+
+func recordBody(t *testing.T, ld plog.Logs) any {
+	require.Equal(t, 1, ld.LogRecordCount())
+	return ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().AsRaw()
+}
+
+func TestUnmarshalProtobuf(t *testing.T) {
+	e := startExtension(t, map[string]string{
+		"/schemas/ids/5": schemaResponse(t, "PROTOBUF", `
+syntax = "proto3";
+package shop;
+import "customer.proto";
+import "google/protobuf/timestamp.proto";
+message Ping {}
+message Order {
+  int64 id = 1;
+  Customer customer = 2;
+  google.protobuf.Timestamp created = 3;
+  message Line { string sku = 1; }
+}`, map[string]any{"name": "customer.proto", "subject": "customer-value", "version": 2}),
+		"/subjects/customer-value/versions/2": schemaResponse(t, "PROTOBUF", `
+syntax = "proto3";
+package shop;
+message Customer { string name = 1; }`),
+	}, componenttest.NewNopTelemetrySettings(), false)
+
+	// Message indexes [1]: the count and index as zig-zag varints.
+	order := []byte{0x02, 0x02,
+		0x08, 42, // id
+		0x12, 5, 0x0a, 3, 'a', 'n', 'n', // customer
+		0x1a, 6, 0x08, 0xc0, 0x93, 0xb2, 0xf0, 0x05, // created: 1577880000 seconds
+	}
+	ld, err := e.UnmarshalLogs(wireFormat(5, order))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int64(42),
+		"customer": map[string]any{"name": "ann"},
+		"created":  "2020-01-01T12:00:00Z",
+	}, recordBody(t, ld))
+
+	// A single 0 stands for the first message, nested messages have several indexes.
+	ld, err = e.UnmarshalLogs(wireFormat(5, []byte{0x00}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, recordBody(t, ld))
+	ld, err = e.UnmarshalLogs(wireFormat(5, []byte{0x04, 0x02, 0x00, 0x0a, 1, 'x'}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"sku": "x"}, recordBody(t, ld))
+
+	_, err = e.UnmarshalLogs(wireFormat(5, []byte{0x02, 0x06}))
+	assert.EqualError(t, err, "decoding with schema 5: invalid message indexes: schema.proto: no message at index path [3]")
+	_, err = e.UnmarshalLogs(wireFormat(5, []byte{0x02, 0x02, 0x12, 9}))
+	assert.EqualError(t, err, "decoding with schema 5: shop.Order.customer: truncated message")
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	e := startExtension(t, map[string]string{
+		"/schemas/ids/6": schemaResponse(t, "JSON", `{"type":"object","properties":{"id":{"type":"integer"}}}`),
+		"/schemas/ids/7": schemaResponse(t, "JSON", `{"type":`),
+	}, componenttest.NewNopTelemetrySettings(), false)
+
+	ld, err := e.UnmarshalLogs(wireFormat(6, []byte(`{"id":42,"price":9.5,"tags":["a",1],"note":null}`)))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":    int64(42),
+		"price": 9.5,
+		"tags":  []any{"a", int64(1)},
+		"note":  nil,
+	}, recordBody(t, ld))
+
+	_, err = e.UnmarshalLogs(wireFormat(6, []byte(`{"id":42} {}`)))
+	assert.EqualError(t, err, "decoding with schema 6: invalid JSON: data after the value")
+	_, err = e.UnmarshalLogs(wireFormat(7, []byte(`{}`)))
+	assert.EqualError(t, err, "schema 7: the JSON schema is not valid JSON")
+}
+
+func TestRawFallback(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	e := startExtension(t, map[string]string{
+		"/schemas/ids/6": schemaResponse(t, "JSON", `{"type":"object"}`),
+	}, tel.NewTelemetrySettings(), true)
+
+	for _, tt := range []struct {
+		msg   []byte
+		body  any
+		attrs map[string]any
+	}{
+		{
+			msg:   []byte(`{"id":42}`),
+			body:  `{"id":42}`,
+			attrs: map[string]any{ErrorAttribute: "not in the Confluent wire format: no magic byte"},
+		},
+		{
+			msg:   wireFormat(6, []byte{0xff}),
+			body:  wireFormat(6, []byte{0xff}),
+			attrs: map[string]any{ErrorAttribute: "decoding with schema 6: invalid character '\\xff' looking for beginning of value", SchemaIDAttribute: int64(6)},
+		},
+		{
+			msg:   wireFormat(9, nil),
+			body:  string(wireFormat(9, nil)),
+			attrs: map[string]any{ErrorAttribute: "schema 9: registry responded 404 Not Found: Schema not found", SchemaIDAttribute: int64(9)},
+		},
+	} {
+		ld, err := e.UnmarshalLogs(tt.msg)
+		require.NoError(t, err)
+		assert.Equal(t, tt.body, recordBody(t, ld))
+		lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		assert.Equal(t, tt.attrs, lr.Attributes().AsRaw())
+		assert.NotZero(t, lr.ObservedTimestamp())
+	}
+
+	m, err := tel.GetMetric("schema_registry_encoding_undecodable_records")
+	require.NoError(t, err)
+	metricdatatest.AssertEqual(t, metricdata.Metrics{
+		Name:        "schema_registry_encoding_undecodable_records",
+		Description: "Number of Kafka messages the schema_registry_encoding extension failed to decode.",
+		Unit:        "{record}",
+		Data: metricdata.Sum[int64]{
+			Temporality: metricdata.CumulativeTemporality,
+			IsMonotonic: true,
+			DataPoints: []metricdata.DataPoint[int64]{
+				{Attributes: attribute.NewSet(attribute.String("reason", reasonWireFormat)), Value: 1},
+				{Attributes: attribute.NewSet(attribute.String("reason", reasonValue)), Value: 1},
+				{Attributes: attribute.NewSet(attribute.String("reason", reasonSchema)), Value: 1},
+			},
+		},
+	}, m, metricdatatest.IgnoreTimestamp())
+}
@@ -0,0 +1,27 @@
+package schemaregistryencodingextension
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension"
+)
+
+var componentType = component.MustNewType("schema_registry_encoding")
+
+// scopeName is the instrumentation scope of the telemetry of the extension.
+const scopeName = "github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/extension/schemaregistryencodingextension"
+
+// NewFactory returns the factory of the schema_registry_encoding extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		componentType,
+		func() component.Config { return createDefaultConfig() },
+		createExtension,
+		component.StabilityLevelAlpha,
+	)
+}
+
+func createExtension(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+	return newSchemaRegistryExtension(cfg.(*Config), set.TelemetrySettings)
+}
@@ -0,0 +1,61 @@
+package schemaregistryencodingextension
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// jsonDecoder decodes the values of a JSON Schema, which are JSON documents. They are not validated against the
+// schema.
+type jsonDecoder struct{}
+
+func newJSONDecoder(schema string) (*jsonDecoder, error) {
+	if !json.Valid([]byte(schema)) {
+		return nil, errors.New("the JSON schema is not valid JSON")
+	}
+	return &jsonDecoder{}, nil
+}
+
+func (jsonDecoder) decode(value []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("invalid JSON: data after the value")
+	}
+	return numbers(v)
+}
+
+// numbers converts the numbers of a decoded document to int64 when integral, float64 otherwise.
+func numbers(v any) (any, error) {
+	var err error
+	switch v := v.(type) {
+	case json.Number:
+		if i, ierr := v.Int64(); ierr == nil {
+			return i, nil
+		}
+		f, ferr := v.Float64()
+		if ferr != nil {
+			return nil, fmt.Errorf("invalid number %s", v)
+		}
+		return f, nil
+	case map[string]any:
+		for k, item := range v {
+			if v[k], err = numbers(item); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, item := range v {
+			if v[i], err = numbers(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package schemaregistryencodingextension
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/protobuf"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/schemaregistry"
+)
+
+// schemaPath is the path of the registered schema among its references, which are named by import path.
+const schemaPath = "schema.proto"
+
+type protobufDecoder struct {
+	file *protobuf.File
+}
+
+// newProtobufDecoder compiles a Protobuf schema with the files it imports, which are its references.
+func newProtobufDecoder(ctx context.Context, registry *schemaregistry.Client, schema *schemaregistry.Schema) (*protobufDecoder, error) {
+	imports := map[string]string{}
+	if err := fetchProtobufReferences(ctx, registry, schema.References, imports); err != nil {
+		return nil, err
+	}
+	file, err := protobuf.Compile(schemaPath, schema.Schema, imports)
+	if err != nil {
+		return nil, err
+	}
+	return &protobufDecoder{file: file}, nil
+}
+
+// fetchProtobufReferences fetches the referenced schemas and their own references into imports, by import path.
+func fetchProtobufReferences(ctx context.Context, registry *schemaregistry.Client, refs []schemaregistry.Reference, imports map[string]string) error {
+	for _, ref := range refs {
+		if _, ok := imports[ref.Name]; ok {
+			continue
+		}
+		s, err := registry.SchemaByReference(ctx, ref)
+		if err != nil {
+			return err
+		}
+		imports[ref.Name] = s.Schema
+		if err := fetchProtobufReferences(ctx, registry, s.References, imports); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errMessageIndexes = errors.New("invalid message indexes")
+
+// decode decodes a value prefixed with the indexes of its message in the schema: their count and the indexes, as
+// zig-zag varints, a single 0 standing for the first message.
+func (d *protobufDecoder) decode(value []byte) (any, error) {
+	count, n := binary.Varint(value)
+	if n <= 0 || count < 0 || count > int64(len(value)) {
+		return nil, errMessageIndexes
+	}
+	value = value[n:]
+	indexes := []int{0}
+	if count > 0 {
+		indexes = make([]int, count)
+		for i := range indexes {
+			index, n := binary.Varint(value)
+			if n <= 0 {
+				return nil, errMessageIndexes
+			}
+			indexes[i] = int(index)
+			value = value[n:]
+		}
+	}
+	m, err := d.file.MessageByIndexes(indexes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errMessageIndexes, err)
+	}
+	return m.Decode(value)
+}
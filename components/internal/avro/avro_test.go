@@ -0,0 +1,140 @@
+package avro
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encoder writes Avro binary data for the tests.
+type encoder []byte
+
+func (e encoder) long(v int64) encoder {
+	return binary.AppendUvarint(e, uint64((v<<1)^(v>>63)))
+}
+
+func (e encoder) str(s string) encoder {
+	return append(e.long(int64(len(s))), s...)
+}
+
+func (e encoder) double(f float64) encoder {
+	return binary.LittleEndian.AppendUint64(e, math.Float64bits(f))
+}
+
+func (e encoder) float(f float32) encoder {
+	return binary.LittleEndian.AppendUint32(e, math.Float32bits(f))
+}
+
+const orderSchema = `{
+  "type": "record",
+  "name": "Order",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "customer", "type": {"type": "record", "name": "Customer", "fields": [
+      {"name": "name", "type": "string"},
+      {"name": "vip", "type": "boolean"}
+    ]}},
+    {"name": "referrer", "type": ["null", "Customer"]},
+    {"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID"]}},
+    {"name": "items", "type": {"type": "array", "items": "string"}},
+    {"name": "tags", "type": {"type": "map", "values": "int"}},
+    {"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 9, "scale": 2}},
+    {"name": "checksum", "type": {"type": "fixed", "name": "MD5", "size": 2}},
+    {"name": "weight", "type": "float"},
+    {"name": "price", "type": "double"},
+    {"name": "note", "type": ["null", "string"]}
+  ]
+}`
+
+func TestDecode(t *testing.T) {
+	s, err := Parse(orderSchema, nil)
+	require.NoError(t, err)
+
+	var data encoder
+	data = data.long(42)
+	data = append(data.str("ann"), 1)
+	data = append(data.long(1).str("bob"), 0)
+	data = data.long(1)
+	// Items in two blocks, the second with a negative count followed by its size in bytes.
+	data = data.long(1).str("book").long(-1).long(4).str("pen").long(0)
+	data = data.long(1).str("gift").long(-3).long(0)
+	// -12.34 is the unscaled value -1234, 0xfb2e in two's complement.
+	data = append(data.long(2), 0xfb, 0x2e)
+	data = append(data, 0xca, 0xfe)
+	data = data.float(1.5).double(9.99)
+	data = data.long(0)
+
+	v, err := s.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int64(42),
+		"customer": map[string]any{"name": "ann", "vip": true},
+		"referrer": map[string]any{"name": "bob", "vip": false},
+		"status":   "PAID",
+		"items":    []any{"book", "pen"},
+		"tags":     map[string]any{"gift": int64(-3)},
+		"amount":   "-12.34",
+		"checksum": []byte{0xca, 0xfe},
+		"weight":   1.5,
+		"price":    9.99,
+		"note":     nil,
+	}, v)
+
+	_, err = s.Decode(data[:len(data)-1])
+	assert.ErrorContains(t, err, "unexpected end of data")
+	_, err = s.Decode(append(data, 0))
+	assert.EqualError(t, err, "1 bytes after the datum")
+}
+
+func TestDecodeErrors(t *testing.T) {
+	s, err := Parse(`{"type":"enum","name":"E","symbols":["A"]}`, nil)
+	require.NoError(t, err)
+	_, err = s.Decode(encoder{}.long(3))
+	assert.EqualError(t, err, "enum E: invalid index 3")
+
+	s, err = Parse(`["null","string"]`, nil)
+	require.NoError(t, err)
+	_, err = s.Decode(encoder{}.long(2))
+	assert.EqualError(t, err, "invalid union branch 2")
+
+	s, err = Parse(`"string"`, nil)
+	require.NoError(t, err)
+	_, err = s.Decode(encoder{}.long(-5))
+	assert.EqualError(t, err, "invalid length -5")
+	_, err = s.Decode(encoder{}.long(10).str("short"))
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	// Named types defined in one schema can be referenced by another, as with Schema Registry references.
+	names := NewNames()
+	_, err := Parse(`{"type":"record","name":"com.example.Address","fields":[{"name":"city","type":"string"}]}`, names)
+	require.NoError(t, err)
+	s, err := Parse(`{"type":"record","name":"User","namespace":"com.example","fields":[{"name":"home","type":"Address"}]}`, names)
+	require.NoError(t, err)
+	v, err := s.Decode(encoder{}.str("Oslo"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"home": map[string]any{"city": "Oslo"}}, v)
+
+	s, err = Parse("long", nil)
+	require.NoError(t, err)
+	v, err = s.Decode(encoder{}.long(-64))
+	require.NoError(t, err)
+	assert.Equal(t, int64(-64), v)
+
+	for schema, msg := range map[string]string{
+		`"Missing"`: `unknown type "Missing"`,
+		`{"type":"record","name":"R","fields":[{"name":"a","type":"Nope"}]}`: `record R: field a: unknown type "Nope"`,
+		`{"type":"record","fields":[]}`:                                      "named type without name",
+		`{"name":"R"}`:                                                       "schema object without type",
+		`[{"type":"enum","name":"E","symbols":[]},{"type":"enum","name":"E","symbols":[]}]`: "type E is defined twice",
+		`{"type":"fixed","name":"F"}`: "fixed F: invalid size",
+	} {
+		_, err := Parse(schema, nil)
+		assert.EqualError(t, err, msg, schema)
+	}
+}
@@ -0,0 +1,211 @@
+package avro
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// maxCollection bounds the length of strings, bytes and blocks of arrays and maps, so that corrupted lengths fail
+// instead of allocating.
+const maxCollection = 1 << 26
+
+var errShort = errors.New("unexpected end of data")
+
+// Decode decodes one datum of s from data, which must hold nothing else. Records and maps become map[string]any,
+// arrays []any, enums their symbol, bytes and fixed []byte, decimals their text, e.g. "12.34", and unions the
+// value of their branch.
+func (s *Schema) Decode(data []byte) (any, error) {
+	d := decoder{data: data}
+	v, err := d.value(s.root)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(d.data) {
+		return nil, fmt.Errorf("%d bytes after the datum", len(d.data)-d.pos)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *decoder) value(n *node) (any, error) {
+	switch n.kind {
+	case kindNull:
+		return nil, nil
+	case kindBoolean:
+		b, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		return b != 0, nil
+	case kindInt, kindLong:
+		return d.long()
+	case kindFloat:
+		b, err := d.read(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case kindDouble:
+		b, err := d.read(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case kindBytes:
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return decimal(n, b), nil
+	case kindString:
+		b, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case kindFixed:
+		b, err := d.read(n.size)
+		if err != nil {
+			return nil, err
+		}
+		return decimal(n, append([]byte(nil), b...)), nil
+	case kindEnum:
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(n.symbols)) {
+			return nil, fmt.Errorf("enum %s: invalid index %d", n.name, i)
+		}
+		return n.symbols[i], nil
+	case kindUnion:
+		i, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(n.branches)) {
+			return nil, fmt.Errorf("invalid union branch %d", i)
+		}
+		return d.value(n.branches[i])
+	case kindRecord:
+		m := make(map[string]any, len(n.fields))
+		for _, f := range n.fields {
+			v, err := d.value(f.node)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", n.name, f.name, err)
+			}
+			m[f.name] = v
+		}
+		return m, nil
+	case kindArray:
+		items := []any{}
+		err := d.blocks(func() error {
+			v, err := d.value(n.items)
+			items = append(items, v)
+			return err
+		})
+		return items, err
+	case kindMap:
+		m := map[string]any{}
+		err := d.blocks(func() error {
+			k, err := d.bytes()
+			if err != nil {
+				return err
+			}
+			v, err := d.value(n.items)
+			m[string(k)] = v
+			return err
+		})
+		return m, err
+	}
+	return nil, fmt.Errorf("unknown schema kind %d", n.kind)
+}
+
+// blocks reads the blocks of an array or map, calling item for each item.
+func (d *decoder) blocks(item func() error) error {
+	for {
+		count, err := d.long()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return nil
+		}
+		if count < 0 {
+			// A negative count is followed by the size of the block in bytes.
+			count = -count
+			if _, err := d.long(); err != nil {
+				return err
+			}
+		}
+		if count > maxCollection {
+			return fmt.Errorf("block of %d items", count)
+		}
+		for ; count > 0; count-- {
+			if err := item(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// long reads a zig-zag encoded variable-length integer.
+func (d *decoder) long() (int64, error) {
+	u, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, errShort
+	}
+	d.pos += n
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errShort
+	}
+	d.pos++
+	return d.data[d.pos-1], nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n > len(d.data)-d.pos {
+		return nil, errShort
+	}
+	d.pos += n
+	return d.data[d.pos-n : d.pos], nil
+}
+
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > maxCollection {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	b, err := d.read(int(n))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
+// decimal returns the text of a decimal, the two's-complement big-endian unscaled value b, or b itself for other
+// types.
+func decimal(n *node, b []byte) any {
+	if n.logicalType != "decimal" {
+		return b
+	}
+	unscaled := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(b))*8))
+	}
+	return new(big.Rat).SetFrac(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.scale)), nil)).FloatString(n.scale)
+}
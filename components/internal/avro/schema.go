@@ -0,0 +1,243 @@
+// Package avro decodes Avro binary data into the values of encoding/json, for turning Avro records into JSON
+// events. See https://avro.apache.org/docs/1.11.1/specification/.
+package avro
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Schema is a parsed Avro schema.
+type Schema struct {
+	root *node
+}
+
+// kind is the type of a schema node.
+type kind int
+
+const (
+	kindNull kind = iota
+	kindBoolean
+	kindInt
+	kindLong
+	kindFloat
+	kindDouble
+	kindBytes
+	kindString
+	kindRecord
+	kindEnum
+	kindArray
+	kindMap
+	kindUnion
+	kindFixed
+)
+
+var primitives = map[string]kind{
+	"null":    kindNull,
+	"boolean": kindBoolean,
+	"int":     kindInt,
+	"long":    kindLong,
+	"float":   kindFloat,
+	"double":  kindDouble,
+	"bytes":   kindBytes,
+	"string":  kindString,
+}
+
+type node struct {
+	kind kind
+	// name is the full name of named types.
+	name string
+	// logicalType is the only logical type with a decoded representation of its own: decimal.
+	logicalType string
+	scale       int
+
+	fields   []field // records
+	symbols  []string
+	items    *node   // arrays and maps
+	branches []*node // unions
+	size     int     // fixed
+}
+
+type field struct {
+	name string
+	node *node
+}
+
+// Names holds the named types (records, enums and fixed) of parsed schemas, so that a schema can reference types
+// defined in other schemas, e.g. Schema Registry references.
+type Names struct {
+	types map[string]*node
+}
+
+// NewNames returns an empty set of named types.
+func NewNames() *Names {
+	return &Names{types: map[string]*node{}}
+}
+
+// Parse parses a schema in its JSON form. Named types it defines are added to names, which may be nil.
+func Parse(schema string, names *Names) (*Schema, error) {
+	if names == nil {
+		names = NewNames()
+	}
+	var v any
+	if err := json.Unmarshal([]byte(schema), &v); err != nil {
+		// A schema may also be the bare name of a type.
+		v = strings.TrimSpace(schema)
+	}
+	root, err := names.parse(v, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+func (n *Names) parse(v any, namespace string) (*node, error) {
+	switch t := v.(type) {
+	case string:
+		if k, ok := primitives[t]; ok {
+			return &node{kind: k}, nil
+		}
+		if named, ok := n.types[fullName(t, namespace)]; ok {
+			return named, nil
+		}
+		if named, ok := n.types[t]; ok {
+			return named, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", t)
+	case []any:
+		u := &node{kind: kindUnion}
+		for _, branch := range t {
+			b, err := n.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			u.branches = append(u.branches, b)
+		}
+		return u, nil
+	case map[string]any:
+		return n.parseObject(t, namespace)
+	default:
+		return nil, fmt.Errorf("invalid schema %v", v)
+	}
+}
+
+func (n *Names) parseObject(m map[string]any, namespace string) (*node, error) {
+	typ, ok := m["type"]
+	if !ok {
+		return nil, errors.New("schema object without type")
+	}
+	name, _ := typ.(string)
+	switch name {
+	case "record", "error":
+		nd, ns, err := n.define(m, namespace, kindRecord)
+		if err != nil {
+			return nil, err
+		}
+		fields, _ := m["fields"].([]any)
+		for _, f := range fields {
+			fm, ok := f.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("record %s: invalid field %v", nd.name, f)
+			}
+			fname, _ := fm["name"].(string)
+			if fname == "" {
+				return nil, fmt.Errorf("record %s: field without name", nd.name)
+			}
+			fnode, err := n.parse(fm["type"], ns)
+			if err != nil {
+				return nil, fmt.Errorf("record %s: field %s: %w", nd.name, fname, err)
+			}
+			nd.fields = append(nd.fields, field{name: fname, node: fnode})
+		}
+		return nd, nil
+	case "enum":
+		nd, _, err := n.define(m, namespace, kindEnum)
+		if err != nil {
+			return nil, err
+		}
+		symbols, _ := m["symbols"].([]any)
+		for _, s := range symbols {
+			symbol, ok := s.(string)
+			if !ok {
+				return nil, fmt.Errorf("enum %s: invalid symbol %v", nd.name, s)
+			}
+			nd.symbols = append(nd.symbols, symbol)
+		}
+		return nd, nil
+	case "fixed":
+		nd, _, err := n.define(m, namespace, kindFixed)
+		if err != nil {
+			return nil, err
+		}
+		size, ok := m["size"].(float64)
+		if !ok || size < 0 {
+			return nil, fmt.Errorf("fixed %s: invalid size", nd.name)
+		}
+		nd.size = int(size)
+		n.setDecimal(nd, m)
+		return nd, nil
+	case "array", "map":
+		key := "items"
+		k := kindArray
+		if name == "map" {
+			key, k = "values", kindMap
+		}
+		items, err := n.parse(m[key], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return &node{kind: k, items: items}, nil
+	}
+	// A primitive or a reference with attributes, e.g. {"type": "bytes", "logicalType": "decimal"}.
+	nd, err := n.parse(typ, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if nd.kind == kindBytes {
+		copied := *nd
+		n.setDecimal(&copied, m)
+		return &copied, nil
+	}
+	return nd, nil
+}
+
+// define registers the named type of m and returns it with the namespace of its own fields.
+func (n *Names) define(m map[string]any, namespace string, k kind) (*node, string, error) {
+	name, _ := m["name"].(string)
+	if name == "" {
+		return nil, "", errors.New("named type without name")
+	}
+	if ns, ok := m["namespace"].(string); ok && !strings.Contains(name, ".") {
+		namespace = ns
+	}
+	full := fullName(name, namespace)
+	if _, ok := n.types[full]; ok {
+		return nil, "", fmt.Errorf("type %s is defined twice", full)
+	}
+	nd := &node{kind: k, name: full}
+	n.types[full] = nd
+	ns := ""
+	if i := strings.LastIndexByte(full, '.'); i >= 0 {
+		ns = full[:i]
+	}
+	return nd, ns, nil
+}
+
+func (n *Names) setDecimal(nd *node, m map[string]any) {
+	if m["logicalType"] != "decimal" {
+		return
+	}
+	nd.logicalType = "decimal"
+	if scale, ok := m["scale"].(float64); ok {
+		nd.scale = int(scale)
+	}
+}
+
+func fullName(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
@@ -0,0 +1,140 @@
+// Package kafkarecord reads the metadata of the Kafka record a batch of telemetry was decoded from.
+//
+// The Kafka receiver calls the next consumer once per record, with the record metadata in the client metadata of
+// the context (see go.opentelemetry.io/collector/client). The upstream receiver reports the topic, partition and
+// offset; the distribution patches it to also report the key, base64-encoded, and the timestamp with its type (see
+// distribution/patches/kafkareceiver-record-metadata.patch). Processors of the distribution read it with FromContext;
+// they must run before anything that merges requests, since merged requests no longer carry the metadata.
+package kafkarecord
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/client"
+)
+
+// Client metadata keys holding the record metadata.
+const (
+	TopicKey         = "kafka.topic"
+	PartitionKey     = "kafka.partition"
+	OffsetKey        = "kafka.offset"
+	KeyKey           = "kafka.key" // base64-encoded
+	TimestampKey     = "kafka.timestamp"
+	TimestampTypeKey = "kafka.timestamp_type"
+)
+
+// Timestamp types of a record, as named by Kafka.
+const (
+	CreateTime    = "CreateTime"
+	LogAppendTime = "LogAppendTime"
+)
+
+// Record is the metadata of a Kafka record. Fields missing from the client metadata hold their zero value and the
+// matching Has flag is false.
+type Record struct {
+	Topic string
+
+	Partition    int64
+	HasPartition bool
+
+	Offset    int64
+	HasOffset bool
+
+	Key    []byte
+	HasKey bool
+
+	// Timestamp is the record timestamp, with the millisecond precision of Kafka.
+	Timestamp time.Time
+	// TimestampType is CreateTime or LogAppendTime, or empty when the receiver did not report it.
+	TimestampType string
+}
+
+// HasTimestamp reports whether the record timestamp is known.
+func (r Record) HasTimestamp() bool {
+	return !r.Timestamp.IsZero()
+}
+
+// FromContext returns the metadata of the record stored in the client metadata of ctx. Values that do not parse
+// are treated as missing.
+func FromContext(ctx context.Context) Record {
+	md := client.FromContext(ctx).Metadata
+	get := func(key string) (string, bool) {
+		values := md.Get(key)
+		if len(values) == 0 {
+			return "", false
+		}
+		return values[0], true
+	}
+
+	var r Record
+	r.Topic, _ = get(TopicKey)
+	if v, ok := get(PartitionKey); ok {
+		r.Partition, r.HasPartition = parseInt(v)
+	}
+	if v, ok := get(OffsetKey); ok {
+		r.Offset, r.HasOffset = parseInt(v)
+	}
+	if v, ok := get(KeyKey); ok {
+		if key, err := base64.StdEncoding.DecodeString(v); err == nil {
+			r.Key, r.HasKey = key, true
+		}
+	}
+	if v, ok := get(TimestampKey); ok {
+		// Kafka timestamps are milliseconds since the epoch; negative values mean no timestamp.
+		if ms, ok := parseInt(v); ok && ms >= 0 {
+			r.Timestamp = time.UnixMilli(ms).UTC()
+		}
+	}
+	r.TimestampType, _ = get(TimestampTypeKey)
+	return r
+}
+
+// Header returns the first value of the record header name, which the Kafka receiver stores in the client
+// metadata of ctx next to the record metadata.
+func Header(ctx context.Context, name string) (string, bool) {
+	values := client.FromContext(ctx).Metadata.Get(name)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// Metadata returns client metadata holding r, the inverse of FromContext. It is used by tests and by components
+// replaying records.
+func (r Record) Metadata() client.Metadata {
+	m := map[string][]string{}
+	if r.Topic != "" {
+		m[TopicKey] = []string{r.Topic}
+	}
+	if r.HasPartition {
+		m[PartitionKey] = []string{strconv.FormatInt(r.Partition, 10)}
+	}
+	if r.HasOffset {
+		m[OffsetKey] = []string{strconv.FormatInt(r.Offset, 10)}
+	}
+	if r.HasKey {
+		m[KeyKey] = []string{base64.StdEncoding.EncodeToString(r.Key)}
+	}
+	if r.HasTimestamp() {
+		m[TimestampKey] = []string{strconv.FormatInt(r.Timestamp.UnixMilli(), 10)}
+	}
+	if r.TimestampType != "" {
+		m[TimestampTypeKey] = []string{r.TimestampType}
+	}
+	return client.NewMetadata(m)
+}
+
+// NewContext returns a copy of ctx whose client metadata holds r, replacing any previous metadata.
+func NewContext(ctx context.Context, r Record) context.Context {
+	info := client.FromContext(ctx)
+	info.Metadata = r.Metadata()
+	return client.NewContext(ctx, info)
+}
+
+func parseInt(s string) (int64, bool) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
@@ -0,0 +1,61 @@
+package kafkarecord
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/client"
+)
+
+func TestFromContext(t *testing.T) {
+	r := Record{
+		Topic:         "orders",
+		Partition:     3,
+		HasPartition:  true,
+		Offset:        1042,
+		HasOffset:     true,
+		Key:           []byte("customer-7"),
+		HasKey:        true,
+		Timestamp:     time.UnixMilli(1_760_000_000_123).UTC(),
+		TimestampType: CreateTime,
+	}
+	assert.Equal(t, r, FromContext(NewContext(context.Background(), r)))
+	assert.Equal(t, Record{}, FromContext(context.Background()))
+
+	ctx := client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(map[string][]string{
+		TopicKey:     {"orders"},
+		PartitionKey: {"three"},
+		OffsetKey:    {"7"},
+		KeyKey:       {"not base64"},
+		TimestampKey: {"-1"},
+		"env":        {"prod", "staging"},
+	})})
+	got := FromContext(ctx)
+	assert.Equal(t, Record{Topic: "orders", Offset: 7, HasOffset: true}, got)
+	assert.False(t, got.HasTimestamp())
+
+	// The receiver reports the key base64-encoded, so binary keys are kept as is.
+	ctx = client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(map[string][]string{
+		KeyKey:           {"/wA="},
+		TimestampKey:     {"1760000000123"},
+		TimestampTypeKey: {LogAppendTime},
+	})})
+	assert.Equal(t, Record{
+		Key:           []byte{0xff, 0x00},
+		HasKey:        true,
+		Timestamp:     time.UnixMilli(1_760_000_000_123).UTC(),
+		TimestampType: LogAppendTime,
+	}, FromContext(ctx))
+
+	ctx = client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(map[string][]string{
+		TopicKey: {"orders"},
+		"env":    {"prod", "staging"},
+	})})
+	env, ok := Header(ctx, "env")
+	assert.True(t, ok)
+	assert.Equal(t, "prod", env)
+	_, ok = Header(ctx, "region")
+	assert.False(t, ok)
+}
@@ -0,0 +1,132 @@
+package protobuf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compile parses the source of a .proto file and of the files it imports, transitively, and resolves the type names
+// of their fields. imports maps import paths to sources; the well-known types under google/protobuf/ are built in.
+func Compile(path, source string, imports map[string]string) (*File, error) {
+	c := &compiler{sources: imports, files: map[string]*File{}, types: map[string]any{}}
+	file, err := c.load(path, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range c.files {
+		if err := c.resolveAll(f.Messages); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+type compiler struct {
+	sources map[string]string
+	files   map[string]*File
+	// types are the messages and enums of all the files by full name.
+	types map[string]any
+}
+
+func (c *compiler) load(path, source string, loading []string) (*File, error) {
+	if f, ok := c.files[path]; ok {
+		return f, nil
+	}
+	for _, p := range loading {
+		if p == path {
+			return nil, fmt.Errorf("import cycle: %s -> %s", strings.Join(loading, " -> "), path)
+		}
+	}
+	f, err := ParseFile(path, source)
+	if err != nil {
+		return nil, err
+	}
+	loading = append(loading, path)
+	for _, imp := range f.Imports {
+		src, ok := c.sources[imp]
+		if !ok {
+			if src, ok = wellKnownSources[imp]; !ok {
+				return nil, fmt.Errorf("%s: import %q is not available", path, imp)
+			}
+		}
+		if _, err := c.load(imp, src, loading); err != nil {
+			return nil, err
+		}
+	}
+	c.files[path] = f
+	c.register(f.Messages, f.Enums)
+	return f, nil
+}
+
+func (c *compiler) register(messages []*Message, enums []*Enum) {
+	for _, m := range messages {
+		c.types[m.FullName] = m
+		c.register(m.Messages, m.Enums)
+	}
+	for _, e := range enums {
+		c.types[e.FullName] = e
+	}
+}
+
+func (c *compiler) resolveAll(messages []*Message) error {
+	for _, m := range messages {
+		m.byNumber = make(map[int]*Field, len(m.Fields))
+		for _, f := range m.Fields {
+			m.byNumber[f.Number] = f
+			if f.TypeName == "" || f.Kind != "" {
+				continue
+			}
+			switch t := c.lookup(m.FullName, f.TypeName).(type) {
+			case *Message:
+				f.Kind, f.Message = "message", t
+			case *Enum:
+				f.Kind, f.Enum = "enum", t
+			default:
+				return fmt.Errorf("message %s: field %s: type %s is not defined", m.FullName, f.Name, f.TypeName)
+			}
+		}
+		if err := c.resolveAll(m.Messages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup resolves a type name like protoc: a leading dot makes it fully qualified, otherwise it is searched in the
+// scope of the message, then in the enclosing scopes up to the root.
+func (c *compiler) lookup(scope, name string) any {
+	if strings.HasPrefix(name, ".") {
+		return c.types[name[1:]]
+	}
+	for {
+		if t, ok := c.types[qualify(scope, name)]; ok {
+			return t
+		}
+		if scope == "" {
+			return nil
+		}
+		if i := strings.LastIndexByte(scope, '.'); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// MessageByIndexes returns the message at a path of indexes, the first into the messages of the file and the next
+// ones into the nested messages, as in the Confluent wire format.
+func (f *File) MessageByIndexes(indexes []int) (*Message, error) {
+	messages := f.Messages
+	var m *Message
+	for _, i := range indexes {
+		if i < 0 || i >= len(messages) {
+			return nil, fmt.Errorf("%s: no message at index path %v", f.Path, indexes)
+		}
+		m = messages[i]
+		messages = m.Messages
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%s: empty index path", f.Path)
+	}
+	return m, nil
+}
@@ -0,0 +1,254 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var errTruncated = errors.New("truncated message")
+
+// Wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Decode decodes a message into a map by field name. Fields absent from the data are omitted, like protojson does,
+// except in map entries. Enums decode to their names, 64-bit integers not fitting an int64 to strings, bytes to
+// []byte and the well-known types to their JSON representation, e.g. a google.protobuf.Timestamp to an RFC 3339
+// string. Unknown fields are skipped.
+func (m *Message) Decode(data []byte) (map[string]any, error) {
+	out := map[string]any{}
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		data = data[n:]
+		number, wire := int(tag>>3), int(tag&7)
+		f := m.byNumber[number]
+		if f == nil {
+			var err error
+			if data, err = skip(data, wire); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var err error
+		if data, err = f.decode(data, wire, out); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", m.FullName, f.Name, err)
+		}
+	}
+	if m.MapEntry {
+		for _, f := range m.Fields {
+			if _, ok := out[f.Name]; !ok {
+				out[f.Name] = f.zero()
+			}
+		}
+	}
+	return out, nil
+}
+
+func skip(data []byte, wire int) ([]byte, error) {
+	switch wire {
+	case wireVarint:
+		_, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errTruncated
+		}
+		return data[n:], nil
+	case wireFixed64:
+		if len(data) < 8 {
+			return nil, errTruncated
+		}
+		return data[8:], nil
+	case wireFixed32:
+		if len(data) < 4 {
+			return nil, errTruncated
+		}
+		return data[4:], nil
+	case wireBytes:
+		_, rest, err := lengthDelimited(data)
+		return rest, err
+	default:
+		return nil, fmt.Errorf("wire type %d is not supported", wire)
+	}
+}
+
+func lengthDelimited(data []byte) (value, rest []byte, err error) {
+	l, n := binary.Uvarint(data)
+	if n <= 0 || l > uint64(len(data)-n) {
+		return nil, nil, errTruncated
+	}
+	return data[n : n+int(l)], data[n+int(l):], nil
+}
+
+// wireType returns the wire type of the values of the field when not packed.
+func (f *Field) wireType() int {
+	switch f.Kind {
+	case "double", "fixed64", "sfixed64":
+		return wireFixed64
+	case "float", "fixed32", "sfixed32":
+		return wireFixed32
+	case "string", "bytes", "message":
+		return wireBytes
+	default:
+		return wireVarint
+	}
+}
+
+func (f *Field) decode(data []byte, wire int, out map[string]any) ([]byte, error) {
+	expected := f.wireType()
+	if wire == wireBytes && expected != wireBytes && f.Repeated {
+		packed, rest, err := lengthDelimited(data)
+		if err != nil {
+			return nil, err
+		}
+		values, _ := out[f.Name].([]any)
+		for len(packed) > 0 {
+			var v any
+			if v, packed, err = f.value(packed, expected); err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		out[f.Name] = values
+		return rest, nil
+	}
+	if wire != expected {
+		return nil, fmt.Errorf("wire type %d does not match type %s", wire, f.Kind)
+	}
+	v, rest, err := f.value(data, wire)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case f.Message != nil && f.Message.MapEntry:
+		entries, _ := out[f.Name].(map[string]any)
+		if entries == nil {
+			entries = map[string]any{}
+		}
+		entry := v.(map[string]any)
+		entries[fmt.Sprint(entry["key"])] = entry["value"]
+		out[f.Name] = entries
+	case f.Repeated:
+		values, _ := out[f.Name].([]any)
+		out[f.Name] = append(values, v)
+	default:
+		out[f.Name] = v
+	}
+	return rest, nil
+}
+
+func (f *Field) value(data []byte, wire int) (any, []byte, error) {
+	switch wire {
+	case wireVarint:
+		u, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, nil, errTruncated
+		}
+		return f.varint(u), data[n:], nil
+	case wireFixed64:
+		if len(data) < 8 {
+			return nil, nil, errTruncated
+		}
+		u := binary.LittleEndian.Uint64(data)
+		switch f.Kind {
+		case "double":
+			return math.Float64frombits(u), data[8:], nil
+		case "sfixed64":
+			return int64(u), data[8:], nil
+		default:
+			return unsigned(u), data[8:], nil
+		}
+	case wireFixed32:
+		if len(data) < 4 {
+			return nil, nil, errTruncated
+		}
+		u := binary.LittleEndian.Uint32(data)
+		switch f.Kind {
+		case "float":
+			return float64(math.Float32frombits(u)), data[4:], nil
+		case "sfixed32":
+			return int64(int32(u)), data[4:], nil
+		default:
+			return int64(u), data[4:], nil
+		}
+	default:
+		b, rest, err := lengthDelimited(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch f.Kind {
+		case "string":
+			return string(b), rest, nil
+		case "bytes":
+			return append([]byte(nil), b...), rest, nil
+		default:
+			m, err := f.Message.Decode(b)
+			if err != nil {
+				return nil, nil, err
+			}
+			return wellKnown(f.Message, m), rest, nil
+		}
+	}
+}
+
+func (f *Field) varint(u uint64) any {
+	switch f.Kind {
+	case "int32":
+		return int64(int32(u))
+	case "uint32":
+		return int64(uint32(u))
+	case "sint32":
+		return int64(int32(uint32(u>>1) ^ -uint32(u&1)))
+	case "sint64":
+		return int64(u>>1) ^ -int64(u&1)
+	case "uint64":
+		return unsigned(u)
+	case "bool":
+		return u != 0
+	case "enum":
+		if name, ok := f.Enum.Values[int32(u)]; ok {
+			return name
+		}
+		return int64(int32(u))
+	default:
+		return int64(u)
+	}
+}
+
+func unsigned(u uint64) any {
+	if u > math.MaxInt64 {
+		return strconv.FormatUint(u, 10)
+	}
+	return int64(u)
+}
+
+// zero returns the default value of a field.
+func (f *Field) zero() any {
+	switch f.Kind {
+	case "string":
+		return ""
+	case "bytes":
+		return []byte{}
+	case "bool":
+		return false
+	case "double", "float":
+		return float64(0)
+	case "enum":
+		if name, ok := f.Enum.Values[0]; ok {
+			return name
+		}
+		return int64(0)
+	case "message":
+		return wellKnown(f.Message, map[string]any{})
+	default:
+		return int64(0)
+	}
+}
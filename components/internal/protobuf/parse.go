@@ -0,0 +1,503 @@
+// Package protobuf decodes Protocol Buffers messages into the values of encoding/json, from the .proto sources of
+// their schema, for turning Protobuf records into JSON events. It supports the proto2 and proto3 definitions of
+// messages and enums; options, services and extensions are skipped.
+package protobuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// File is a parsed .proto file.
+type File struct {
+	Path     string
+	Package  string
+	Imports  []string
+	Messages []*Message
+	Enums    []*Enum
+}
+
+// Message is a message type.
+type Message struct {
+	FullName string
+	Fields   []*Field
+	Messages []*Message
+	Enums    []*Enum
+	// MapEntry marks the messages synthesized for map fields, whose key is field 1 and value field 2.
+	MapEntry bool
+
+	byNumber map[int]*Field
+}
+
+// Enum is an enum type.
+type Enum struct {
+	FullName string
+	Values   map[int32]string
+}
+
+// Field is a field of a message.
+type Field struct {
+	Name     string
+	Number   int
+	Repeated bool
+	// Kind is the scalar type name, e.g. int32 or string, or "message" or "enum" once the type is resolved.
+	Kind string
+	// TypeName is the type name as written in the source, for message and enum fields.
+	TypeName string
+	Message  *Message
+	Enum     *Enum
+}
+
+var scalars = map[string]bool{
+	"double": true, "float": true, "int32": true, "int64": true, "uint32": true, "uint64": true, "sint32": true,
+	"sint64": true, "fixed32": true, "fixed64": true, "sfixed32": true, "sfixed64": true, "bool": true,
+	"string": true, "bytes": true,
+}
+
+// ParseFile parses the source of a .proto file. Type names are resolved by Compile.
+func ParseFile(path, source string) (*File, error) {
+	toks, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	p := &parser{toks: toks, file: &File{Path: path}}
+	if err := p.parseFile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p.file, nil
+}
+
+type token struct {
+	text string
+	line int
+	// str marks string literals, whose text is unquoted.
+	str bool
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			text := src[i+1 : j]
+			if unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(text, `"`, `\"`) + `"`); err == nil {
+				text = unquoted
+			}
+			toks = append(toks, token{text: text, line: line, str: true})
+			i = j + 1
+		case isIdent(c) || c == '.' && i+1 < len(src) && isIdent(src[i+1]):
+			j := i
+			for j < len(src) && (isIdent(src[j]) || src[j] == '.' || c >= '0' && c <= '9' && (src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E')) {
+				j++
+			}
+			toks = append(toks, token{text: src[i:j], line: line})
+			i = j
+		default:
+			toks = append(toks, token{text: string(c), line: line})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+type parser struct {
+	toks []token
+	pos  int
+	file *File
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos].text
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.toks) {
+		return token{}, fmt.Errorf("unexpected end of file")
+	}
+	p.pos++
+	return p.toks[p.pos-1], nil
+}
+
+func (p *parser) expect(text string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.text != text || t.str {
+		return fmt.Errorf("line %d: expected %q, found %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	t, err := p.next()
+	if err != nil {
+		return "", err
+	}
+	if t.str || t.text == "" || !isIdent(t.text[0]) && t.text[0] != '.' {
+		return "", fmt.Errorf("line %d: expected a name, found %q", t.line, t.text)
+	}
+	return t.text, nil
+}
+
+// skipStatement skips the tokens up to the end of a statement, a semicolon or a block, outside brackets.
+func (p *parser) skipStatement() error {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.str {
+			continue
+		}
+		switch t.text {
+		case "{", "[", "(", "<":
+			depth++
+		case "}", "]", ")", ">":
+			depth--
+			if depth == 0 && t.text == "}" && p.peek() != ";" {
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) parseFile() error {
+	for p.pos < len(p.toks) {
+		t, _ := p.next()
+		switch t.text {
+		case ";":
+		case "syntax", "edition":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "package":
+			name, err := p.name()
+			if err != nil {
+				return err
+			}
+			p.file.Package = name
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "import":
+			if p.peek() == "public" || p.peek() == "weak" {
+				p.pos++
+			}
+			path, err := p.next()
+			if err != nil {
+				return err
+			}
+			if !path.str {
+				return fmt.Errorf("line %d: expected an import path, found %q", path.line, path.text)
+			}
+			p.file.Imports = append(p.file.Imports, path.text)
+			if err := p.expect(";"); err != nil {
+				return err
+			}
+		case "message":
+			m, err := p.parseMessage(p.file.Package)
+			if err != nil {
+				return err
+			}
+			p.file.Messages = append(p.file.Messages, m)
+		case "enum":
+			e, err := p.parseEnum(p.file.Package)
+			if err != nil {
+				return err
+			}
+			p.file.Enums = append(p.file.Enums, e)
+		case "option", "service", "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+		}
+	}
+	return nil
+}
+
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func (p *parser) parseMessage(scope string) (*Message, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{FullName: qualify(scope, name)}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.parseMessageBody(m, false); err != nil {
+		return nil, fmt.Errorf("message %s: %w", m.FullName, err)
+	}
+	return m, nil
+}
+
+// parseMessageBody parses the body of a message, or of a oneof of it, up to its closing brace.
+func (p *parser) parseMessageBody(m *Message, oneof bool) error {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch t.text {
+		case "}":
+			return nil
+		case ";":
+		case "message":
+			if oneof {
+				return fmt.Errorf("line %d: message in a oneof", t.line)
+			}
+			nested, err := p.parseMessage(m.FullName)
+			if err != nil {
+				return err
+			}
+			m.Messages = append(m.Messages, nested)
+		case "enum":
+			e, err := p.parseEnum(m.FullName)
+			if err != nil {
+				return err
+			}
+			m.Enums = append(m.Enums, e)
+		case "oneof":
+			if _, err := p.name(); err != nil {
+				return err
+			}
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(m, true); err != nil {
+				return err
+			}
+		case "option", "reserved", "extensions", "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "map":
+			if err := p.parseMapField(m); err != nil {
+				return err
+			}
+		default:
+			p.pos--
+			if err := p.parseField(m); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *parser) parseField(m *Message) error {
+	f := &Field{}
+	switch p.peek() {
+	case "repeated":
+		f.Repeated = true
+		p.pos++
+	case "optional", "required":
+		p.pos++
+	}
+	typ, err := p.name()
+	if err != nil {
+		return err
+	}
+	if typ == "group" {
+		return fmt.Errorf("line %d: groups are not supported", p.toks[p.pos-1].line)
+	}
+	if scalars[typ] {
+		f.Kind = typ
+	} else {
+		f.TypeName = typ
+	}
+	if f.Name, err = p.name(); err != nil {
+		return err
+	}
+	if f.Number, err = p.fieldNumber(); err != nil {
+		return err
+	}
+	m.Fields = append(m.Fields, f)
+	return p.endField()
+}
+
+func (p *parser) parseMapField(m *Message) error {
+	if err := p.expect("<"); err != nil {
+		return err
+	}
+	key, err := p.name()
+	if err != nil {
+		return err
+	}
+	if err := p.expect(","); err != nil {
+		return err
+	}
+	value, err := p.name()
+	if err != nil {
+		return err
+	}
+	if err := p.expect(">"); err != nil {
+		return err
+	}
+	f := &Field{Repeated: true}
+	if f.Name, err = p.name(); err != nil {
+		return err
+	}
+	if f.Number, err = p.fieldNumber(); err != nil {
+		return err
+	}
+	// Like protoc, a map is a repeated field of a nested entry message.
+	entry := &Message{FullName: m.FullName + "." + mapEntryName(f.Name), MapEntry: true}
+	entry.Fields = append(entry.Fields, &Field{Name: "key", Number: 1, Kind: key})
+	valueField := &Field{Name: "value", Number: 2}
+	if scalars[value] {
+		valueField.Kind = value
+	} else {
+		valueField.TypeName = value
+	}
+	entry.Fields = append(entry.Fields, valueField)
+	m.Messages = append(m.Messages, entry)
+	f.Kind, f.Message = "message", entry
+	m.Fields = append(m.Fields, f)
+	return p.endField()
+}
+
+// mapEntryName returns the name protoc gives to the entry message of a map field, e.g. LabelsEntry for labels.
+func mapEntryName(field string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range field {
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		b.WriteRune(c)
+	}
+	return b.String() + "Entry"
+}
+
+func (p *parser) fieldNumber() (int, error) {
+	if err := p.expect("="); err != nil {
+		return 0, err
+	}
+	t, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(t.text, 0, 32)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("line %d: invalid field number %q", t.line, t.text)
+	}
+	return int(n), nil
+}
+
+// endField skips the options of a field and its semicolon.
+func (p *parser) endField() error {
+	if p.peek() == "[" {
+		if err := p.skipStatement(); err != nil {
+			return err
+		}
+		return nil
+	}
+	return p.expect(";")
+}
+
+func (p *parser) parseEnum(scope string) (*Enum, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	e := &Enum{FullName: qualify(scope, name), Values: map[int32]string{}}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch t.text {
+		case "}":
+			return e, nil
+		case ";":
+		case "option", "reserved":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			v, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			text := v.text
+			if text == "-" {
+				if v, err = p.next(); err != nil {
+					return nil, err
+				}
+				text = "-" + v.text
+			}
+			n, err := strconv.ParseInt(text, 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("enum %s: line %d: invalid value %q", e.FullName, v.line, text)
+			}
+			// With allow_alias, the first name of a value is used.
+			if _, ok := e.Values[int32(n)]; !ok {
+				e.Values[int32(n)] = t.text
+			}
+			if err := p.endField(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commonProto = `
+syntax = "proto3";
+package com.example.common;
+
+enum Level {
+  LEVEL_UNSPECIFIED = 0;
+  INFO = 1;
+  ERROR = 2;
+}
+
+message Host { string name = 1; }
+`
+
+const orderProto = `
+syntax = "proto3";
+package com.example;
+
+import "common.proto";
+import "google/protobuf/timestamp.proto";
+
+option java_package = "com.example.proto";
+
+/* The first message. */
+message Other { int32 id = 1; }
+
+message Order {
+  message Line {
+    string sku = 1;
+    uint32 quantity = 2 [deprecated = true];
+  }
+  reserved 20 to 30;
+
+  int64 id = 1;
+  string customer = 2;
+  repeated Line lines = 3;
+  common.Level level = 4;
+  map<string, double> prices = 5;
+  repeated sint32 deltas = 6;
+  bool paid = 7;
+  bytes signature = 8;
+  .com.example.common.Host host = 9;
+  google.protobuf.Timestamp created = 10;
+  oneof payment {
+    string card = 11;
+    fixed64 account = 12;
+  }
+  float ratio = 13;
+  uint64 big = 14;
+}
+`
+
+// encoder builds wire format data.
+type encoder []byte
+
+func (e encoder) tag(number, wire int) encoder {
+	return binary.AppendUvarint(e, uint64(number<<3|wire))
+}
+
+func (e encoder) varint(number int, v uint64) encoder {
+	return binary.AppendUvarint(e.tag(number, wireVarint), v)
+}
+
+func (e encoder) bytes(number int, b []byte) encoder {
+	return append(binary.AppendUvarint(e.tag(number, wireBytes), uint64(len(b))), b...)
+}
+
+func (e encoder) fixed64(number int, v uint64) encoder {
+	return binary.LittleEndian.AppendUint64(e.tag(number, wireFixed64), v)
+}
+
+func (e encoder) fixed32(number int, v uint32) encoder {
+	return binary.LittleEndian.AppendUint32(e.tag(number, wireFixed32), v)
+}
+
+func compileOrder(t *testing.T) *File {
+	file, err := Compile("order.proto", orderProto, map[string]string{"common.proto": commonProto})
+	require.NoError(t, err)
+	return file
+}
+
+func TestDecode(t *testing.T) {
+	order, err := compileOrder(t).MessageByIndexes([]int{1})
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.Order", order.FullName)
+
+	line := encoder{}.bytes(1, []byte("A-1")).varint(2, 3)
+	entry := encoder{}.bytes(1, []byte("A-1")).fixed64(2, math.Float64bits(9.5))
+	// Packed zig-zag values -1, 2.
+	deltas := encoder{}
+	deltas = binary.AppendUvarint(deltas, 1)
+	deltas = binary.AppendUvarint(deltas, 4)
+	data := encoder{}.
+		varint(1, 42).
+		bytes(2, []byte("alice")).
+		bytes(3, line).
+		bytes(3, encoder{}.bytes(1, []byte("B-2"))).
+		varint(4, 2).
+		bytes(5, entry).
+		bytes(6, deltas).
+		varint(7, 1).
+		bytes(8, []byte{0xde, 0xad}).
+		bytes(9, encoder{}.bytes(1, []byte("web-1"))).
+		bytes(10, encoder{}.varint(1, 1577880000).varint(2, 500000000)).
+		bytes(99, []byte("unknown")).
+		bytes(11, []byte("visa")).
+		fixed32(13, math.Float32bits(0.5)).
+		varint(14, math.MaxUint64)
+
+	got, err := order.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":       int64(42),
+		"customer": "alice",
+		"lines": []any{
+			map[string]any{"sku": "A-1", "quantity": int64(3)},
+			map[string]any{"sku": "B-2"},
+		},
+		"level":     "ERROR",
+		"prices":    map[string]any{"A-1": 9.5},
+		"deltas":    []any{int64(-1), int64(2)},
+		"paid":      true,
+		"signature": []byte{0xde, 0xad},
+		"host":      map[string]any{"name": "web-1"},
+		"created":   "2020-01-01T12:00:00.5Z",
+		"card":      "visa",
+		"ratio":     0.5,
+		"big":       "18446744073709551615",
+	}, got)
+
+	got, err = order.Decode(nil)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestDecodeWellKnown(t *testing.T) {
+	file, err := Compile("event.proto", `
+syntax = "proto3";
+import "google/protobuf/struct.proto";
+import "google/protobuf/wrappers.proto";
+import "google/protobuf/duration.proto";
+message Event {
+  google.protobuf.Struct data = 1;
+  google.protobuf.BoolValue flag = 2;
+  google.protobuf.Duration took = 3;
+  map<string, int32> counts = 4;
+}`, nil)
+	require.NoError(t, err)
+	event, err := file.MessageByIndexes([]int{0})
+	require.NoError(t, err)
+
+	str := encoder{}.bytes(3, []byte("x"))
+	null := encoder{}.varint(1, 0)
+	list := encoder{}.bytes(1, encoder{}.fixed64(2, math.Float64bits(1))).bytes(1, null)
+	fields := encoder{}.
+		bytes(1, encoder{}.bytes(1, []byte("s")).bytes(2, str)).
+		bytes(1, encoder{}.bytes(1, []byte("n")).bytes(2, null)).
+		bytes(1, encoder{}.bytes(1, []byte("l")).bytes(2, encoder{}.bytes(6, list)))
+	data := encoder{}.
+		bytes(1, fields).
+		bytes(2, nil).
+		bytes(3, encoder{}.varint(1, 1).varint(2, 500000000)).
+		bytes(4, encoder{}.bytes(1, []byte("zero")))
+
+	got, err := event.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"data":   map[string]any{"s": "x", "n": nil, "l": []any{float64(1), nil}},
+		"flag":   false,
+		"took":   "1.5s",
+		"counts": map[string]any{"zero": int64(0)},
+	}, got)
+}
+
+func TestMessageByIndexes(t *testing.T) {
+	file := compileOrder(t)
+	m, err := file.MessageByIndexes([]int{1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.Order.Line", m.FullName)
+	// The entry message of the prices map follows Line, as protoc orders them.
+	m, err = file.MessageByIndexes([]int{1, 1})
+	require.NoError(t, err)
+	assert.Equal(t, "com.example.Order.PricesEntry", m.FullName)
+
+	_, err = file.MessageByIndexes([]int{2})
+	assert.ErrorContains(t, err, "no message at index path [2]")
+	_, err = file.MessageByIndexes(nil)
+	assert.Error(t, err)
+}
+
+func TestCompileErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		source  string
+		imports map[string]string
+		err     string
+	}{
+		"undefined type": {
+			source: `syntax = "proto3"; message A { B b = 1; }`,
+			err:    "message A: field b: type B is not defined",
+		},
+		"missing import": {
+			source: `syntax = "proto3"; import "other.proto";`,
+			err:    `import "other.proto" is not available`,
+		},
+		"import cycle": {
+			source:  `syntax = "proto3"; import "b.proto";`,
+			imports: map[string]string{"b.proto": `import "a.proto";`, "a.proto": `import "b.proto";`},
+			err:     "import cycle",
+		},
+		"syntax error": {
+			source: `syntax = "proto3"; message A { string a = ; }`,
+			err:    `line 1: invalid field number ";"`,
+		},
+		"group": {
+			source: `syntax = "proto2"; message A { optional group G = 1 { optional int32 a = 2; } }`,
+			err:    "groups are not supported",
+		},
+		"unterminated comment": {
+			source: `/* syntax`,
+			err:    "unterminated comment",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile("a.proto", tc.source, tc.imports)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	order, err := compileOrder(t).MessageByIndexes([]int{1})
+	require.NoError(t, err)
+
+	_, err = order.Decode([]byte{0x12, 0x05, 'a'})
+	assert.ErrorContains(t, err, "com.example.Order.customer: truncated message")
+	_, err = order.Decode(encoder{}.fixed32(2, 1))
+	assert.ErrorContains(t, err, "wire type 5 does not match type string")
+	_, err = order.Decode(encoder{}.tag(50, 3))
+	assert.ErrorContains(t, err, "wire type 3 is not supported")
+}
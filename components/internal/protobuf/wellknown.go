@@ -0,0 +1,81 @@
+package protobuf
+
+import (
+	"strconv"
+	"time"
+)
+
+// wellKnownSources are the sources of the well-known types, which schema registries do not store.
+var wellKnownSources = map[string]string{
+	"google/protobuf/any.proto": `syntax = "proto3"; package google.protobuf;
+message Any { string type_url = 1; bytes value = 2; }`,
+	"google/protobuf/duration.proto": `syntax = "proto3"; package google.protobuf;
+message Duration { int64 seconds = 1; int32 nanos = 2; }`,
+	"google/protobuf/empty.proto": `syntax = "proto3"; package google.protobuf;
+message Empty {}`,
+	"google/protobuf/field_mask.proto": `syntax = "proto3"; package google.protobuf;
+message FieldMask { repeated string paths = 1; }`,
+	"google/protobuf/struct.proto": `syntax = "proto3"; package google.protobuf;
+message Struct { map<string, Value> fields = 1; }
+message Value {
+  oneof kind {
+    NullValue null_value = 1; double number_value = 2; string string_value = 3; bool bool_value = 4;
+    Struct struct_value = 5; ListValue list_value = 6;
+  }
+}
+enum NullValue { NULL_VALUE = 0; }
+message ListValue { repeated Value values = 1; }`,
+	"google/protobuf/timestamp.proto": `syntax = "proto3"; package google.protobuf;
+message Timestamp { int64 seconds = 1; int32 nanos = 2; }`,
+	"google/protobuf/wrappers.proto": `syntax = "proto3"; package google.protobuf;
+message DoubleValue { double value = 1; }
+message FloatValue { float value = 1; }
+message Int64Value { int64 value = 1; }
+message UInt64Value { uint64 value = 1; }
+message Int32Value { int32 value = 1; }
+message UInt32Value { uint32 value = 1; }
+message BoolValue { bool value = 1; }
+message StringValue { string value = 1; }
+message BytesValue { bytes value = 1; }`,
+}
+
+// wellKnown converts a decoded well-known type to its JSON representation, e.g. a Timestamp to an RFC 3339 string,
+// and returns other messages unchanged.
+func wellKnown(m *Message, v map[string]any) any {
+	switch m.FullName {
+	case "google.protobuf.Timestamp":
+		seconds, _ := v["seconds"].(int64)
+		nanos, _ := v["nanos"].(int64)
+		return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+	case "google.protobuf.Duration":
+		seconds, _ := v["seconds"].(int64)
+		nanos, _ := v["nanos"].(int64)
+		d := time.Duration(seconds)*time.Second + time.Duration(nanos)
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+	case "google.protobuf.DoubleValue", "google.protobuf.FloatValue", "google.protobuf.Int64Value",
+		"google.protobuf.UInt64Value", "google.protobuf.Int32Value", "google.protobuf.UInt32Value",
+		"google.protobuf.BoolValue", "google.protobuf.StringValue", "google.protobuf.BytesValue":
+		if value, ok := v["value"]; ok {
+			return value
+		}
+		return m.Fields[0].zero()
+	case "google.protobuf.Struct":
+		if fields, ok := v["fields"]; ok {
+			return fields
+		}
+		return map[string]any{}
+	case "google.protobuf.ListValue":
+		if values, ok := v["values"]; ok {
+			return values
+		}
+		return []any{}
+	case "google.protobuf.Value":
+		for _, k := range []string{"number_value", "string_value", "bool_value", "struct_value", "list_value"} {
+			if value, ok := v[k]; ok {
+				return value
+			}
+		}
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+// Package schemaregistry reads schemas from a Confluent Schema Registry and splits messages in the Confluent wire
+// format, a zero magic byte and a 4-byte big-endian schema ID followed by the serialized value.
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Schema types, as reported by the registry. Schemas registered without a type are Avro.
+const (
+	Avro     = "AVRO"
+	Protobuf = "PROTOBUF"
+	JSON     = "JSON"
+)
+
+// Schema is a schema registered in the registry.
+type Schema struct {
+	Type       string      `json:"schemaType"`
+	Schema     string      `json:"schema"`
+	References []Reference `json:"references"`
+}
+
+// Reference is a schema a schema depends on, e.g. an Avro named type or an imported Protobuf file, by the name it
+// is referenced with.
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Client reads schemas from a registry. Registered schemas never change, so they are cached for the lifetime of
+// the client. It is safe for concurrent use.
+type Client struct {
+	url      string
+	http     *http.Client
+	username string
+	password string
+
+	mu        sync.Mutex
+	byID      map[int]*Schema
+	bySubject map[Reference]*Schema
+}
+
+// NewClient returns a client of the registry at baseURL, authenticating with basic auth when username is set.
+func NewClient(baseURL string, httpClient *http.Client, username, password string) *Client {
+	return &Client{
+		url:       strings.TrimSuffix(baseURL, "/"),
+		http:      httpClient,
+		username:  username,
+		password:  password,
+		byID:      map[int]*Schema{},
+		bySubject: map[Reference]*Schema{},
+	}
+}
+
+// SchemaByID returns the schema with the given ID.
+func (c *Client) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	c.mu.Lock()
+	s, ok := c.byID[id]
+	c.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+	s, err := c.get(ctx, fmt.Sprintf("/schemas/ids/%d", id))
+	if err != nil {
+		return nil, fmt.Errorf("schema %d: %w", id, err)
+	}
+	c.mu.Lock()
+	c.byID[id] = s
+	c.mu.Unlock()
+	return s, nil
+}
+
+// SchemaByReference returns the schema of a reference.
+func (c *Client) SchemaByReference(ctx context.Context, ref Reference) (*Schema, error) {
+	key := Reference{Subject: ref.Subject, Version: ref.Version}
+	c.mu.Lock()
+	s, ok := c.bySubject[key]
+	c.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+	s, err := c.get(ctx, fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(ref.Subject), ref.Version))
+	if err != nil {
+		return nil, fmt.Errorf("subject %s version %d: %w", ref.Subject, ref.Version, err)
+	}
+	c.mu.Lock()
+	c.bySubject[key] = s
+	c.mu.Unlock()
+	return s, nil
+}
+
+func (c *Client) get(ctx context.Context, path string) (*Schema, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &e) == nil && e.Message != "" {
+			return nil, fmt.Errorf("registry responded %s: %s", resp.Status, e.Message)
+		}
+		return nil, fmt.Errorf("registry responded %s", resp.Status)
+	}
+	var s Schema
+	if err := json.Unmarshal(body, &s); err != nil {
+		return nil, fmt.Errorf("decoding the registry response: %w", err)
+	}
+	if s.Type == "" {
+		s.Type = Avro
+	}
+	return &s, nil
+}
+
+// ErrNotWireFormat is returned for messages that do not start with the magic byte of the wire format.
+var ErrNotWireFormat = errors.New("not in the Confluent wire format: no magic byte")
+
+// SplitWireFormat returns the schema ID and the serialized value of a message in the wire format.
+func SplitWireFormat(msg []byte) (int, []byte, error) {
+	if len(msg) == 0 || msg[0] != 0 {
+		return 0, nil, ErrNotWireFormat
+	}
+	if len(msg) < 5 {
+		return 0, nil, errors.New("message shorter than the wire format header")
+	}
+	return int(binary.BigEndian.Uint32(msg[1:5])), msg[5:], nil
+}
@@ -0,0 +1,71 @@
+package schemaregistry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var requests atomic.Int32
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "sr" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error_code":401,"message":"Unauthorized"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			_, _ = w.Write([]byte(`{"schema":"\"string\""}`))
+		case "/schemas/ids/2":
+			_, _ = w.Write([]byte(`{"schemaType":"PROTOBUF","schema":"syntax = \"proto3\";","references":[{"name":"common.proto","subject":"common value","version":3}]}`))
+		case "/subjects/common value/versions/3":
+			_, _ = w.Write([]byte(`{"subject":"common value","version":3,"id":7,"schemaType":"PROTOBUF","schema":"message Common {}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40403,"message":"Schema not found"}`))
+		}
+	}))
+	defer registry.Close()
+
+	ctx := context.Background()
+	c := NewClient(registry.URL+"/", registry.Client(), "sr", "secret")
+	s, err := c.SchemaByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{Type: Avro, Schema: `"string"`}, s)
+	_, err = c.SchemaByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "schemas are cached")
+
+	s, err = c.SchemaByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, Protobuf, s.Type)
+	require.Len(t, s.References, 1)
+	ref, err := c.SchemaByReference(ctx, s.References[0])
+	require.NoError(t, err)
+	assert.Equal(t, "message Common {}", ref.Schema)
+
+	_, err = c.SchemaByID(ctx, 9)
+	assert.EqualError(t, err, "schema 9: registry responded 404 Not Found: Schema not found")
+	_, err = NewClient(registry.URL, registry.Client(), "", "").SchemaByID(ctx, 1)
+	assert.EqualError(t, err, "schema 1: registry responded 401 Unauthorized: Unauthorized")
+}
+
+func TestSplitWireFormat(t *testing.T) {
+	id, value, err := SplitWireFormat([]byte{0, 0, 0, 1, 2, 'v'})
+	require.NoError(t, err)
+	assert.Equal(t, 258, id)
+	assert.Equal(t, []byte("v"), value)
+
+	_, _, err = SplitWireFormat([]byte(`{"a":1}`))
+	assert.ErrorIs(t, err, ErrNotWireFormat)
+	_, _, err = SplitWireFormat([]byte{0, 0, 1})
+	assert.EqualError(t, err, "message shorter than the wire format header")
+}
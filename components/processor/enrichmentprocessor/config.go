@@ -0,0 +1,83 @@
+package enrichmentprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Config configures the enrichment processor.
+type Config struct {
+	// Fields maps the names of the fields to add to their value, static text or a template referencing the Kafka
+	// topic, record headers and resource attributes.
+	Fields map[string]string `mapstructure:"fields"`
+	// Enrichment is the value of the splunk.hec.json.event.enrichment setting of Splunk Connect for Kafka, a comma
+	// separated list of field=value pairs, added to Fields.
+	Enrichment string `mapstructure:"enrichment"`
+	// Overwrite replaces attributes the log records already have. By default they are kept.
+	Overwrite bool `mapstructure:"overwrite"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{}
+}
+
+// Validate checks that there are fields to add and that their values are valid templates.
+func (cfg *Config) Validate() error {
+	_, err := cfg.templates()
+	return err
+}
+
+// templates returns the fields of Fields and Enrichment.
+func (cfg *Config) templates() (map[string]template, error) {
+	fields := map[string]string{}
+	for name, value := range cfg.Fields {
+		fields[name] = value
+	}
+	enrichment, err := parseEnrichment(cfg.Enrichment)
+	if err != nil {
+		return nil, fmt.Errorf("enrichment: %w", err)
+	}
+	for name, value := range enrichment {
+		if _, ok := fields[name]; ok {
+			return nil, fmt.Errorf("enrichment: %s is also set in fields", name)
+		}
+		fields[name] = value
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("fields or enrichment is required")
+	}
+
+	templates := map[string]template{}
+	for name, value := range fields {
+		if name == "" {
+			return nil, errors.New("fields: empty field name")
+		}
+		t, err := parseTemplate(value)
+		if err != nil {
+			return nil, fmt.Errorf("fields: %s: %w", name, err)
+		}
+		templates[name] = t
+	}
+	return templates, nil
+}
+
+// parseEnrichment parses the field=value pairs of splunk.hec.json.event.enrichment, e.g. org=fin,bu=south-east-us.
+func parseEnrichment(s string) (map[string]string, error) {
+	fields := map[string]string{}
+	if strings.TrimSpace(s) == "" {
+		return fields, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%q is not a field=value pair", strings.TrimSpace(pair))
+		}
+		if _, ok := fields[name]; ok {
+			return nil, fmt.Errorf("%s is set twice", name)
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
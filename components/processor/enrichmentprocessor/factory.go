@@ -0,0 +1,30 @@
+package enrichmentprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+var componentType = component.MustNewType("enrichment")
+
+// NewFactory returns the factory of the enrichment processor.
+func NewFactory() processor.Factory {
+	return processor.NewFactory(
+		componentType,
+		func() component.Config { return createDefaultConfig() },
+		processor.WithLogs(createLogs, component.StabilityLevelAlpha),
+	)
+}
+
+func createLogs(ctx context.Context, set processor.Settings, cfg component.Config, next consumer.Logs) (processor.Logs, error) {
+	p, err := newEnricher(cfg.(*Config))
+	if err != nil {
+		return nil, err
+	}
+	return processorhelper.NewLogs(ctx, set, cfg, next, p.processLogs,
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+}
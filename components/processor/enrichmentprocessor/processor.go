@@ -0,0 +1,69 @@
+// Package enrichmentprocessor implements the enrichment processor, which adds fields with static or templated
+// values to every log record, sent by the splunk_hec exporter as HEC indexed fields. It is the equivalent of the
+// splunk.hec.json.event.enrichment setting of Splunk Connect for Kafka.
+package enrichmentprocessor
+
+import (
+	"context"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+type enricher struct {
+	overwrite bool
+	names     []string
+	templates map[string]template
+}
+
+func newEnricher(cfg *Config) (*enricher, error) {
+	templates, err := cfg.templates()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return &enricher{overwrite: cfg.Overwrite, names: names, templates: templates}, nil
+}
+
+func (e *enricher) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		fields := e.fields(ctx, rl.Resource().Attributes())
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				attrs := lrs.At(k).Attributes()
+				for _, f := range fields {
+					if _, ok := attrs.Get(f.name); ok && !e.overwrite {
+						continue
+					}
+					attrs.PutStr(f.name, f.value)
+				}
+			}
+		}
+	}
+	return ld, nil
+}
+
+type field struct {
+	name, value string
+}
+
+// fields renders the fields for the log records of a resource. Fields whose template references a missing value
+// are skipped.
+func (e *enricher) fields(ctx context.Context, res pcommon.Map) []field {
+	fields := make([]field, 0, len(e.names))
+	for _, name := range e.names {
+		if value, ok := e.templates[name].render(ctx, res); ok {
+			fields = append(fields, field{name: name, value: value})
+		}
+	}
+	return fields
+}
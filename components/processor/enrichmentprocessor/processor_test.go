@@ -0,0 +1,96 @@
+package enrichmentprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	assert.EqualError(t, cfg.Validate(), "fields or enrichment is required")
+
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "valid", cfg: Config{Fields: map[string]string{"env": "{{ header.env }}-{{topic}}"}, Enrichment: "org=fin, bu = south-east-us"}},
+		{name: "not a pair", cfg: Config{Enrichment: "org=fin,bu"}, err: `enrichment: "bu" is not a field=value pair`},
+		{name: "empty name", cfg: Config{Enrichment: "=fin"}, err: `enrichment: "=fin" is not a field=value pair`},
+		{name: "duplicate pair", cfg: Config{Enrichment: "org=fin,org=hr"}, err: "enrichment: org is set twice"},
+		{name: "set twice", cfg: Config{Fields: map[string]string{"org": "fin"}, Enrichment: "org=hr"}, err: "enrichment: org is also set in fields"},
+		{name: "unterminated", cfg: Config{Fields: map[string]string{"env": "{{ topic"}}, err: `fields: env: unterminated reference "{{ topic"`},
+		{name: "unknown reference", cfg: Config{Fields: map[string]string{"env": "{{ partition }}"}}, err: "fields: env: unknown reference {{ partition }}"},
+		{name: "header without name", cfg: Config{Fields: map[string]string{"env": "{{ header. }}"}}, err: "unknown reference {{ header. }}"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Fields = map[string]string{
+		"env":     "{{ header.env }}",
+		"source":  "kafka:{{ topic }}/{{ resource.service.name }}",
+		"region":  "{{ header.region }}",
+		"cluster": "main",
+	}
+	cfg.Enrichment = "org=fin,bu=south-east-us"
+	require.NoError(t, cfg.Validate())
+	sink := new(consumertest.LogsSink)
+	p, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, sink)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "billing")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr("hello")
+	lr.Attributes().PutStr("org", "hr")
+
+	ctx := client.NewContext(context.Background(), client.Info{Metadata: client.NewMetadata(map[string][]string{
+		kafkarecord.TopicKey: {"orders"},
+		"env":                {"prod"},
+	})})
+	require.NoError(t, p.ConsumeLogs(ctx, ld))
+
+	require.Len(t, sink.AllLogs(), 1)
+	got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, map[string]any{
+		"org":     "hr",
+		"bu":      "south-east-us",
+		"env":     "prod",
+		"source":  "kafka:orders/billing",
+		"cluster": "main",
+	}, got.Attributes().AsRaw())
+}
+
+func TestOverwrite(t *testing.T) {
+	e, err := newEnricher(&Config{Enrichment: "org=fin", Overwrite: true})
+	require.NoError(t, err)
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Attributes().PutStr("org", "hr")
+	out, err := e.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"org": "fin"}, out.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+}
@@ -0,0 +1,87 @@
+package enrichmentprocessor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+// Reference kinds of a template.
+const (
+	refTopic    = "topic"
+	refHeader   = "header."
+	refResource = "resource."
+)
+
+// template is a field value: literal text with references to the Kafka topic, record headers and resource
+// attributes, written {{ topic }}, {{ header.<name> }} and {{ resource.<attribute> }}.
+type template struct {
+	literals []string
+	// refs[i] follows literals[i]; literals has one more element than refs.
+	refs []string
+}
+
+func parseTemplate(s string) (template, error) {
+	var t template
+	for {
+		open := strings.Index(s, "{{")
+		if open < 0 {
+			t.literals = append(t.literals, s)
+			return t, nil
+		}
+		end := strings.Index(s[open:], "}}")
+		if end < 0 {
+			return template{}, fmt.Errorf("unterminated reference %q", s[open:])
+		}
+		ref := strings.TrimSpace(s[open+2 : open+end])
+		switch {
+		case ref == refTopic:
+		case strings.HasPrefix(ref, refHeader) && len(ref) > len(refHeader):
+		case strings.HasPrefix(ref, refResource) && len(ref) > len(refResource):
+		default:
+			return template{}, fmt.Errorf("unknown reference {{ %s }}, expected topic, header.<name> or resource.<attribute>", ref)
+		}
+		t.literals = append(t.literals, s[:open])
+		t.refs = append(t.refs, ref)
+		s = s[open+end+2:]
+	}
+}
+
+// render returns the value of t for a record of the request of ctx with the resource attributes res. It is false
+// when a reference has no value.
+func (t template) render(ctx context.Context, res pcommon.Map) (string, bool) {
+	if len(t.refs) == 0 {
+		return t.literals[0], true
+	}
+	var b strings.Builder
+	for i, ref := range t.refs {
+		b.WriteString(t.literals[i])
+		v, ok := resolve(ctx, res, ref)
+		if !ok {
+			return "", false
+		}
+		b.WriteString(v)
+	}
+	b.WriteString(t.literals[len(t.refs)])
+	return b.String(), true
+}
+
+func resolve(ctx context.Context, res pcommon.Map, ref string) (string, bool) {
+	switch {
+	case ref == refTopic:
+		topic := kafkarecord.FromContext(ctx).Topic
+		return topic, topic != ""
+	case strings.HasPrefix(ref, refHeader):
+		return kafkarecord.Header(ctx, strings.TrimPrefix(ref, refHeader))
+	default:
+		v, ok := res.Get(strings.TrimPrefix(ref, refResource))
+		if !ok {
+			return "", false
+		}
+		return v.AsString(), true
+	}
+}
@@ -0,0 +1,38 @@
+package hecenvelopeprocessor
+
+import "errors"
+
+// Config configures the hecenvelope processor.
+type Config struct {
+	// HECMetadata names the attributes receiving the index, source, sourcetype and host of envelopes. It must match
+	// the otel_attrs_to_hec_metadata setting of the splunk_hec exporter, and has the same defaults.
+	HECMetadata HECMetadata `mapstructure:"otel_attrs_to_hec_metadata"`
+}
+
+// HECMetadata maps HEC metadata to attribute names.
+type HECMetadata struct {
+	Source     string `mapstructure:"source"`
+	SourceType string `mapstructure:"sourcetype"`
+	Index      string `mapstructure:"index"`
+	Host       string `mapstructure:"host"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		HECMetadata: HECMetadata{
+			Source:     "com.splunk.source",
+			SourceType: "com.splunk.sourcetype",
+			Index:      "com.splunk.index",
+			Host:       "host.name",
+		},
+	}
+}
+
+// Validate checks that every HEC metadata has an attribute.
+func (cfg *Config) Validate() error {
+	m := cfg.HECMetadata
+	if m.Source == "" || m.SourceType == "" || m.Index == "" || m.Host == "" {
+		return errors.New("otel_attrs_to_hec_metadata: source, sourcetype, index and host are required")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package hecenvelopeprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// envelope is a decoded HEC event, see
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/FormateventsforHTTPEventCollector.
+type envelope struct {
+	event any
+	time  time.Time
+	// metadata holds index, source, sourcetype and host, when set.
+	metadata map[string]string
+	fields   map[string]any
+}
+
+var metadataKeys = []string{"index", "source", "sourcetype", "host"}
+
+// envelopeKeys are the keys of an envelope. JSON objects with other keys are events of their own, e.g. a log with an
+// event attribute, not envelopes.
+var envelopeKeys = append([]string{"event", "time", "fields"}, metadataKeys...)
+
+// decodeJSON decodes an envelope from its JSON text.
+func decodeJSON(data []byte) (envelope, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return envelope{}, errors.New("not a JSON object")
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]any
+	if err := dec.Decode(&m); err != nil {
+		return envelope{}, err
+	}
+	if dec.More() {
+		return envelope{}, errors.New("text after the JSON object")
+	}
+	return decode(m)
+}
+
+// decode decodes an envelope from a JSON object, with numbers as json.Number, int64 or float64.
+func decode(m map[string]any) (envelope, error) {
+	for key := range m {
+		if !slices.Contains(envelopeKeys, key) {
+			return envelope{}, fmt.Errorf("unknown key %q", key)
+		}
+	}
+	event, ok := m["event"]
+	if !ok || event == nil {
+		return envelope{}, errors.New("no event")
+	}
+	e := envelope{event: normalize(event), metadata: map[string]string{}}
+	if t, ok := m["time"]; ok && t != nil {
+		var err error
+		if e.time, err = parseTime(t); err != nil {
+			return envelope{}, fmt.Errorf("time: %w", err)
+		}
+	}
+	for _, key := range metadataKeys {
+		v, ok := m[key]
+		if !ok || v == nil {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			return envelope{}, fmt.Errorf("%s: not a string", key)
+		}
+		if s != "" {
+			e.metadata[key] = s
+		}
+	}
+	if f, ok := m["fields"]; ok && f != nil {
+		fields, ok := f.(map[string]any)
+		if !ok {
+			return envelope{}, errors.New("fields: not an object")
+		}
+		e.fields = normalize(fields).(map[string]any)
+	}
+	return e, nil
+}
+
+// parseTime parses the epoch time of an envelope, in seconds with an optional fraction, as a number or a string.
+func parseTime(v any) (time.Time, error) {
+	var seconds float64
+	switch t := v.(type) {
+	case json.Number:
+		f, err := t.Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		seconds = f
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is not a number", t)
+		}
+		seconds = f
+	case int64:
+		seconds = float64(t)
+	case float64:
+		seconds = t
+	default:
+		return time.Time{}, errors.New("not a number")
+	}
+	if seconds < 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return time.Time{}, fmt.Errorf("%v is not an epoch time", v)
+	}
+	// Millisecond precision, the precision of HEC.
+	return time.UnixMilli(int64(math.Round(seconds * 1000))).UTC(), nil
+}
+
+// normalize converts the json.Number values of v to int64 or float64, the types pcommon accepts.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]any:
+		for k, item := range t {
+			t[k] = normalize(item)
+		}
+		return t
+	case []any:
+		for i, item := range t {
+			t[i] = normalize(item)
+		}
+		return t
+	default:
+		return v
+	}
+}
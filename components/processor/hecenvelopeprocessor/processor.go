@@ -0,0 +1,74 @@
+// Package hecenvelopeprocessor implements the hecenvelope processor, which unwraps log records holding a complete
+// HEC event, {"event": ..., "time": ..., "index": ..., "fields": {...}}, so that the splunk_hec exporter sends the
+// event with its own time, metadata and indexed fields. It replaces the splunk.hec.json.event.formatted setting
+// of Splunk Connect for Kafka.
+package hecenvelopeprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+type envelopeProcessor struct {
+	// attributes maps the HEC metadata keys to the attributes read by the exporter.
+	attributes map[string]string
+}
+
+func newEnvelopeProcessor(cfg *Config) *envelopeProcessor {
+	return &envelopeProcessor{attributes: map[string]string{
+		"index":      cfg.HECMetadata.Index,
+		"source":     cfg.HECMetadata.Source,
+		"sourcetype": cfg.HECMetadata.SourceType,
+		"host":       cfg.HECMetadata.Host,
+	}}
+}
+
+func (p *envelopeProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				p.unwrap(lrs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// unwrap replaces a log record holding an envelope by its event. Records that are not envelopes, or malformed
+// ones, are left as they are and sent as raw text.
+func (p *envelopeProcessor) unwrap(lr plog.LogRecord) {
+	var (
+		e   envelope
+		err error
+	)
+	switch body := lr.Body(); body.Type() {
+	case pcommon.ValueTypeStr:
+		e, err = decodeJSON([]byte(body.Str()))
+	case pcommon.ValueTypeBytes:
+		e, err = decodeJSON(body.Bytes().AsRaw())
+	case pcommon.ValueTypeMap:
+		e, err = decode(body.Map().AsRaw())
+	default:
+		return
+	}
+	if err != nil {
+		return
+	}
+	// FromRaw only fails on types JSON does not produce.
+	_ = lr.Body().FromRaw(e.event)
+	if !e.time.IsZero() {
+		lr.SetTimestamp(pcommon.NewTimestampFromTime(e.time))
+	}
+	attrs := lr.Attributes()
+	for key, value := range e.metadata {
+		attrs.PutStr(p.attributes[key], value)
+	}
+	for name, value := range e.fields {
+		_ = attrs.PutEmpty(name).FromRaw(value)
+	}
+}
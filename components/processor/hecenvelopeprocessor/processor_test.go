@@ -0,0 +1,137 @@
+package hecenvelopeprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	require.NoError(t, cfg.Validate())
+	cfg.HECMetadata.Host = ""
+	assert.ErrorContains(t, cfg.Validate(), "otel_attrs_to_hec_metadata")
+}
+
+func TestProcessLogs(t *testing.T) {
+	collected := time.Date(2025, 6, 26, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		body  func(pcommon.Value)
+		want  any
+		time  time.Time
+		attrs map[string]any
+	}{
+		{
+			name: "full envelope",
+			body: func(v pcommon.Value) {
+				v.SetStr(`{"event":"hello","time":1750939500.123,"index":"main","source":"app","sourcetype":"app:log",` +
+					`"host":"web-1","fields":{"env":"prod","region":["eu","us"],"shard":7}}`)
+			},
+			want: "hello",
+			time: time.UnixMilli(1750939500123).UTC(),
+			attrs: map[string]any{
+				"kafka.topic":           "orders",
+				"com.splunk.index":      "main",
+				"com.splunk.source":     "app",
+				"com.splunk.sourcetype": "app:log",
+				"host.name":             "web-1",
+				"env":                   "prod",
+				"region":                []any{"eu", "us"},
+				"shard":                 int64(7),
+			},
+		},
+		{
+			name: "JSON event and string time",
+			body: func(v pcommon.Value) {
+				v.SetStr(` {"event":{"user":"ann","count":2,"ratio":0.5},"time":"1750939500"}` + "\n")
+			},
+			want:  map[string]any{"user": "ann", "count": int64(2), "ratio": 0.5},
+			time:  time.Unix(1750939500, 0).UTC(),
+			attrs: map[string]any{"kafka.topic": "orders"},
+		},
+		{
+			name: "map body",
+			body: func(v pcommon.Value) {
+				m := v.SetEmptyMap()
+				m.PutStr("event", "hello")
+				m.PutInt("time", 1750939500)
+				m.PutStr("index", "main")
+			},
+			want:  "hello",
+			time:  time.Unix(1750939500, 0).UTC(),
+			attrs: map[string]any{"kafka.topic": "orders", "com.splunk.index": "main"},
+		},
+		{
+			name:  "bytes body",
+			body:  func(v pcommon.Value) { v.SetEmptyBytes().FromRaw([]byte(`{"event":"hello"}`)) },
+			want:  "hello",
+			time:  collected,
+			attrs: map[string]any{"kafka.topic": "orders"},
+		},
+		{
+			name:  "plain text",
+			body:  func(v pcommon.Value) { v.SetStr("hello") },
+			want:  "hello",
+			time:  collected,
+			attrs: map[string]any{"kafka.topic": "orders"},
+		},
+	}
+	malformed := map[string]string{
+		"invalid JSON":      `{"event":"hello"`,
+		"no event":          `{"time":1750939500}`,
+		"null event":        `{"event":null}`,
+		"unknown key":       `{"event":"login","user":"ann"}`,
+		"invalid time":      `{"event":"hello","time":"yesterday"}`,
+		"negative time":     `{"event":"hello","time":-1}`,
+		"numeric index":     `{"event":"hello","index":5}`,
+		"fields not object": `{"event":"hello","fields":["env"]}`,
+		"trailing text":     `{"event":"hello"} {"event":"bye"}`,
+	}
+	for name, body := range malformed {
+		tests = append(tests, struct {
+			name  string
+			body  func(pcommon.Value)
+			want  any
+			time  time.Time
+			attrs map[string]any
+		}{
+			name:  "malformed: " + name,
+			body:  func(v pcommon.Value) { v.SetStr(body) },
+			want:  body,
+			time:  collected,
+			attrs: map[string]any{"kafka.topic": "orders"},
+		})
+	}
+
+	factory := NewFactory()
+	sink := new(consumertest.LogsSink)
+	p, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), factory.CreateDefaultConfig(), sink)
+	require.NoError(t, err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink.Reset()
+			ld := plog.NewLogs()
+			lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(collected))
+			lr.Attributes().PutStr("kafka.topic", "orders")
+			tt.body(lr.Body())
+			require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+
+			require.Len(t, sink.AllLogs(), 1)
+			got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			assert.Equal(t, tt.want, got.Body().AsRaw())
+			assert.Equal(t, tt.time, got.Timestamp().AsTime())
+			assert.Equal(t, tt.attrs, got.Attributes().AsRaw())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package kafkakeyprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Built-in encodings of the keys.
+const (
+	encodingString = "string"
+	encodingJSON   = "json"
+	encodingBase64 = "base64"
+)
+
+// keyPath is the path of the whole key in the fields, those of its fields are below it, e.g. key.tenant.
+const keyPath = "key"
+
+// Config configures the kafkakey processor.
+type Config struct {
+	// Encoding decodes the keys: string, json, base64, or the ID of an encoding extension such as
+	// schema_registry_encoding. string by default.
+	Encoding string `mapstructure:"encoding"`
+	// Attribute is the log record attribute the decoded key is attached as, kafka.key by default. Empty to attach
+	// only the fields.
+	Attribute string `mapstructure:"attribute"`
+	// Fields maps log record attributes to the key, with the path key, or to a field of it, e.g. key.tenant. Mapping
+	// host.name sets the host of the HEC events.
+	Fields map[string]string `mapstructure:"fields"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		Encoding:  encodingString,
+		Attribute: "kafka.key",
+	}
+}
+
+// Validate checks the encoding and the paths of the fields.
+func (cfg *Config) Validate() error {
+	if _, err := cfg.extension(); err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	if cfg.Attribute == "" && len(cfg.Fields) == 0 {
+		return errors.New("attribute or fields: at least one is required")
+	}
+	for attr, path := range cfg.Fields {
+		if attr == "" {
+			return errors.New("fields: empty attribute name")
+		}
+		if path != keyPath && (!strings.HasPrefix(path, keyPath+".") || strings.Contains(path+".", "..")) {
+			return fmt.Errorf("fields: %s: path %q must be key or a field of it, e.g. key.tenant", attr, path)
+		}
+	}
+	return nil
+}
+
+// extension returns the ID of the encoding extension, nil for the built-in encodings.
+func (cfg *Config) extension() (*component.ID, error) {
+	switch cfg.Encoding {
+	case encodingString, encodingJSON, encodingBase64:
+		return nil, nil
+	case "":
+		return nil, errors.New("required")
+	}
+	var id component.ID
+	if err := id.UnmarshalText([]byte(cfg.Encoding)); err != nil {
+		return nil, fmt.Errorf("%q is neither string, json, base64 nor an extension ID: %w", cfg.Encoding, err)
+	}
+	return &id, nil
+}
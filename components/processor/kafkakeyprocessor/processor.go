@@ -0,0 +1,126 @@
+// Package kafkakeyprocessor implements the kafkakey processor, which decodes the key of the Kafka record every log
+// record was read from and attaches it, or fields of it, as attributes, the equivalent of the key.converter
+// settings of Splunk Connect for Kafka. Producers often key records by host or tenant, which can then set the host
+// of the HEC events or an indexed field.
+package kafkakeyprocessor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+type keyProcessor struct {
+	cfg *Config
+	// unmarshaler is the encoding extension decoding the keys, nil for the built-in encodings.
+	unmarshaler plog.Unmarshaler
+}
+
+func newKeyProcessor(cfg *Config) *keyProcessor {
+	return &keyProcessor{cfg: cfg}
+}
+
+// start looks up the encoding extension.
+func (p *keyProcessor) start(_ context.Context, host component.Host) error {
+	id, err := p.cfg.extension()
+	if err != nil || id == nil {
+		return err
+	}
+	ext, ok := host.GetExtensions()[*id]
+	if !ok {
+		return fmt.Errorf("encoding: extension %s is not configured", id)
+	}
+	if p.unmarshaler, ok = ext.(plog.Unmarshaler); !ok {
+		return fmt.Errorf("encoding: extension %s is not a logs encoding", id)
+	}
+	return nil
+}
+
+func (p *keyProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	r := kafkarecord.FromContext(ctx)
+	if !r.HasKey {
+		return ld, nil
+	}
+	attrs := p.attributes(p.decode(r.Key))
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				target := lrs.At(k).Attributes()
+				attrs.Range(func(name string, v pcommon.Value) bool {
+					v.CopyTo(target.PutEmpty(name))
+					return true
+				})
+			}
+		}
+	}
+	return ld, nil
+}
+
+// decode decodes a key with the configured encoding. Keys the encoding fails to decode fall back to the string
+// encoding, so that they are never lost.
+func (p *keyProcessor) decode(key []byte) pcommon.Value {
+	v := pcommon.NewValueEmpty()
+	switch {
+	case p.unmarshaler != nil:
+		if ld, err := p.unmarshaler.UnmarshalLogs(key); err == nil && ld.LogRecordCount() > 0 {
+			ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().CopyTo(v)
+			return v
+		}
+	case p.cfg.Encoding == encodingJSON:
+		var decoded any
+		if err := json.Unmarshal(key, &decoded); err == nil && v.FromRaw(decoded) == nil {
+			return v
+		}
+	case p.cfg.Encoding == encodingBase64:
+		v.SetStr(base64.StdEncoding.EncodeToString(key))
+		return v
+	}
+	// Binary keys are base64 encoded.
+	if utf8.Valid(key) {
+		v.SetStr(string(key))
+	} else {
+		v.SetStr(base64.StdEncoding.EncodeToString(key))
+	}
+	return v
+}
+
+// attributes returns the attributes of a decoded key: the key itself and the configured fields it has.
+func (p *keyProcessor) attributes(key pcommon.Value) pcommon.Map {
+	m := pcommon.NewMap()
+	if p.cfg.Attribute != "" {
+		key.CopyTo(m.PutEmpty(p.cfg.Attribute))
+	}
+	for attr, path := range p.cfg.Fields {
+		if v, ok := lookup(key, path); ok {
+			v.CopyTo(m.PutEmpty(attr))
+		}
+	}
+	return m
+}
+
+// lookup returns the value of a key at a path, key or the dotted path of a field of it, e.g. key.tenant.
+func lookup(key pcommon.Value, path string) (pcommon.Value, bool) {
+	v := key
+	for _, name := range strings.Split(path, ".")[1:] {
+		if v.Type() != pcommon.ValueTypeMap {
+			return pcommon.Value{}, false
+		}
+		var ok bool
+		if v, ok = v.Map().Get(name); !ok {
+			return pcommon.Value{}, false
+		}
+	}
+	return v, true
+}
@@ -0,0 +1,213 @@
+package kafkakeyprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "string", cfg.Encoding)
+	assert.Equal(t, "kafka.key", cfg.Attribute)
+
+	for _, tt := range []struct {
+		modify func(*Config)
+		err    string
+	}{
+		{modify: func(c *Config) { c.Encoding = "" }, err: "encoding: required"},
+		{modify: func(c *Config) { c.Encoding = "avro converter" }, err: `encoding: "avro converter" is neither string, json, base64 nor an extension ID`},
+		{modify: func(c *Config) { c.Attribute = "" }, err: "attribute or fields: at least one is required"},
+		{modify: func(c *Config) { c.Fields = map[string]string{"host.name": "tenant"} }, err: `fields: host.name: path "tenant" must be key or a field of it`},
+		{modify: func(c *Config) { c.Fields = map[string]string{"tenant": "key..id"} }, err: `path "key..id"`},
+		{modify: func(c *Config) { c.Fields = map[string]string{"": "key"} }, err: "fields: empty attribute name"},
+	} {
+		c := *cfg
+		tt.modify(&c)
+		assert.ErrorContains(t, c.Validate(), tt.err)
+	}
+
+	cfg.Encoding = "schema_registry_encoding/keys"
+	cfg.Attribute = ""
+	cfg.Fields = map[string]string{"host.name": "key", "tenant": "key.tenant.id"}
+	require.NoError(t, cfg.Validate())
+}
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// encodingExtension decodes keys made of a zero byte and a host into a map body.
+type encodingExtension struct {
+	nopExtension
+}
+
+func (encodingExtension) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	if len(buf) == 0 || buf[0] != 0 {
+		return plog.Logs{}, errors.New("not in the wire format")
+	}
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetEmptyMap().PutStr("host", string(buf[1:]))
+	return ld, nil
+}
+
+// testHost provides the extensions of the tests.
+type testHost map[component.ID]component.Component
+
+func (h testHost) GetExtensions() map[component.ID]component.Component {
+	return h
+}
+
+func newTestProcessor(t *testing.T, cfg *Config) (processor.Logs, *consumertest.LogsSink) {
+	require.NoError(t, cfg.Validate())
+	sink := new(consumertest.LogsSink)
+	p, err := NewFactory().CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, sink)
+	require.NoError(t, err)
+	host := testHost{
+		component.MustNewID("keys"):   encodingExtension{},
+		component.MustNewID("health"): nopExtension{},
+	}
+	require.NoError(t, p.Start(context.Background(), host))
+	t.Cleanup(func() { require.NoError(t, p.Shutdown(context.Background())) })
+	return p, sink
+}
+
+func TestProcessLogs(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   func(*Config)
+		key   []byte
+		attrs map[string]any
+	}{
+		{
+			name:  "string",
+			key:   []byte("web-1"),
+			attrs: map[string]any{"kafka.key": "web-1", "existing": "yes"},
+		},
+		{
+			name:  "binary string is base64 encoded",
+			key:   []byte{0xff, 0x00},
+			attrs: map[string]any{"kafka.key": "/wA=", "existing": "yes"},
+		},
+		{
+			name:  "base64",
+			cfg:   func(c *Config) { c.Encoding = "base64" },
+			key:   []byte("web-1"),
+			attrs: map[string]any{"kafka.key": "d2ViLTE=", "existing": "yes"},
+		},
+		{
+			name: "JSON with fields",
+			cfg: func(c *Config) {
+				c.Encoding = "json"
+				c.Fields = map[string]string{"host.name": "key.host", "tenant": "key.tenant.id", "region": "key.region"}
+			},
+			key: []byte(`{"host":"web-1","tenant":{"id":7}}`),
+			attrs: map[string]any{
+				"kafka.key": map[string]any{"host": "web-1", "tenant": map[string]any{"id": float64(7)}},
+				"host.name": "web-1",
+				"tenant":    float64(7),
+				"existing":  "yes",
+			},
+		},
+		{
+			name: "invalid JSON falls back to the string",
+			cfg: func(c *Config) {
+				c.Encoding = "json"
+				c.Fields = map[string]string{"host.name": "key.host"}
+			},
+			key:   []byte("web-1"),
+			attrs: map[string]any{"kafka.key": "web-1", "existing": "yes"},
+		},
+		{
+			name: "whole key to the host only, replacing the attribute",
+			cfg: func(c *Config) {
+				c.Attribute = ""
+				c.Fields = map[string]string{"host.name": "key", "existing": "key"}
+			},
+			key:   []byte("web-1"),
+			attrs: map[string]any{"host.name": "web-1", "existing": "web-1"},
+		},
+		{
+			name: "encoding extension",
+			cfg: func(c *Config) {
+				c.Encoding = "keys"
+				c.Fields = map[string]string{"host.name": "key.host"}
+			},
+			key:   []byte("\x00web-1"),
+			attrs: map[string]any{"kafka.key": map[string]any{"host": "web-1"}, "host.name": "web-1", "existing": "yes"},
+		},
+		{
+			name:  "encoding extension failure falls back to the string",
+			cfg:   func(c *Config) { c.Encoding = "keys" },
+			key:   []byte("web-1"),
+			attrs: map[string]any{"kafka.key": "web-1", "existing": "yes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig()
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			p, sink := newTestProcessor(t, cfg)
+
+			ld := plog.NewLogs()
+			lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+			for range 2 {
+				lr := lrs.AppendEmpty()
+				lr.Body().SetStr("hello")
+				lr.Attributes().PutStr("existing", "yes")
+			}
+			ctx := kafkarecord.NewContext(context.Background(), kafkarecord.Record{Topic: "orders", Key: tt.key, HasKey: true})
+			require.NoError(t, p.ConsumeLogs(ctx, ld))
+
+			require.Len(t, sink.AllLogs(), 1)
+			got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			for i := 0; i < got.Len(); i++ {
+				assert.Equal(t, tt.attrs, got.At(i).Attributes().AsRaw())
+				assert.Equal(t, "hello", got.At(i).Body().Str())
+			}
+		})
+	}
+}
+
+func TestProcessLogsWithoutKey(t *testing.T) {
+	p := newKeyProcessor(createDefaultConfig())
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+	ctx := kafkarecord.NewContext(context.Background(), kafkarecord.Record{Topic: "orders"})
+	out, err := p.processLogs(ctx, ld)
+	require.NoError(t, err)
+	assert.Equal(t, 0, out.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Len())
+}
+
+func TestStartErrors(t *testing.T) {
+	for _, tt := range []struct {
+		encoding string
+		err      string
+	}{
+		{encoding: "schema_registry_encoding", err: "encoding: extension schema_registry_encoding is not configured"},
+		{encoding: "health", err: "encoding: extension health is not a logs encoding"},
+	} {
+		cfg := createDefaultConfig()
+		cfg.Encoding = tt.encoding
+		p := newKeyProcessor(cfg)
+		host := testHost{component.MustNewID("health"): nopExtension{}}
+		assert.EqualError(t, p.start(context.Background(), host), tt.err)
+	}
+}
@@ -0,0 +1,58 @@
+package kafkametadataprocessor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Names of the record metadata the processor can attach.
+const (
+	topic     = "topic"
+	partition = "partition"
+	offset    = "offset"
+	key       = "key"
+	timestamp = "timestamp"
+)
+
+var allMetadata = []string{topic, partition, offset, key, timestamp}
+
+// Config configures the kafkametadata processor.
+type Config struct {
+	// Metadata lists the record metadata to attach: topic, partition, offset, key and timestamp. All of them by
+	// default.
+	Metadata []string `mapstructure:"metadata"`
+	// Prefix is prepended to the metadata names to name the fields, "kafka." by default. "kafka_" gives the field
+	// names of the splunk.hec.track.data setting of Splunk Connect for Kafka.
+	Prefix string `mapstructure:"prefix"`
+	// IndexedFields attaches the metadata as log record attributes, which the splunk_hec exporter sends as HEC
+	// indexed fields. When false, the metadata is added to the body of JSON events instead and extracted by Splunk
+	// at search time; other bodies are left as they are.
+	IndexedFields bool `mapstructure:"indexed_fields"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		Metadata:      slices.Clone(allMetadata),
+		Prefix:        "kafka.",
+		IndexedFields: true,
+	}
+}
+
+// Validate checks the metadata names.
+func (cfg *Config) Validate() error {
+	if len(cfg.Metadata) == 0 {
+		return errors.New("metadata: at least one of topic, partition, offset, key and timestamp is required")
+	}
+	seen := map[string]bool{}
+	for _, name := range cfg.Metadata {
+		if !slices.Contains(allMetadata, name) {
+			return fmt.Errorf("metadata: unknown %q, expected topic, partition, offset, key or timestamp", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("metadata: %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Package kafkametadataprocessor implements the kafkametadata processor, which attaches the topic, partition,
+// offset, key and timestamp of the Kafka record every log record was read from, so that any event indexed in
+// Splunk can be traced back to its record.
+package kafkametadataprocessor
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+type metadataProcessor struct {
+	cfg *Config
+}
+
+func newMetadataProcessor(cfg *Config) *metadataProcessor {
+	return &metadataProcessor{cfg: cfg}
+}
+
+func (p *metadataProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	fields := p.fields(kafkarecord.FromContext(ctx))
+	if fields.Len() == 0 {
+		return ld, nil
+	}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				target := lr.Attributes()
+				if !p.cfg.IndexedFields {
+					if lr.Body().Type() != pcommon.ValueTypeMap {
+						continue
+					}
+					target = lr.Body().Map()
+				}
+				fields.Range(func(name string, v pcommon.Value) bool {
+					v.CopyTo(target.PutEmpty(name))
+					return true
+				})
+			}
+		}
+	}
+	return ld, nil
+}
+
+// fields returns the configured metadata of r the record knows, keyed by field name.
+func (p *metadataProcessor) fields(r kafkarecord.Record) pcommon.Map {
+	m := pcommon.NewMap()
+	for _, name := range p.cfg.Metadata {
+		field := p.cfg.Prefix + name
+		switch name {
+		case topic:
+			if r.Topic != "" {
+				m.PutStr(field, r.Topic)
+			}
+		case partition:
+			if r.HasPartition {
+				m.PutInt(field, r.Partition)
+			}
+		case offset:
+			if r.HasOffset {
+				m.PutInt(field, r.Offset)
+			}
+		case key:
+			if !r.HasKey {
+				continue
+			}
+			// Keys are arbitrary bytes; the exporter base64-encodes the ones that are not text.
+			if utf8.Valid(r.Key) {
+				m.PutStr(field, string(r.Key))
+			} else {
+				m.PutEmptyBytes(field).FromRaw(r.Key)
+			}
+		case timestamp:
+			// Milliseconds since the epoch, as Kafka and Splunk Connect for Kafka report it.
+			if r.HasTimestamp() {
+				m.PutInt(field, r.Timestamp.UnixMilli())
+			}
+		}
+	}
+	return m
+}
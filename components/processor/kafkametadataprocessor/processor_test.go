@@ -0,0 +1,149 @@
+package kafkametadataprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+var testRecord = kafkarecord.Record{
+	Topic:        "orders",
+	Partition:    3,
+	HasPartition: true,
+	Offset:       1042,
+	HasOffset:    true,
+	Key:          []byte("customer-7"),
+	HasKey:       true,
+	Timestamp:    time.UnixMilli(1_760_000_000_123).UTC(),
+}
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, allMetadata, cfg.Metadata)
+	assert.True(t, cfg.IndexedFields)
+
+	for _, tt := range []struct {
+		metadata []string
+		err      string
+	}{
+		{metadata: nil, err: "at least one"},
+		{metadata: []string{"topic", "headers"}, err: `unknown "headers"`},
+		{metadata: []string{"offset", "offset"}, err: `"offset" is listed twice`},
+	} {
+		cfg.Metadata = tt.metadata
+		assert.ErrorContains(t, cfg.Validate(), tt.err)
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    func(*Config)
+		record kafkarecord.Record
+		body   func(pcommon.Value)
+		// attrs and want are the expected attributes and body of the log record.
+		attrs map[string]any
+		want  any
+	}{
+		{
+			name:   "indexed fields",
+			record: testRecord,
+			body:   func(v pcommon.Value) { v.SetStr("hello") },
+			attrs: map[string]any{
+				"kafka.topic":     "orders",
+				"kafka.partition": int64(3),
+				"kafka.offset":    int64(1042),
+				"kafka.key":       "customer-7",
+				"kafka.timestamp": int64(1_760_000_000_123),
+			},
+			want: "hello",
+		},
+		{
+			name: "selected metadata with the Splunk Connect for Kafka names",
+			cfg: func(cfg *Config) {
+				cfg.Metadata = []string{topic, offset}
+				cfg.Prefix = "kafka_"
+			},
+			record: testRecord,
+			body:   func(v pcommon.Value) { v.SetStr("hello") },
+			attrs:  map[string]any{"kafka_topic": "orders", "kafka_offset": int64(1042)},
+			want:   "hello",
+		},
+		{
+			name:   "missing metadata is skipped",
+			record: kafkarecord.Record{Topic: "orders", Offset: 0, HasOffset: true},
+			body:   func(v pcommon.Value) { v.SetStr("hello") },
+			attrs:  map[string]any{"kafka.topic": "orders", "kafka.offset": int64(0)},
+			want:   "hello",
+		},
+		{
+			name:   "binary key",
+			cfg:    func(cfg *Config) { cfg.Metadata = []string{key} },
+			record: kafkarecord.Record{Key: []byte{0xff, 0x00}, HasKey: true},
+			body:   func(v pcommon.Value) { v.SetStr("hello") },
+			attrs:  map[string]any{"kafka.key": []byte{0xff, 0x00}},
+			want:   "hello",
+		},
+		{
+			name:   "JSON body",
+			cfg:    func(cfg *Config) { cfg.IndexedFields = false; cfg.Metadata = []string{topic, partition} },
+			record: testRecord,
+			body:   func(v pcommon.Value) { v.SetEmptyMap().PutStr("message", "hello") },
+			attrs:  map[string]any{},
+			want:   map[string]any{"message": "hello", "kafka.topic": "orders", "kafka.partition": int64(3)},
+		},
+		{
+			name:   "text body left as is",
+			cfg:    func(cfg *Config) { cfg.IndexedFields = false },
+			record: testRecord,
+			body:   func(v pcommon.Value) { v.SetStr("hello") },
+			attrs:  map[string]any{},
+			want:   "hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			require.NoError(t, cfg.Validate())
+			sink := new(consumertest.LogsSink)
+			p, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, sink)
+			require.NoError(t, err)
+
+			ld := plog.NewLogs()
+			lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			tt.body(lr.Body())
+			ctx := kafkarecord.NewContext(context.Background(), tt.record)
+			require.NoError(t, p.ConsumeLogs(ctx, ld))
+
+			require.Len(t, sink.AllLogs(), 1)
+			got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			assert.Equal(t, tt.attrs, got.Attributes().AsRaw())
+			assert.Equal(t, tt.want, got.Body().AsRaw())
+		})
+	}
+}
+
+func TestProcessLogsWithoutRecord(t *testing.T) {
+	p := newMetadataProcessor(createDefaultConfig())
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("hello")
+	out, err := p.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, 0, out.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Len())
+}
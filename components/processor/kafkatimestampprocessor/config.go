@@ -0,0 +1,62 @@
+package kafkatimestampprocessor
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+// Sources of the event time.
+const (
+	// sourceExtracted is the time already set on the log record, e.g. by a transform processor extracting it from
+	// the body earlier in the pipeline.
+	sourceExtracted = "extracted"
+	// sourceRecord is the timestamp of the Kafka record.
+	sourceRecord = "record"
+)
+
+// Config configures the kafkatimestamp processor.
+type Config struct {
+	// Sources is the order in which the time of a log record is taken: the first source that has a time wins.
+	// extracted keeps the time the log record already has, record sets the timestamp of the Kafka record. The
+	// default, [extracted, record], uses the record timestamp for events whose body has no timestamp.
+	Sources []string `mapstructure:"sources"`
+	// TimestampTypes lists the record timestamp types to use, CreateTime and LogAppendTime by default. Records whose
+	// timestamp type was not reported by the receiver are always used.
+	TimestampTypes []string `mapstructure:"timestamp_types"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{
+		Sources:        []string{sourceExtracted, sourceRecord},
+		TimestampTypes: []string{kafkarecord.CreateTime, kafkarecord.LogAppendTime},
+	}
+}
+
+// Validate checks the sources and timestamp types.
+func (cfg *Config) Validate() error {
+	if !slices.Contains(cfg.Sources, sourceRecord) {
+		return errors.New("sources: record is required")
+	}
+	if err := checkNames("sources", cfg.Sources, sourceExtracted, sourceRecord); err != nil {
+		return err
+	}
+	if len(cfg.TimestampTypes) == 0 {
+		return errors.New("timestamp_types: at least one of CreateTime and LogAppendTime is required")
+	}
+	return checkNames("timestamp_types", cfg.TimestampTypes, kafkarecord.CreateTime, kafkarecord.LogAppendTime)
+}
+
+func checkNames(setting string, names []string, known ...string) error {
+	for i, name := range names {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("%s: unknown %q, expected %s or %s", setting, name, known[0], known[1])
+		}
+		if slices.Contains(names[:i], name) {
+			return fmt.Errorf("%s: %q is listed twice", setting, name)
+		}
+	}
+	return nil
+}
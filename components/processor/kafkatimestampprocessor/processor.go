@@ -0,0 +1,65 @@
+// Package kafkatimestampprocessor implements the kafkatimestamp processor, which sets the time of log records to
+// the timestamp of the Kafka record they were read from, so that events are indexed in Splunk at the time they
+// were produced rather than at the time they were collected.
+package kafkatimestampprocessor
+
+import (
+	"context"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+type timestampProcessor struct {
+	cfg *Config
+}
+
+func newTimestampProcessor(cfg *Config) *timestampProcessor {
+	return &timestampProcessor{cfg: cfg}
+}
+
+func (p *timestampProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	recordTime, ok := p.recordTime(kafkarecord.FromContext(ctx))
+	if !ok {
+		return ld, nil
+	}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				p.setTime(lrs.At(k), recordTime)
+			}
+		}
+	}
+	return ld, nil
+}
+
+// recordTime returns the timestamp of r when it is known and of an accepted type.
+func (p *timestampProcessor) recordTime(r kafkarecord.Record) (pcommon.Timestamp, bool) {
+	if !r.HasTimestamp() {
+		return 0, false
+	}
+	if r.TimestampType != "" && !slices.Contains(p.cfg.TimestampTypes, r.TimestampType) {
+		return 0, false
+	}
+	return pcommon.NewTimestampFromTime(r.Timestamp), true
+}
+
+func (p *timestampProcessor) setTime(lr plog.LogRecord, recordTime pcommon.Timestamp) {
+	for _, source := range p.cfg.Sources {
+		switch source {
+		case sourceExtracted:
+			if lr.Timestamp() != 0 {
+				return
+			}
+		case sourceRecord:
+			lr.SetTimestamp(recordTime)
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package kafkatimestampprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/kafkarecord"
+)
+
+var (
+	recordTime    = time.Date(2021, 3, 4, 5, 6, 7, 89_000_000, time.UTC)
+	extractedTime = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	require.NoError(t, cfg.Validate())
+
+	for _, tt := range []struct {
+		sources []string
+		types   []string
+		err     string
+	}{
+		{sources: []string{"extracted"}, types: cfg.TimestampTypes, err: "sources: record is required"},
+		{sources: []string{"record", "body"}, types: cfg.TimestampTypes, err: `sources: unknown "body"`},
+		{sources: []string{"record", "record"}, types: cfg.TimestampTypes, err: `sources: "record" is listed twice`},
+		{sources: []string{"record"}, types: nil, err: "timestamp_types: at least one"},
+		{sources: []string{"record"}, types: []string{"NoTimestampType"}, err: `timestamp_types: unknown "NoTimestampType"`},
+	} {
+		c := *cfg
+		c.Sources, c.TimestampTypes = tt.sources, tt.types
+		assert.ErrorContains(t, c.Validate(), tt.err)
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	record := kafkarecord.Record{Topic: "orders", Timestamp: recordTime, TimestampType: kafkarecord.CreateTime}
+	tests := []struct {
+		name   string
+		cfg    func(*Config)
+		record kafkarecord.Record
+		// extracted is the time the log record has before the processor, zero when none.
+		extracted time.Time
+		want      time.Time
+	}{
+		{
+			name:   "record timestamp",
+			record: record,
+			want:   recordTime,
+		},
+		{
+			name:      "extracted time first",
+			record:    record,
+			extracted: extractedTime,
+			want:      extractedTime,
+		},
+		{
+			name:      "record timestamp first",
+			cfg:       func(cfg *Config) { cfg.Sources = []string{sourceRecord, sourceExtracted} },
+			record:    record,
+			extracted: extractedTime,
+			want:      recordTime,
+		},
+		{
+			name:      "record without timestamp",
+			record:    kafkarecord.Record{Topic: "orders"},
+			extracted: extractedTime,
+			want:      extractedTime,
+		},
+		{
+			name:   "timestamp type not accepted",
+			cfg:    func(cfg *Config) { cfg.TimestampTypes = []string{kafkarecord.LogAppendTime} },
+			record: record,
+		},
+		{
+			name:   "timestamp type not reported",
+			cfg:    func(cfg *Config) { cfg.TimestampTypes = []string{kafkarecord.LogAppendTime} },
+			record: kafkarecord.Record{Timestamp: recordTime},
+			want:   recordTime,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			if tt.cfg != nil {
+				tt.cfg(cfg)
+			}
+			require.NoError(t, cfg.Validate())
+			sink := new(consumertest.LogsSink)
+			p, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, sink)
+			require.NoError(t, err)
+
+			ld := plog.NewLogs()
+			lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+			lr.Body().SetStr("hello")
+			if !tt.extracted.IsZero() {
+				lr.SetTimestamp(pcommon.NewTimestampFromTime(tt.extracted))
+			}
+			require.NoError(t, p.ConsumeLogs(kafkarecord.NewContext(context.Background(), tt.record), ld))
+
+			require.Len(t, sink.AllLogs(), 1)
+			got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Timestamp()
+			if tt.want.IsZero() {
+				assert.Zero(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got.AsTime())
+		})
+	}
+}
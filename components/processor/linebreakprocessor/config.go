@@ -0,0 +1,90 @@
+package linebreakprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/strptime"
+)
+
+// timestampGroup is the name of the capture group holding the timestamp of an event.
+const timestampGroup = "timestamp"
+
+// Config configures the linebreak processor.
+type Config struct {
+	// Delimiter is the literal text separating the events of a record, "\n" when neither Delimiter nor Regex is
+	// set. It is the splunk.hec.raw.line.breaker setting of Splunk Connect for Kafka.
+	Delimiter string `mapstructure:"delimiter"`
+	// Regex is a regular expression matching the text separating the events of a record, instead of Delimiter.
+	// When it has capture groups, only the text of the first one separates events and the rest of the match stays
+	// with them, like LINE_BREAKER in props.conf.
+	Regex string `mapstructure:"regex"`
+	// KeepEmpty keeps events that are empty or only whitespace. They are dropped by default, since HEC rejects them.
+	KeepEmpty bool `mapstructure:"keep_empty"`
+	// MaxEvents is the maximum number of events a record is split into; the rest of the record is kept unsplit in
+	// the last one. 0 means no limit.
+	MaxEvents int `mapstructure:"max_events"`
+	// Timestamp optionally sets the time of every event from a timestamp in its text.
+	Timestamp *TimestampConfig `mapstructure:"timestamp"`
+}
+
+// TimestampConfig configures the extraction of the time of events.
+type TimestampConfig struct {
+	// Regex matches the timestamp in the text of an event, in a capture group named timestamp.
+	Regex string `mapstructure:"regex"`
+	// Format is the strptime format of the timestamp, e.g. %Y-%m-%d %H:%M:%S.
+	Format string `mapstructure:"format"`
+	// Location is the IANA time zone of timestamps without a zone, UTC by default.
+	Location string `mapstructure:"location"`
+}
+
+func createDefaultConfig() *Config {
+	return &Config{MaxEvents: 1000}
+}
+
+// Validate checks the delimiter, the regular expressions and the timestamp format.
+func (cfg *Config) Validate() error {
+	if cfg.Delimiter != "" && cfg.Regex != "" {
+		return errors.New("delimiter and regex are mutually exclusive")
+	}
+	if cfg.Regex != "" {
+		re, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return fmt.Errorf("regex: %w", err)
+		}
+		if re.MatchString("") {
+			return fmt.Errorf("regex: %q matches empty text", cfg.Regex)
+		}
+	}
+	if cfg.MaxEvents < 0 {
+		return errors.New("max_events: must not be negative")
+	}
+	if cfg.Timestamp != nil {
+		if err := cfg.Timestamp.validate(); err != nil {
+			return fmt.Errorf("timestamp: %w", err)
+		}
+	}
+	return nil
+}
+
+func (cfg *TimestampConfig) validate() error {
+	re, err := regexp.Compile(cfg.Regex)
+	if err != nil {
+		return fmt.Errorf("regex: %w", err)
+	}
+	if re.SubexpIndex(timestampGroup) < 0 {
+		return fmt.Errorf("regex: %q has no capture group named %s", cfg.Regex, timestampGroup)
+	}
+	if cfg.Format == "" {
+		return errors.New("format: required")
+	}
+	if _, err := strptime.Layout(cfg.Format); err != nil {
+		return fmt.Errorf("format: %w", err)
+	}
+	if _, err := time.LoadLocation(cfg.Location); err != nil {
+		return fmt.Errorf("location: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package linebreakprocessor implements the linebreak processor, which splits log records whose body holds many
+// events, e.g. Kafka records in which producers batch log lines, into one log record per event.
+package linebreakprocessor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/components/internal/strptime"
+)
+
+type lineBreaker struct {
+	cfg       *Config
+	delimiter string
+	regex     *regexp.Regexp
+
+	timestampRegex  *regexp.Regexp
+	timestampLayout string
+	location        *time.Location
+}
+
+func newLineBreaker(cfg *Config) (*lineBreaker, error) {
+	b := &lineBreaker{cfg: cfg, delimiter: cfg.Delimiter}
+	if cfg.Regex != "" {
+		re, err := regexp.Compile(cfg.Regex)
+		if err != nil {
+			return nil, err
+		}
+		b.regex = re
+	} else if b.delimiter == "" {
+		b.delimiter = "\n"
+	}
+	if ts := cfg.Timestamp; ts != nil {
+		var err error
+		if b.timestampRegex, err = regexp.Compile(ts.Regex); err != nil {
+			return nil, err
+		}
+		if b.timestampLayout, err = strptime.Layout(ts.Format); err != nil {
+			return nil, err
+		}
+		if b.location, err = time.LoadLocation(ts.Location); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (b *lineBreaker) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			b.breakRecords(sls.At(j).LogRecords())
+		}
+	}
+	return ld, nil
+}
+
+// breakRecords replaces the log records of lrs holding many events with one copy per event. Records whose body is
+// not a string are left as they are.
+func (b *lineBreaker) breakRecords(lrs plog.LogRecordSlice) {
+	out := plog.NewLogRecordSlice()
+	out.EnsureCapacity(lrs.Len())
+	for i := 0; i < lrs.Len(); i++ {
+		lr := lrs.At(i)
+		if lr.Body().Type() != pcommon.ValueTypeStr {
+			lr.MoveTo(out.AppendEmpty())
+			continue
+		}
+		for _, event := range b.split(lr.Body().Str()) {
+			child := out.AppendEmpty()
+			lr.CopyTo(child)
+			child.Body().SetStr(event)
+			b.setTime(child, event)
+		}
+	}
+	lrs.RemoveIf(func(plog.LogRecord) bool { return true })
+	out.MoveAndAppendTo(lrs)
+}
+
+// split returns the events of s, without the empty ones unless KeepEmpty is set.
+func (b *lineBreaker) split(s string) []string {
+	var events []string
+	start := 0
+	for _, sep := range b.separators(s) {
+		if event := s[start:sep[0]]; b.keep(event) {
+			if b.cfg.MaxEvents > 0 && len(events) == b.cfg.MaxEvents-1 {
+				break
+			}
+			events = append(events, event)
+		}
+		start = sep[1]
+	}
+	if rest := s[start:]; b.keep(rest) {
+		events = append(events, rest)
+	}
+	return events
+}
+
+func (b *lineBreaker) keep(event string) bool {
+	return b.cfg.KeepEmpty || strings.TrimSpace(event) != ""
+}
+
+// separators returns the start and end offsets of the text separating the events of s.
+func (b *lineBreaker) separators(s string) [][2]int {
+	var seps [][2]int
+	if b.regex == nil {
+		for offset := 0; ; {
+			i := strings.Index(s[offset:], b.delimiter)
+			if i < 0 {
+				return seps
+			}
+			seps = append(seps, [2]int{offset + i, offset + i + len(b.delimiter)})
+			offset += i + len(b.delimiter)
+		}
+	}
+	for _, m := range b.regex.FindAllStringSubmatchIndex(s, -1) {
+		// Like LINE_BREAKER, only the first capture group separates events when there is one.
+		if len(m) > 2 && m[2] >= 0 {
+			m = m[2:]
+		}
+		seps = append(seps, [2]int{m[0], m[1]})
+	}
+	return seps
+}
+
+// setTime sets the time of lr to the timestamp found in event. Events without a timestamp that parses keep the
+// time of the record they were split from.
+func (b *lineBreaker) setTime(lr plog.LogRecord, event string) {
+	if b.timestampRegex == nil {
+		return
+	}
+	m := b.timestampRegex.FindStringSubmatch(event)
+	if m == nil {
+		return
+	}
+	t, err := time.ParseInLocation(b.timestampLayout, m[b.timestampRegex.SubexpIndex(timestampGroup)], b.location)
+	if err != nil {
+		return
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(t))
+}
@@ -0,0 +1,170 @@
+package linebreakprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processortest"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig().(*Config)
+	require.NoError(t, componenttest.CheckConfigStruct(cfg))
+	require.NoError(t, cfg.Validate())
+
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{name: "delimiter and regex", cfg: Config{Delimiter: "####", Regex: `\n`}, err: "mutually exclusive"},
+		{name: "invalid regex", cfg: Config{Regex: `(`}, err: "regex: error parsing regexp"},
+		{name: "empty match", cfg: Config{Regex: `\n*`}, err: "matches empty text"},
+		{name: "negative limit", cfg: Config{MaxEvents: -1}, err: "max_events"},
+		{
+			name: "timestamp without group",
+			cfg:  Config{Timestamp: &TimestampConfig{Regex: `^\S+`, Format: "%Y"}},
+			err:  "timestamp: regex: \"^\\\\S+\" has no capture group named timestamp",
+		},
+		{
+			name: "timestamp without format",
+			cfg:  Config{Timestamp: &TimestampConfig{Regex: `^(?P<timestamp>\S+)`}},
+			err:  "timestamp: format: required",
+		},
+		{
+			name: "unsupported format",
+			cfg:  Config{Timestamp: &TimestampConfig{Regex: `^(?P<timestamp>\S+)`, Format: "%Q"}},
+			err:  "timestamp: format: unsupported directive %Q",
+		},
+		{
+			name: "epoch seconds",
+			cfg:  Config{Timestamp: &TimestampConfig{Regex: `^(?P<timestamp>\S+)`, Format: "%s"}},
+			err:  "timestamp: format: fractional seconds directive %s must follow '.' or ','",
+		},
+		{
+			name: "fractional seconds without separator",
+			cfg:  Config{Timestamp: &TimestampConfig{Regex: `^(?P<timestamp>\S+)`, Format: "%H%M%S%L"}},
+			err:  "timestamp: format: fractional seconds directive %L must follow '.' or ','",
+		},
+		{
+			name: "unknown location",
+			cfg:  Config{Timestamp: &TimestampConfig{Regex: `^(?P<timestamp>\S+)`, Format: "%F", Location: "Mars/Olympus"}},
+			err:  "timestamp: location",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, tt.cfg.Validate(), tt.err)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		in   string
+		want []string
+	}{
+		{name: "lines", in: "a\nb\n\nc\n", want: []string{"a", "b", "c"}},
+		{name: "single line", in: "a", want: []string{"a"}},
+		{name: "keep empty", cfg: Config{KeepEmpty: true}, in: "a\n\nb", want: []string{"a", "", "b"}},
+		{name: "only whitespace", in: "\n \n", want: nil},
+		{name: "literal delimiter", cfg: Config{Delimiter: "####"}, in: "a####b\n1####c", want: []string{"a", "b\n1", "c"}},
+		{name: "regex", cfg: Config{Regex: `[\r\n]+`}, in: "a\r\nb\n\nc", want: []string{"a", "b", "c"}},
+		{
+			name: "regex first group",
+			cfg:  Config{Regex: `([\r\n]+)\d{4}-`},
+			in:   "2025-01-01 a\n  at x\n2025-01-02 b",
+			want: []string{"2025-01-01 a\n  at x", "2025-01-02 b"},
+		},
+		{name: "limit", cfg: Config{MaxEvents: 2}, in: "a\n\nb\nc\nd", want: []string{"a", "b\nc\nd"}},
+		{name: "limit of one", cfg: Config{MaxEvents: 1}, in: "a\nb", want: []string{"a\nb"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.cfg.Validate())
+			b, err := newLineBreaker(&tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, b.split(tt.in))
+		})
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	recordTime := time.Date(2025, 6, 26, 0, 0, 0, 0, time.UTC)
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Timestamp = &TimestampConfig{
+		Regex:    `^\[(?P<timestamp>[^\]]+)\]`,
+		Format:   "%Y-%m-%d %H:%M:%S",
+		Location: "Europe/Warsaw",
+	}
+	require.NoError(t, cfg.Validate())
+	sink := new(consumertest.LogsSink)
+	p, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, sink)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("kafka.header.env", "prod")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	lr := lrs.AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(recordTime))
+	lr.Attributes().PutStr("kafka.topic", "app")
+	lr.Body().SetStr("[2025-06-26 11:45:00] first\n[2025-06-26 11:45:01] second\nno timestamp")
+	lrs.AppendEmpty().Body().SetEmptyMap().PutStr("message", "a\nb")
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+
+	require.Len(t, sink.AllLogs(), 1)
+	out := sink.AllLogs()[0].ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{"kafka.header.env": "prod"}, out.Resource().Attributes().AsRaw())
+	got := out.ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 4, got.Len())
+
+	warsaw, err := time.LoadLocation("Europe/Warsaw")
+	require.NoError(t, err)
+	for i, want := range []struct {
+		body string
+		time time.Time
+	}{
+		{body: "[2025-06-26 11:45:00] first", time: time.Date(2025, 6, 26, 11, 45, 0, 0, warsaw)},
+		{body: "[2025-06-26 11:45:01] second", time: time.Date(2025, 6, 26, 11, 45, 1, 0, warsaw)},
+		{body: "no timestamp", time: recordTime},
+	} {
+		child := got.At(i)
+		assert.Equal(t, want.body, child.Body().Str())
+		assert.True(t, want.time.Equal(child.Timestamp().AsTime()), "time of %q is %s", want.body, child.Timestamp().AsTime())
+		assert.Equal(t, map[string]any{"kafka.topic": "app"}, child.Attributes().AsRaw())
+	}
+	assert.Equal(t, map[string]any{"message": "a\nb"}, got.At(3).Body().AsRaw())
+}
+
+func TestProcessLogsFractionalSeconds(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Timestamp = &TimestampConfig{Regex: `^(?P<timestamp>\S+ \S+)`, Format: "%H%M%S%L"}
+	_, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, new(consumertest.LogsSink))
+	require.ErrorContains(t, err, "fractional seconds directive %L must follow '.' or ','")
+
+	cfg.Timestamp.Format = "%Y-%m-%d %H:%M:%S,%s"
+	require.NoError(t, cfg.Validate())
+	sink := new(consumertest.LogsSink)
+	p, err := factory.CreateLogs(context.Background(), processortest.NewNopSettings(componentType), cfg, sink)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().
+		SetStr("2025-06-26 11:45:00,123456789 first\n2025-06-26 11:45:01,5 second")
+	require.NoError(t, p.ConsumeLogs(context.Background(), ld))
+
+	got := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, got.Len())
+	assert.True(t, time.Date(2025, 6, 26, 11, 45, 0, 123456789, time.UTC).Equal(got.At(0).Timestamp().AsTime()))
+	assert.True(t, time.Date(2025, 6, 26, 11, 45, 1, 500000000, time.UTC).Equal(got.At(1).Timestamp().AsTime()))
+}
@@ -0,0 +1,33 @@
+#!/bin/bash
+# Builds the SOC4Kafka collector distribution described by builder-config.yaml with the OpenTelemetry Collector
+# Builder. The binary is written to _build/otelcol-soc4kafka, or to the path given as first argument.
+#
+# GOOS and GOARCH select the target platform, e.g. GOOS=darwin GOARCH=arm64 ./build.sh otelcol_darwin_arm64
+set -euo pipefail
+
+OUTPUT="${1:-}"
+if [ -n "$OUTPUT" ] && [ "${OUTPUT#/}" = "$OUTPUT" ]; then
+  OUTPUT="$PWD/$OUTPUT"
+fi
+cd "$(dirname "$0")"
+
+# The builder is released with the collector core, keep it on the version of the distribution.
+OCB_VERSION="${OCB_VERSION:-$(sed -nr 's/^  version: (.+)$/\1/p' builder-config.yaml)}"
+
+# The Kafka receiver is built from a patched copy adding the record key and timestamp to the client metadata, see
+# patches/kafkareceiver-record-metadata.patch. builder-config.yaml replaces the module with _patched/kafkareceiver.
+KAFKARECEIVER=github.com/open-telemetry/opentelemetry-collector-contrib/receiver/kafkareceiver
+KAFKARECEIVER_VERSION="$(sed -nr "s#^  - gomod: ${KAFKARECEIVER} (.+)\$#\1#p" builder-config.yaml)"
+KAFKARECEIVER_DIR="$(go mod download -json "${KAFKARECEIVER}@${KAFKARECEIVER_VERSION}" | sed -nr 's/^\t"Dir": "(.+)",$/\1/p')"
+rm -rf _patched/kafkareceiver
+mkdir -p _patched
+cp -r "$KAFKARECEIVER_DIR" _patched/kafkareceiver
+chmod -R u+w _patched/kafkareceiver
+patch -s -d _patched/kafkareceiver -p1 < patches/kafkareceiver-record-metadata.patch
+
+CGO_ENABLED=0 go run "go.opentelemetry.io/collector/cmd/builder@v${OCB_VERSION}" --config builder-config.yaml
+
+if [ -n "$OUTPUT" ]; then
+  cp _build/otelcol-soc4kafka "$OUTPUT"
+  echo "Collector written to $OUTPUT"
+fi
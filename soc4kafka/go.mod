@@ -0,0 +1,16 @@
+module github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka
+
+go 1.24.2
+
+require (
+	github.com/confluentinc/confluent-kafka-go/v2 v2.10.1
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/term v0.40.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+)
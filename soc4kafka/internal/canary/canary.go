@@ -0,0 +1,186 @@
+// Package canary measures the end-to-end latency of SOC4Kafka pipelines: a uniquely marked message is produced to a
+// consumed topic, then searched in Splunk until it is indexed.
+package canary
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+// Target is a topic probed through a receiver.
+type Target struct {
+	Receiver string `json:"receiver"`
+	Topic    string `json:"topic"`
+	Encoding string `json:"encoding"`
+}
+
+// Targets returns a target per topic consumed by r, regex topics are matched against existing. Only the text, raw
+// and json encodings are supported: the canary message must be indexed with its marker readable.
+func Targets(r collectorconfig.KafkaReceiver, existing []string) ([]Target, error) {
+	encoding := r.Encoding
+	if encoding == "" {
+		encoding = "otlp_proto"
+	}
+	if encoding != "raw" && encoding != "json" && encoding != "text" && !strings.HasPrefix(encoding, "text_") {
+		return nil, fmt.Errorf("%s: encoding %s is not supported, the canary produces text, raw or json messages", r.ID, encoding)
+	}
+	topics, err := r.ResolveTopics(existing)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", r.ID, err)
+	}
+	var targets []Target
+	for _, t := range topics {
+		targets = append(targets, Target{Receiver: r.ID, Topic: t, Encoding: encoding})
+	}
+	return targets, nil
+}
+
+// Producer writes records to a topic, see kafkaclient.Client.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) (kafkaclient.TopicPartition, int64, error)
+}
+
+// Searcher runs searches, see splunksearch.Client.
+type Searcher interface {
+	Results(ctx context.Context, query, earliest, latest string) ([]map[string]any, error)
+}
+
+// Result is the outcome of a probe. Latency is measured from the production of the message to its index time, as
+// recorded by Splunk with a one second resolution.
+type Result struct {
+	Target
+	ID        string    `json:"id"`
+	Sent      time.Time `json:"sent"`
+	Partition int32     `json:"partition"`
+	Offset    int64     `json:"offset"`
+	Latency   float64   `json:"latency_seconds,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Success reports whether the message was indexed in time.
+func (r Result) Success() bool {
+	return r.Error == ""
+}
+
+// Prober runs probes.
+type Prober struct {
+	Searcher Searcher
+	// Index searched for the canary messages, defaults to all the non internal indexes.
+	Index string
+	// Timeout is the time allowed for a message to be searchable, defaults to five minutes.
+	Timeout time.Duration
+	// PollInterval between two searches, defaults to ten seconds.
+	PollInterval time.Duration
+	// Now and NewID default to time.Now and a random identifier.
+	Now   func() time.Time
+	NewID func() string
+}
+
+// Message returns the value of the canary message of a target. The identifier is a single search term.
+func Message(t Target, id string, sent time.Time) []byte {
+	if t.Encoding == "json" {
+		data, _ := json.Marshal(map[string]string{
+			"message":   "soc4kafka canary " + id,
+			"canary_id": id,
+			"receiver":  t.Receiver,
+			"topic":     t.Topic,
+			"sent":      sent.UTC().Format(time.RFC3339Nano),
+		})
+		return data
+	}
+	return fmt.Appendf(nil, "soc4kafka canary %s receiver=%s topic=%s sent=%s", id, t.Receiver, t.Topic, sent.UTC().Format(time.RFC3339Nano))
+}
+
+// Query returns the search finding the canary message id produced at sent.
+func (p *Prober) Query(id string, sent time.Time) string {
+	index := p.Index
+	if index == "" {
+		index = "*"
+	}
+	return fmt.Sprintf(`search index=%s _index_earliest=%d "%s" | head 1 | eval indextime=_indextime | table indextime`,
+		index, sent.Unix()-1, id)
+}
+
+// Probe produces the canary message of t and searches it until it is found or the timeout expires.
+func (p *Prober) Probe(ctx context.Context, producer Producer, t Target) Result {
+	now, newID := p.Now, p.NewID
+	if now == nil {
+		now = time.Now
+	}
+	if newID == nil {
+		newID = randomID
+	}
+	timeout, interval := p.Timeout, p.PollInterval
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	res := Result{Target: t, ID: newID(), Sent: now()}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	tp, offset, err := producer.Produce(ctx, t.Topic, []byte(res.ID), Message(t, res.ID, res.Sent))
+	if err != nil {
+		res.Error = fmt.Sprintf("producing the canary message: %v", err)
+		return res
+	}
+	res.Partition, res.Offset = tp.Partition, offset
+
+	var searchErr error
+	for {
+		select {
+		case <-ctx.Done():
+			res.Error = fmt.Sprintf("the canary message was not found in Splunk within %s", timeout)
+			if searchErr != nil {
+				res.Error += fmt.Sprintf(", the last search failed: %v", searchErr)
+			}
+			return res
+		case <-time.After(interval):
+		}
+		// Events timestamped from their content may be in the past or in the future, _index_earliest narrows the search.
+		results, err := p.Searcher.Results(ctx, p.Query(res.ID, res.Sent), "-24h", "+24h")
+		if err != nil {
+			if ctx.Err() == nil {
+				searchErr = err
+			}
+			continue
+		}
+		if len(results) == 0 {
+			continue
+		}
+		indexed := now()
+		if s, ok := indexTime(results[0]); ok {
+			indexed = time.Unix(s, 0)
+		}
+		res.Latency = max(indexed.Sub(res.Sent).Seconds(), 0)
+		return res
+	}
+}
+
+func indexTime(result map[string]any) (int64, bool) {
+	for _, field := range []string{"indextime", "_indextime"} {
+		if s, ok := result[field].(string); ok {
+			if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "soc4kafka-canary-" + hex.EncodeToString(b)
+}
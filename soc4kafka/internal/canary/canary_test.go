@@ -0,0 +1,141 @@
+package canary
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/splunksearch"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/splunktest"
+)
+
+func TestTargets(t *testing.T) {
+	r := collectorconfig.KafkaReceiver{ID: "kafka/main", Topics: []string{"orders", "^app-.*"}, ExcludeTopics: []string{"app-internal"}, Encoding: "json"}
+	targets, err := Targets(r, []string{"app-a", "app-internal", "orders"})
+	require.NoError(t, err)
+	assert.Equal(t, []Target{
+		{Receiver: "kafka/main", Topic: "app-a", Encoding: "json"},
+		{Receiver: "kafka/main", Topic: "orders", Encoding: "json"},
+	}, targets)
+
+	_, err = Targets(collectorconfig.KafkaReceiver{ID: "kafka/otlp", Topics: []string{"otlp_logs"}}, nil)
+	require.EqualError(t, err, "kafka/otlp: encoding otlp_proto is not supported, the canary produces text, raw or json messages")
+}
+
+type fakeProducer struct {
+	values []string
+	err    error
+}
+
+func (p *fakeProducer) Produce(_ context.Context, topic string, _, value []byte) (kafkaclient.TopicPartition, int64, error) {
+	if p.err != nil {
+		return kafkaclient.TopicPartition{}, 0, p.err
+	}
+	p.values = append(p.values, string(value))
+	return kafkaclient.TopicPartition{Topic: topic, Partition: 2}, int64(len(p.values) - 1), nil
+}
+
+func TestProbe(t *testing.T) {
+	server := splunktest.NewServer(t)
+	producer := &fakeProducer{}
+	sent := time.Now().Add(-3 * time.Second)
+	server.Feed = func() []string { return producer.values }
+	p := &Prober{
+		Searcher:     &splunksearch.Client{BaseURL: server.URL, User: splunktest.User, Password: splunktest.Password, HTTPClient: splunksearch.NewHTTPClient(true, 5*time.Second), PollInterval: time.Millisecond},
+		Index:        "kafka",
+		PollInterval: time.Millisecond,
+		Now:          func() time.Time { return sent },
+		NewID:        func() string { return "soc4kafka-canary-0011" },
+	}
+	target := Target{Receiver: "kafka/main", Topic: "logs", Encoding: "text"}
+
+	res := p.Probe(context.Background(), producer, target)
+	require.True(t, res.Success(), res.Error)
+	assert.Equal(t, int32(2), res.Partition)
+	assert.InDelta(t, 3, res.Latency, 1.5)
+	assert.Equal(t, "soc4kafka canary soc4kafka-canary-0011 receiver=kafka/main topic=logs sent="+sent.UTC().Format(time.RFC3339Nano), producer.values[0])
+	assert.Equal(t, fmt.Sprintf(`search index=kafka _index_earliest=%d "soc4kafka-canary-0011" | head 1 | eval indextime=_indextime | table indextime`,
+		sent.Unix()-1), server.Queries[0])
+
+	server.Feed = nil
+	p.NewID = func() string { return "soc4kafka-canary-0022" }
+	p.Timeout = 50 * time.Millisecond
+	res = p.Probe(context.Background(), producer, target)
+	assert.Equal(t, "the canary message was not found in Splunk within 50ms", res.Error)
+
+	res = p.Probe(context.Background(), &fakeProducer{err: errors.New("topic authorization failed")}, target)
+	assert.Equal(t, "producing the canary message: topic authorization failed", res.Error)
+}
+
+func TestMessageJSON(t *testing.T) {
+	sent := time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)
+	assert.JSONEq(t, `{"message": "soc4kafka canary id1", "canary_id": "id1", "receiver": "kafka/main", "topic": "orders", "sent": "2024-05-01T10:20:30Z"}`,
+		string(Message(Target{Receiver: "kafka/main", Topic: "orders", Encoding: "json"}, "id1", sent)))
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	sent := time.Unix(1714558830, 500_000_000)
+	a := Target{Receiver: "kafka/main", Topic: "b-logs"}
+	b := Target{Receiver: "kafka/main", Topic: `a"logs`}
+	m.Observe(Result{Target: a, Sent: sent, Latency: 4})
+	m.Observe(Result{Target: a, Sent: sent, Latency: 90})
+	m.Observe(Result{Target: b, Sent: sent, Error: "not found"})
+
+	var buf bytes.Buffer
+	require.NoError(t, m.Write(&buf))
+	assert.Equal(t, `# HELP soc4kafka_canary_probes_total Canary messages produced, by result.
+# TYPE soc4kafka_canary_probes_total counter
+soc4kafka_canary_probes_total{receiver="kafka/main",topic="a\"logs",result="success"} 0
+soc4kafka_canary_probes_total{receiver="kafka/main",topic="a\"logs",result="failure"} 1
+soc4kafka_canary_probes_total{receiver="kafka/main",topic="b-logs",result="success"} 2
+soc4kafka_canary_probes_total{receiver="kafka/main",topic="b-logs",result="failure"} 0
+# HELP soc4kafka_canary_success Whether the last canary message was indexed within the timeout.
+# TYPE soc4kafka_canary_success gauge
+soc4kafka_canary_success{receiver="kafka/main",topic="a\"logs"} 0
+soc4kafka_canary_success{receiver="kafka/main",topic="b-logs"} 1
+# HELP soc4kafka_canary_last_probe_timestamp_seconds Time the last canary message was produced.
+# TYPE soc4kafka_canary_last_probe_timestamp_seconds gauge
+soc4kafka_canary_last_probe_timestamp_seconds{receiver="kafka/main",topic="a\"logs"} 1714558830.5
+soc4kafka_canary_last_probe_timestamp_seconds{receiver="kafka/main",topic="b-logs"} 1714558830.5
+# HELP soc4kafka_canary_last_latency_seconds End-to-end latency of the last canary message indexed.
+# TYPE soc4kafka_canary_last_latency_seconds gauge
+soc4kafka_canary_last_latency_seconds{receiver="kafka/main",topic="b-logs"} 90
+# HELP soc4kafka_canary_latency_seconds End-to-end latency of the canary messages, from their production to their indexing.
+# TYPE soc4kafka_canary_latency_seconds histogram
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="1"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="2"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="5"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="10"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="20"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="30"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="60"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="120"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="300"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="600"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="a\"logs",le="+Inf"} 0
+soc4kafka_canary_latency_seconds_sum{receiver="kafka/main",topic="a\"logs"} 0
+soc4kafka_canary_latency_seconds_count{receiver="kafka/main",topic="a\"logs"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="1"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="2"} 0
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="5"} 1
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="10"} 1
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="20"} 1
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="30"} 1
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="60"} 1
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="120"} 2
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="300"} 2
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="600"} 2
+soc4kafka_canary_latency_seconds_bucket{receiver="kafka/main",topic="b-logs",le="+Inf"} 2
+soc4kafka_canary_latency_seconds_sum{receiver="kafka/main",topic="b-logs"} 94
+soc4kafka_canary_latency_seconds_count{receiver="kafka/main",topic="b-logs"} 2
+`, buf.String())
+}
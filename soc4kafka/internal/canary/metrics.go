@@ -0,0 +1,134 @@
+package canary
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the latency histogram.
+var LatencyBuckets = []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600}
+
+// Metrics aggregates the probe results by target and exposes them in the Prometheus text format.
+type Metrics struct {
+	mu     sync.Mutex
+	series map[Target]*series
+}
+
+type series struct {
+	success, failure uint64
+	last             Result
+	lastLatency      float64
+	buckets          []uint64
+	sum              float64
+	count            uint64
+}
+
+// NewMetrics returns metrics without results.
+func NewMetrics() *Metrics {
+	return &Metrics{series: map[Target]*series{}}
+}
+
+// Observe records the result of a probe.
+func (m *Metrics) Observe(r Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.series[r.Target]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(LatencyBuckets))}
+		m.series[r.Target] = s
+	}
+	s.last = r
+	if !r.Success() {
+		s.failure++
+		return
+	}
+	s.success++
+	s.lastLatency = r.Latency
+	for i, le := range LatencyBuckets {
+		if r.Latency <= le {
+			s.buckets[i]++
+		}
+	}
+	s.sum += r.Latency
+	s.count++
+}
+
+// Write writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	targets := make([]Target, 0, len(m.series))
+	for t := range m.series {
+		targets = append(targets, t)
+	}
+	slices.SortFunc(targets, func(a, b Target) int {
+		return cmp.Or(strings.Compare(a.Receiver, b.Receiver), strings.Compare(a.Topic, b.Topic))
+	})
+
+	bw := bufio.NewWriter(w)
+	header := func(name, typ, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+	header("soc4kafka_canary_probes_total", "counter", "Canary messages produced, by result.")
+	for _, t := range targets {
+		s := m.series[t]
+		fmt.Fprintf(bw, "soc4kafka_canary_probes_total{%s,result=\"success\"} %d\n", labels(t), s.success)
+		fmt.Fprintf(bw, "soc4kafka_canary_probes_total{%s,result=\"failure\"} %d\n", labels(t), s.failure)
+	}
+	header("soc4kafka_canary_success", "gauge", "Whether the last canary message was indexed within the timeout.")
+	for _, t := range targets {
+		success := 0
+		if m.series[t].last.Success() {
+			success = 1
+		}
+		fmt.Fprintf(bw, "soc4kafka_canary_success{%s} %d\n", labels(t), success)
+	}
+	header("soc4kafka_canary_last_probe_timestamp_seconds", "gauge", "Time the last canary message was produced.")
+	for _, t := range targets {
+		fmt.Fprintf(bw, "soc4kafka_canary_last_probe_timestamp_seconds{%s} %s\n", labels(t), formatFloat(float64(m.series[t].last.Sent.UnixMilli())/1000))
+	}
+	header("soc4kafka_canary_last_latency_seconds", "gauge", "End-to-end latency of the last canary message indexed.")
+	for _, t := range targets {
+		if s := m.series[t]; s.count > 0 {
+			fmt.Fprintf(bw, "soc4kafka_canary_last_latency_seconds{%s} %s\n", labels(t), formatFloat(s.lastLatency))
+		}
+	}
+	header("soc4kafka_canary_latency_seconds", "histogram", "End-to-end latency of the canary messages, from their production to their indexing.")
+	for _, t := range targets {
+		s := m.series[t]
+		for i, le := range LatencyBuckets {
+			fmt.Fprintf(bw, "soc4kafka_canary_latency_seconds_bucket{%s,le=\"%s\"} %d\n", labels(t), formatFloat(le), s.buckets[i])
+		}
+		fmt.Fprintf(bw, "soc4kafka_canary_latency_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(t), s.count)
+		fmt.Fprintf(bw, "soc4kafka_canary_latency_seconds_sum{%s} %s\n", labels(t), formatFloat(s.sum))
+		fmt.Fprintf(bw, "soc4kafka_canary_latency_seconds_count{%s} %d\n", labels(t), s.count)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics, for a Prometheus scrape.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.Write(w)
+}
+
+func labels(t Target) string {
+	return fmt.Sprintf(`receiver="%s",topic="%s"`, escapeLabel(t.Receiver), escapeLabel(t.Topic))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/canary"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/splunksearch"
+)
+
+func init() {
+	register(command{
+		name:    "canary",
+		summary: "Produce marked messages to the consumed topics and measure their end-to-end latency to Splunk",
+		run:     runCanary,
+	})
+}
+
+type canaryReport struct {
+	Results []canary.Result `json:"results"`
+}
+
+// canaryProbe is a target with the client of its receiver.
+type canaryProbe struct {
+	target canary.Target
+	client *kafkaclient.Client
+}
+
+func runCanary(e *env, args []string) error {
+	fs := newFlagSet(e, "canary", "canary [flags] <config.yaml|values.yaml>")
+	var (
+		format       string
+		splunkURL    string
+		splunkUser   string
+		insecure     bool
+		index        string
+		receivers    stringList
+		once         bool
+		interval     time.Duration
+		timeout      time.Duration
+		poll         time.Duration
+		kafkaTimeout time.Duration
+		listen       string
+		metricsFile  string
+	)
+	fs.StringVar(&splunkURL, "splunk-url", "", "URL of the Splunk management port used to search the messages, e.g. https://splunk:8089")
+	fs.StringVar(&splunkUser, "splunk-user", "admin", "Splunk user running the searches, its password is read from SPLUNK_PASSWORD")
+	fs.BoolVar(&insecure, "insecure-skip-verify", false, "Do not verify the certificate of the Splunk management port")
+	fs.StringVar(&index, "index", "*", "Splunk index searched for the messages")
+	fs.Var(&receivers, "receiver", "Receiver IDs to probe, comma separated or repeated, defaults to all kafka receivers")
+	fs.BoolVar(&once, "once", false, "Probe every topic once, print the results and exit with 1 if any message is not indexed in time")
+	fs.DurationVar(&interval, "interval", time.Minute, "Interval between two rounds of probes")
+	fs.DurationVar(&timeout, "timeout", 5*time.Minute, "Time allowed for a message to be searchable")
+	fs.DurationVar(&poll, "poll", 10*time.Second, "Interval between two searches of a message")
+	fs.DurationVar(&kafkaTimeout, "kafka-timeout", 10*time.Second, "Timeout of each Kafka request")
+	fs.StringVar(&listen, "listen", ":9464", "Address serving the Prometheus metrics on /metrics, empty to disable; ignored with --once")
+	fs.StringVar(&metricsFile, "metrics-file", "", "File rewritten with the Prometheus metrics after each round, e.g. for the node_exporter textfile collector")
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	if splunkURL == "" {
+		return fmt.Errorf("--splunk-url is required")
+	}
+	password, ok := os.LookupEnv("SPLUNK_PASSWORD")
+	if !ok {
+		return fmt.Errorf("SPLUNK_PASSWORD must hold the password of %s", splunkUser)
+	}
+	cfg, err := loadCollectorConfig(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var probes []canaryProbe
+	for _, r := range cfg.KafkaReceivers {
+		if len(receivers) > 0 && !slices.Contains(receivers, r.ID) {
+			continue
+		}
+		client, err := kafkaclient.New(r, kafkaTimeout)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+		defer client.Close()
+		targets, err := canaryTargets(context.Background(), client, r)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "warning: %v\n", err)
+			continue
+		}
+		for _, t := range targets {
+			probes = append(probes, canaryProbe{target: t, client: client})
+		}
+	}
+	if len(probes) == 0 {
+		return fmt.Errorf("%s has no topic to probe", fs.Arg(0))
+	}
+
+	prober := &canary.Prober{
+		Searcher: &splunksearch.Client{BaseURL: splunkURL, User: splunkUser, Password: password,
+			HTTPClient: splunksearch.NewHTTPClient(insecure, time.Minute)},
+		Index:        index,
+		Timeout:      timeout,
+		PollInterval: poll,
+	}
+	metrics := canary.NewMetrics()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if !once && listen != "" {
+		ln, err := net.Listen("tcp", listen)
+		if err != nil {
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go server.Serve(ln)
+		defer server.Close()
+		fmt.Fprintf(e.stderr, "serving the metrics on http://%s/metrics\n", ln.Addr())
+	}
+
+	for {
+		started := time.Now()
+		results := runCanaryRound(ctx, prober, probes)
+		if ctx.Err() != nil {
+			return nil
+		}
+		failed := false
+		for _, r := range results {
+			metrics.Observe(r)
+			failed = failed || !r.Success()
+		}
+		if err := printCanary(e, format, once, results); err != nil {
+			return err
+		}
+		if metricsFile != "" {
+			if err := writeMetricsFile(metricsFile, metrics); err != nil {
+				return err
+			}
+		}
+		if once {
+			if failed {
+				return &exitError{code: 1}
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(started.Add(interval))):
+		}
+	}
+}
+
+func canaryTargets(ctx context.Context, client *kafkaclient.Client, r collectorconfig.KafkaReceiver) ([]canary.Target, error) {
+	topics, err := client.Topics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: listing the topics: %w", r.ID, err)
+	}
+	existing := make([]string, 0, len(topics))
+	for t := range topics {
+		existing = append(existing, t)
+	}
+	return canary.Targets(r, existing)
+}
+
+// runCanaryRound probes every target concurrently and returns the results in the order of the probes.
+func runCanaryRound(ctx context.Context, prober *canary.Prober, probes []canaryProbe) []canary.Result {
+	results := make([]canary.Result, len(probes))
+	var wg sync.WaitGroup
+	for i, p := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = prober.Probe(ctx, p.client, p.target)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func printCanary(e *env, format string, once bool, results []canary.Result) error {
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		if once {
+			enc.SetIndent("", "  ")
+			return enc.Encode(canaryReport{Results: results})
+		}
+		// Continuous mode emits one document per result so the output can be piped into line based tools.
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, r := range results {
+		prefix := fmt.Sprintf("%s %s %s", r.Sent.UTC().Format(time.RFC3339), r.Receiver, r.Topic)
+		if !r.Success() {
+			fmt.Fprintf(e.stdout, "%s: FAIL %s: %s\n", prefix, r.ID, r.Error)
+			continue
+		}
+		fmt.Fprintf(e.stdout, "%s[%d]@%d: OK %s indexed after %gs\n", prefix, r.Partition, r.Offset, r.ID, r.Latency)
+	}
+	return nil
+}
+
+// writeMetricsFile replaces the file atomically, so that a collector never reads a partial file.
+func writeMetricsFile(path string, metrics *canary.Metrics) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".soc4kafka-canary-*")
+	if err != nil {
+		return err
+	}
+	err = errors.Join(metrics.Write(f), f.Chmod(0o644), f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkatest"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/splunktest"
+)
+
+func TestCanaryOnce(t *testing.T) {
+	cluster := kafkatest.NewCluster(t, map[string]int{"app-a": 1, "app-b": 1, "other": 1})
+	server := splunktest.NewServer(t)
+	// The collector stand-in indexes every record of app-a, the records of app-b are never indexed.
+	reader, err := kafkaclient.New(collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{cluster.Brokers()}}, 10*time.Second)
+	require.NoError(t, err)
+	defer reader.Close()
+	server.Feed = func() []string {
+		tp := kafkaclient.TopicPartition{Topic: "app-a"}
+		end, err := reader.EndOffsets(context.Background(), []kafkaclient.TopicPartition{tp})
+		require.NoError(t, err)
+		records, err := reader.Read(context.Background(), map[kafkaclient.TopicPartition]kafkaclient.OffsetRange{tp: {Start: 0, End: end[tp]}})
+		require.NoError(t, err)
+		var raws []string
+		for _, r := range records {
+			raws = append(raws, string(r.Value))
+		}
+		return raws
+	}
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+receivers:
+  kafka/main:
+    brokers: ["`+cluster.Brokers()+`"]
+    logs:
+      topics: ["^app-.*"]
+      encoding: text
+  kafka/otlp:
+    brokers: ["`+cluster.Brokers()+`"]
+    logs:
+      topics: ["other"]
+`), 0o600))
+	metricsPath := filepath.Join(dir, "canary.prom")
+	t.Setenv("SPLUNK_PASSWORD", splunktest.Password)
+
+	code, stdout, stderr := runCLI(t, "", "canary", "--once", "--splunk-url", server.URL, "--insecure-skip-verify",
+		"--poll", "10ms", "--timeout", "3s", "--metrics-file", metricsPath, "--format", "json", cfgPath)
+	assert.Equal(t, 1, code, stderr)
+	assert.Equal(t, "warning: kafka/otlp: encoding otlp_proto is not supported, the canary produces text, raw or json messages\n", stderr)
+	var report canaryReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	require.Len(t, report.Results, 2)
+	assert.Equal(t, "app-a", report.Results[0].Topic)
+	assert.True(t, report.Results[0].Success(), report.Results[0].Error)
+	assert.Equal(t, "app-b", report.Results[1].Topic)
+	assert.Equal(t, "the canary message was not found in Splunk within 3s", report.Results[1].Error)
+
+	metrics, err := os.ReadFile(metricsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(metrics), `soc4kafka_canary_success{receiver="kafka/main",topic="app-a"} 1`)
+	assert.Contains(t, string(metrics), `soc4kafka_canary_probes_total{receiver="kafka/main",topic="app-b",result="failure"} 1`)
+}
+
+func TestCanaryRequiresPassword(t *testing.T) {
+	t.Setenv("SPLUNK_PASSWORD", "")
+	os.Unsetenv("SPLUNK_PASSWORD")
+	code, _, stderr := runCLI(t, "", "canary", "--splunk-url", "https://splunk:8089", "../lint/testdata/clean.yaml")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "soc4kafka canary: SPLUNK_PASSWORD must hold the password of admin\n", stderr)
+}
@@ -0,0 +1,147 @@
+// Package cli implements the soc4kafka subcommands.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+)
+
+// env carries the standard streams so that commands can be exercised from tests.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+var commands = map[string]command{}
+
+func register(c command) {
+	commands[c.name] = c
+}
+
+// errUsage is returned by commands when the arguments are invalid and the usage was already printed.
+var errUsage = errors.New("invalid usage")
+
+// Run executes the subcommand named by the first argument and returns the process exit code.
+func Run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage(stderr)
+		return 2
+	}
+	c, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "soc4kafka: unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	err := c.run(e, args[1:])
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp), errors.Is(err, errUsage):
+		return 2
+	default:
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			return exitErr.code
+		}
+		fmt.Fprintf(stderr, "soc4kafka %s: %v\n", c.name, err)
+		return 1
+	}
+}
+
+// exitError makes a command exit with a specific code without printing an additional error message.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit code %d", e.code)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: soc4kafka <command> [flags]\n\nCommands:\n")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, "\nRun 'soc4kafka <command> -h' for the flags of a command.\n")
+}
+
+func newFlagSet(e *env, name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: soc4kafka %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// stringList is a flag.Value collecting repeated or comma separated values.
+type stringList []string
+
+func (s *stringList) String() string {
+	return fmt.Sprint(*s)
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, splitList(value)...)
+	return nil
+}
+
+// repeatedString is a flag.Value collecting repeated values as is, for values that may contain commas.
+type repeatedString []string
+
+func (s *repeatedString) String() string {
+	return fmt.Sprint(*s)
+}
+
+func (s *repeatedString) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// loadCollectorConfig loads a collector configuration, or renders it when path is a values file of the Helm chart.
+func loadCollectorConfig(path string) (*collectorconfig.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !helmvalues.IsValues(raw) {
+		return collectorconfig.Parse(data)
+	}
+	values, err := helmvalues.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg, err := helmvalues.Config(values)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return collectorconfig.FromMap(cfg), nil
+}
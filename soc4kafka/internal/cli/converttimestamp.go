@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/sc4kafka"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/timestamp"
+)
+
+func init() {
+	register(command{
+		name:    "convert-timestamp",
+		summary: "Translate the timestamp extraction of a Splunk Connect for Kafka connector to a transform processor",
+		run:     runConvertTimestamp,
+	})
+}
+
+type convertTimestampReport struct {
+	sc4kafka.Conversion
+	Warnings []string `json:"warnings"`
+	timestampReport
+}
+
+func runConvertTimestamp(e *env, args []string) error {
+	fs := newFlagSet(e, "convert-timestamp", "convert-timestamp [flags] [connector.json|connector.properties]")
+	var (
+		format      string
+		processor   string
+		samples     repeatedString
+		samplesFile string
+	)
+	fs.String("java-regex", "", "timestamp.regex of the connector, a Java regex with a named group time")
+	fs.String("java-format", "", "timestamp.format of the connector, a SimpleDateFormat pattern, e.g. yyyy-MM-dd'T'HH:mm:ss.SSSZ")
+	fs.String("java-timezone", "", "timestamp.timezone of the connector, a Java time zone ID")
+	fs.StringVar(&processor, "processor", timestamp.DefaultProcessor, "ID of the generated transform processor")
+	fs.Var(&samples, "sample", "Sample message to test the translated extraction on, repeated for several samples")
+	fs.StringVar(&samplesFile, "samples", "", "File with one sample message per line, - for stdin")
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+
+	settings := map[string]string{}
+	if fs.NArg() == 1 {
+		var err error
+		if settings, err = sc4kafka.LoadConnectorConfig(fs.Arg(0)); err != nil {
+			return err
+		}
+	}
+	// Flags override the settings of the connector config.
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "java-regex":
+			settings[sc4kafka.TimestampRegex] = f.Value.String()
+		case "java-format":
+			settings[sc4kafka.TimestampFormat] = f.Value.String()
+		case "java-timezone":
+			settings[sc4kafka.TimestampTimezone] = f.Value.String()
+		}
+	})
+	if settings[sc4kafka.TimestampRegex] == "" && settings[sc4kafka.TimestampFormat] == "" {
+		fmt.Fprintf(e.stderr, "a connector config or --java-regex and --java-format are required\n\n")
+		fs.Usage()
+		return errUsage
+	}
+	if samplesFile != "" {
+		lines, err := readLines(e, samplesFile)
+		if err != nil {
+			return err
+		}
+		samples = append(samples, lines...)
+	}
+
+	conversion, err := sc4kafka.ConvertTimestamp(settings)
+	if err != nil {
+		return err
+	}
+	x := conversion.Extraction
+	results, err := x.Test(samples)
+	if err != nil {
+		return err
+	}
+	report := convertTimestampReport{
+		Conversion: conversion,
+		Warnings:   conversion.Warnings(),
+		timestampReport: timestampReport{
+			Processor: processor, Statements: x.Statements(), YAML: x.Processor(processor), Results: results,
+		},
+	}
+	if report.Warnings == nil {
+		report.Warnings = []string{}
+	}
+
+	failed := false
+	for _, r := range results {
+		failed = failed || r.Error != ""
+	}
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(e.stdout, "# %s: %s\n", sc4kafka.TimestampRegex, conversion.Regex.Value)
+		fmt.Fprintf(e.stdout, "# %s: %s\n", sc4kafka.TimestampFormat, conversion.Format.Value)
+		fmt.Fprintf(e.stdout, "# %s: %s\n", sc4kafka.TimestampTimezone, conversion.Timezone.Value)
+		for _, w := range report.Warnings {
+			fmt.Fprintf(e.stdout, "# warning: %s\n", w)
+		}
+		if err := printTimestampReport(e.stdout, report.timestampReport); err != nil {
+			return err
+		}
+	}
+	if failed {
+		return &exitError{code: 1}
+	}
+	return nil
+}
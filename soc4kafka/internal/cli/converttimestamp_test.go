@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertTimestamp(t *testing.T) {
+	connector := filepath.Join(t.TempDir(), "connector.json")
+	require.NoError(t, os.WriteFile(connector, []byte(`{"name": "splunk", "config": {
+  "enable.timestamp.extraction": "true",
+  "timestamp.regex": "\"time\":\\s*\"(?<time>[^\"]+)\"",
+  "timestamp.format": "yyyy-MM-dd'T'HH:mm:ss.SSSZ",
+  "timestamp.timezone": "UTC"
+}}`), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "convert-timestamp", "--sample", `{"time": "2024-05-01T10:20:30.123-0700", "level": "info"}`, connector)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "# timestamp.format: %Y-%m-%dT%H:%M:%S.%L%z\n")
+	assert.Contains(t, stdout, `      - set(log.attributes["extracted_ts"], ExtractPatterns(log.body, "\"time\":\\s*\"(?P<timestamp>[^\"]+)\""))`)
+	assert.Contains(t, stdout, "2024-05-01T10:20:30.123-07:00")
+
+	code, stdout, stderr = runCLI(t, "", "convert-timestamp", "--format", "json", "--java-timezone", "PST", connector)
+	require.Equal(t, 0, code, stderr)
+	var report struct {
+		Timezone struct{ Value string } `json:"timezone"`
+		Warnings []string               `json:"warnings"`
+		YAML     string                 `json:"yaml"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Equal(t, "America/Los_Angeles", report.Timezone.Value)
+	assert.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.YAML, `"America/Los_Angeles"`)
+}
+
+func TestConvertTimestampUnsupported(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "convert-timestamp", "--java-regex", `(?<time>\d+)(?=ms)`, "--java-format", "YYYY")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "lookahead (?= at offset 12")
+	assert.Contains(t, stderr, "pattern letter Y (week year) is not supported")
+
+	code, _, _ = runCLI(t, "", "convert-timestamp")
+	assert.Equal(t, 2, code)
+}
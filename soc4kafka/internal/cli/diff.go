@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/configdiff"
+)
+
+func init() {
+	register(command{
+		name:    "diff",
+		summary: "Show the semantic changes between two collector configs or Helm values files",
+		run:     runDiff,
+	})
+}
+
+func runDiff(e *env, args []string) error {
+	fs := newFlagSet(e, "diff", "diff [flags] <old config.yaml|values.yaml> <new config.yaml|values.yaml>")
+	var (
+		format   string
+		exitCode bool
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	fs.BoolVar(&exitCode, "exit-code", false, "Exit with code 1 when the configs differ")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	var configs [2]*collectorconfig.Config
+	for i := range configs {
+		cfg, err := loadCollectorConfig(fs.Arg(i))
+		if err != nil {
+			return err
+		}
+		configs[i] = collectorconfig.FromMap(configdiff.Normalize(cfg.Raw))
+	}
+	changes := configdiff.Diff(configs[0], configs[1])
+
+	if format == "json" {
+		if changes == nil {
+			changes = []configdiff.Change{}
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(changes); err != nil {
+			return err
+		}
+	} else {
+		if len(changes) == 0 {
+			fmt.Fprintln(e.stdout, "No semantic changes.")
+		}
+		for _, c := range changes {
+			fmt.Fprintln(e.stdout, c)
+			if c.Note != "" {
+				fmt.Fprintf(e.stdout, "    note: %s\n", c.Note)
+			}
+		}
+	}
+	if exitCode && len(changes) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffHelmValues(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "diff", "--exit-code", "../../../rendered/values_base.yaml", "../../../rendered/values_auth.yaml")
+	assert.Equal(t, 1, code, stderr)
+	assert.Contains(t, stdout, "~ topic perf2 -> splunk_hec: group_id soc4kafka-main2 => soc4kafka-main\n    note: ")
+	assert.Contains(t, stdout, "- topic perf4 -> splunk_hec: kafka/main (group soc4kafka-main1)")
+
+	code, stdout, stderr = runCLI(t, "", "diff", "--exit-code", "../../../rendered/values_base.yaml", "../../../rendered/values_base.yaml")
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, "No semantic changes.\n", stdout)
+}
+
+func TestDiffJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "diff", "--format", "json", "../../../rendered/values_base.yaml", "../../../rendered/values_auth.yaml")
+	require.Equal(t, 0, code, stderr)
+	var changes []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &changes))
+	assert.Contains(t, changes, map[string]any{
+		"kind": "component_removed", "component": "kafka/third", "path": "receivers.kafka/third",
+	})
+
+	code, _, _ = runCLI(t, "", "diff", "../../../rendered/values_base.yaml")
+	assert.Equal(t, 2, code)
+}
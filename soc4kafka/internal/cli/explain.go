@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/topology"
+)
+
+func init() {
+	register(command{
+		name:    "explain",
+		summary: "Render the pipeline topology of a collector config or Helm values file as text, DOT or Mermaid",
+		run:     runExplain,
+	})
+}
+
+func runExplain(e *env, args []string) error {
+	fs := newFlagSet(e, "explain", "explain [flags] <config.yaml|values.yaml>")
+	var (
+		format string
+		strict bool
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text, dot, mermaid or json")
+	fs.BoolVar(&strict, "strict", false, "Exit with code 1 when the config has unused components or dangling references")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	cfg, err := loadCollectorConfig(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	t := topology.Build(cfg)
+
+	switch format {
+	case "text":
+		err = t.WriteText(e.stdout)
+	case "dot":
+		err = t.WriteDOT(e.stdout)
+	case "mermaid":
+		err = t.WriteMermaid(e.stdout)
+	case "json":
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(t)
+	default:
+		return fmt.Errorf("unknown format %q, expected text, dot, mermaid or json", format)
+	}
+	if err != nil {
+		return err
+	}
+	if format == "dot" || format == "mermaid" {
+		// Keep the diagram output clean, report the issues on stderr.
+		for _, i := range t.Issues {
+			fmt.Fprintf(e.stderr, "%s: %s\n", i.Kind, i.Message())
+		}
+	}
+	if strict && len(t.Issues) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
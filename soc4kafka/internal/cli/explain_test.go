@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainHelmValues(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "explain", "--strict", "../../../rendered/values_auth.yaml")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "logs/base_logs\n")
+	assert.Contains(t, stdout, "receiver    kafka/sasl_password (group group1)")
+	assert.Contains(t, stdout, "exporter    splunk_hec -> index=kafka-otel source=soc4kafka sourcetype=otel:logs")
+	assert.NotContains(t, stdout, "Issues:")
+}
+
+func TestExplainStrict(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "explain", "--format", "mermaid", "--strict", "../topology/testdata/config.yaml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "flowchart LR\n")
+	assert.Contains(t, stderr, "dangling: pipeline logs/archive references exporter splunk_hec/missing, which is not defined")
+
+	code, _, _ = runCLI(t, "", "explain", "--format", "svg", "../topology/testdata/config.yaml")
+	assert.Equal(t, 1, code)
+}
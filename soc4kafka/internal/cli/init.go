@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/initconfig"
+)
+
+// collectorVersion is the splunk-otel-collector release the generated configuration is written for.
+const collectorVersion = "0.155.0"
+
+func init() {
+	register(command{
+		name:    "init",
+		summary: "Generate a collector config, and optionally a systemd unit and env file",
+		run:     runInit,
+	})
+}
+
+func runInit(e *env, args []string) error {
+	fs := newFlagSet(e, "init", "init [flags]")
+	var (
+		brokers, topics stringList
+		o               initconfig.Options
+		authType        string
+		username        string
+		password        string
+		saslMechanism   string
+		kafkaTLS        bool
+		tlsOpts         initconfig.KafkaTLS
+		output          string
+		envFile         string
+		systemdUnit     string
+		systemdUser     string
+		binaryPath      string
+		noPrompt        bool
+		force           bool
+	)
+	fs.Var(&brokers, "brokers", "Kafka brokers in host:port format, comma separated or repeated")
+	fs.Var(&topics, "topics", "Kafka topics to consume, comma separated or repeated; prefix with ^ for a regex")
+	fs.StringVar(&o.Encoding, "encoding", "text", "Encoding of the Kafka messages (text, json, ...)")
+	fs.StringVar(&o.GroupID, "group-id", "", "Kafka consumer group id, shared by all instances when scaling")
+	fs.StringVar(&o.HECEndpoint, "hec-endpoint", "", "Splunk HEC endpoint, e.g. https://splunk:8088/services/collector")
+	fs.StringVar(&o.HECToken, "hec-token", "", "Splunk HEC token")
+	fs.BoolVar(&o.HECInsecureSkipVerify, "hec-insecure-skip-verify", false, "Skip verification of the Splunk HEC certificate (not recommended for production)")
+	fs.StringVar(&o.Index, "index", "", "Splunk index")
+	fs.StringVar(&o.Source, "source", "otel", "Splunk source")
+	fs.StringVar(&o.Sourcetype, "sourcetype", "otel", "Splunk sourcetype")
+	fs.StringVar(&authType, "auth", "", "Kafka authentication: plain_text or sasl (default none)")
+	fs.StringVar(&username, "username", "", "Kafka username")
+	fs.StringVar(&password, "password", "", "Kafka password")
+	fs.StringVar(&saslMechanism, "sasl-mechanism", "SCRAM-SHA-512", "SASL mechanism: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or AWS_MSK_IAM_OAUTHBEARER")
+	fs.BoolVar(&kafkaTLS, "tls", false, "Connect to the Kafka brokers over TLS")
+	fs.StringVar(&tlsOpts.CAFile, "tls-ca-file", "", "CA certificate used to verify the Kafka brokers")
+	fs.StringVar(&tlsOpts.CertFile, "tls-cert-file", "", "Client certificate for Kafka mutual TLS")
+	fs.StringVar(&tlsOpts.KeyFile, "tls-key-file", "", "Client key for Kafka mutual TLS")
+	fs.BoolVar(&tlsOpts.InsecureSkipVerify, "tls-insecure-skip-verify", false, "Skip verification of the Kafka broker certificates")
+	fs.StringVar(&output, "output", "config.yaml", "Path of the generated collector config")
+	fs.StringVar(&envFile, "env-file", "", "Write secrets to this env file and reference them from the config")
+	fs.StringVar(&systemdUnit, "systemd-unit", "", "Also write a systemd unit to this path")
+	fs.StringVar(&systemdUser, "systemd-user", "", "User the systemd service runs as")
+	fs.StringVar(&binaryPath, "binary-path", "", "Collector binary used by the systemd unit (default ./otelcol_<os>_<arch>)")
+	fs.BoolVar(&noPrompt, "no-prompt", false, "Fail on missing values instead of prompting for them")
+	fs.BoolVar(&force, "force", false, "Overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !noPrompt {
+		p := newPrompter(e)
+		p.list("Kafka brokers (host:port, comma separated)", &brokers, set["brokers"])
+		p.list("Kafka topics (comma separated, prefix with ^ for a regex)", &topics, set["topics"])
+		p.value("Message encoding", &o.Encoding, set["encoding"])
+		p.value("Splunk HEC endpoint", &o.HECEndpoint, set["hec-endpoint"])
+		p.secret("Splunk HEC token", &o.HECToken, set["hec-token"])
+		p.value("Splunk index", &o.Index, set["index"])
+		p.value("Splunk source", &o.Source, set["source"])
+		p.value("Splunk sourcetype", &o.Sourcetype, set["sourcetype"])
+		if authType != "" {
+			p.value("Kafka username", &username, set["username"])
+			p.secret("Kafka password", &password, set["password"])
+		}
+		if p.err != nil {
+			return p.err
+		}
+	}
+
+	o.Brokers = brokers
+	o.Topics = topics
+	if authType != "" {
+		o.Auth = &initconfig.Auth{Type: authType, Username: username, Password: password}
+		if authType == initconfig.AuthSASL {
+			o.Auth.Mechanism = saslMechanism
+		}
+	}
+	if kafkaTLS || tlsOpts != (initconfig.KafkaTLS{}) {
+		o.KafkaTLS = &tlsOpts
+	}
+	o.SecretsFromEnv = envFile != ""
+
+	cfg, err := initconfig.RenderConfig(o)
+	if err != nil {
+		return err
+	}
+	// Without an env file the secrets are written inline, keep them from other users.
+	perm := os.FileMode(0o644)
+	if !o.SecretsFromEnv && (o.HECToken != "" || password != "") {
+		perm = 0o600
+	}
+	if err := writeNewFile(output, cfg, perm, force); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "Collector config written to %s\n", output)
+
+	if envFile != "" {
+		if err := writeNewFile(envFile, initconfig.RenderEnvFile(o), 0o600, force); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Secrets written to %s\n", envFile)
+	}
+
+	binaryName := fmt.Sprintf("otelcol_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if binaryPath == "" {
+		binaryPath = "./" + binaryName
+	}
+	if systemdUnit != "" {
+		unit, err := initconfig.RenderSystemdUnit(initconfig.SystemdOptions{
+			BinaryPath:  mustAbs(binaryPath),
+			ConfigPath:  mustAbs(output),
+			EnvFilePath: absIfSet(envFile),
+			User:        systemdUser,
+		})
+		if err != nil {
+			return err
+		}
+		if err := writeNewFile(systemdUnit, unit, 0o644, force); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Systemd unit written to %s\n", systemdUnit)
+	}
+
+	fmt.Fprintf(e.stdout, "\nDownload the collector if you have not done so yet:\n")
+	fmt.Fprintf(e.stdout, "  wget https://github.com/signalfx/splunk-otel-collector/releases/download/v%s/%s && chmod a+x %s\n", collectorVersion, binaryName, binaryName)
+	if systemdUnit != "" {
+		unitName := filepath.Base(systemdUnit)
+		fmt.Fprintf(e.stdout, "\nInstall and start the service:\n")
+		fmt.Fprintf(e.stdout, "  sudo cp %s /etc/systemd/system/%s && sudo systemctl daemon-reload && sudo systemctl enable --now %s\n", systemdUnit, unitName, unitName)
+		return nil
+	}
+	fmt.Fprintf(e.stdout, "\nRun the following command to start the SOC4Kafka Collector:\n")
+	if envFile != "" {
+		fmt.Fprintf(e.stdout, "  (set -a && . %s && %s --config %s)\n", envFile, binaryPath, output)
+	} else {
+		fmt.Fprintf(e.stdout, "  %s --config %s\n", binaryPath, output)
+	}
+	return nil
+}
+
+// prompter asks for values that were not provided as flags. The first error stops further prompts.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	err error
+	// readSecret reads a line without echoing it, nil when the input is not a terminal.
+	readSecret func() (string, error)
+}
+
+func newPrompter(e *env) *prompter {
+	p := &prompter{in: bufio.NewReader(e.stdin), out: e.stderr}
+	if f, ok := e.stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		p.readSecret = func() (string, error) {
+			line, err := term.ReadPassword(int(f.Fd()))
+			// The newline typed by the user is not echoed either.
+			fmt.Fprintln(p.out)
+			return string(line), err
+		}
+	}
+	return p
+}
+
+func (p *prompter) ask(label string, current string, secret bool) string {
+	if p.err != nil {
+		return current
+	}
+	switch {
+	case current != "" && secret:
+		fmt.Fprintf(p.out, "%s [keep current]: ", label)
+	case current != "":
+		fmt.Fprintf(p.out, "%s [%s]: ", label, current)
+	default:
+		fmt.Fprintf(p.out, "%s: ", label)
+	}
+	var line string
+	var err error
+	if secret && p.readSecret != nil {
+		line, err = p.readSecret()
+	} else {
+		line, err = p.in.ReadString('\n')
+	}
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		p.err = fmt.Errorf("reading %s: %w (use flags together with --no-prompt for non-interactive use)", strings.ToLower(label), err)
+		return current
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line
+	}
+	return current
+}
+
+func (p *prompter) value(label string, v *string, isSet bool) {
+	if !isSet {
+		*v = p.ask(label, *v, false)
+	}
+}
+
+// secret is value for secrets, which are not echoed when reading from a terminal.
+func (p *prompter) secret(label string, v *string, isSet bool) {
+	if !isSet {
+		*v = p.ask(label, *v, true)
+	}
+}
+
+func (p *prompter) list(label string, v *stringList, isSet bool) {
+	if !isSet {
+		*v = splitList(p.ask(label, strings.Join(*v, ","), false))
+	}
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func writeNewFile(path string, data []byte, perm os.FileMode, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+		}
+	}
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of a file it overwrites.
+	return os.Chmod(path, perm)
+}
+
+func mustAbs(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func absIfSet(path string) string {
+	if path == "" {
+		return ""
+	}
+	return mustAbs(path)
+}
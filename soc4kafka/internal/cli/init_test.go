@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestInitNonInteractive(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	envPath := filepath.Join(dir, "soc4kafka.env")
+	unitPath := filepath.Join(dir, "soc4kafka.service")
+
+	code, stdout, stderr := runCLI(t, "", "init", "--no-prompt",
+		"--brokers", "broker1:9092,broker2:9092",
+		"--topics", "example-topic",
+		"--hec-endpoint", "https://splunk:8088/services/collector",
+		"--hec-token", "secret-token",
+		"--index", "kafka",
+		"--auth", "sasl", "--username", "user", "--password", "secret-password",
+		"--output", cfgPath, "--env-file", envPath, "--systemd-unit", unitPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "systemctl enable --now soc4kafka.service")
+
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(cfg), "secret-token")
+	assert.NotContains(t, string(cfg), "secret-password")
+	assert.Contains(t, string(cfg), `token: "${SPLUNK_HEC_TOKEN}"`)
+
+	env, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(env), `SPLUNK_HEC_TOKEN="secret-token"`)
+	assert.Contains(t, string(env), `KAFKA_PASSWORD="secret-password"`)
+	assertMode(t, envPath, 0o600)
+	assertMode(t, cfgPath, 0o644)
+
+	unit, err := os.ReadFile(unitPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(unit), "EnvironmentFile="+envPath)
+
+	// Existing files are not overwritten without --force.
+	code, _, stderr = runCLI(t, "", "init", "--no-prompt", "--brokers", "b:9092", "--topics", "t",
+		"--hec-endpoint", "https://splunk:8088/services/collector", "--hec-token", "x", "--index", "i", "--output", cfgPath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "already exists")
+}
+
+func TestInitInlineSecretsNotWorldReadable(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, nil, 0o644))
+
+	code, _, stderr := runCLI(t, "", "init", "--no-prompt", "--force", "--brokers", "b:9092", "--topics", "t",
+		"--hec-endpoint", "https://splunk:8088/services/collector", "--hec-token", "secret-token", "--index", "i",
+		"--output", cfgPath)
+	require.Equal(t, 0, code, stderr)
+
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(cfg), "secret-token")
+	assertMode(t, cfgPath, 0o600)
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, want, info.Mode().Perm(), "mode of %s", path)
+}
+
+func TestInitPrompts(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	answers := strings.Join([]string{
+		"broker1:9092",
+		"topic-a, topic-b",
+		"",
+		"https://splunk:8088/services/collector",
+		"token",
+		"kafka",
+		"",
+		"my-sourcetype",
+	}, "\n") + "\n"
+
+	code, _, stderr := runCLI(t, answers, "init", "--output", cfgPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "Message encoding [text]: ")
+	assert.Contains(t, stderr, "Splunk HEC token: ")
+
+	cfg, err := os.ReadFile(cfgPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(cfg), "- \"topic-a\"\n        - \"topic-b\"")
+	assert.Contains(t, string(cfg), `source: "otel"`)
+	assert.Contains(t, string(cfg), `sourcetype: "my-sourcetype"`)
+}
+
+func TestInitMissingValuesWithoutPrompt(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "init", "--no-prompt", "--output", filepath.Join(t.TempDir(), "config.yaml"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "at least one Kafka broker is required")
+	assert.Contains(t, stderr, "Splunk HEC token is required")
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/lag"
+)
+
+func init() {
+	register(command{
+		name:    "lag",
+		summary: "Show the consumer lag of the kafka receivers of a collector config or Helm values file",
+		run:     runLag,
+	})
+}
+
+// clearScreen moves the cursor home and clears the terminal between watch iterations.
+const clearScreen = "\033[H\033[2J"
+
+type lagReport struct {
+	Time      time.Time    `json:"time"`
+	Receivers []lag.Report `json:"receivers"`
+	Errors    []lagError   `json:"errors,omitempty"`
+}
+
+type lagError struct {
+	Receiver string `json:"receiver"`
+	Error    string `json:"error"`
+}
+
+func runLag(e *env, args []string) error {
+	fs := newFlagSet(e, "lag", "lag [flags] <config.yaml|values.yaml>")
+	var (
+		format    string
+		watch     time.Duration
+		timeout   time.Duration
+		receivers stringList
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	fs.DurationVar(&watch, "watch", 0, "Refresh the lag at this interval until interrupted, e.g. 5s")
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "Timeout of each Kafka request")
+	fs.Var(&receivers, "receiver", "Receiver IDs to inspect, comma separated or repeated, defaults to all kafka receivers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	cfg, err := loadCollectorConfig(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var selected []collectorconfig.KafkaReceiver
+	for _, r := range cfg.KafkaReceivers {
+		if len(receivers) == 0 || slices.Contains(receivers, r.ID) {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("%s has no matching kafka receivers", fs.Arg(0))
+	}
+
+	clients := make([]*kafkaclient.Client, len(selected))
+	for i, r := range selected {
+		if clients[i], err = kafkaclient.New(r, timeout); err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+		defer clients[i].Close()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for {
+		report := lagReport{Time: time.Now().UTC(), Receivers: []lag.Report{}}
+		for i, r := range selected {
+			rep, err := lag.Collect(ctx, clients[i], r)
+			if err != nil {
+				report.Errors = append(report.Errors, lagError{Receiver: r.ID, Error: err.Error()})
+				continue
+			}
+			report.Receivers = append(report.Receivers, rep)
+		}
+		if err := printLag(e, format, watch > 0, report); err != nil {
+			return err
+		}
+		if watch <= 0 {
+			if len(report.Errors) > 0 {
+				return &exitError{code: 1}
+			}
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watch):
+		}
+	}
+}
+
+func printLag(e *env, format string, watching bool, report lagReport) error {
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		// Watch mode emits one document per line so the output can be piped into line based tools.
+		if !watching {
+			enc.SetIndent("", "  ")
+		}
+		return enc.Encode(report)
+	}
+
+	if watching {
+		fmt.Fprint(e.stdout, clearScreen)
+		fmt.Fprintf(e.stdout, "%s\n\n", report.Time.Format(time.RFC3339))
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "RECEIVER\tGROUP\tTOPIC\tPARTITION\tCOMMITTED\tLOG-END\tLAG\tMEMBER")
+	for _, r := range report.Receivers {
+		for _, p := range r.Partitions {
+			committed, lagValue := "-", "-"
+			if p.Lag != nil {
+				committed = strconv.FormatInt(p.Committed, 10)
+				lagValue = strconv.FormatInt(*p.Lag, 10)
+			}
+			member := "-"
+			if p.Member != "" {
+				member = p.Member + " (" + p.Host + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\n", r.Receiver, r.Group, p.Topic, p.Partition, committed, p.LogEnd, lagValue, member)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(e.stdout)
+	for _, r := range report.Receivers {
+		state := r.State
+		if state == "" {
+			state = "unknown"
+		}
+		fmt.Fprintf(e.stdout, "%s: group %s is %s with %d member(s), total lag %d\n", r.Receiver, r.Group, state, r.Members, r.TotalLag)
+		if r.Warning != "" {
+			fmt.Fprintf(e.stdout, "%s: warning: %s\n", r.Receiver, r.Warning)
+		}
+	}
+	for _, err := range report.Errors {
+		fmt.Fprintf(e.stdout, "%s: error: %s\n", err.Receiver, err.Error)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkatest"
+)
+
+func TestLagHelmValues(t *testing.T) {
+	cluster := kafkatest.NewCluster(t, map[string]int{"logs": 2})
+	cluster.Produce("logs", 0, 5)
+	cluster.Produce("logs", 1, 2)
+	cluster.Commit("soc4kafka-main", "logs", 0, 1)
+
+	valuesPath := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(valuesPath, []byte(`
+kafkaReceivers:
+  - name: main
+    brokers: [ "`+cluster.Brokers()+`" ]
+    logs:
+      topics: [ logs ]
+`), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "lag", "--format", "json", valuesPath)
+	require.Equal(t, 0, code, stderr)
+	var report lagReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	require.Len(t, report.Receivers, 1)
+	r := report.Receivers[0]
+	assert.Equal(t, "kafka/main", r.Receiver)
+	assert.Equal(t, "soc4kafka-main", r.Group)
+	assert.Equal(t, int64(4), r.TotalLag)
+	require.Len(t, r.Partitions, 2)
+	assert.Nil(t, r.Partitions[1].Lag)
+
+	code, stdout, _ = runCLI(t, "", "lag", valuesPath)
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "kafka/main  soc4kafka-main  logs   0          1          5        4    -")
+	assert.Contains(t, stdout, "kafka/main: group soc4kafka-main is")
+}
+
+func TestLagUnknownReceiver(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "lag", "--receiver", "kafka/missing", "../lint/testdata/clean.yaml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "has no matching kafka receivers")
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/lint"
+)
+
+func init() {
+	register(command{
+		name:    "lint",
+		summary: "Statically check collector configs for known misconfigurations",
+		run:     runLint,
+	})
+}
+
+type lintFinding struct {
+	File string `json:"file"`
+	lint.Finding
+}
+
+type lintReport struct {
+	Findings []lintFinding         `json:"findings"`
+	Summary  map[lint.Severity]int `json:"summary"`
+}
+
+func runLint(e *env, args []string) error {
+	fs := newFlagSet(e, "lint", "lint [flags] <config.yaml>...")
+	var (
+		format    string
+		failOn    string
+		disabled  stringList
+		listRules bool
+		opts      lint.Options
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	fs.StringVar(&failOn, "fail-on", "error", "Exit with code 1 when a finding has at least this severity: info, warning or error")
+	fs.Var(&disabled, "disable", "Rule IDs or names to skip, comma separated or repeated")
+	fs.IntVar(&opts.Replicas, "replicas", 1, "Number of collector instances running the config, enables scaling checks when above 1")
+	fs.BoolVar(&listRules, "list-rules", false, "List the rules and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if listRules {
+		return printRules(e, format)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	threshold, err := lint.ParseSeverity(failOn)
+	if err != nil {
+		return err
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	opts.Disabled = disabled
+
+	report := lintReport{Findings: []lintFinding{}, Summary: map[lint.Severity]int{
+		lint.SeverityError: 0, lint.SeverityWarning: 0, lint.SeverityInfo: 0,
+	}}
+	for _, path := range fs.Args() {
+		cfg, err := collectorconfig.Load(path)
+		if err != nil {
+			return err
+		}
+		for _, f := range lint.Run(cfg, opts) {
+			report.Findings = append(report.Findings, lintFinding{File: path, Finding: f})
+			report.Summary[f.Severity]++
+		}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range report.Findings {
+			fmt.Fprintf(e.stdout, "%s: %s %s [%s] %s: %s\n", f.File, f.Severity, f.Rule, f.Component, f.Path, f.Message)
+		}
+		fmt.Fprintf(e.stdout, "%d error(s), %d warning(s), %d info\n",
+			report.Summary[lint.SeverityError], report.Summary[lint.SeverityWarning], report.Summary[lint.SeverityInfo])
+	}
+
+	for _, f := range report.Findings {
+		if f.Severity.Rank() >= threshold.Rank() {
+			return &exitError{code: 1}
+		}
+	}
+	return nil
+}
+
+func printRules(e *env, format string) error {
+	if format == "json" {
+		type rule struct {
+			ID          string        `json:"id"`
+			Name        string        `json:"name"`
+			Severity    lint.Severity `json:"severity"`
+			Description string        `json:"description"`
+		}
+		var out []rule
+		for _, r := range lint.Rules() {
+			out = append(out, rule{ID: r.ID, Name: r.Name, Severity: r.Severity, Description: r.Description})
+		}
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tSEVERITY\tDESCRIPTION")
+	for _, r := range lint.Rules() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.ID, r.Name, r.Severity, r.Description)
+	}
+	return w.Flush()
+}
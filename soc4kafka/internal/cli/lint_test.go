@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "lint", "--format", "json", "--replicas", "2", "../lint/testdata/misconfigured.yaml")
+	require.Equal(t, 1, code, stderr)
+
+	var report lintReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Equal(t, 3, report.Summary["error"])
+	assert.Equal(t, 7, report.Summary["warning"])
+	assert.Equal(t, "../lint/testdata/misconfigured.yaml", report.Findings[0].File)
+	assert.Equal(t, "SOC001", report.Findings[0].Rule)
+}
+
+func TestLintFailOn(t *testing.T) {
+	code, stdout, _ := runCLI(t, "", "lint", "--disable", "SOC001,SOC004", "../lint/testdata/misconfigured.yaml")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "warning SOC002 [kafka/a] receivers.kafka/a.logs.topics")
+
+	code, _, _ = runCLI(t, "", "lint", "--fail-on", "warning", "../lint/testdata/clean.yaml")
+	assert.Equal(t, 0, code)
+}
+
+func TestLintListRules(t *testing.T) {
+	code, stdout, _ := runCLI(t, "", "lint", "--list-rules")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "SOC004  duplicate-consumer")
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/preflight"
+)
+
+func init() {
+	register(command{
+		name:    "preflight",
+		summary: "Verify Kafka and Splunk HEC connectivity and permissions of a collector config",
+		run:     runPreflight,
+	})
+}
+
+func runPreflight(e *env, args []string) error {
+	fs := newFlagSet(e, "preflight", "preflight [flags] <config.yaml>")
+	var (
+		format        string
+		timeout       time.Duration
+		skipTestEvent bool
+		skipACLs      bool
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "Timeout of each network check")
+	fs.BoolVar(&skipTestEvent, "skip-test-event", false, "Do not send a test event to verify the HEC index permission")
+	fs.BoolVar(&skipACLs, "skip-acls", false, "Do not verify the topic and consumer group ACLs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	cfg, err := collectorconfig.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if len(cfg.KafkaReceivers) == 0 && len(cfg.HECExporters) == 0 {
+		return fmt.Errorf("%s has no kafka receivers or splunk_hec exporters", fs.Arg(0))
+	}
+
+	checker := &preflight.Checker{
+		Timeout:       timeout,
+		SendTestEvent: !skipTestEvent,
+		CheckACLs:     !skipACLs,
+	}
+	results := checker.Run(context.Background(), cfg)
+
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "COMPONENT\tCHECK\tTARGET\tSTATUS\tDETAIL")
+		for _, r := range results {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Component, r.Check, r.Target, r.Status, r.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if preflight.Failed(results) {
+		return &exitError{code: 1}
+	}
+	return nil
+}
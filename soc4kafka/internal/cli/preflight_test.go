@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/preflight"
+)
+
+func TestPreflight(t *testing.T) {
+	hec := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/services/collector/health" {
+			_, _ = io.WriteString(w, `{"text":"HEC is healthy","code":17}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = io.WriteString(w, `{"text":"No data","code":5}`)
+	}))
+	defer hec.Close()
+
+	// A listener closed right away gives an address nothing listens on.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	broker := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+exporters:
+  splunk_hec:
+    endpoint: `+hec.URL+`/services/collector
+    token: token
+`), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "preflight", "--skip-test-event", cfgPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "COMPONENT   CHECK   TARGET")
+	assert.Contains(t, stdout, "token accepted")
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+receivers:
+  kafka:
+    brokers: [ "`+broker+`" ]
+    logs: { topics: [ logs ] }
+`), 0o600))
+	code, stdout, _ = runCLI(t, "", "preflight", "--format", "json", "--timeout", "1s", cfgPath)
+	require.Equal(t, 1, code)
+	var results []preflight.Result
+	require.NoError(t, json.Unmarshal([]byte(stdout), &results))
+	require.Len(t, results, 2)
+	assert.Equal(t, preflight.StatusFail, results[0].Status)
+	assert.Equal(t, broker, results[0].Target)
+}
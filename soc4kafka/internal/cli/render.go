@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/dryrun"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/ottl"
+)
+
+func init() {
+	register(command{
+		name:    "render",
+		summary: "Show the HEC events a configuration produces from Kafka records or samples, without sending them",
+		run:     runRender,
+	})
+}
+
+type renderReport struct {
+	Note     string          `json:"note"`
+	Warnings []string        `json:"warnings"`
+	Outputs  []dryrun.Output `json:"outputs"`
+}
+
+func runRender(e *env, args []string) error {
+	fs := newFlagSet(e, "render", "render [flags] <config.yaml|values.yaml>")
+	var (
+		format      string
+		samplesPath string
+		receiverID  string
+		count       int
+		from        string
+		timeout     time.Duration
+		hostname    string
+	)
+	fs.StringVar(&samplesPath, "samples", "", "JSON lines file of sample messages with their topic and headers, - for stdin; Kafka is not contacted")
+	fs.StringVar(&receiverID, "receiver", "", "Kafka receiver to consume from, or consuming the samples; defaults to the only receiver, or to the receivers matching the topic of each sample")
+	fs.IntVar(&count, "count", 10, "Number of records to consume")
+	fs.StringVar(&from, "from", "latest", "Records to consume: latest for the most recent ones, earliest for the oldest ones still retained")
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "Timeout of the Kafka requests and of the consumption")
+	fs.StringVar(&hostname, "hostname", "", "host.name set by the system detector of the resourcedetection processor, defaults to the local hostname")
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	if from != "latest" && from != "earliest" {
+		return fmt.Errorf("unknown --from %q, expected latest or earliest", from)
+	}
+	if count <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+	cfg, err := loadCollectorConfig(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	renderer, err := dryrun.New(cfg, dryrun.Options{Hostname: hostname})
+	if err != nil {
+		return err
+	}
+
+	var messages []dryrun.Message
+	if samplesPath != "" {
+		var in io.Reader = e.stdin
+		if samplesPath != "-" {
+			f, err := os.Open(samplesPath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		if messages, err = dryrun.ReadSamples(in); err != nil {
+			return fmt.Errorf("%s: %w", samplesPath, err)
+		}
+		for i := range messages {
+			if receiverID != "" || messages[i].Topic == "" {
+				r, err := selectReceiver(cfg, receiverID)
+				if err != nil {
+					return fmt.Errorf("sample %d has no topic: %w", i+1, err)
+				}
+				messages[i].Receiver = r.ID
+			}
+		}
+	} else {
+		r, err := selectReceiver(cfg, receiverID)
+		if err != nil {
+			return err
+		}
+		client, err := kafkaclient.New(r, timeout)
+		if err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+		defer client.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if messages, err = dryrun.Fetch(ctx, client, r, count, from == "earliest"); err != nil {
+			return fmt.Errorf("%s: %w", r.ID, err)
+		}
+		if len(messages) == 0 {
+			fmt.Fprintf(e.stderr, "%s: the topics hold no records\n", r.ID)
+		}
+	}
+
+	report := renderReport{Note: ottlNote(), Warnings: renderer.Warnings, Outputs: []dryrun.Output{}}
+	if report.Warnings == nil {
+		report.Warnings = []string{}
+	}
+	failed := false
+	for _, m := range messages {
+		for _, o := range renderer.Render(m) {
+			failed = failed || o.Error != ""
+			report.Outputs = append(report.Outputs, o)
+		}
+	}
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(e.stderr, "note: %s\n", report.Note)
+		for _, w := range report.Warnings {
+			fmt.Fprintf(e.stderr, "warning: %s\n", w)
+		}
+		if err := printRender(e.stdout, report.Outputs); err != nil {
+			return err
+		}
+	}
+	if failed {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// ottlNote tells that the events are simulated with the OTTL subset of the ottl package rather than by the collector.
+func ottlNote() string {
+	return fmt.Sprintf("OTTL statements are evaluated by the interpreter of soc4kafka, not by the collector: only the "+
+		"editors %s and the converters %s are supported, other statements are skipped with a warning and the "+
+		"rendered events may differ from the indexed ones",
+		strings.Join(ottl.Editors(), ", "), strings.Join(ottl.Converters(), ", "))
+}
+
+// printRender prints each output as a comment line locating the message, followed by the HEC event as sent.
+func printRender(w io.Writer, outputs []dryrun.Output) error {
+	for _, o := range outputs {
+		m := o.Message
+		route := fmt.Sprintf("%s[%d]@%d", m.Topic, m.Partition, m.Offset)
+		if m.Topic == "" {
+			route = fmt.Sprintf("sample %d", m.Offset+1)
+		}
+		for _, hop := range []string{o.Receiver, o.Pipeline, o.Exporter} {
+			if hop != "" {
+				route += " -> " + hop
+			}
+		}
+		switch {
+		case o.Error != "":
+			fmt.Fprintf(w, "# %s: error: %s\n", route, o.Error)
+		case o.Dropped != "":
+			fmt.Fprintf(w, "# %s: %s\n", route, o.Dropped)
+		default:
+			fmt.Fprintf(w, "# %s\n", route)
+		}
+		for _, note := range o.Notes {
+			fmt.Fprintf(w, "# ignored error: %s\n", note)
+		}
+		if o.Event != nil {
+			data, err := json.Marshal(o.Event)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s\n", data)
+		}
+	}
+	return nil
+}
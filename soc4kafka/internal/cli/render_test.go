@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/ottl"
+)
+
+const renderConfig = `
+receivers:
+  kafka/main:
+    brokers: ["localhost:9092"]
+    logs:
+      topics: ["app-logs"]
+      encoding: json
+processors:
+  resourcedetection:
+    detectors: [system]
+  transform/level:
+    log_statements:
+      - set(log.attributes["level"], ToLowerCase(log.body["level"]))
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    index: kafka
+service:
+  pipelines:
+    logs:
+      receivers: [kafka/main]
+      processors: [resourcedetection, transform/level]
+      exporters: [splunk_hec]
+`
+
+func TestRenderSamples(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(renderConfig), 0o600))
+	samples := `{"topic": "app-logs", "offset": 4, "value": {"level": "WARN", "msg": "slow"}}
+{"topic": "other", "value": "x"}
+`
+
+	code, stdout, stderr := runCLI(t, samples, "render", "--samples", "-", "--hostname", "h", cfgPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Equal(t, `# app-logs[0]@4 -> kafka/main -> logs -> splunk_hec
+{"host":"h","index":"kafka","event":{"level":"WARN","msg":"slow"},"fields":{"level":"warn","os.type":"`+runtime.GOOS+`"}}
+# other[0]@1: no logs pipeline consumes topic other
+`, stdout)
+	assert.Contains(t, stderr, "note: OTTL statements are evaluated by the interpreter of soc4kafka, not by the collector")
+	assert.Contains(t, stderr, "the converters Concat, Double, ExtractPatterns,")
+
+	code, stdout, stderr = runCLI(t, samples, "render", "--samples", "-", "--hostname", "h", "--format", "json", cfgPath)
+	require.Equal(t, 0, code, stderr)
+	var report renderReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	require.Len(t, report.Outputs, 2)
+	assert.Equal(t, "splunk_hec", report.Outputs[0].Exporter)
+	assert.Equal(t, "h", report.Outputs[0].Event.Host)
+	assert.Empty(t, report.Warnings)
+	assert.Contains(t, report.Note, "editors delete_key, delete_matching_keys,")
+
+	code, stdout, _ = runCLI(t, `{"value": {"msg": "no level"}}`, "render", "--samples", "-", "--hostname", "h", cfgPath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "# sample 1 -> kafka/main -> logs: error: transform/level:")
+}
+
+func TestRenderUsage(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "render")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "render [flags] <config.yaml|values.yaml>")
+}
+
+// The README lists the OTTL functions render supports.
+func TestRenderReadmeListsOTTLSubset(t *testing.T) {
+	readme, err := os.ReadFile("../../README.md")
+	require.NoError(t, err)
+	row := func(kind string) string {
+		for _, line := range strings.Split(string(readme), "\n") {
+			if strings.HasPrefix(line, "| "+kind+" ") {
+				return line
+			}
+		}
+		return ""
+	}
+	quoted := func(names []string) string {
+		return "`" + strings.Join(names, "`, `") + "`"
+	}
+	assert.Contains(t, row("Editors"), quoted(ottl.Editors()))
+	assert.Contains(t, row("Converters"), quoted(ottl.Converters()))
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/offsetreset"
+)
+
+func init() {
+	register(command{
+		name:    "reset-offsets",
+		summary: "Reset the consumer group offsets of a kafka receiver, e.g. to replay a time window",
+		run:     runResetOffsets,
+	})
+}
+
+type resetOffsetsReport struct {
+	offsetreset.Plan
+	Executed bool `json:"executed"`
+}
+
+func runResetOffsets(e *env, args []string) error {
+	fs := newFlagSet(e, "reset-offsets", "reset-offsets [flags] (--to-earliest|--to-latest|--to-offset N|--to-datetime TIME) <config.yaml|values.yaml>")
+	var (
+		format     string
+		receiverID string
+		topics     stringList
+		partitions stringList
+		execute    bool
+		timeout    time.Duration
+		target     offsetreset.Target
+	)
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	fs.StringVar(&receiverID, "receiver", "", "Receiver ID whose consumer group is reset, required when the config has several kafka receivers")
+	fs.Var(&topics, "topic", "Topics to reset, comma separated or repeated, defaults to all topics consumed by the receiver")
+	fs.Var(&partitions, "partitions", "Partitions to reset in each topic, comma separated or repeated, defaults to all partitions")
+	fs.Bool("to-earliest", false, "Reset to the log start offset")
+	fs.Bool("to-latest", false, "Reset to the log end offset, skipping the pending records")
+	fs.Int64("to-offset", 0, "Reset to this offset, clamped to the offsets available in each partition")
+	fs.String("to-datetime", "", "Reset to the first record at or after this RFC 3339 time, e.g. 2025-01-31T22:00:00Z")
+	fs.BoolVar(&execute, "execute", false, "Commit the new offsets, without it the planned reset is only printed")
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "Timeout of each Kafka request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+
+	var targets []string
+	var targetErr error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "to-earliest":
+			if f.Value.String() != "true" {
+				return
+			}
+			target.Kind = offsetreset.ToEarliest
+		case "to-latest":
+			if f.Value.String() != "true" {
+				return
+			}
+			target.Kind = offsetreset.ToLatest
+		case "to-offset":
+			target.Kind = offsetreset.ToOffset
+			target.Offset, _ = strconv.ParseInt(f.Value.String(), 10, 64)
+		case "to-datetime":
+			target.Kind = offsetreset.ToTime
+			if target.Time, targetErr = time.Parse(time.RFC3339, f.Value.String()); targetErr != nil {
+				targetErr = fmt.Errorf("invalid --to-datetime: %w", targetErr)
+			}
+		default:
+			return
+		}
+		targets = append(targets, "--"+f.Name)
+	})
+	if len(targets) != 1 {
+		fmt.Fprintf(e.stderr, "exactly one of --to-earliest, --to-latest, --to-offset or --to-datetime is required\n\n")
+		fs.Usage()
+		return errUsage
+	}
+	if targetErr != nil {
+		return targetErr
+	}
+	var scope offsetreset.Scope
+	scope.Topics = topics
+	for _, p := range partitions {
+		n, err := strconv.ParseInt(p, 10, 32)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid partition %q", p)
+		}
+		scope.Partitions = append(scope.Partitions, int32(n))
+	}
+
+	cfg, err := loadCollectorConfig(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	r, err := selectReceiver(cfg, receiverID)
+	if err != nil {
+		return err
+	}
+	client, err := kafkaclient.New(r, timeout)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.ID, err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	plan, err := offsetreset.NewPlan(ctx, client, r, scope, target)
+	if err != nil {
+		return err
+	}
+	report := resetOffsetsReport{Plan: plan}
+	if execute {
+		if err := offsetreset.Apply(ctx, client, plan); err != nil {
+			return err
+		}
+		report.Executed = true
+		report.Warning = ""
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	w := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITION\tCURRENT\tTARGET\tLOG-START\tLOG-END\tNOTE")
+	for _, c := range plan.Changes {
+		current := "-"
+		if c.Current != kafkaclient.NoOffset {
+			current = strconv.FormatInt(c.Current, 10)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%d\t%d\t%d\t%s\n", c.Topic, c.Partition, current, c.Target, c.LogStart, c.LogEnd, c.Note)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(e.stdout)
+	if report.Executed {
+		fmt.Fprintf(e.stdout, "Reset the offsets of group %s to %s on %d partition(s).\n", plan.Group, plan.Target, len(plan.Changes))
+		return nil
+	}
+	if plan.Warning != "" {
+		fmt.Fprintf(e.stdout, "Warning: %s\n", plan.Warning)
+	}
+	fmt.Fprintf(e.stdout, "Dry run: the offsets of group %s were not changed, add --execute to apply the reset.\n", plan.Group)
+	return nil
+}
+
+// selectReceiver returns the kafka receiver with the ID, or the only kafka receiver when id is empty.
+func selectReceiver(cfg *collectorconfig.Config, id string) (collectorconfig.KafkaReceiver, error) {
+	if id != "" {
+		r, ok := cfg.KafkaReceiver(id)
+		if !ok {
+			return r, fmt.Errorf("kafka receiver %s not found", id)
+		}
+		return r, nil
+	}
+	switch len(cfg.KafkaReceivers) {
+	case 0:
+		return collectorconfig.KafkaReceiver{}, fmt.Errorf("no kafka receiver found")
+	case 1:
+		return cfg.KafkaReceivers[0], nil
+	default:
+		var ids []string
+		for _, r := range cfg.KafkaReceivers {
+			ids = append(ids, r.ID)
+		}
+		return collectorconfig.KafkaReceiver{}, fmt.Errorf("several kafka receivers found, select one with --receiver: %v", ids)
+	}
+}
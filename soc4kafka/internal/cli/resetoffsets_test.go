@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkatest"
+)
+
+func TestResetOffsetsDryRun(t *testing.T) {
+	cluster := kafkatest.NewCluster(t, map[string]int{"logs": 2})
+	cluster.Produce("logs", 0, 5)
+	cluster.Commit("soc4kafka", "logs", 0, 5)
+
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+receivers:
+  kafka:
+    brokers: [ "`+cluster.Brokers()+`" ]
+    group_id: soc4kafka
+    logs:
+      topics: [ logs ]
+`), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "reset-offsets", "--to-offset", "2", "--partitions", "0", "--format", "json", cfgPath)
+	require.Equal(t, 0, code, stderr)
+	var report resetOffsetsReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.False(t, report.Executed)
+	assert.Equal(t, "soc4kafka", report.Group)
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, int64(5), report.Changes[0].Current)
+	assert.Equal(t, int64(2), report.Changes[0].Target)
+
+	code, stdout, _ = runCLI(t, "", "reset-offsets", "--to-earliest", cfgPath)
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "logs   0          5        0       0          5")
+	assert.Contains(t, stdout, "Dry run: the offsets of group soc4kafka were not changed")
+}
+
+func TestResetOffsetsUsage(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "reset-offsets", "--to-earliest", "--to-latest", "config.yaml")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "exactly one of --to-earliest, --to-latest, --to-offset or --to-datetime is required")
+
+	code, _, stderr = runCLI(t, "", "reset-offsets", "--to-latest", "../lint/testdata/misconfigured.yaml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "several kafka receivers found, select one with --receiver")
+}
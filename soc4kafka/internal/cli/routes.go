@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/routing"
+)
+
+func init() {
+	register(command{
+		name:    "routes",
+		summary: "Compile a topic to index routing table into a collector config or Helm values",
+		run:     runRoutes,
+	})
+}
+
+func runRoutes(e *env, args []string) error {
+	fs := newFlagSet(e, "routes", "routes [flags] <routes.yaml|routes.csv>")
+	var (
+		output       string
+		defaultsPath string
+		topicsPath   string
+	)
+	fs.StringVar(&output, "output", "config", "What to generate: config for a collector config, values for Helm values")
+	fs.StringVar(&defaultsPath, "defaults", "", "YAML routing table holding the defaults and exporters, required for CSV tables")
+	fs.StringVar(&topicsPath, "topics", "", "File listing the existing topics, one per line, to verify that regex routes do not overlap; - for stdin")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	if output != "config" && output != "values" {
+		return fmt.Errorf("unknown output %q, expected config or values", output)
+	}
+
+	var base *routing.Table
+	if defaultsPath != "" {
+		var err error
+		if base, err = routing.Load(defaultsPath, nil); err != nil {
+			return err
+		}
+	}
+	table, err := routing.Load(fs.Arg(0), base)
+	if err != nil {
+		return err
+	}
+	var existing []string
+	if topicsPath != "" {
+		if existing, err = readLines(e, topicsPath); err != nil {
+			return err
+		}
+		if existing == nil {
+			existing = []string{}
+		}
+	}
+	res, err := routing.Compile(table, existing)
+	if err != nil {
+		return err
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintf(e.stderr, "warning: %s\n", w)
+	}
+
+	var data []byte
+	if output == "values" {
+		data, err = res.Values()
+	} else {
+		data, err = res.Config()
+	}
+	if err != nil {
+		return err
+	}
+	_, err = e.stdout.Write(data)
+	fmt.Fprintf(e.stderr, "Compiled %d route(s) into %d receiver(s) and pipeline(s).\n", len(table.Routes), len(res.Groups))
+	return err
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoutes(t *testing.T) {
+	dir := t.TempDir()
+	defaults := filepath.Join(dir, "defaults.yaml")
+	require.NoError(t, os.WriteFile(defaults, []byte(`defaults:
+  brokers: ["kafka:9092"]
+  index: kafka
+exporters:
+  primary:
+    endpoint: https://splunk:8088/services/collector
+`), 0o600))
+	routes := filepath.Join(dir, "routes.csv")
+	require.NoError(t, os.WriteFile(routes, []byte("topic,index\napp-logs,app\n^audit-.*,audit\naudit-special,app\n"), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "routes", "--defaults", defaults, routes)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "    kafka/audit:\n")
+	assert.Contains(t, stdout, "            exclude_topics:\n                - ^audit-special$\n")
+	assert.Contains(t, stdout, `set(resource.attributes["com.splunk.index"], "audit")`)
+	assert.Contains(t, stderr, "Compiled 3 route(s) into 2 receiver(s) and pipeline(s).\n")
+
+	code, stdout, stderr = runCLI(t, "audit-special\naudit-1\n", "routes", "--output", "values", "--topics", "-", "--defaults", defaults, routes)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "kafkaReceivers:\n")
+	assert.NotContains(t, stderr, "warning:")
+
+	require.NoError(t, os.WriteFile(routes, []byte("topic,index\napp-logs,app\napp-logs,audit\n"), 0o600))
+	code, _, stderr = runCLI(t, "", "routes", "--defaults", defaults, routes)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "line 3: topic app-logs is already routed at line 2")
+
+	code, _, _ = runCLI(t, "", "routes", "--output", "helm", routes)
+	assert.Equal(t, 1, code)
+	code, _, _ = runCLI(t, "", "routes")
+	assert.Equal(t, 2, code)
+}
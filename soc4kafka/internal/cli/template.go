@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+)
+
+func init() {
+	register(command{
+		name:    "template",
+		summary: "Render Helm values into a standalone collector config and the env file of its secrets",
+		run:     runTemplate,
+	})
+}
+
+func runTemplate(e *env, args []string) error {
+	fs := newFlagSet(e, "template", "template [flags] <values.yaml>")
+	var (
+		output  string
+		envFile string
+		force   bool
+	)
+	fs.StringVar(&output, "output", "", "Write the collector config to this file instead of stdout")
+	fs.StringVar(&envFile, "env-file", "", "Write the environment variables of the collector, its secrets, to this file")
+	fs.BoolVar(&force, "force", false, "Overwrite existing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if !helmvalues.IsValues(raw) {
+		return fmt.Errorf("%s is not a values file of the Helm chart", path)
+	}
+	values, err := helmvalues.Parse(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cfg, err := helmvalues.Config(values)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+
+	if output == "" {
+		if _, err := e.stdout.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		if err := writeNewFile(output, buf.Bytes(), 0o644, force); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stderr, "Collector config written to %s\n", output)
+	}
+	if envFile != "" {
+		vars := helmvalues.EnvVars(values)
+		if err := writeNewFile(envFile, helmvalues.RenderEnvFile(vars), 0o600, force); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stderr, "Environment written to %s\n", envFile)
+		for _, v := range vars {
+			if v.Source != "" {
+				fmt.Fprintf(e.stderr, "warning: set %s in %s, %s\n", v.Name, envFile, v.Source)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+func TestTemplate(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	envPath := filepath.Join(dir, "soc4kafka.env")
+
+	code, stdout, stderr := runCLI(t, "", "template", "--output", cfgPath, "--env-file", envPath, "../../../rendered/values_auth.yaml")
+	require.Equal(t, 0, code, stderr)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "warning: set KAFKA_KAFKA_SASL_SASL_PASSWORD in "+envPath+", the chart reads it from the key password of secret kafka-sasl-secret\n")
+
+	cfg, err := collectorconfig.Load(cfgPath)
+	require.NoError(t, err)
+	r, ok := cfg.KafkaReceiver("kafka/sasl")
+	require.True(t, ok)
+	assert.Equal(t, "${KAFKA_KAFKA_SASL_SASL_PASSWORD}", collectorconfig.String(collectorconfig.Map(collectorconfig.Map(r.Raw, "auth"), "sasl"), "password"))
+	env, err := os.ReadFile(envPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(env), "\nSPLUNK_HEC_TOKEN_PRIMARY=\"00000000-0000-0000-0000-000000000000\"\n")
+	info, err := os.Stat(envPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	code, _, stderr = runCLI(t, "", "template", "--output", cfgPath, "../../../rendered/values_auth.yaml")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "already exists, use --force to overwrite it")
+
+	code, stdout, _ = runCLI(t, "", "template", "../../../rendered/values_enable_collector_logs.yaml")
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "    logs/internal:\n")
+}
+
+func TestTemplateRejectsCollectorConfig(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "template", "../lint/testdata/clean.yaml")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "soc4kafka template: ../lint/testdata/clean.yaml is not a values file of the Helm chart\n", stderr)
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/timestamp"
+)
+
+func init() {
+	register(command{
+		name:    "timestamp",
+		summary: "Generate and test the transform processor setting the record time from a timestamp in the message",
+		run:     runTimestamp,
+	})
+}
+
+type timestampReport struct {
+	Processor  string             `json:"processor"`
+	Statements []string           `json:"statements"`
+	YAML       string             `json:"yaml"`
+	Results    []timestamp.Result `json:"results"`
+}
+
+func runTimestamp(e *env, args []string) error {
+	fs := newFlagSet(e, "timestamp", "timestamp --pattern REGEX --time-format FORMAT [flags] [samples.txt|-]...")
+	var (
+		format    string
+		processor string
+		samples   repeatedString
+		x         timestamp.Extraction
+	)
+	fs.StringVar(&x.Pattern, "pattern", "", "Regular expression with a named group timestamp, e.g. \\[(?P<timestamp>[^\\]]+)\\], without any escaping for YAML")
+	fs.StringVar(&x.Format, "time-format", "", "strptime format of the timestamp, e.g. %Y-%m-%d %H:%M:%S")
+	fs.StringVar(&x.Timezone, "timezone", "UTC", "Timezone of timestamps without a zone")
+	fs.StringVar(&processor, "processor", timestamp.DefaultProcessor, "ID of the generated transform processor")
+	fs.Var(&samples, "sample", "Sample message, repeated for several samples; files and - (stdin) hold one message per line")
+	fs.StringVar(&format, "format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if x.Pattern == "" || x.Format == "" {
+		fmt.Fprintf(e.stderr, "--pattern and --time-format are required\n\n")
+		fs.Usage()
+		return errUsage
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q, expected text or json", format)
+	}
+	for _, path := range fs.Args() {
+		lines, err := readLines(e, path)
+		if err != nil {
+			return err
+		}
+		samples = append(samples, lines...)
+	}
+	if err := x.Validate(); err != nil {
+		return err
+	}
+	results, err := x.Test(samples)
+	if err != nil {
+		return err
+	}
+	report := timestampReport{Processor: processor, Statements: x.Statements(), YAML: x.Processor(processor), Results: results}
+
+	failed := false
+	for _, r := range results {
+		failed = failed || r.Error != ""
+	}
+	if format == "json" {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if err := printTimestampReport(e.stdout, report); err != nil {
+		return err
+	}
+	if failed {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+func printTimestampReport(w io.Writer, report timestampReport) error {
+	fmt.Fprintln(w, "processors:")
+	for _, line := range strings.SplitAfter(strings.TrimSuffix(report.YAML, "\n"), "\n") {
+		fmt.Fprint(w, "  "+line)
+	}
+	fmt.Fprintf(w, "\n\nAdd %s to the processors of the pipelines consuming these messages.\n", report.Processor)
+	if len(report.Results) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "SAMPLE\tEXTRACTED\tTIME\tERROR")
+	for _, r := range report.Results {
+		parsed := "-"
+		if r.Time != nil {
+			parsed = r.Time.Format(time.RFC3339Nano)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", truncate(r.Sample, 50), r.Extracted, parsed, r.Error)
+	}
+	return tw.Flush()
+}
+
+// readLines returns the non empty lines of a file, or of stdin for "-".
+func readLines(e *env, path string) ([]string, error) {
+	in := e.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	var lines []string
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func truncate(s string, n int) string {
+	if len([]rune(s)) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-3]) + "..."
+}
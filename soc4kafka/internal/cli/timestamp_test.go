@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamp(t *testing.T) {
+	samples := filepath.Join(t.TempDir(), "samples.txt")
+	require.NoError(t, os.WriteFile(samples, []byte("2020-01-01T12:00:00.250+02:00 file sample\n"), 0o600))
+
+	code, stdout, stderr := runCLI(t, "2020-01-01T12:00:00Z stdin sample\n", "timestamp",
+		"--pattern", `^(?P<timestamp>\S+)`, "--time-format", "%Y-%m-%dT%H:%M:%S.%L%j", "--sample", "not a timestamp, with a comma", samples, "-")
+	assert.Equal(t, 1, code, stderr)
+	assert.Contains(t, stdout, "processors:\n  transform/timestamp:\n    error_mode: ignore\n")
+	assert.Contains(t, stdout, `      - set(log.time, Time(log.attributes["extracted_ts"]["timestamp"], "%Y-%m-%dT%H:%M:%S.%L%j", "UTC"))`)
+	assert.Contains(t, stdout, "2020-01-01T12:00:00.250+02:00 file sample  2020-01-01T12:00:00.250+02:00  2020-01-01T12:00:00.25+02:00")
+	assert.Contains(t, stdout, `cannot parse "not"`)
+	assert.Contains(t, stdout, "not a timestamp, with a comma ")
+	assert.Contains(t, stdout, `cannot parse "2020-01-01T12:00:00Z"`)
+}
+
+func TestTimestampJSON(t *testing.T) {
+	code, stdout, stderr := runCLI(t, "", "timestamp", "--format", "json", "--processor", "transform/ts",
+		"--pattern", `on (?P<timestamp>\S+)`, "--time-format", "%d/%b/%Y", "--sample", "deployed on 01/Jan/2020")
+	require.Equal(t, 0, code, stderr)
+	var report timestampReport
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.Equal(t, "transform/ts", report.Processor)
+	assert.Len(t, report.Statements, 3)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "01/Jan/2020", report.Results[0].Extracted)
+
+	code, _, stderr = runCLI(t, "", "timestamp", "--pattern", `(?P<ts>\d+)`, "--time-format", "%s")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no named capturing group timestamp")
+
+	code, _, _ = runCLI(t, "", "timestamp", "--pattern", `(?P<timestamp>\d+)`)
+	assert.Equal(t, 2, code)
+}
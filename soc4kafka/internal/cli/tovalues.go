@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/configdiff"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+)
+
+func init() {
+	register(command{
+		name:    "to-values",
+		summary: "Convert a collector config into values of the Helm chart and verify they render the same config",
+		run:     runToValues,
+	})
+}
+
+// valuesKeys is the order of the top-level keys written by to-values, the order of the chart values file.
+var valuesKeys = []string{"kafkaReceivers", "splunkExporters", "pipelines", "defaults", "configOverride"}
+
+func runToValues(e *env, args []string) error {
+	fs := newFlagSet(e, "to-values", "to-values [flags] <config.yaml>")
+	var (
+		output string
+		force  bool
+	)
+	fs.StringVar(&output, "output", "", "Write the values to this file instead of stdout")
+	fs.BoolVar(&force, "force", false, "Overwrite an existing output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if helmvalues.IsValues(raw) {
+		return fmt.Errorf("%s is already a values file of the Helm chart", path)
+	}
+
+	c := helmvalues.FromConfig(raw)
+	doc, err := valuesYAML(c.Values)
+	if err != nil {
+		return err
+	}
+	rendered, err := renderValues(doc)
+	if err != nil {
+		return fmt.Errorf("rendering the converted values: %w", err)
+	}
+	changes := configdiff.Diff(collectorconfig.FromMap(c.Expected), collectorconfig.FromMap(rendered))
+
+	if output == "" {
+		if _, err := e.stdout.Write(doc); err != nil {
+			return err
+		}
+	} else {
+		if err := writeNewFile(output, doc, 0o644, force); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stderr, "Values written to %s\n", output)
+	}
+	for _, r := range c.Renames {
+		fmt.Fprintf(e.stderr, "renamed %s to %s\n", r.From, r.To)
+	}
+	for _, s := range c.Secrets {
+		from := "the inline value of " + s.Setting
+		if s.Env != "" {
+			from = fmt.Sprintf("the environment variable %s read by %s", s.Env, s.Setting)
+		}
+		fmt.Fprintf(e.stderr, "create the secret %s with the key %s holding %s\n", s.Name, s.Key, from)
+	}
+	for _, note := range c.Notes {
+		fmt.Fprintf(e.stderr, "note: %s\n", note)
+	}
+	if len(changes) > 0 {
+		fmt.Fprintf(e.stderr, "round trip: the values render a different config:\n")
+		for _, change := range changes {
+			fmt.Fprintf(e.stderr, "  %s\n", change)
+		}
+		return &exitError{code: 1}
+	}
+	fmt.Fprintf(e.stderr, "round trip: the values render an equivalent config\n")
+	return nil
+}
+
+// renderValues renders the collector config of a values document the way the chart does.
+func renderValues(doc []byte) (map[string]any, error) {
+	values, err := helmvalues.Parse(doc)
+	if err != nil {
+		return nil, err
+	}
+	return helmvalues.Config(values)
+}
+
+// valuesYAML encodes values with the top-level keys in the order of the chart values file and the name of list
+// entries first.
+func valuesYAML(values map[string]any) ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range valuesKeys {
+		v, ok := values[key]
+		if !ok {
+			continue
+		}
+		node, err := valuesNode(v)
+		if err != nil {
+			return nil, err
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, node)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func valuesNode(v any) (*yaml.Node, error) {
+	items, ok := v.([]any)
+	if !ok {
+		node := &yaml.Node{}
+		return node, node.Encode(v)
+	}
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range items {
+		node := &yaml.Node{}
+		if err := node.Encode(item); err != nil {
+			return nil, err
+		}
+		if node.Kind == yaml.MappingNode {
+			var pairs [][]*yaml.Node
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				pairs = append(pairs, node.Content[i:i+2])
+			}
+			slices.SortStableFunc(pairs, func(a, b []*yaml.Node) int {
+				return entryKeyRank(a[0].Value) - entryKeyRank(b[0].Value)
+			})
+			node.Content = slices.Concat(pairs...)
+		}
+		list.Content = append(list.Content, node)
+	}
+	return list, nil
+}
+
+func entryKeyRank(key string) int {
+	switch key {
+	case "name":
+		return 0
+	case "type":
+		return 1
+	default:
+		return 2
+	}
+}
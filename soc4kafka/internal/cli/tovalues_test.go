@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+)
+
+func TestToValues(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	valuesPath := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+receivers:
+  kafka/main:
+    brokers: [kafka:9092]
+    logs:
+      topics: [app]
+      encoding: text
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    token: ${SPLUNK_HEC_TOKEN}
+processors:
+  batch:
+service:
+  pipelines:
+    logs/main:
+      receivers: [kafka/main]
+      processors: [batch]
+      exporters: [splunk_hec]
+`), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "to-values", "--output", valuesPath, cfgPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "Values written to "+valuesPath+"\n"+
+		"create the secret soc4kafka-hec-primary with the key splunk-hec-token holding the environment variable SPLUNK_HEC_TOKEN read by exporters.splunk_hec.token\n"+
+		"round trip: the values render an equivalent config\n", stderr)
+	values, err := helmvalues.Load(valuesPath)
+	require.NoError(t, err)
+	assert.Equal(t, []any{map[string]any{"name": "primary", "secret": "soc4kafka-hec-primary", "endpoint": "https://splunk:8088/services/collector"}},
+		values["splunkExporters"])
+
+	code, stdout, _ = runCLI(t, "", "to-values", cfgPath)
+	require.Equal(t, 0, code)
+	assert.Contains(t, stdout, "kafkaReceivers:\n  - name: main\n")
+
+	code, _, stderr = runCLI(t, "", "to-values", "../../../rendered/values_base.yaml")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "soc4kafka to-values: ../../../rendered/values_base.yaml is already a values file of the Helm chart\n", stderr)
+}
+
+func TestToValuesReportsDifferences(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	// Helm removes the defaults set to null, the chart cannot render a null endpoint.
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+extensions:
+  health_check:
+    endpoint: null
+service:
+  extensions: [health_check]
+`), 0o600))
+	code, _, stderr := runCLI(t, "", "to-values", cfgPath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "round trip: the values render a different config:\n  ~ extensions.health_check.endpoint: null => (none)\n")
+}
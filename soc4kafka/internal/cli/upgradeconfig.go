@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/upgrade"
+)
+
+func init() {
+	register(command{
+		name:    "upgrade-config",
+		summary: "Rewrite a collector config written for an older collector release for the release pinned by the Helm chart",
+		run:     runUpgradeConfig,
+	})
+}
+
+func runUpgradeConfig(e *env, args []string) error {
+	fs := newFlagSet(e, "upgrade-config", "upgrade-config [flags] <config.yaml>")
+	var (
+		from   string
+		output string
+		force  bool
+		check  bool
+	)
+	fs.StringVar(&from, "from", "", "Collector release the config was written for, e.g. 0.120.0; by default every outdated setting found is rewritten")
+	fs.StringVar(&output, "output", "", "Write the upgraded config to this file instead of stdout")
+	fs.BoolVar(&force, "force", false, "Overwrite an existing output file")
+	fs.BoolVar(&check, "check", false, "Only list the rewrites and exit with 1 if the config needs any")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	path := fs.Arg(0)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// The document is upgraded as a node tree to keep its comments and key order.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	var raw map[string]any
+	if doc.Kind != 0 {
+		if err := doc.Decode(&raw); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if helmvalues.IsValues(raw) {
+		return fmt.Errorf("%s is a values file of the Helm chart, upgrade-config takes a collector config", path)
+	}
+
+	to := collectorconfig.String(collectorconfig.Map(helmvalues.Defaults(), "image"), "tag")
+	rewrites, err := upgrade.UpgradeNode(&doc, from, to)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, r := range rewrites {
+		if r.Advisory {
+			fmt.Fprintf(e.stderr, "advisory: %s: %s [%s, %s]\n", r.Path, r.Explanation, r.Rule, r.Version)
+			continue
+		}
+		changed = true
+		fmt.Fprintf(e.stderr, "%s: %s [%s, %s]\n", r.Path, r.Explanation, r.Rule, r.Version)
+	}
+	if check {
+		if changed {
+			return &exitError{code: 1}
+		}
+		fmt.Fprintf(e.stderr, "%s is up to date for %s\n", path, to)
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if output == "" {
+		_, err := e.stdout.Write(buf.Bytes())
+		return err
+	}
+	if err := writeNewFile(output, buf.Bytes(), 0o644, force); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "Config upgraded to %s written to %s\n", to, output)
+	return nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+func TestUpgradeConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	upgraded := filepath.Join(dir, "upgraded.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+receivers:
+  kafka:
+    # The production cluster.
+    brokers: [kafka:9092]
+    topic: app
+exporters:
+  splunk_hec:
+    sending_queue:
+      blocking: true
+service:
+  pipelines:
+    logs:
+      receivers: [kafka]
+      exporters: [splunk_hec]
+`), 0o600))
+
+	code, stdout, stderr := runCLI(t, "", "upgrade-config", "--check", cfgPath)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Equal(t, "receivers.kafka.topic: topic is moved to logs.topic, the signals consumed by the pipelines of the receiver [kafka-signal-settings, 0.124.0]\n"+
+		"exporters.splunk_hec.sending_queue.blocking: blocking is renamed block_on_overflow [sending-queue-block-on-overflow, 0.126.0]\n"+
+		"receivers.kafka.logs.topic: topic is replaced by the list topics [kafka-topics-list, 0.142.0]\n", stderr)
+
+	code, _, stderr = runCLI(t, "", "upgrade-config", "--output", upgraded, cfgPath)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stderr, "Config upgraded to 0.155.0 written to "+upgraded+"\n")
+	cfg, err := collectorconfig.Load(upgraded)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app"}, collectorconfig.Strings(collectorconfig.Map(cfg.KafkaReceivers[0].Raw, "logs"), "topics"))
+
+	// Comments and key order are kept.
+	data, err := os.ReadFile(upgraded)
+	require.NoError(t, err)
+	assert.Equal(t, `receivers:
+  kafka:
+    # The production cluster.
+    brokers: ['kafka:9092']
+    logs:
+      topics:
+        - app
+exporters:
+  splunk_hec:
+    sending_queue:
+      block_on_overflow: true
+service:
+  pipelines:
+    logs:
+      receivers: [kafka]
+      exporters: [splunk_hec]
+`, string(data))
+
+	code, _, stderr = runCLI(t, "", "upgrade-config", "--check", upgraded)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, upgraded+" is up to date for 0.155.0\n", stderr)
+
+	code, stdout, stderr = runCLI(t, "", "upgrade-config", "--from", "0.130.0", cfgPath)
+	require.Equal(t, 0, code)
+	// The rules of 0.130.0 and before are trusted to be applied already.
+	assert.Contains(t, stdout, "    topic: app\n")
+	assert.Empty(t, stderr)
+}
+
+func TestUpgradeConfigRejectsValues(t *testing.T) {
+	code, _, stderr := runCLI(t, "", "upgrade-config", "../../../rendered/values_base.yaml")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "soc4kafka upgrade-config: ../../../rendered/values_base.yaml is a values file of the Helm chart, upgrade-config takes a collector config\n", stderr)
+}
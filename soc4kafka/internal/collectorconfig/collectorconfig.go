@@ -0,0 +1,261 @@
+// Package collectorconfig loads OpenTelemetry Collector configuration files and exposes the parts SOC4Kafka
+// cares about: Kafka receivers, Splunk HEC exporters and the pipelines connecting them.
+package collectorconfig
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	KafkaReceiverType = "kafka"
+	HECExporterType   = "splunk_hec"
+
+	// DefaultGroupID is the consumer group used by the Kafka receiver when group_id is not set.
+	DefaultGroupID = "otel-collector"
+	// HeaderAttributePrefix is prepended to extracted Kafka headers by the Kafka receiver.
+	HeaderAttributePrefix = "kafka.header."
+)
+
+// Config is a parsed collector configuration.
+type Config struct {
+	Raw map[string]any
+
+	KafkaReceivers []KafkaReceiver
+	HECExporters   []HECExporter
+	Pipelines      []Pipeline
+}
+
+// KafkaReceiver is a kafka receiver instance.
+type KafkaReceiver struct {
+	ID            string
+	Brokers       []string
+	Topics        []string
+	ExcludeTopics []string
+	Encoding      string
+	// GroupID is the configured consumer group, empty when the receiver default is used.
+	GroupID          string
+	ExtractHeaders   bool
+	ExtractedHeaders []string
+	Raw              map[string]any
+}
+
+// EffectiveGroupID returns the consumer group the receiver joins.
+func (r KafkaReceiver) EffectiveGroupID() string {
+	if r.GroupID != "" {
+		return r.GroupID
+	}
+	return DefaultGroupID
+}
+
+// HECExporter is a splunk_hec exporter instance.
+type HECExporter struct {
+	ID         string
+	Endpoint   string
+	Token      string
+	Index      string
+	Source     string
+	Sourcetype string
+	// HECMetadata is the otel_attrs_to_hec_metadata mapping, keyed by HEC field (index, source, sourcetype, host).
+	HECMetadata map[string]string
+	Raw         map[string]any
+}
+
+// Pipeline is a service pipeline.
+type Pipeline struct {
+	ID         string
+	Receivers  []string
+	Processors []string
+	Exporters  []string
+}
+
+// Load reads and parses the collector configuration at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse parses a collector configuration document.
+func Parse(data []byte) (*Config, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	return FromMap(raw), nil
+}
+
+// FromMap builds a Config from an already decoded configuration.
+func FromMap(raw map[string]any) *Config {
+	cfg := &Config{Raw: raw}
+	receivers := Map(raw, "receivers")
+	for _, id := range SortedKeys(receivers) {
+		if ComponentType(id) != KafkaReceiverType {
+			continue
+		}
+		cfg.KafkaReceivers = append(cfg.KafkaReceivers, parseKafkaReceiver(id, Map(receivers, id)))
+	}
+	exporters := Map(raw, "exporters")
+	for _, id := range SortedKeys(exporters) {
+		if ComponentType(id) != HECExporterType {
+			continue
+		}
+		cfg.HECExporters = append(cfg.HECExporters, parseHECExporter(id, Map(exporters, id)))
+	}
+	pipelines := Map(Map(raw, "service"), "pipelines")
+	for _, id := range SortedKeys(pipelines) {
+		p := Map(pipelines, id)
+		cfg.Pipelines = append(cfg.Pipelines, Pipeline{
+			ID:         id,
+			Receivers:  Strings(p, "receivers"),
+			Processors: Strings(p, "processors"),
+			Exporters:  Strings(p, "exporters"),
+		})
+	}
+	return cfg
+}
+
+func parseKafkaReceiver(id string, m map[string]any) KafkaReceiver {
+	logs := Map(m, "logs")
+	r := KafkaReceiver{
+		ID:            id,
+		Brokers:       Strings(m, "brokers"),
+		Topics:        Strings(logs, "topics"),
+		ExcludeTopics: Strings(logs, "exclude_topics"),
+		Encoding:      String(logs, "encoding"),
+		GroupID:       String(m, "group_id"),
+		Raw:           m,
+	}
+	// Releases before the per signal settings used a single top level topic and encoding.
+	if len(r.Topics) == 0 {
+		r.Topics = Strings(m, "topic")
+	}
+	if r.Encoding == "" {
+		r.Encoding = String(m, "encoding")
+	}
+	headers := Map(m, "header_extraction")
+	r.ExtractHeaders, _ = headers["extract_headers"].(bool)
+	r.ExtractedHeaders = Strings(headers, "headers")
+	return r
+}
+
+func parseHECExporter(id string, m map[string]any) HECExporter {
+	e := HECExporter{
+		ID:          id,
+		Endpoint:    String(m, "endpoint"),
+		Token:       String(m, "token"),
+		Index:       String(m, "index"),
+		Source:      String(m, "source"),
+		Sourcetype:  String(m, "sourcetype"),
+		HECMetadata: map[string]string{},
+		Raw:         m,
+	}
+	for k, v := range Map(m, "otel_attrs_to_hec_metadata") {
+		if s, ok := v.(string); ok {
+			e.HECMetadata[k] = s
+		}
+	}
+	return e
+}
+
+// KafkaReceiver returns the kafka receiver with the given ID.
+func (c *Config) KafkaReceiver(id string) (KafkaReceiver, bool) {
+	for _, r := range c.KafkaReceivers {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return KafkaReceiver{}, false
+}
+
+// HECExporter returns the splunk_hec exporter with the given ID.
+func (c *Config) HECExporter(id string) (HECExporter, bool) {
+	for _, e := range c.HECExporters {
+		if e.ID == id {
+			return e, true
+		}
+	}
+	return HECExporter{}, false
+}
+
+// PipelinesWithExporter returns the pipelines sending data to the given exporter.
+func (c *Config) PipelinesWithExporter(id string) []Pipeline {
+	var out []Pipeline
+	for _, p := range c.Pipelines {
+		for _, e := range p.Exporters {
+			if e == id {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ComponentType returns the type part of a component ID, e.g. "kafka" for "kafka/main".
+func ComponentType(id string) string {
+	t, _, _ := strings.Cut(id, "/")
+	return t
+}
+
+// IsRegexTopic reports whether the Kafka receiver treats the topic as a regular expression.
+func IsRegexTopic(topic string) bool {
+	return strings.HasPrefix(topic, "^")
+}
+
+// Map returns the map stored under key, or nil.
+func Map(m map[string]any, key string) map[string]any {
+	v, _ := m[key].(map[string]any)
+	return v
+}
+
+// String returns the scalar stored under key formatted as a string, or "".
+func String(m map[string]any, key string) string {
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Strings returns the list stored under key. A single scalar is returned as a one element list.
+func Strings(m map[string]any, key string) []string {
+	switch v := m[key].(type) {
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	case string:
+		return []string{v}
+	default:
+		return nil
+	}
+}
+
+// SortedKeys returns the keys of m in lexical order.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
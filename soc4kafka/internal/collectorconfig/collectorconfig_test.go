@@ -0,0 +1,125 @@
+package collectorconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multipleTopics = `
+receivers:
+  kafka/one:
+    brokers: [ "localhost:9092" ]
+    group_id: group-one
+    logs:
+      topics: [ topic-1 ]
+      encoding: "text"
+    header_extraction:
+      extract_headers: true
+      headers: [ index ]
+  kafka/legacy:
+    brokers: localhost:9092
+    topic: legacy-topic
+    encoding: json
+  filelog:
+    include: [ /var/log/*.log ]
+
+exporters:
+  splunk_hec/one:
+    token: "token"
+    endpoint: https://splunk:8088/services/collector
+    index: kafka
+    otel_attrs_to_hec_metadata:
+      index: kafka.header.index
+  debug: {}
+
+service:
+  pipelines:
+    logs/1:
+      receivers: [ kafka/one ]
+      processors: [ resourcedetection ]
+      exporters: [ splunk_hec/one ]
+`
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(multipleTopics))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.KafkaReceivers, 2)
+	legacy, ok := cfg.KafkaReceiver("kafka/legacy")
+	require.True(t, ok)
+	assert.Equal(t, []string{"localhost:9092"}, legacy.Brokers)
+	assert.Equal(t, []string{"legacy-topic"}, legacy.Topics)
+	assert.Equal(t, "json", legacy.Encoding)
+	assert.Equal(t, DefaultGroupID, legacy.EffectiveGroupID())
+
+	one, ok := cfg.KafkaReceiver("kafka/one")
+	require.True(t, ok)
+	assert.Equal(t, "group-one", one.EffectiveGroupID())
+	assert.True(t, one.ExtractHeaders)
+	assert.Equal(t, []string{"index"}, one.ExtractedHeaders)
+
+	require.Len(t, cfg.HECExporters, 1)
+	assert.Equal(t, map[string]string{"index": "kafka.header.index"}, cfg.HECExporters[0].HECMetadata)
+
+	require.Len(t, cfg.Pipelines, 1)
+	assert.Equal(t, Pipeline{
+		ID:         "logs/1",
+		Receivers:  []string{"kafka/one"},
+		Processors: []string{"resourcedetection"},
+		Exporters:  []string{"splunk_hec/one"},
+	}, cfg.Pipelines[0])
+	assert.Len(t, cfg.PipelinesWithExporter("splunk_hec/one"), 1)
+	assert.Empty(t, cfg.PipelinesWithExporter("debug"))
+}
+
+func TestComponentType(t *testing.T) {
+	assert.Equal(t, "kafka", ComponentType("kafka"))
+	assert.Equal(t, "splunk_hec", ComponentType("splunk_hec/primary"))
+	assert.True(t, IsRegexTopic("^logs-.*"))
+	assert.False(t, IsRegexTopic("logs"))
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("SOC4KAFKA_TEST_TOKEN", "secret")
+	assert.Equal(t, "secret", ExpandEnv("${SOC4KAFKA_TEST_TOKEN}"))
+	assert.Equal(t, "Splunk secret", ExpandEnv("Splunk ${env:SOC4KAFKA_TEST_TOKEN}"))
+	assert.Equal(t, "fallback", ExpandEnv("${env:SOC4KAFKA_TEST_UNSET:-fallback}"))
+	assert.Equal(t, "", ExpandEnv("${SOC4KAFKA_TEST_UNSET}"))
+	assert.Equal(t, "plain", ExpandEnv("plain"))
+	assert.Equal(t, []string{"A", "B"}, EnvRefs("${A}:${env:B:-x}"))
+}
+
+func TestResolveTopics(t *testing.T) {
+	// Exclude entries are unanchored regexes, as for the receiver: logs-b also excludes logs-b-eu and xlogs-b, and
+	// archive any topic containing it.
+	r := KafkaReceiver{Topics: []string{"app", "^logs-.*", "^xlogs-.*", "^logs-a$"}, ExcludeTopics: []string{"^logs-internal", "logs-b", "archive"}}
+	topics, err := r.ResolveTopics([]string{"app", "logs-a", "logs-b", "logs-b-eu", "logs-c", "logs-c-archive", "logs-internal-1", "xlogs-b", "xlogs-c", "other"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "logs-a", "logs-c", "xlogs-c"}, topics)
+
+	r.ExcludeTopics = []string{"^logs-b$"}
+	topics, err = r.ResolveTopics([]string{"logs-b", "logs-b-eu"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "logs-b-eu"}, topics)
+
+	_, err = KafkaReceiver{Topics: []string{"^logs-.*"}, ExcludeTopics: []string{"logs-("}}.ResolveTopics(nil)
+	assert.Error(t, err)
+	_, err = KafkaReceiver{Topics: []string{"^logs-.*"}, ExcludeTopics: []string{""}}.ResolveTopics(nil)
+	assert.Error(t, err)
+
+	_, err = KafkaReceiver{Topics: []string{"^logs-("}}.ResolveTopics(nil)
+	assert.Error(t, err)
+}
+
+func TestRenderEnvFile(t *testing.T) {
+	assert.Equal(t, "# Secrets of the collector. Keep this file readable by the collector user only.\n"+
+		"SPLUNK_HEC_TOKEN=\"secret-token\"\n"+
+		"# KAFKA_PASSWORD: read from a secret\n"+
+		"KAFKA_PASSWORD=\"p@ss \\\\ \\\"word\\\" \\$HOME \\`id\\`\"\n",
+		string(RenderEnvFile("Secrets of the collector", []EnvFileVar{
+			{Name: "SPLUNK_HEC_TOKEN", Value: "secret-token"},
+			{Name: "KAFKA_PASSWORD", Value: "p@ss \\ \"word\" $HOME `id`", Comment: "KAFKA_PASSWORD: read from a secret"},
+		})))
+}
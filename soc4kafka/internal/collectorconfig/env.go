@@ -0,0 +1,63 @@
+package collectorconfig
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envRefPattern matches the ${NAME}, ${env:NAME} and ${env:NAME:-default} references expanded by the collector.
+var envRefPattern = regexp.MustCompile(`\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// ExpandEnv replaces environment variable references the same way the collector does when loading its config.
+// Unset variables without a default expand to an empty string.
+func ExpandEnv(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return envRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envRefPattern.FindStringSubmatch(ref)
+		if v, ok := os.LookupEnv(m[1]); ok {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// EnvRefs returns the names of the environment variables referenced in s.
+func EnvRefs(s string) []string {
+	var names []string
+	for _, m := range envRefPattern.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// EnvFileVar is a variable set by an environment file.
+type EnvFileVar struct {
+	Name  string
+	Value string
+	// Comment is written on the line before the variable when set.
+	Comment string
+}
+
+// RenderEnvFile returns an environment file setting vars, under a title comment asking to keep the file private as it
+// holds the secrets of the collector. Values are double-quoted so that the file can be sourced by a shell as well as
+// read by systemd as an EnvironmentFile.
+func RenderEnvFile(title string, vars []EnvFileVar) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s. Keep this file readable by the collector user only.\n", title)
+	for _, v := range vars {
+		if v.Comment != "" {
+			fmt.Fprintf(&buf, "# %s\n", v.Comment)
+		}
+		fmt.Fprintf(&buf, "%s=\"%s\"\n", v.Name, envValueEscaper.Replace(v.Value))
+	}
+	return buf.Bytes()
+}
+
+// envValueEscaper escapes the characters that keep a special meaning in double quotes, for both the shell and
+// systemd.
+var envValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
@@ -0,0 +1,60 @@
+package collectorconfig
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// TopicPatterns matches topic names against exclude_topics style entries.
+type TopicPatterns []func(string) bool
+
+// Match reports whether any pattern matches topic.
+func (p TopicPatterns) Match(topic string) bool {
+	return slices.ContainsFunc(p, func(m func(string) bool) bool { return m(topic) })
+}
+
+// CompileTopicPatterns compiles exclude_topics entries. Like the Kafka receiver, it compiles every entry as an
+// unanchored regular expression, with or without a leading ^: logs-b excludes logs-b-eu too.
+func CompileTopicPatterns(patterns []string) (TopicPatterns, error) {
+	var out TopicPatterns
+	for _, p := range patterns {
+		if p == "" {
+			return out, errors.New("empty topic pattern, which would match all topics")
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return out, fmt.Errorf("invalid topic pattern %q: %w", p, err)
+		}
+		out = append(out, re.MatchString)
+	}
+	return out, nil
+}
+
+// ResolveTopics returns the existing topics the receiver consumes, in lexical order. Literal topics are returned
+// even if they do not exist, regex topics are matched against existing minus the excluded topics.
+func (r KafkaReceiver) ResolveTopics(existing []string) ([]string, error) {
+	excluded, err := CompileTopicPatterns(r.ExcludeTopics)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, t := range r.Topics {
+		if !IsRegexTopic(t) {
+			out = append(out, t)
+			continue
+		}
+		re, err := regexp.Compile(t)
+		if err != nil {
+			return nil, fmt.Errorf("invalid topic pattern %q: %w", t, err)
+		}
+		for _, name := range existing {
+			if re.MatchString(name) && !excluded.Match(name) {
+				out = append(out, name)
+			}
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
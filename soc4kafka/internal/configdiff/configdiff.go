@@ -0,0 +1,462 @@
+// Package configdiff compares two collector configurations semantically: which topics are consumed by which
+// consumer groups and routed to which indexes through which processors, rather than how the YAML is laid out.
+package configdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// Kind is the kind of a change.
+type Kind string
+
+const (
+	TopicAdded          Kind = "topic_added"
+	TopicRemoved        Kind = "topic_removed"
+	RouteAdded          Kind = "route_added"
+	RouteRemoved        Kind = "route_removed"
+	GroupIDChanged      Kind = "group_id_changed"
+	IndexChanged        Kind = "index_changed"
+	SourceChanged       Kind = "source_changed"
+	SourcetypeChanged   Kind = "sourcetype_changed"
+	ProcessorsChanged   Kind = "processors_changed"
+	StatementAdded      Kind = "statement_added"
+	StatementRemoved    Kind = "statement_removed"
+	StatementsReordered Kind = "statements_reordered"
+	ComponentAdded      Kind = "component_added"
+	ComponentRemoved    Kind = "component_removed"
+	SettingChanged      Kind = "setting_changed"
+)
+
+// Change is a semantic difference between two configurations.
+type Change struct {
+	Kind Kind `json:"kind"`
+	// Topic is set for routing changes.
+	Topic string `json:"topic,omitempty"`
+	// Component is the exporter of routing changes, or the changed component.
+	Component string `json:"component,omitempty"`
+	// Path is the dotted path of a changed setting.
+	Path string `json:"path,omitempty"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+	// Note explains the impact of the change during a rolling update.
+	Note string `json:"note,omitempty"`
+}
+
+// String describes the change on a single line.
+func (c Change) String() string {
+	switch c.Kind {
+	case TopicAdded:
+		return fmt.Sprintf("+ topic %s -> %s: %s", c.Topic, c.Component, c.New)
+	case RouteAdded:
+		return fmt.Sprintf("+ topic %s -> %s (new route): %s", c.Topic, c.Component, c.New)
+	case TopicRemoved:
+		return fmt.Sprintf("- topic %s -> %s: %s", c.Topic, c.Component, c.Old)
+	case RouteRemoved:
+		return fmt.Sprintf("- topic %s -> %s (route removed): %s", c.Topic, c.Component, c.Old)
+	case GroupIDChanged, IndexChanged, SourceChanged, SourcetypeChanged, ProcessorsChanged:
+		field := strings.TrimSuffix(string(c.Kind), "_changed")
+		return fmt.Sprintf("~ topic %s -> %s: %s %s => %s", c.Topic, c.Component, field, c.Old, c.New)
+	case StatementAdded:
+		return fmt.Sprintf("+ %s statement: %s", c.Component, c.New)
+	case StatementRemoved:
+		return fmt.Sprintf("- %s statement: %s", c.Component, c.Old)
+	case StatementsReordered:
+		return fmt.Sprintf("~ %s statements reordered", c.Component)
+	case ComponentAdded:
+		return fmt.Sprintf("+ %s", c.Path)
+	case ComponentRemoved:
+		return fmt.Sprintf("- %s", c.Path)
+	default:
+		return fmt.Sprintf("~ %s: %s => %s", c.Path, orNone(c.Old), orNone(c.New))
+	}
+}
+
+// Normalize returns a copy of a decoded configuration with equivalent component IDs renamed to a canonical form,
+// so that configurations written by hand compare equal to the ones rendered by the Helm chart. The chart names
+// the exporter of its primary splunkExporters entry splunk_hec, hand written configs often splunk_hec/primary.
+func Normalize(raw map[string]any) map[string]any {
+	rename := func(id string) string {
+		if id == collectorconfig.HECExporterType+"/primary" {
+			return collectorconfig.HECExporterType
+		}
+		return id
+	}
+	out := make(map[string]any, len(raw))
+	for k, v := range raw {
+		out[k] = v
+	}
+	if exporters := collectorconfig.Map(raw, "exporters"); exporters != nil {
+		renamed := make(map[string]any, len(exporters))
+		for id, cfg := range exporters {
+			renamed[rename(id)] = cfg
+		}
+		out["exporters"] = renamed
+	}
+	service := collectorconfig.Map(raw, "service")
+	if pipelines := collectorconfig.Map(service, "pipelines"); pipelines != nil {
+		renamed := make(map[string]any, len(pipelines))
+		for id := range pipelines {
+			p := collectorconfig.Map(pipelines, id)
+			copied := make(map[string]any, len(p))
+			for k, v := range p {
+				copied[k] = v
+			}
+			if exporters := collectorconfig.Strings(p, "exporters"); exporters != nil {
+				var list []any
+				for _, e := range exporters {
+					list = append(list, rename(e))
+				}
+				copied["exporters"] = list
+			}
+			renamed[id] = copied
+		}
+		newService := make(map[string]any, len(service))
+		for k, v := range service {
+			newService[k] = v
+		}
+		newService["pipelines"] = renamed
+		out["service"] = newService
+	}
+	return out
+}
+
+// Diff returns the changes from old to new. Both configurations should be normalized.
+func Diff(old, new *collectorconfig.Config) []Change {
+	var changes []Change
+	changes = append(changes, diffRoutes(routes(old), routes(new))...)
+	for _, section := range []string{"receivers", "processors", "exporters", "connectors", "extensions"} {
+		changes = append(changes, diffComponents(section, collectorconfig.Map(old.Raw, section), collectorconfig.Map(new.Raw, section))...)
+	}
+	changes = append(changes, diffService(collectorconfig.Map(old.Raw, "service"), collectorconfig.Map(new.Raw, "service"))...)
+	return changes
+}
+
+// route is how records of a topic reach an exporter.
+type route struct {
+	topic      string
+	exporter   string
+	groups     []string
+	processors []string
+	receivers  []string
+	index      string
+	source     string
+	sourcetype string
+}
+
+func (r route) String() string {
+	return fmt.Sprintf("%s (group %s) processors %s index=%s source=%s sourcetype=%s",
+		strings.Join(r.receivers, ", "), strings.Join(r.groups, ", "), strings.Join(r.processors, " | "), orNone(r.index), orNone(r.source), orNone(r.sourcetype))
+}
+
+type routeKey struct{ topic, exporter string }
+
+func routes(cfg *collectorconfig.Config) map[routeKey]*route {
+	out := map[routeKey]*route{}
+	for _, p := range cfg.Pipelines {
+		for _, rid := range p.Receivers {
+			r, ok := cfg.KafkaReceiver(rid)
+			if !ok {
+				continue
+			}
+			for _, topic := range r.Topics {
+				if collectorconfig.IsRegexTopic(topic) && len(r.ExcludeTopics) > 0 {
+					topic += " excluding " + strings.Join(r.ExcludeTopics, ", ")
+				}
+				for _, eid := range p.Exporters {
+					key := routeKey{topic: topic, exporter: eid}
+					rt, ok := out[key]
+					if !ok {
+						rt = &route{topic: topic, exporter: eid}
+						if e, ok := cfg.HECExporter(eid); ok {
+							rt.index, rt.source, rt.sourcetype = e.Index, e.Source, e.Sourcetype
+						}
+						out[key] = rt
+					}
+					rt.groups = appendUnique(rt.groups, r.EffectiveGroupID())
+					rt.receivers = appendUnique(rt.receivers, r.ID)
+					chain := list(p.Processors)
+					if !slices.Contains(rt.processors, chain) {
+						rt.processors = append(rt.processors, chain)
+					}
+				}
+			}
+		}
+	}
+	for _, rt := range out {
+		sort.Strings(rt.groups)
+		sort.Strings(rt.receivers)
+		sort.Strings(rt.processors)
+	}
+	return out
+}
+
+func diffRoutes(old, new map[routeKey]*route) []Change {
+	var changes []Change
+	topics := func(routes map[routeKey]*route) map[string]bool {
+		out := map[string]bool{}
+		for k := range routes {
+			out[k.topic] = true
+		}
+		return out
+	}
+	oldTopics, newTopics := topics(old), topics(new)
+	for _, key := range sortedKeys(new) {
+		if _, ok := old[key]; !ok {
+			kind := RouteAdded
+			if !oldTopics[key.topic] {
+				kind = TopicAdded
+			}
+			changes = append(changes, Change{Kind: kind, Topic: key.topic, Component: key.exporter, New: new[key].String()})
+		}
+	}
+	for _, key := range sortedKeys(old) {
+		n, ok := new[key]
+		o := old[key]
+		if !ok {
+			kind := RouteRemoved
+			if !newTopics[key.topic] {
+				kind = TopicRemoved
+			}
+			changes = append(changes, Change{Kind: kind, Topic: key.topic, Component: key.exporter, Old: o.String()})
+			continue
+		}
+		add := func(kind Kind, oldValue, newValue, note string) {
+			if oldValue != newValue {
+				changes = append(changes, Change{Kind: kind, Topic: key.topic, Component: key.exporter, Old: oldValue, New: newValue, Note: note})
+			}
+		}
+		add(GroupIDChanged, strings.Join(o.groups, ", "), strings.Join(n.groups, ", "),
+			"during a rolling update the old and the new group both consume the topic, the new group starts from the receiver initial_offset")
+		add(IndexChanged, orNone(o.index), orNone(n.index), "")
+		add(SourceChanged, orNone(o.source), orNone(n.source), "")
+		add(SourcetypeChanged, orNone(o.sourcetype), orNone(n.sourcetype), "")
+		add(ProcessorsChanged, strings.Join(o.processors, " | "), strings.Join(n.processors, " | "), "")
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Topic < changes[j].Topic })
+	return changes
+}
+
+// routedSettings are compared per topic by diffRoutes, or per statement for processors.
+var routedSettings = map[string][]string{
+	"receivers":  {"group_id", "topic", "logs.topics", "logs.exclude_topics"},
+	"exporters":  {"index", "source", "sourcetype"},
+	"processors": {"log_statements", "metric_statements", "trace_statements", "statements"},
+}
+
+func diffComponents(section string, old, new map[string]any) []Change {
+	var changes []Change
+	for _, id := range collectorconfig.SortedKeys(new) {
+		if _, ok := old[id]; !ok {
+			changes = append(changes, Change{Kind: ComponentAdded, Component: id, Path: section + "." + id})
+		}
+	}
+	for _, id := range collectorconfig.SortedKeys(old) {
+		newValue, ok := new[id]
+		if !ok {
+			changes = append(changes, Change{Kind: ComponentRemoved, Component: id, Path: section + "." + id})
+			continue
+		}
+		oldSettings, newSettings := map[string]string{}, map[string]string{}
+		flatten("", old[id], oldSettings)
+		flatten("", newValue, newSettings)
+		if section == "processors" {
+			changes = append(changes, diffStatements(id, old[id], newValue)...)
+		}
+		for _, path := range sortedUnion(oldSettings, newSettings) {
+			if oldSettings[path] == newSettings[path] || isRouted(section, path) {
+				continue
+			}
+			changes = append(changes, Change{Kind: SettingChanged, Component: id, Path: section + "." + id + "." + path,
+				Old: oldSettings[path], New: newSettings[path]})
+		}
+	}
+	return changes
+}
+
+func diffService(old, new map[string]any) []Change {
+	var changes []Change
+	oldPipelines, newPipelines := collectorconfig.Map(old, "pipelines"), collectorconfig.Map(new, "pipelines")
+	for _, id := range collectorconfig.SortedKeys(newPipelines) {
+		if _, ok := oldPipelines[id]; !ok {
+			changes = append(changes, Change{Kind: ComponentAdded, Component: id, Path: "service.pipelines." + id})
+		}
+	}
+	for _, id := range collectorconfig.SortedKeys(oldPipelines) {
+		if _, ok := newPipelines[id]; !ok {
+			changes = append(changes, Change{Kind: ComponentRemoved, Component: id, Path: "service.pipelines." + id})
+		}
+	}
+	oldSettings, newSettings := map[string]string{}, map[string]string{}
+	// Pipeline contents are compared through the routes.
+	flatten("service", omit(old, "pipelines"), oldSettings)
+	flatten("service", omit(new, "pipelines"), newSettings)
+	for _, path := range sortedUnion(oldSettings, newSettings) {
+		if oldSettings[path] != newSettings[path] {
+			changes = append(changes, Change{Kind: SettingChanged, Path: path, Old: oldSettings[path], New: newSettings[path]})
+		}
+	}
+	return changes
+}
+
+// diffStatements compares the OTTL statements of transform and filter processors.
+func diffStatements(id string, old, new any) []Change {
+	oldStatements, newStatements := statements(old), statements(new)
+	var changes []Change
+	for _, s := range subtract(newStatements, oldStatements) {
+		changes = append(changes, Change{Kind: StatementAdded, Component: id, New: s})
+	}
+	for _, s := range subtract(oldStatements, newStatements) {
+		changes = append(changes, Change{Kind: StatementRemoved, Component: id, Old: s})
+	}
+	if len(changes) == 0 && !slices.Equal(oldStatements, newStatements) {
+		changes = append(changes, Change{Kind: StatementsReordered, Component: id,
+			Old: strings.Join(oldStatements, "; "), New: strings.Join(newStatements, "; ")})
+	}
+	return changes
+}
+
+// statements returns the statements of a processor in order. Statements of grouped entries are prefixed with their
+// context, e.g. "log: set(...)".
+func statements(cfg any) []string {
+	m, _ := cfg.(map[string]any)
+	var out []string
+	for _, key := range routedSettings["processors"] {
+		entries, _ := m[key].([]any)
+		for _, entry := range entries {
+			switch e := entry.(type) {
+			case string:
+				out = append(out, e)
+			case map[string]any:
+				prefix := ""
+				if context := collectorconfig.String(e, "context"); context != "" {
+					prefix = context + ": "
+				}
+				for _, s := range collectorconfig.Strings(e, "statements") {
+					out = append(out, prefix+s)
+				}
+			}
+		}
+	}
+	return out
+}
+
+func isRouted(section, path string) bool {
+	for _, routed := range routedSettings[section] {
+		if path == routed || strings.HasPrefix(path, routed+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// flatten stores the leaves of v by dotted path. Lists are compared as a whole.
+func flatten(prefix string, v any, out map[string]string) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		if v != nil || prefix != "" {
+			out[prefix] = render(v)
+		}
+		return
+	}
+	if len(m) == 0 && prefix != "" {
+		out[prefix] = "{}"
+	}
+	for k, item := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		flatten(path, item, out)
+	}
+}
+
+func render(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+func omit(m map[string]any, key string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		if k != key {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// subtract returns the items of a missing from b, keeping duplicates.
+func subtract(a, b []string) []string {
+	remaining := map[string]int{}
+	for _, s := range b {
+		remaining[s]++
+	}
+	var out []string
+	for _, s := range a {
+		if remaining[s] > 0 {
+			remaining[s]--
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func appendUnique(items []string, item string) []string {
+	if slices.Contains(items, item) {
+		return items
+	}
+	return append(items, item)
+}
+
+func sortedUnion(a, b map[string]string) []string {
+	keys := collectorconfig.SortedKeys(a)
+	for _, k := range collectorconfig.SortedKeys(b) {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(routes map[routeKey]*route) []routeKey {
+	keys := make([]routeKey, 0, len(routes))
+	for k := range routes {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].topic != keys[j].topic {
+			return keys[i].topic < keys[j].topic
+		}
+		return keys[i].exporter < keys[j].exporter
+	})
+	return keys
+}
+
+func list(items []string) string {
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
@@ -0,0 +1,161 @@
+package configdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+)
+
+const oldConfig = `
+receivers:
+  kafka/main:
+    brokers: [kafka:9092]
+    group_id: soc4kafka
+    logs:
+      topics: [app, audit]
+processors:
+  resourcedetection:
+    detectors: [system]
+  transform/timestamp:
+    error_mode: ignore
+    log_statements:
+      - context: log
+        statements:
+          - set(time, Now())
+          - set(attributes["env"], "prod")
+exporters:
+  splunk_hec/primary:
+    endpoint: https://splunk:8088/services/collector
+    token: ${SPLUNK_HEC_TOKEN}
+    index: kafka
+    sending_queue:
+      queue_size: 10000
+service:
+  pipelines:
+    logs:
+      receivers: [kafka/main]
+      processors: [resourcedetection, transform/timestamp]
+      exporters: [splunk_hec/primary]
+`
+
+const newConfig = `
+receivers:
+  kafka/main:
+    brokers: [kafka:9092]
+    group_id: soc4kafka-v2
+    logs:
+      topics: [app, metrics]
+processors:
+  resourcedetection:
+    detectors: [system]
+  transform/timestamp:
+    error_mode: propagate
+    log_statements:
+      - context: log
+        statements:
+          - set(time, Now())
+          - set(attributes["env"], "staging")
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    token: ${SPLUNK_HEC_TOKEN}
+    index: kafka-v2
+    sending_queue:
+      queue_size: 5000
+service:
+  pipelines:
+    logs:
+      receivers: [kafka/main]
+      processors: [transform/timestamp, resourcedetection]
+      exporters: [splunk_hec]
+`
+
+func parse(t *testing.T, data string) *collectorconfig.Config {
+	cfg, err := collectorconfig.Parse([]byte(data))
+	require.NoError(t, err)
+	return collectorconfig.FromMap(Normalize(cfg.Raw))
+}
+
+func TestDiff(t *testing.T) {
+	changes := Diff(parse(t, oldConfig), parse(t, newConfig))
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		"~ topic app -> splunk_hec: group_id soc4kafka => soc4kafka-v2",
+		"~ topic app -> splunk_hec: index kafka => kafka-v2",
+		"~ topic app -> splunk_hec: processors [resourcedetection, transform/timestamp] => [transform/timestamp, resourcedetection]",
+		"- topic audit -> splunk_hec: kafka/main (group soc4kafka) processors [resourcedetection, transform/timestamp] index=kafka source=(none) sourcetype=(none)",
+		"+ topic metrics -> splunk_hec: kafka/main (group soc4kafka-v2) processors [transform/timestamp, resourcedetection] index=kafka-v2 source=(none) sourcetype=(none)",
+		`+ transform/timestamp statement: log: set(attributes["env"], "staging")`,
+		`- transform/timestamp statement: log: set(attributes["env"], "prod")`,
+		"~ processors.transform/timestamp.error_mode: ignore => propagate",
+		"~ exporters.splunk_hec.sending_queue.queue_size: 10000 => 5000",
+	}, lines)
+	assert.NotEmpty(t, changes[0].Note)
+}
+
+func TestDiffIdentical(t *testing.T) {
+	assert.Empty(t, Diff(parse(t, oldConfig), parse(t, oldConfig)))
+}
+
+func TestStatementsReordered(t *testing.T) {
+	changes := diffStatements("transform",
+		map[string]any{"log_statements": []any{"a", "b"}},
+		map[string]any{"log_statements": []any{"b", "a"}})
+	require.Len(t, changes, 1)
+	assert.Equal(t, StatementsReordered, changes[0].Kind)
+}
+
+// The chart renders the primary exporter as splunk_hec, with the defaults of its values.yaml and configOverride
+// merged in: a hand written config with the same content must not differ from it.
+func TestDiffHelmValues(t *testing.T) {
+	values, err := helmvalues.Load("../../../rendered/values_base.yaml")
+	require.NoError(t, err)
+	rendered, err := helmvalues.Config(values)
+	require.NoError(t, err)
+	chart := collectorconfig.FromMap(Normalize(rendered))
+
+	handWritten := map[string]any{}
+	for k, v := range rendered {
+		handWritten[k] = v
+	}
+	exporters := map[string]any{}
+	for id, cfg := range collectorconfig.Map(rendered, "exporters") {
+		if id == "splunk_hec" {
+			id = "splunk_hec/primary"
+		}
+		exporters[id] = cfg
+	}
+	handWritten["exporters"] = exporters
+	pipelines := map[string]any{}
+	for id, p := range collectorconfig.Map(collectorconfig.Map(rendered, "service"), "pipelines") {
+		copied := map[string]any{}
+		for k, v := range p.(map[string]any) {
+			copied[k] = v
+		}
+		var ids []any
+		for _, e := range collectorconfig.Strings(copied, "exporters") {
+			if e == "splunk_hec" {
+				e = "splunk_hec/primary"
+			}
+			ids = append(ids, e)
+		}
+		copied["exporters"] = ids
+		pipelines[id] = copied
+	}
+	service := map[string]any{}
+	for k, v := range collectorconfig.Map(rendered, "service") {
+		service[k] = v
+	}
+	service["pipelines"] = pipelines
+	handWritten["service"] = service
+	require.Contains(t, exporters, "splunk_hec/primary")
+
+	assert.Empty(t, Diff(chart, collectorconfig.FromMap(Normalize(handWritten))))
+}
@@ -0,0 +1,331 @@
+// Package dryrun renders the HEC events a collector configuration produces from Kafka records, applying the header
+// extraction and encoding of the kafka receivers, the processors of the pipelines and the HEC metadata mapping of
+// the splunk_hec exporters, without sending anything.
+package dryrun
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/ottl"
+)
+
+// Default otel_attrs_to_hec_metadata mapping of the splunk_hec exporter.
+var defaultHECMetadata = map[string]string{
+	"host":       "host.name",
+	"index":      "com.splunk.index",
+	"source":     "com.splunk.source",
+	"sourcetype": "com.splunk.sourcetype",
+}
+
+const (
+	// unknownHost is the host of events without a host attribute.
+	unknownHost = "unknown"
+	// tokenAttribute overrides the HEC token, it is never sent as a field.
+	tokenAttribute = "com.splunk.hec.access_token"
+)
+
+// Message is a Kafka record to render.
+type Message struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Timestamp time.Time         `json:"timestamp,omitzero"`
+	Key       string            `json:"key,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Value     []byte            `json:"-"`
+	// Receiver is the ID of the kafka receiver consuming the message, the receivers whose topics match when empty.
+	Receiver string `json:"receiver,omitempty"`
+}
+
+// Event is the HEC event sent by the splunk_hec exporter, with the same fields in the same order.
+type Event struct {
+	Time       float64        `json:"time,omitempty"`
+	Host       string         `json:"host"`
+	Source     string         `json:"source,omitempty"`
+	Sourcetype string         `json:"sourcetype,omitempty"`
+	Index      string         `json:"index,omitempty"`
+	Event      any            `json:"event"`
+	Fields     map[string]any `json:"fields,omitempty"`
+}
+
+// Output is the result of a message in a pipeline.
+type Output struct {
+	Message  Message `json:"message"`
+	Receiver string  `json:"receiver,omitempty"`
+	Pipeline string  `json:"pipeline,omitempty"`
+	Exporter string  `json:"exporter,omitempty"`
+	// Event is the HEC event, nil when the message is dropped or fails.
+	Event *Event `json:"event,omitempty"`
+	// Dropped explains why the message produces no event, e.g. a filter processor.
+	Dropped string `json:"dropped,omitempty"`
+	// Error is the error failing the message, e.g. a statement error with error_mode: propagate.
+	Error string `json:"error,omitempty"`
+	// Notes are the statement errors ignored by the processors.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Options tune the simulation.
+type Options struct {
+	// Hostname is the host.name set by the system detector of the resourcedetection processor, the local hostname
+	// when empty.
+	Hostname string
+	// Now is the observed time of the records, time.Now when nil.
+	Now func() time.Time
+}
+
+type pipeline struct {
+	id         string
+	receivers  []collectorconfig.KafkaReceiver
+	processors []processor
+	exporters  []collectorconfig.HECExporter
+}
+
+// Renderer renders messages through the logs pipelines of a configuration.
+type Renderer struct {
+	// Warnings list the parts of the configuration that are not simulated.
+	Warnings []string
+
+	cfg       *collectorconfig.Config
+	pipelines []pipeline
+	hostname  string
+	now       func() time.Time
+}
+
+// New prepares the pipelines of the configuration. It fails on statements the collector would reject.
+func New(cfg *collectorconfig.Config, opts Options) (*Renderer, error) {
+	rd := &Renderer{cfg: cfg, hostname: opts.Hostname, now: opts.Now}
+	if rd.hostname == "" {
+		var err error
+		if rd.hostname, err = os.Hostname(); err != nil {
+			return nil, err
+		}
+	}
+	if rd.now == nil {
+		rd.now = time.Now
+	}
+	processors := collectorconfig.Map(cfg.Raw, "processors")
+	compiled := map[string]processor{}
+	var errs []error
+	for _, p := range cfg.Pipelines {
+		if collectorconfig.ComponentType(p.ID) != "logs" {
+			continue
+		}
+		pl := pipeline{id: p.ID}
+		for _, id := range p.Receivers {
+			r, ok := cfg.KafkaReceiver(id)
+			if !ok {
+				rd.warnf("%s: receiver %s is not a kafka receiver, its records are not rendered", p.ID, id)
+				continue
+			}
+			pl.receivers = append(pl.receivers, r)
+		}
+		for _, id := range p.Processors {
+			proc, ok := compiled[id]
+			if !ok {
+				var err error
+				if proc, err = rd.compileProcessor(id, collectorconfig.Map(processors, id)); err != nil {
+					errs = append(errs, err)
+				}
+				compiled[id] = proc
+			}
+			if proc != nil {
+				pl.processors = append(pl.processors, proc)
+			}
+		}
+		for _, id := range p.Exporters {
+			e, ok := cfg.HECExporter(id)
+			if !ok {
+				rd.warnf("%s: exporter %s is not a splunk_hec exporter, its events are not rendered", p.ID, id)
+				continue
+			}
+			pl.exporters = append(pl.exporters, e)
+		}
+		if len(pl.receivers) > 0 {
+			rd.pipelines = append(rd.pipelines, pl)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rd, nil
+}
+
+func (rd *Renderer) warnf(format string, args ...any) {
+	w := fmt.Sprintf(format, args...)
+	if !slices.Contains(rd.Warnings, w) {
+		rd.Warnings = append(rd.Warnings, w)
+	}
+}
+
+// Render returns the outputs of a message: one per pipeline and exporter it reaches, or a single output without
+// pipeline when no kafka receiver consumes its topic.
+func (rd *Renderer) Render(m Message) []Output {
+	var outputs []Output
+	for _, pl := range rd.pipelines {
+		for _, r := range pl.receivers {
+			if !consumes(r, m) {
+				continue
+			}
+			outputs = append(outputs, rd.render(m, r, pl)...)
+		}
+	}
+	if len(outputs) == 0 {
+		reason := fmt.Sprintf("no logs pipeline consumes topic %s", m.Topic)
+		if m.Receiver != "" {
+			reason = fmt.Sprintf("receiver %s is not in a logs pipeline", m.Receiver)
+		}
+		outputs = append(outputs, Output{Message: m, Dropped: reason})
+	}
+	return outputs
+}
+
+func consumes(r collectorconfig.KafkaReceiver, m Message) bool {
+	if m.Receiver != "" {
+		return r.ID == m.Receiver
+	}
+	topics, err := r.ResolveTopics([]string{m.Topic})
+	return err == nil && slices.Contains(topics, m.Topic)
+}
+
+func (rd *Renderer) render(m Message, r collectorconfig.KafkaReceiver, pl pipeline) []Output {
+	out := Output{Message: m, Receiver: r.ID, Pipeline: pl.id}
+	record, err := rd.decode(r, m)
+	if err != nil {
+		out.Error = err.Error()
+		return []Output{out}
+	}
+	for _, proc := range pl.processors {
+		notes, err := proc(record)
+		out.Notes = append(out.Notes, notes...)
+		if errors.Is(err, errDropped) {
+			out.Dropped = err.Error()
+			return []Output{out}
+		}
+		if err != nil {
+			out.Error = err.Error()
+			return []Output{out}
+		}
+	}
+	var outputs []Output
+	for _, e := range pl.exporters {
+		o := out
+		o.Exporter = e.ID
+		o.Event = hecEvent(e, record)
+		outputs = append(outputs, o)
+	}
+	if len(outputs) == 0 {
+		out.Dropped = fmt.Sprintf("%s has no splunk_hec exporter", pl.id)
+		outputs = append(outputs, out)
+	}
+	return outputs
+}
+
+// decode builds the log record the kafka receiver creates from a message.
+func (rd *Renderer) decode(r collectorconfig.KafkaReceiver, m Message) (*ottl.Record, error) {
+	record := ottl.NewRecord()
+	record.ObservedTime = rd.now().UTC()
+	switch encoding := r.Encoding; {
+	case encoding == "text" || len(encoding) > 5 && encoding[:5] == "text_":
+		record.Body = string(m.Value)
+	case encoding == "raw":
+		record.Body = m.Value
+	case encoding == "json":
+		var body any
+		if err := json.Unmarshal(m.Value, &body); err != nil {
+			return nil, fmt.Errorf("%s: the message is not valid JSON: %w", r.ID, err)
+		}
+		record.Body = body
+	case encoding == "":
+		return nil, fmt.Errorf("%s: the default otlp_proto encoding is not supported by soc4kafka, set logs.encoding", r.ID)
+	default:
+		return nil, fmt.Errorf("%s: encoding %s is not supported by soc4kafka", r.ID, encoding)
+	}
+	if r.ExtractHeaders {
+		for _, h := range r.ExtractedHeaders {
+			if v, ok := m.Headers[h]; ok {
+				record.Resource[collectorconfig.HeaderAttributePrefix+h] = v
+			}
+		}
+	}
+	return record, nil
+}
+
+// hecEvent maps a record to a HEC event as the splunk_hec exporter does: the mapped attributes set the metadata,
+// the other resource and record attributes become indexed fields, flattened with dotted keys.
+func hecEvent(e collectorconfig.HECExporter, r *ottl.Record) *Event {
+	mapping := map[string]string{}
+	for field, attr := range defaultHECMetadata {
+		mapping[field] = attr
+	}
+	for field, attr := range e.HECMetadata {
+		mapping[field] = attr
+	}
+	ev := &Event{Host: unknownHost, Source: e.Source, Sourcetype: e.Sourcetype, Index: e.Index, Fields: map[string]any{}}
+	if !r.Time.IsZero() {
+		ev.Time = math.Round(float64(r.Time.UnixNano())/1e6) / 1e3
+	}
+	if r.SeverityText != "" {
+		ev.Fields["otel.log.severity.text"] = r.SeverityText
+	}
+	if r.SeverityNumber != 0 {
+		ev.Fields["otel.log.severity.number"] = r.SeverityNumber
+	}
+	for _, attrs := range []map[string]any{r.Resource, r.Attributes} {
+		for _, k := range collectorconfig.SortedKeys(attrs) {
+			v := attrs[k]
+			switch k {
+			case mapping["host"]:
+				ev.Host = ottl.String(v)
+			case mapping["source"]:
+				ev.Source = ottl.String(v)
+			case mapping["sourcetype"]:
+				ev.Sourcetype = ottl.String(v)
+			case mapping["index"]:
+				ev.Index = ottl.String(v)
+			case tokenAttribute:
+			default:
+				mergeField(ev.Fields, k, v)
+			}
+		}
+	}
+	ev.Event = r.Body
+	if ev.Event == nil {
+		ev.Event = ""
+	}
+	return ev
+}
+
+func mergeField(fields map[string]any, key string, v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			mergeField(fields, key+"."+k, item)
+		}
+	case []any:
+		if flat(v) {
+			fields[key] = v
+		} else {
+			data, _ := json.Marshal(v)
+			fields[key] = string(data)
+		}
+	default:
+		fields[key] = v
+	}
+}
+
+func flat(values []any) bool {
+	for _, v := range values {
+		switch v.(type) {
+		case map[string]any, []any:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,237 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+const config = `
+receivers:
+  kafka/main:
+    brokers: ["localhost:9092"]
+    logs:
+      topics: ["^app-.*"]
+      encoding: text
+    header_extraction:
+      extract_headers: true
+      headers: ["index", "tenant"]
+  kafka/json:
+    brokers: ["localhost:9092"]
+    logs:
+      topics: ["orders"]
+      encoding: json
+processors:
+  resourcedetection:
+    detectors: [system, ec2]
+  transform/timestamp:
+    error_mode: ignore
+    log_statements:
+      - set(log.attributes["extracted_ts"], ExtractPatterns(log.body, "\\[(?P<timestamp>[^\\]]+)\\]"))
+      - set(log.time, Time(log.attributes["extracted_ts"]["timestamp"], "%Y-%m-%d %H:%M:%S.%L", "UTC"))
+      - delete_key(log.attributes, "extracted_ts")
+      - set(log.attributes["level"], "error") where IsMatch(log.body, "ERROR")
+      - set(log.body, SHA256(log.body))
+  transform/orders:
+    log_statements:
+      - context: log
+        conditions: ['body["total"] > 100']
+        statements:
+          - set(attributes["big"], true)
+          - set(attributes["customer"], body["customer"])
+      - set(log.attributes["currency"], ToLowerCase(log.body["currency"]))
+  filter/debug:
+    error_mode: ignore
+    logs:
+      log_record:
+        - IsMatch(log.body, "DEBUG")
+  batch: {}
+  k8sattributes: {}
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    index: kafka
+    source: soc4kafka
+    sourcetype: kafka:logs
+    otel_attrs_to_hec_metadata:
+      index: kafka.header.index
+  splunk_hec/orders:
+    endpoint: https://splunk:8088/services/collector
+    index: orders
+  debug: {}
+service:
+  pipelines:
+    logs:
+      receivers: [kafka/main]
+      processors: [resourcedetection, filter/debug, transform/timestamp, batch]
+      exporters: [splunk_hec]
+    logs/orders:
+      receivers: [kafka/json]
+      processors: [transform/orders, k8sattributes]
+      exporters: [splunk_hec/orders, debug]
+`
+
+func renderer(t *testing.T, data string) *Renderer {
+	t.Helper()
+	cfg, err := collectorconfig.Parse([]byte(data))
+	require.NoError(t, err)
+	rd, err := New(cfg, Options{Hostname: "collector-1"})
+	require.NoError(t, err)
+	return rd
+}
+
+func event(t *testing.T, o Output) string {
+	t.Helper()
+	require.NotNil(t, o.Event, "%+v", o)
+	data, err := json.Marshal(o.Event)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRender(t *testing.T) {
+	rd := renderer(t, config)
+	assert.Equal(t, []string{
+		"resourcedetection: the ec2 detector is not simulated",
+		`transform/timestamp: "set(log.body, SHA256(log.body))" is skipped: converter SHA256 is not supported by soc4kafka`,
+		"k8sattributes: the processor is not simulated, its changes are not shown",
+		"logs/orders: exporter debug is not a splunk_hec exporter, its events are not rendered",
+	}, rd.Warnings)
+
+	outputs := rd.Render(Message{Topic: "app-web", Offset: 3, Value: []byte("[2024-05-01 10:20:30.125] ERROR boom"),
+		Headers: map[string]string{"index": "web", "tenant": "acme", "other": "x"}})
+	require.Len(t, outputs, 1)
+	assert.Equal(t, "kafka/main", outputs[0].Receiver)
+	assert.Equal(t, "logs", outputs[0].Pipeline)
+	assert.Equal(t, "splunk_hec", outputs[0].Exporter)
+	assert.JSONEq(t, `{"time": 1714558830.125, "host": "collector-1", "source": "soc4kafka", "sourcetype": "kafka:logs",
+		"index": "web", "event": "[2024-05-01 10:20:30.125] ERROR boom",
+		"fields": {"kafka.header.tenant": "acme", "level": "error", "os.type": "`+runtime.GOOS+`"}}`, event(t, outputs[0]))
+
+	outputs = rd.Render(Message{Topic: "app-web", Value: []byte("no timestamp")})
+	require.Len(t, outputs, 1)
+	assert.NotContains(t, event(t, outputs[0]), `"time"`)
+	assert.Contains(t, event(t, outputs[0]), `"index":"kafka"`)
+	assert.Equal(t, []string{`transform/timestamp: "set(log.time, Time(log.attributes[\"extracted_ts\"][\"timestamp\"], \"%Y-%m-%d %H:%M:%S.%L\", \"UTC\"))": Time: expected string but got nil`}, outputs[0].Notes)
+
+	outputs = rd.Render(Message{Topic: "app-web", Value: []byte("DEBUG noise")})
+	assert.Equal(t, `dropped by filter/debug: "IsMatch(log.body, \"DEBUG\")"`, outputs[0].Dropped)
+	assert.Nil(t, outputs[0].Event)
+
+	outputs = rd.Render(Message{Topic: "unknown", Value: []byte("x")})
+	assert.Equal(t, []Output{{Message: Message{Topic: "unknown", Value: []byte("x")}, Dropped: "no logs pipeline consumes topic unknown"}}, outputs)
+}
+
+func TestRenderJSON(t *testing.T) {
+	rd := renderer(t, config)
+	outputs := rd.Render(Message{Topic: "orders", Value: []byte(`{"total": 250, "customer": {"id": 7, "tags": ["a", "b"]}, "currency": "EUR"}`)})
+	require.Len(t, outputs, 1)
+	assert.Equal(t, "splunk_hec/orders", outputs[0].Exporter)
+	assert.JSONEq(t, `{"host": "unknown", "index": "orders",
+		"event": {"total": 250, "customer": {"id": 7, "tags": ["a", "b"]}, "currency": "EUR"},
+		"fields": {"big": true, "customer.id": 7, "customer.tags": ["a", "b"], "currency": "eur"}}`, event(t, outputs[0]))
+
+	outputs = rd.Render(Message{Topic: "orders", Value: []byte(`{"total": 5}`)})
+	assert.Equal(t, `transform/orders: "set(log.attributes[\"currency\"], ToLowerCase(log.body[\"currency\"]))": ToLowerCase: expected string but got nil (error_mode: propagate fails the whole batch)`, outputs[0].Error)
+	assert.Nil(t, outputs[0].Event)
+
+	outputs = rd.Render(Message{Topic: "orders", Value: []byte(`not json`)})
+	assert.Equal(t, "kafka/json: the message is not valid JSON: invalid character 'o' in literal null (expecting 'u')", outputs[0].Error)
+}
+
+func TestNewRejectsInvalidStatements(t *testing.T) {
+	cfg, err := collectorconfig.Parse([]byte(strings.Replace(config, `- delete_key(log.attributes, "extracted_ts")`,
+		`- delete_key(log.attributes, "extracted_ts"`, 1)))
+	require.NoError(t, err)
+	_, err = New(cfg, Options{Hostname: "h"})
+	require.EqualError(t, err, `transform/timestamp: invalid statement "delete_key(log.attributes, \"extracted_ts\"": unexpected end of statement, expected ","`)
+}
+
+func TestReadSamples(t *testing.T) {
+	messages, err := ReadSamples(strings.NewReader(`{"topic": "app-web", "headers": {"index": "web"}, "value": "hello"}
+
+{"topic": "orders", "offset": 42, "value": {"total": 5}}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []Message{
+		{Topic: "app-web", Headers: map[string]string{"index": "web"}, Value: []byte("hello")},
+		{Topic: "orders", Offset: 42, Value: []byte(`{"total": 5}`)},
+	}, messages)
+
+	_, err = ReadSamples(strings.NewReader(`{"topic": "a"}`))
+	require.EqualError(t, err, "line 1: value is required")
+	_, err = ReadSamples(strings.NewReader(`{"value": "a", "header": {}}`))
+	require.EqualError(t, err, `line 1: json: unknown field "header"`)
+}
+
+type fakeCluster struct {
+	topics  map[string]int
+	start   map[kafkaclient.TopicPartition]int64
+	end     map[kafkaclient.TopicPartition]int64
+	records []kafkaclient.Record
+	ranges  map[kafkaclient.TopicPartition]kafkaclient.OffsetRange
+}
+
+func (f *fakeCluster) Topics(context.Context) (map[string]int, error) { return f.topics, nil }
+
+func (f *fakeCluster) StartOffsets(context.Context, []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.start, nil
+}
+
+func (f *fakeCluster) EndOffsets(context.Context, []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.end, nil
+}
+
+func (f *fakeCluster) Read(_ context.Context, ranges map[kafkaclient.TopicPartition]kafkaclient.OffsetRange) ([]kafkaclient.Record, error) {
+	f.ranges = ranges
+	var out []kafkaclient.Record
+	for _, r := range f.records {
+		if rg, ok := ranges[r.TopicPartition]; ok && r.Offset >= rg.Start && r.Offset < rg.End {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestFetch(t *testing.T) {
+	a0 := kafkaclient.TopicPartition{Topic: "app-a", Partition: 0}
+	b0 := kafkaclient.TopicPartition{Topic: "app-b", Partition: 0}
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	f := &fakeCluster{
+		topics: map[string]int{"app-a": 1, "app-b": 1, "other": 1},
+		start:  map[kafkaclient.TopicPartition]int64{a0: 0, b0: 5},
+		end:    map[kafkaclient.TopicPartition]int64{a0: 3, b0: 7},
+	}
+	for i := range 3 {
+		f.records = append(f.records, kafkaclient.Record{TopicPartition: a0, Offset: int64(i), Timestamp: base.Add(time.Duration(2*i) * time.Minute),
+			Value: []byte("a"), Headers: []kafkaclient.Header{{Key: "h", Value: []byte("first")}, {Key: "h", Value: []byte("second")}}})
+	}
+	for i := 5; i < 7; i++ {
+		f.records = append(f.records, kafkaclient.Record{TopicPartition: b0, Offset: int64(i), Timestamp: base.Add(time.Duration(2*i-9) * time.Minute), Value: []byte("b")})
+	}
+	r := collectorconfig.KafkaReceiver{ID: "kafka/main", Topics: []string{"^app-.*"}}
+
+	messages, err := Fetch(context.Background(), f, r, 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[kafkaclient.TopicPartition]kafkaclient.OffsetRange{a0: {Start: 1, End: 3}, b0: {Start: 5, End: 7}}, f.ranges)
+	require.Len(t, messages, 2)
+	assert.Equal(t, []int64{6, 2}, []int64{messages[0].Offset, messages[1].Offset})
+	assert.Equal(t, "kafka/main", messages[0].Receiver)
+	assert.Equal(t, map[string]string{"h": "first"}, messages[1].Headers)
+
+	messages, err = Fetch(context.Background(), f, r, 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, map[kafkaclient.TopicPartition]kafkaclient.OffsetRange{a0: {Start: 0, End: 2}, b0: {Start: 5, End: 7}}, f.ranges)
+	assert.Equal(t, []int64{0, 5}, []int64{messages[0].Offset, messages[1].Offset})
+
+	_, err = Fetch(context.Background(), f, collectorconfig.KafkaReceiver{ID: "kafka/x", Topics: []string{"^none"}}, 2, true)
+	require.EqualError(t, err, "kafka/x: none of the topics [^none] exists")
+}
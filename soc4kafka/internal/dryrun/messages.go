@@ -0,0 +1,137 @@
+package dryrun
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+// Cluster is the subset of the Kafka API used to fetch messages.
+type Cluster interface {
+	Topics(ctx context.Context) (map[string]int, error)
+	StartOffsets(ctx context.Context, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	EndOffsets(ctx context.Context, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	Read(ctx context.Context, ranges map[kafkaclient.TopicPartition]kafkaclient.OffsetRange) ([]kafkaclient.Record, error)
+}
+
+// Fetch reads up to count messages of the topics consumed by the receiver, the latest ones or, with earliest, the
+// oldest ones still retained. Messages are read without joining a consumer group, so the offsets of the collectors
+// are left untouched.
+func Fetch(ctx context.Context, c Cluster, r collectorconfig.KafkaReceiver, count int, earliest bool) ([]Message, error) {
+	topics, err := c.Topics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names, err := r.ResolveTopics(collectorconfig.SortedKeys(topics))
+	if err != nil {
+		return nil, err
+	}
+	partitions := kafkaclient.Partitions(topics, names)
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("%s: none of the topics %v exists", r.ID, r.Topics)
+	}
+	start, err := c.StartOffsets(ctx, partitions)
+	if err != nil {
+		return nil, err
+	}
+	end, err := c.EndOffsets(ctx, partitions)
+	if err != nil {
+		return nil, err
+	}
+	// Each partition may hold the count messages, the selection across partitions is made by timestamp.
+	ranges := map[kafkaclient.TopicPartition]kafkaclient.OffsetRange{}
+	for _, tp := range partitions {
+		rg := kafkaclient.OffsetRange{Start: start[tp], End: end[tp]}
+		if earliest {
+			rg.End = min(rg.End, rg.Start+int64(count))
+		} else {
+			rg.Start = max(rg.Start, rg.End-int64(count))
+		}
+		ranges[tp] = rg
+	}
+	records, err := c.Read(ctx, ranges)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(records, func(a, b kafkaclient.Record) int { return a.Timestamp.Compare(b.Timestamp) })
+	if len(records) > count {
+		if earliest {
+			records = records[:count]
+		} else {
+			records = records[len(records)-count:]
+		}
+	}
+	messages := make([]Message, 0, len(records))
+	for _, rec := range records {
+		m := Message{Topic: rec.Topic, Partition: rec.Partition, Offset: rec.Offset, Timestamp: rec.Timestamp,
+			Key: string(rec.Key), Value: rec.Value, Receiver: r.ID}
+		for _, h := range rec.Headers {
+			if m.Headers == nil {
+				m.Headers = map[string]string{}
+			}
+			// The kafka receiver extracts the first value of repeated headers.
+			if _, ok := m.Headers[h.Key]; !ok {
+				m.Headers[h.Key] = string(h.Value)
+			}
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// sample is a line of a sample file. Value is a string, or any other JSON value used as the JSON text of the
+// message.
+type sample struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    *int64            `json:"offset"`
+	Timestamp time.Time         `json:"timestamp"`
+	Key       string            `json:"key"`
+	Headers   map[string]string `json:"headers"`
+	Value     json.RawMessage   `json:"value"`
+}
+
+// ReadSamples reads messages from JSON lines such as
+//
+//	{"topic": "orders", "headers": {"index": "main"}, "value": "the message"}
+//
+// Offsets default to the line number minus one. Blank lines are skipped.
+func ReadSamples(in io.Reader) ([]Message, error) {
+	var messages []Message
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var s sample
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if s.Value == nil {
+			return nil, fmt.Errorf("line %d: value is required", line)
+		}
+		m := Message{Topic: s.Topic, Partition: s.Partition, Offset: int64(line - 1), Timestamp: s.Timestamp,
+			Key: s.Key, Headers: s.Headers, Value: s.Value}
+		if s.Offset != nil {
+			m.Offset = *s.Offset
+		}
+		var text string
+		if json.Unmarshal(s.Value, &text) == nil {
+			m.Value = []byte(text)
+		}
+		messages = append(messages, m)
+	}
+	return messages, scanner.Err()
+}
@@ -0,0 +1,313 @@
+package dryrun
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/ottl"
+)
+
+// Error modes of the transform and filter processors.
+const (
+	propagate = "propagate"
+	ignore    = "ignore"
+	silent    = "silent"
+)
+
+// errDropped is returned by processors removing the record.
+var errDropped = errors.New("dropped")
+
+// processor applies a processor to a record. Errors the processor ignores are returned as notes, the error fails
+// the record.
+type processor func(r *ottl.Record) (notes []string, err error)
+
+// noop are the processors that do not change the records.
+var noop = map[string]bool{"batch": true, "memory_limiter": true}
+
+// compileProcessor returns the simulation of a processor, nil with a warning when it is not simulated.
+func (rd *Renderer) compileProcessor(id string, cfg map[string]any) (processor, error) {
+	switch collectorconfig.ComponentType(id) {
+	case "transform":
+		return rd.transform(id, cfg)
+	case "filter":
+		return rd.filter(id, cfg)
+	case "resourcedetection":
+		return rd.resourceDetection(id, cfg), nil
+	case "resource":
+		return rd.attributeActions(id, cfg, func(r *ottl.Record) map[string]any { return r.Resource }), nil
+	case "attributes":
+		for _, key := range []string{"include", "exclude"} {
+			if cfg[key] != nil {
+				rd.warnf("%s: %s is not simulated, the actions apply to every record", id, key)
+			}
+		}
+		return rd.attributeActions(id, cfg, func(r *ottl.Record) map[string]any { return r.Attributes }), nil
+	}
+	if !noop[collectorconfig.ComponentType(id)] {
+		rd.warnf("%s: the processor is not simulated, its changes are not shown", id)
+	}
+	return nil, nil
+}
+
+func errorMode(cfg map[string]any, fallback string) (string, error) {
+	mode := collectorconfig.String(cfg, "error_mode")
+	switch mode {
+	case "":
+		return fallback, nil
+	case propagate, ignore, silent:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid error_mode %q", mode)
+}
+
+// handle applies the error mode of a processor to an error of a statement or condition.
+func handle(id, mode, text string, err error, notes []string) ([]string, error) {
+	err = fmt.Errorf("%s: %q: %w", id, text, err)
+	switch mode {
+	case propagate:
+		return notes, fmt.Errorf("%w (error_mode: propagate fails the whole batch)", err)
+	case ignore:
+		return append(notes, err.Error()), nil
+	}
+	return notes, nil
+}
+
+type statementGroup struct {
+	mode       string
+	conditions []*ottl.Condition
+	statements []*ottl.Statement
+}
+
+func (rd *Renderer) transform(id string, cfg map[string]any) (processor, error) {
+	mode, err := errorMode(cfg, propagate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	var groups []statementGroup
+	items, _ := cfg["log_statements"].([]any)
+	for _, item := range items {
+		g := statementGroup{mode: mode}
+		ctx := ""
+		var statements, conditions []string
+		switch item := item.(type) {
+		case string:
+			statements = []string{item}
+		case map[string]any:
+			ctx = collectorconfig.String(item, "context")
+			if ctx == "" {
+				ctx = ottl.LogContext
+			}
+			statements = collectorconfig.Strings(item, "statements")
+			conditions = collectorconfig.Strings(item, "conditions")
+			if g.mode, err = errorMode(item, mode); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s: invalid log_statements entry %v", id, item)
+		}
+		skip := false
+		for _, text := range conditions {
+			c, err := ottl.ParseCondition(text, ctx)
+			if err != nil {
+				if err := rd.invalid(id, text, err); err != nil {
+					return nil, err
+				}
+				// Without the condition, the statements of the group cannot be applied faithfully.
+				skip = true
+				continue
+			}
+			g.conditions = append(g.conditions, c)
+		}
+		if skip {
+			rd.warnf("%s: the statements %q are skipped with their conditions", id, statements)
+			continue
+		}
+		for _, text := range statements {
+			st, err := ottl.ParseStatement(text, ctx)
+			if err != nil {
+				if err := rd.invalid(id, text, err); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			g.statements = append(g.statements, st)
+		}
+		groups = append(groups, g)
+	}
+	return func(r *ottl.Record) ([]string, error) {
+		r.ResetCache()
+		var notes []string
+		for _, g := range groups {
+			matched := len(g.conditions) == 0
+			for _, c := range g.conditions {
+				ok, err := c.Eval(r)
+				if err != nil {
+					if notes, err = handle(id, g.mode, c.Text, err, notes); err != nil {
+						return notes, err
+					}
+					continue
+				}
+				if matched = ok; matched {
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+			for _, st := range g.statements {
+				if _, err := st.Execute(r); err != nil {
+					if notes, err = handle(id, g.mode, st.Text, err, notes); err != nil {
+						return notes, err
+					}
+				}
+			}
+		}
+		return notes, nil
+	}, nil
+}
+
+func (rd *Renderer) filter(id string, cfg map[string]any) (processor, error) {
+	mode, err := errorMode(cfg, propagate)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	logs := collectorconfig.Map(cfg, "logs")
+	for _, key := range collectorconfig.SortedKeys(logs) {
+		if key != "log_record" {
+			rd.warnf("%s: logs.%s is not simulated", id, key)
+		}
+	}
+	var conditions []*ottl.Condition
+	for _, text := range collectorconfig.Strings(logs, "log_record") {
+		c, err := ottl.ParseCondition(text, ottl.LogContext)
+		if err != nil {
+			if err := rd.invalid(id, text, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		conditions = append(conditions, c)
+	}
+	return func(r *ottl.Record) ([]string, error) {
+		var notes []string
+		for _, c := range conditions {
+			ok, err := c.Eval(r)
+			if err != nil {
+				if notes, err = handle(id, mode, c.Text, err, notes); err != nil {
+					return notes, err
+				}
+				continue
+			}
+			if ok {
+				return notes, fmt.Errorf("%w by %s: %q", errDropped, id, c.Text)
+			}
+		}
+		return notes, nil
+	}, nil
+}
+
+// invalid reports a statement or condition that cannot be parsed: unsupported ones are skipped with a warning,
+// the others are configuration errors the collector would refuse to start with.
+func (rd *Renderer) invalid(id, text string, err error) error {
+	var unsupported *ottl.UnsupportedError
+	if errors.As(err, &unsupported) {
+		rd.warnf("%s: %q is skipped: %v", id, text, err)
+		return nil
+	}
+	return fmt.Errorf("%s: invalid statement %q: %w", id, text, err)
+}
+
+func (rd *Renderer) resourceDetection(id string, cfg map[string]any) processor {
+	override := true
+	if v, ok := cfg["override"].(bool); ok {
+		override = v
+	}
+	attrs := map[string]any{}
+	for _, detector := range collectorconfig.Strings(cfg, "detectors") {
+		switch detector {
+		case "system":
+			attrs["host.name"] = rd.hostname
+			attrs["os.type"] = runtime.GOOS
+		case "env":
+			for _, kv := range strings.Split(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"), ",") {
+				if k, v, ok := strings.Cut(kv, "="); ok {
+					attrs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+				}
+			}
+		default:
+			rd.warnf("%s: the %s detector is not simulated", id, detector)
+		}
+	}
+	return func(r *ottl.Record) ([]string, error) {
+		for k, v := range attrs {
+			if _, exists := r.Resource[k]; override || !exists {
+				r.Resource[k] = v
+			}
+		}
+		return nil, nil
+	}
+}
+
+type action struct {
+	key, action, fromAttribute string
+	value                      any
+}
+
+func (rd *Renderer) attributeActions(id string, cfg map[string]any, attributes func(*ottl.Record) map[string]any) processor {
+	var actions []action
+	items, _ := cfg["actions"].([]any)
+	for _, item := range items {
+		m, _ := item.(map[string]any)
+		a := action{key: collectorconfig.String(m, "key"), action: collectorconfig.String(m, "action"),
+			fromAttribute: collectorconfig.String(m, "from_attribute"), value: normalize(m["value"])}
+		switch a.action {
+		case "insert", "update", "upsert", "delete":
+			actions = append(actions, a)
+		default:
+			rd.warnf("%s: the %s action on %s is not simulated", id, a.action, a.key)
+		}
+	}
+	return func(r *ottl.Record) ([]string, error) {
+		attrs := attributes(r)
+		for _, a := range actions {
+			if a.action == "delete" {
+				delete(attrs, a.key)
+				continue
+			}
+			value := a.value
+			if a.fromAttribute != "" {
+				v, ok := attrs[a.fromAttribute]
+				if !ok {
+					continue
+				}
+				value = v
+			}
+			_, exists := attrs[a.key]
+			if a.action == "upsert" || a.action == "insert" && !exists || a.action == "update" && exists {
+				attrs[a.key] = value
+			}
+		}
+		return nil, nil
+	}
+}
+
+// normalize converts the numbers decoded from YAML to the int64 and float64 of the records.
+func normalize(v any) any {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalize(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalize(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,70 @@
+package helmvalues
+
+import (
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// EnvVar is an environment variable the chart sets on the collector container.
+type EnvVar struct {
+	Name  string
+	Value string
+	// Source explains why the value is not known, e.g. the chart reads it from a Kubernetes secret.
+	Source string
+}
+
+// EnvVars returns the environment variables of the collector container, in the order of the deployment template:
+// the HEC tokens, the Kafka passwords read from secrets, then extraEnv. Inline tokens are returned with their value.
+func EnvVars(values map[string]any) []EnvVar {
+	var vars []EnvVar
+	for _, item := range list(values, "splunkExporters") {
+		input, _ := item.(map[string]any)
+		name := collectorconfig.String(input, "name")
+		v := EnvVar{Name: TokenEnvVar(name)}
+		switch secret, token := collectorconfig.String(input, "secret"), collectorconfig.String(input, "token"); {
+		case secret != "":
+			v.Source = fmt.Sprintf("the chart reads it from the key splunk-hec-token of secret %s", secret)
+		case token != "":
+			v.Value = token
+		default:
+			v.Source = fmt.Sprintf("splunkExporters %s has neither token nor secret, the chart leaves it unset", name)
+		}
+		vars = append(vars, v)
+	}
+	for _, item := range list(values, "kafkaReceivers") {
+		input, _ := item.(map[string]any)
+		name := ReceiverName(collectorconfig.String(input, "name"))
+		auth := collectorconfig.Map(input, "auth")
+		for _, mechanism := range []string{"plain_text", "sasl", "kerberos"} {
+			if secret := collectorconfig.String(collectorconfig.Map(auth, mechanism), "secret"); secret != "" {
+				vars = append(vars, EnvVar{Name: PasswordEnvVar(name, mechanism), Source: fmt.Sprintf("the chart reads it from the key password of secret %s", secret)})
+			}
+		}
+	}
+	for _, item := range list(values, "extraEnv") {
+		input, _ := item.(map[string]any)
+		v := EnvVar{Name: collectorconfig.String(input, "name")}
+		if _, ok := input["valueFrom"]; ok {
+			v.Source = "the chart reads it from the valueFrom source of extraEnv"
+		} else if value, ok := input["value"]; ok && value != nil {
+			v.Value = fmt.Sprint(value)
+		}
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// RenderEnvFile returns an environment file setting vars. Variables without a known value are left empty, preceded
+// by a comment explaining why.
+func RenderEnvFile(vars []EnvVar) []byte {
+	fileVars := make([]collectorconfig.EnvFileVar, 0, len(vars))
+	for _, v := range vars {
+		fv := collectorconfig.EnvFileVar{Name: v.Name, Value: v.Value}
+		if v.Source != "" {
+			fv.Comment = v.Name + ": " + v.Source
+		}
+		fileVars = append(fileVars, fv)
+	}
+	return collectorconfig.RenderEnvFile("Environment of the SOC4Kafka collector rendered from Helm values", fileVars)
+}
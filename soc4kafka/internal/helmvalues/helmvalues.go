@@ -0,0 +1,313 @@
+// Package helmvalues reads values files of the splunk-opentelemetry-collector-for-kafka Helm chart and renders the
+// collector configuration the chart generates from them.
+package helmvalues
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// chartValues are the default values of the chart, kept in sync with the chart by go generate.
+//
+//go:generate cp ../../../helm-chart/splunk-opentelemetry-collector-for-kafka/values.yaml values.yaml
+//go:embed values.yaml
+var chartValues []byte
+
+// IsValues reports whether a decoded YAML document is a values file of the chart rather than a collector
+// configuration.
+func IsValues(raw map[string]any) bool {
+	if _, ok := raw["receivers"]; ok {
+		return false
+	}
+	for _, key := range []string{"kafkaReceivers", "splunkExporters", "pipelines", "configOverride"} {
+		if _, ok := raw[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Defaults returns the default values of the chart.
+func Defaults() map[string]any {
+	var values map[string]any
+	if err := yaml.Unmarshal(chartValues, &values); err != nil {
+		panic(fmt.Sprintf("invalid embedded chart values: %v", err))
+	}
+	return values
+}
+
+// Load reads the values file at path and merges it over the chart defaults.
+func Load(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// Parse merges a values document over the chart defaults, the same way helm does.
+func Parse(data []byte) (map[string]any, error) {
+	var user map[string]any
+	if err := yaml.Unmarshal(data, &user); err != nil {
+		return nil, err
+	}
+	return coalesce(Defaults(), user), nil
+}
+
+// Config renders the collector configuration of the receivers, exporters and pipelines defined in the values, with
+// the collectorLogs and collectorMetrics pipelines and configOverride applied, like the soc4kafka.finalConfig
+// template of the chart.
+func Config(values map[string]any) (map[string]any, error) {
+	defaults := collectorconfig.Map(values, "defaults")
+	extensions := deepCopy(collectorconfig.Map(defaults, "extensions")).(map[string]any)
+	extensionNames := stringsToList(collectorconfig.SortedKeys(extensions))
+	receivers := Receivers(values)
+	pipelines := Pipelines(values)
+	service := map[string]any{"extensions": extensionNames, "pipelines": pipelines}
+
+	collectorLogs := collectorconfig.Map(values, "collectorLogs")
+	forwardLogs := !isEmpty(collectorLogs["enabled"]) && !isEmpty(collectorconfig.Map(collectorLogs, "forwardToSplunk")["enabled"])
+	collectorMetrics := collectorconfig.Map(values, "collectorMetrics")
+	telemetry := map[string]any{}
+	if !isEmpty(collectorLogs["enabled"]) {
+		telemetry["logs"] = map[string]any{
+			"level":              collectorLogs["level"],
+			"output_paths":       deepCopy(collectorLogs["outputPaths"]),
+			"error_output_paths": deepCopy(collectorLogs["errorOutputPaths"]),
+		}
+	}
+	if forwardLogs {
+		fileStorage := collectorconfig.Map(collectorLogs, "fileStorage")
+		extensions["file_storage"] = map[string]any{
+			"directory":        fileStorage["directory"],
+			"create_directory": fileStorage["createDirectory"],
+		}
+		service["extensions"] = append(extensionNames, "file_storage")
+		receivers["filelog"] = map[string]any{
+			"include":  []any{"/var/log/otelcol/*.log"},
+			"start_at": "beginning",
+			"storage":  "file_storage",
+		}
+		exporter, err := internalExporter(values, collectorconfig.String(collectorconfig.Map(collectorLogs, "forwardToSplunk"), "exporter"))
+		if err != nil {
+			return nil, fmt.Errorf("collectorLogs.forwardToSplunk: %w", err)
+		}
+		pipelines["logs/internal"] = map[string]any{
+			"receivers":  []any{"filelog"},
+			"processors": []any{"resourcedetection"},
+			"exporters":  []any{exporter},
+		}
+	}
+	if !isEmpty(collectorMetrics["enabled"]) {
+		receivers["prometheus"] = prometheusReceiver()
+		receivers["hostmetrics"] = hostMetricsReceiver()
+		telemetry["metrics"] = map[string]any{
+			"level": "detailed",
+			"readers": []any{map[string]any{"pull": map[string]any{"exporter": map[string]any{
+				"prometheus": map[string]any{"host": "0.0.0.0", "port": 8888},
+			}}}},
+		}
+		exporter, err := internalExporter(values, collectorconfig.String(collectorMetrics, "exporter"))
+		if err != nil {
+			return nil, fmt.Errorf("collectorMetrics: %w", err)
+		}
+		pipelines["metrics"] = map[string]any{
+			"receivers":  []any{"prometheus", "hostmetrics"},
+			"processors": []any{"resourcedetection"},
+			"exporters":  []any{exporter},
+		}
+	}
+	if len(telemetry) > 0 {
+		service["telemetry"] = telemetry
+	}
+
+	generated := map[string]any{
+		"extensions": extensions,
+		"receivers":  receivers,
+		"processors": deepCopy(collectorconfig.Map(defaults, "processors")),
+		"exporters":  Exporters(values),
+		"service":    service,
+	}
+	override := collectorconfig.Map(values, "configOverride")
+	return mergeOverwrite(generated, deepCopy(override)).(map[string]any), nil
+}
+
+// internalExporter returns the exporter of the collector logs or metrics pipeline, the first splunkExporters entry
+// by default.
+func internalExporter(values map[string]any, name string) (string, error) {
+	if name == "" {
+		exporters := list(values, "splunkExporters")
+		if len(exporters) == 0 {
+			return "", fmt.Errorf("no exporter is set and splunkExporters is empty")
+		}
+		first, _ := exporters[0].(map[string]any)
+		name = collectorconfig.String(first, "name")
+	}
+	return ExporterName(name), nil
+}
+
+func prometheusReceiver() map[string]any {
+	return map[string]any{"config": map[string]any{"scrape_configs": []any{map[string]any{
+		"job_name":        "otel-collector",
+		"scrape_interval": "1s",
+		"static_configs":  []any{map[string]any{"targets": []any{"0.0.0.0:8888"}}},
+	}}}}
+}
+
+func hostMetricsReceiver() map[string]any {
+	enabled := func(names ...string) map[string]any {
+		metrics := map[string]any{}
+		for _, name := range names {
+			metrics[name] = map[string]any{"enabled": true}
+		}
+		return map[string]any{"metrics": metrics}
+	}
+	process := enabled("process.memory.utilization", "process.cpu.utilization")
+	process["mute_process_all_errors"] = true
+	process["include"] = map[string]any{"names": []any{"otelcol"}, "match_type": "regexp"}
+	return map[string]any{
+		"collection_interval": "1s",
+		"scrapers": map[string]any{
+			"cpu":        enabled("system.cpu.utilization", "system.cpu.logical.count"),
+			"memory":     enabled("system.memory.utilization", "system.memory.limit"),
+			"process":    process,
+			"filesystem": enabled("system.filesystem.utilization"),
+			"disk":       enabled("system.disk.io"),
+			"network":    nil,
+		},
+	}
+}
+
+// Receivers renders the receivers section of the kafkaReceivers values: each entry is merged over
+// defaults.receivers.kafka and secret references are replaced by the environment variables the chart injects.
+func Receivers(values map[string]any) map[string]any {
+	defaults := collectorconfig.Map(collectorconfig.Map(values, "defaults"), "receivers")
+	receivers := map[string]any{}
+	for _, item := range list(values, "kafkaReceivers") {
+		input, _ := item.(map[string]any)
+		name := ReceiverName(collectorconfig.String(input, "name"))
+		cfg := mergeOverwrite(deepCopy(collectorconfig.Map(defaults, "kafka")), deepCopy(omit(input, "name"))).(map[string]any)
+		if auth := collectorconfig.Map(cfg, "auth"); auth != nil {
+			for _, mechanism := range []string{"plain_text", "sasl", "kerberos"} {
+				settings := collectorconfig.Map(auth, mechanism)
+				if settings == nil || isEmpty(settings["secret"]) {
+					continue
+				}
+				settings = omit(settings, "secret")
+				settings["password"] = "${" + PasswordEnvVar(name, mechanism) + "}"
+				auth[mechanism] = settings
+			}
+		}
+		receivers[name] = cfg
+	}
+	return receivers
+}
+
+// Exporters renders the exporters section of the splunkExporters values: each entry is merged over
+// defaults.exporters.splunk_hec and its token is read from the environment variable the chart injects.
+func Exporters(values map[string]any) map[string]any {
+	defaults := collectorconfig.Map(collectorconfig.Map(values, "defaults"), "exporters")
+	exporters := map[string]any{}
+	for _, item := range list(values, "splunkExporters") {
+		input, _ := item.(map[string]any)
+		name := collectorconfig.String(input, "name")
+		cfg := mergeOverwrite(deepCopy(collectorconfig.Map(defaults, "splunk_hec")), deepCopy(omit(input, "name", "token", "secret"))).(map[string]any)
+		cfg["token"] = "${" + TokenEnvVar(name) + "}"
+		exporters[ExporterName(name)] = cfg
+	}
+	return exporters
+}
+
+// Pipelines renders the service pipelines of the pipelines values. Pipelines without processors use
+// defaults.pipelineProcessors.
+func Pipelines(values map[string]any) map[string]any {
+	defaultProcessors := list(collectorconfig.Map(values, "defaults"), "pipelineProcessors")
+	if len(defaultProcessors) == 0 {
+		defaultProcessors = []any{"resourcedetection"}
+	}
+	pipelines := map[string]any{}
+	for _, item := range list(values, "pipelines") {
+		input, _ := item.(map[string]any)
+		var receivers, exporters []any
+		for _, name := range collectorconfig.Strings(input, "receivers") {
+			receivers = append(receivers, ReceiverName(name))
+		}
+		for _, name := range collectorconfig.Strings(input, "exporters") {
+			exporters = append(exporters, ExporterName(name))
+		}
+		processors := list(input, "processors")
+		if len(processors) == 0 {
+			processors = defaultProcessors
+		}
+		id := collectorconfig.String(input, "type") + "/" + collectorconfig.String(input, "name")
+		pipelines[id] = map[string]any{
+			"receivers":  receivers,
+			"processors": deepCopy(processors),
+			"exporters":  exporters,
+		}
+	}
+	return pipelines
+}
+
+// ExporterName returns the component ID of a splunkExporters entry: the exporter named primary is the default
+// splunk_hec exporter.
+func ExporterName(name string) string {
+	if name == "primary" {
+		return collectorconfig.HECExporterType
+	}
+	return collectorconfig.HECExporterType + "/" + name
+}
+
+// TokenEnvVar returns the environment variable holding the HEC token of a splunkExporters entry.
+func TokenEnvVar(name string) string {
+	return "SPLUNK_HEC_TOKEN_" + strings.ReplaceAll(strings.ToUpper(name), "-", "_")
+}
+
+// ReceiverName returns the component ID of a kafkaReceivers entry.
+func ReceiverName(name string) string {
+	return collectorconfig.KafkaReceiverType + "/" + name
+}
+
+// PasswordEnvVar returns the environment variable holding the password of a receiver for an auth mechanism
+// (plain_text, sasl or kerberos) when it is read from a secret.
+func PasswordEnvVar(receiverName, mechanism string) string {
+	return "KAFKA_" + envName(receiverName) + "_" + strings.ToUpper(mechanism) + "_PASSWORD"
+}
+
+func envName(name string) string {
+	return strings.NewReplacer("/", "_", "-", "_").Replace(strings.ToUpper(name))
+}
+
+func stringsToList(items []string) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = item
+	}
+	return out
+}
+
+func list(m map[string]any, key string) []any {
+	v, _ := m[key].([]any)
+	return v
+}
+
+func omit(m map[string]any, keys ...string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	for _, k := range keys {
+		delete(out, k)
+	}
+	return out
+}
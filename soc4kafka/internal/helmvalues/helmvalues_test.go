@@ -0,0 +1,386 @@
+package helmvalues
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/configdiff"
+)
+
+const chartDir = "../../../helm-chart/splunk-opentelemetry-collector-for-kafka"
+
+func TestChartValuesInSync(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(chartValues), "run go generate ./... to copy the chart values")
+}
+
+// renderedConfig returns the collector configuration of the configmap rendered by helm for a values file in
+// the rendered directory.
+func renderedConfig(t *testing.T, valuesFile string) map[string]any {
+	t.Helper()
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(valuesFile), "values_"), ".yaml")
+	data, err := os.ReadFile(filepath.Join("../../../rendered/manifests", "tests_"+name,
+		"splunk-opentelemetry-collector-for-kafka/templates/configmap.yaml"))
+	require.NoError(t, err)
+	var configMap struct {
+		Data map[string]string `yaml:"data"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &configMap))
+	var cfg map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(configMap.Data["config.yaml"]), &cfg))
+	return cfg
+}
+
+func TestReceiversMatchRenderedChart(t *testing.T) {
+	files, err := filepath.Glob("../../../rendered/values_*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			values, err := Load(file)
+			require.NoError(t, err)
+			expected := collectorconfig.Map(renderedConfig(t, file), "receivers")
+			for id := range expected {
+				if collectorconfig.ComponentType(id) != collectorconfig.KafkaReceiverType {
+					delete(expected, id)
+				}
+			}
+			assert.Equal(t, expected, Receivers(values))
+		})
+	}
+}
+
+func TestConfigMatchesRenderedChart(t *testing.T) {
+	files, err := filepath.Glob("../../../rendered/values_*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			values, err := Load(file)
+			require.NoError(t, err)
+			cfg, err := Config(values)
+			require.NoError(t, err)
+			assert.Equal(t, renderedConfig(t, file), cfg)
+		})
+	}
+}
+
+func TestIsValues(t *testing.T) {
+	assert.True(t, IsValues(map[string]any{"kafkaReceivers": []any{}}))
+	assert.False(t, IsValues(map[string]any{"receivers": map[string]any{}}))
+	assert.False(t, IsValues(map[string]any{}))
+}
+
+func TestConfigOverride(t *testing.T) {
+	values, err := Parse([]byte(`
+kafkaReceivers:
+  - name: main
+    brokers: [ "kafka:9092" ]
+    group_id: ""
+    logs:
+      topics: [ logs ]
+configOverride:
+  receivers:
+    kafka/main:
+      initial_offset: earliest
+`))
+	require.NoError(t, err)
+	rendered, err := Config(values)
+	require.NoError(t, err)
+	cfg := collectorconfig.FromMap(rendered)
+	require.Len(t, cfg.KafkaReceivers, 1)
+	r := cfg.KafkaReceivers[0]
+	assert.Equal(t, "kafka/main", r.ID)
+	// An empty value does not overwrite the chart default, like sprig mustMergeOverwrite.
+	assert.Equal(t, "soc4kafka-main", r.GroupID)
+	assert.Equal(t, "earliest", r.Raw["initial_offset"])
+	assert.Equal(t, "text", r.Encoding)
+}
+
+func TestCoalesce(t *testing.T) {
+	values, err := Parse([]byte(`
+defaults:
+  receivers:
+    kafka:
+      group_id: null
+      logs:
+        encoding: json
+`))
+	require.NoError(t, err)
+	kafka := collectorconfig.Map(collectorconfig.Map(collectorconfig.Map(values, "defaults"), "receivers"), "kafka")
+	assert.Equal(t, map[string]any{"logs": map[string]any{"encoding": "json"}}, kafka)
+	assert.Equal(t, 1, values["replicaCount"])
+}
+
+func TestCollectorMetrics(t *testing.T) {
+	values, err := Parse([]byte(`
+kafkaReceivers:
+  - name: main
+    brokers: [ "kafka:9092" ]
+    logs:
+      topics: [ logs ]
+splunkExporters:
+  - name: primary
+    endpoint: https://splunk:8088/services/collector
+  - name: metrics
+    endpoint: https://splunk-metrics:8088/services/collector
+pipelines:
+  - name: logs
+    type: logs
+    receivers: [ main ]
+    exporters: [ primary ]
+collectorMetrics:
+  enabled: true
+  exporter: metrics
+`))
+	require.NoError(t, err)
+	cfg, err := Config(values)
+	require.NoError(t, err)
+	// The receivers, telemetry and pipeline of the chart template, as written in _config.tpl.
+	var expected map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(`
+receivers:
+  prometheus:
+    config:
+      scrape_configs:
+        - job_name: 'otel-collector'
+          scrape_interval: 1s
+          static_configs:
+            - targets: ['0.0.0.0:8888']
+  hostmetrics:
+    collection_interval: 1s
+    scrapers:
+      cpu:
+        metrics:
+          system.cpu.utilization:
+            enabled: true
+          system.cpu.logical.count:
+            enabled: true
+      memory:
+        metrics:
+          system.memory.utilization:
+            enabled: true
+          system.memory.limit:
+            enabled: true
+      process:
+        mute_process_all_errors: true
+        include:
+          names: ["otelcol"]
+          match_type: "regexp"
+        metrics:
+          process.memory.utilization:
+            enabled: true
+          process.cpu.utilization:
+            enabled: true
+      filesystem:
+        metrics:
+          system.filesystem.utilization:
+            enabled: true
+      disk:
+        metrics:
+          system.disk.io:
+            enabled: true
+      network:
+telemetry:
+  metrics:
+    level: "detailed"
+    readers:
+      - pull:
+          exporter:
+            prometheus:
+              host: '0.0.0.0'
+              port: 8888
+pipeline:
+  receivers:
+    - prometheus
+    - hostmetrics
+  processors:
+    - resourcedetection
+  exporters:
+    - splunk_hec/metrics
+`), &expected))
+	receivers := collectorconfig.Map(cfg, "receivers")
+	assert.Equal(t, collectorconfig.Map(expected, "receivers")["prometheus"], receivers["prometheus"])
+	assert.Equal(t, collectorconfig.Map(expected, "receivers")["hostmetrics"], receivers["hostmetrics"])
+	service := collectorconfig.Map(cfg, "service")
+	assert.Equal(t, expected["telemetry"], service["telemetry"])
+	assert.Equal(t, expected["pipeline"], collectorconfig.Map(service, "pipelines")["metrics"])
+
+	values["splunkExporters"] = []any{}
+	values["collectorMetrics"] = map[string]any{"enabled": true}
+	_, err = Config(values)
+	require.EqualError(t, err, "collectorMetrics: no exporter is set and splunkExporters is empty")
+}
+
+func TestEnvVars(t *testing.T) {
+	values, err := Load("../../../rendered/values_auth.yaml")
+	require.NoError(t, err)
+	values["extraEnv"] = []any{
+		map[string]any{"name": "OTEL_LOG_LEVEL", "value": "debug"},
+		map[string]any{"name": "PROXY_PASSWORD", "valueFrom": map[string]any{"secretKeyRef": map[string]any{"name": "proxy"}}},
+	}
+	assert.Equal(t, []EnvVar{
+		{Name: "SPLUNK_HEC_TOKEN_PRIMARY", Value: "00000000-0000-0000-0000-000000000000"},
+		{Name: "KAFKA_KAFKA_PLAIN_PLAIN_TEXT_PASSWORD", Source: "the chart reads it from the key password of secret kafka-plain-secret"},
+		{Name: "KAFKA_KAFKA_KERBEROS_KERBEROS_PASSWORD", Source: "the chart reads it from the key password of secret kafka-kerberos-secret"},
+		{Name: "KAFKA_KAFKA_SASL_SASL_PASSWORD", Source: "the chart reads it from the key password of secret kafka-sasl-secret"},
+		{Name: "OTEL_LOG_LEVEL", Value: "debug"},
+		{Name: "PROXY_PASSWORD", Source: "the chart reads it from the valueFrom source of extraEnv"},
+	}, EnvVars(values))
+
+	assert.Equal(t, `# Environment of the SOC4Kafka collector rendered from Helm values. Keep this file readable by the collector user only.
+SPLUNK_HEC_TOKEN_PRIMARY="secret-token"
+PROXY_PASSWORD="p@ss \\ \"word\" \$HOME"
+# KAFKA_KAFKA_SASL_SASL_PASSWORD: the chart reads it from the key password of secret kafka-sasl-secret
+KAFKA_KAFKA_SASL_SASL_PASSWORD=""
+`, string(RenderEnvFile([]EnvVar{
+		{Name: "SPLUNK_HEC_TOKEN_PRIMARY", Value: "secret-token"},
+		{Name: "PROXY_PASSWORD", Value: `p@ss \ "word" $HOME`},
+		{Name: "KAFKA_KAFKA_SASL_SASL_PASSWORD", Source: "the chart reads it from the key password of secret kafka-sasl-secret"},
+	})))
+}
+
+// Every variable referenced by the rendered configurations must be listed in the env file.
+func TestEnvVarsCoverConfig(t *testing.T) {
+	files, err := filepath.Glob("../../../rendered/values_*.yaml")
+	require.NoError(t, err)
+	for _, file := range files {
+		values, err := Load(file)
+		require.NoError(t, err)
+		cfg, err := Config(values)
+		require.NoError(t, err)
+		data, err := yaml.Marshal(cfg)
+		require.NoError(t, err)
+		var names []string
+		for _, v := range EnvVars(values) {
+			names = append(names, v.Name)
+		}
+		for _, ref := range collectorconfig.EnvRefs(string(data)) {
+			assert.Contains(t, names, ref, file)
+		}
+	}
+}
+
+// renderConversion renders the values of a conversion the way helm would read them from a file.
+func renderConversion(t *testing.T, c *Conversion) map[string]any {
+	t.Helper()
+	data, err := yaml.Marshal(c.Values)
+	require.NoError(t, err)
+	values, err := Parse(data)
+	require.NoError(t, err)
+	cfg, err := Config(values)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestFromConfigRoundTripsRenderedChart(t *testing.T) {
+	files, err := filepath.Glob("../../../rendered/values_*.yaml")
+	require.NoError(t, err)
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			c := FromConfig(renderedConfig(t, file))
+			assert.Empty(t, configdiff.Diff(collectorconfig.FromMap(c.Expected), collectorconfig.FromMap(renderConversion(t, c))))
+			assert.Empty(t, c.Renames)
+		})
+	}
+}
+
+func TestFromConfig(t *testing.T) {
+	var raw map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(`
+receivers:
+  kafka:
+    brokers: [kafka:9092]
+    logs:
+      topics: [app]
+      encoding: text
+    group_id: soc4kafka-main
+    auth:
+      sasl:
+        username: user
+        password: secret
+        mechanism: SCRAM-SHA-512
+  kafka/audit:
+    brokers: [kafka:9092]
+    logs:
+      topics: [audit]
+  otlp:
+    protocols:
+      grpc:
+processors:
+  batch:
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    token: 00000000-0000-0000-0000-000000000000
+    sending_queue:
+      enabled: false
+  splunk_hec/primary:
+    endpoint: https://other:8088/services/collector
+    token: ${env:HEC_TOKEN}
+  debug:
+extensions:
+  health_check:
+  pprof:
+service:
+  extensions: [pprof, health_check]
+  telemetry:
+    logs:
+      level: debug
+  pipelines:
+    logs:
+      receivers: [kafka]
+      processors: [batch]
+      exporters: [splunk_hec]
+    logs/audit:
+      receivers: [kafka/audit]
+      exporters: [splunk_hec/primary]
+    traces:
+      receivers: [otlp]
+      exporters: [debug]
+`), &raw))
+	c := FromConfig(raw)
+
+	assert.Equal(t, []Rename{
+		{From: "kafka", To: "kafka/default"},
+		{From: "service.pipelines.logs", To: "service.pipelines.logs/default"},
+		{From: "service.pipelines.traces", To: "service.pipelines.traces/default"},
+		{From: "splunk_hec/primary", To: "splunk_hec/primary-2"},
+	}, c.Renames)
+	assert.Equal(t, []Secret{
+		{Name: "soc4kafka-default-sasl", Key: "password", Setting: "receivers.kafka.auth.sasl.password"},
+		{Name: "soc4kafka-hec-primary", Key: "splunk-hec-token", Setting: "exporters.splunk_hec.token"},
+		{Name: "soc4kafka-hec-primary-2", Key: "splunk-hec-token", Setting: "exporters.splunk_hec/primary.token", Env: "HEC_TOKEN"},
+	}, c.Secrets)
+	assert.Equal(t, []any{
+		map[string]any{"name": "default", "brokers": []any{"kafka:9092"}, "logs": map[string]any{"topics": []any{"app"}, "encoding": "text"},
+			"group_id": "soc4kafka-main", "auth": map[string]any{"sasl": map[string]any{"username": "user", "mechanism": "SCRAM-SHA-512", "secret": "soc4kafka-default-sasl"}}},
+		map[string]any{"name": "audit", "brokers": []any{"kafka:9092"}, "logs": map[string]any{"topics": []any{"audit"}}},
+	}, c.Values["kafkaReceivers"])
+	// The receivers do not share any default, the exporters disable the queue or leave the tls settings unset.
+	assert.Equal(t, map[string]any{
+		"receivers":  map[string]any{"kafka": map[string]any{"logs": nil, "group_id": nil}},
+		"exporters":  map[string]any{"splunk_hec": map[string]any{"tls": nil, "splunk_app_name": nil, "sending_queue": nil}},
+		"processors": map[string]any{"resourcedetection": nil, "batch": map[string]any{}},
+		"extensions": map[string]any{"health_check": map[string]any{"endpoint": nil}, "pprof": map[string]any{}},
+	}, c.Values["defaults"])
+	assert.Equal(t, []any{
+		map[string]any{"name": "default", "type": "logs", "receivers": []any{"default"}, "processors": []any{"batch"}, "exporters": []any{"primary"}},
+	}, c.Values["pipelines"])
+	override := collectorconfig.Map(c.Values, "configOverride")
+	assert.ElementsMatch(t, []string{"exporters", "receivers", "service"}, collectorconfig.SortedKeys(override))
+	assert.ElementsMatch(t, []string{"logs/audit", "traces/default"}, collectorconfig.SortedKeys(collectorconfig.Map(collectorconfig.Map(override, "service"), "pipelines")))
+	assert.Contains(t, c.Notes, "service.pipelines.logs/audit has no processors, the chart would add defaults.pipelineProcessors, kept in configOverride")
+
+	assert.Empty(t, configdiff.Diff(collectorconfig.FromMap(c.Expected), collectorconfig.FromMap(renderConversion(t, c))))
+	exporter := collectorconfig.Map(collectorconfig.Map(c.Expected, "exporters"), "splunk_hec/primary-2")
+	assert.Equal(t, "${SPLUNK_HEC_TOKEN_PRIMARY_2}", exporter["token"])
+}
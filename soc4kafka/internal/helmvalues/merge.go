@@ -0,0 +1,83 @@
+package helmvalues
+
+// coalesce merges user values over the chart defaults like helm: maps are merged recursively, other values replace
+// the defaults and null removes a default.
+func coalesce(defaults, user map[string]any) map[string]any {
+	for k, v := range user {
+		if v == nil {
+			delete(defaults, k)
+			continue
+		}
+		src, srcIsMap := v.(map[string]any)
+		dst, dstIsMap := defaults[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			defaults[k] = coalesce(dst, src)
+			continue
+		}
+		defaults[k] = v
+	}
+	return defaults
+}
+
+// mergeOverwrite reproduces the sprig mustMergeOverwrite function used by the chart templates: maps are merged
+// recursively and src values replace dst values, except empty ones (false, 0, "", null, empty lists and maps) which
+// only fill missing keys.
+func mergeOverwrite(dst, src any) any {
+	dstMap, dstIsMap := dst.(map[string]any)
+	srcMap, srcIsMap := src.(map[string]any)
+	if !dstIsMap || !srcIsMap {
+		if isEmpty(src) {
+			return dst
+		}
+		return src
+	}
+	for k, v := range srcMap {
+		existing, ok := dstMap[k]
+		if !ok {
+			dstMap[k] = v
+			continue
+		}
+		dstMap[k] = mergeOverwrite(existing, v)
+	}
+	return dstMap
+}
+
+func isEmpty(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case int:
+		return v == 0
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = deepCopy(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = deepCopy(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,510 @@
+package helmvalues
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// authMechanisms are the auth settings of a kafka receiver whose password the chart reads from a secret.
+var authMechanisms = []string{"plain_text", "sasl", "kerberos"}
+
+// Conversion is the result of FromConfig.
+type Conversion struct {
+	// Values is the values document, holding only what differs from the chart defaults.
+	Values map[string]any
+	// Secrets are the Kubernetes secrets the values reference instead of the inline tokens and passwords.
+	Secrets []Secret
+	// Renames are the component IDs the chart cannot generate, renamed to ones it can.
+	Renames []Rename
+	// Notes explain what was kept in configOverride and why.
+	Notes []string
+	// Expected is the source configuration with the renames applied and the tokens and passwords read from the
+	// environment variables the chart injects: rendering Values must produce an equivalent configuration.
+	Expected map[string]any
+}
+
+// Secret is a Kubernetes secret to create before installing the chart.
+type Secret struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	// Setting is the dotted path of the setting of the source configuration holding the value.
+	Setting string `json:"setting"`
+	// Env is the environment variable the source configuration reads the value from, empty for inline values.
+	Env string `json:"env,omitempty"`
+}
+
+// Rename is a component or pipeline ID changed by the conversion.
+type Rename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// FromConfig converts a decoded collector configuration into chart values: kafka receivers become kafkaReceivers,
+// splunk_hec exporters splunkExporters, and the pipelines between them pipelines. Settings shared with the chart
+// defaults are left out, inline tokens and passwords are replaced by secret references and everything the chart
+// does not model is kept in configOverride.
+func FromConfig(raw map[string]any) *Conversion {
+	raw = deepCopy(raw).(map[string]any)
+	defaults := collectorconfig.Map(Defaults(), "defaults")
+	c := &Conversion{Values: map[string]any{}}
+	valuesDefaults := map[string]any{}
+	override := map[string]any{}
+	renames := map[string]string{}
+
+	receivers := components(raw, "receivers")
+	var kafkaIDs []string
+	for _, id := range collectorconfig.SortedKeys(receivers) {
+		if collectorconfig.ComponentType(id) == collectorconfig.KafkaReceiverType {
+			kafkaIDs = append(kafkaIDs, id)
+		} else {
+			addOverride(override, "receivers", id, receivers[id])
+			c.Notes = append(c.Notes, fmt.Sprintf("receivers.%s is not a kafka receiver, kept in configOverride", id))
+		}
+	}
+	receiverNames := componentNames(kafkaIDs, "", "default")
+	var receiverConfigs []map[string]any
+	for _, id := range kafkaIDs {
+		name := receiverNames[id]
+		if newID := ReceiverName(name); newID != id {
+			renames[id] = newID
+		}
+		cfg := deepCopy(receivers[id]).(map[string]any)
+		auth := collectorconfig.Map(cfg, "auth")
+		for _, mechanism := range authMechanisms {
+			settings := collectorconfig.Map(auth, mechanism)
+			password, ok := settings["password"].(string)
+			if !ok {
+				continue
+			}
+			secret := Secret{Name: secretName(name, mechanism), Key: "password", Setting: "receivers." + id + ".auth." + mechanism + ".password"}
+			if refs := collectorconfig.EnvRefs(password); len(refs) > 0 {
+				secret.Env = refs[0]
+			}
+			c.Secrets = append(c.Secrets, secret)
+			delete(settings, "password")
+			settings["secret"] = secret.Name
+		}
+		receiverConfigs = append(receiverConfigs, cfg)
+	}
+	kafkaDefaults := collectorconfig.Map(collectorconfig.Map(defaults, "receivers"), "kafka")
+	shared := sharedDefaults(kafkaDefaults, receiverConfigs)
+	if patch := coalescePatch(kafkaDefaults, shared); len(patch) > 0 {
+		valuesDefaults["receivers"] = map[string]any{"kafka": patch}
+	}
+	var kafkaReceivers []any
+	for i, id := range kafkaIDs {
+		entry := map[string]any{"name": receiverNames[id]}
+		for k, v := range strip(receiverConfigs[i], shared) {
+			entry[k] = v
+		}
+		kafkaReceivers = append(kafkaReceivers, entry)
+	}
+
+	exporters := components(raw, "exporters")
+	var hecIDs []string
+	for _, id := range collectorconfig.SortedKeys(exporters) {
+		if collectorconfig.ComponentType(id) == collectorconfig.HECExporterType {
+			hecIDs = append(hecIDs, id)
+		} else {
+			addOverride(override, "exporters", id, exporters[id])
+			c.Notes = append(c.Notes, fmt.Sprintf("exporters.%s is not a splunk_hec exporter, kept in configOverride", id))
+		}
+	}
+	exporterNames := componentNames(hecIDs, "primary", "primary")
+	var exporterConfigs []map[string]any
+	for _, id := range hecIDs {
+		name := exporterNames[id]
+		if newID := ExporterName(name); newID != id {
+			renames[id] = newID
+		}
+		cfg := deepCopy(exporters[id]).(map[string]any)
+		if token, ok := cfg["token"].(string); ok {
+			secret := Secret{Name: secretName("hec", name), Key: "splunk-hec-token", Setting: "exporters." + id + ".token"}
+			if refs := collectorconfig.EnvRefs(token); len(refs) > 0 {
+				secret.Env = refs[0]
+			}
+			c.Secrets = append(c.Secrets, secret)
+		} else {
+			c.Notes = append(c.Notes, fmt.Sprintf("exporters.%s has no token, the chart sets it from %s", id, TokenEnvVar(name)))
+		}
+		delete(cfg, "token")
+		exporterConfigs = append(exporterConfigs, cfg)
+	}
+	hecDefaults := collectorconfig.Map(collectorconfig.Map(defaults, "exporters"), collectorconfig.HECExporterType)
+	shared = sharedDefaults(hecDefaults, exporterConfigs)
+	if patch := coalescePatch(hecDefaults, shared); len(patch) > 0 {
+		valuesDefaults["exporters"] = map[string]any{collectorconfig.HECExporterType: patch}
+	}
+	var splunkExporters []any
+	for i, id := range hecIDs {
+		entry := map[string]any{"name": exporterNames[id], "secret": secretName("hec", exporterNames[id])}
+		for k, v := range strip(exporterConfigs[i], shared) {
+			entry[k] = v
+		}
+		splunkExporters = append(splunkExporters, entry)
+	}
+
+	if patch := coalescePatch(collectorconfig.Map(defaults, "processors"), components(raw, "processors")); len(patch) > 0 {
+		valuesDefaults["processors"] = patch
+	}
+
+	service := collectorconfig.Map(raw, "service")
+	enabled := collectorconfig.Strings(service, "extensions")
+	extensions := components(raw, "extensions")
+	wanted := map[string]any{}
+	for _, id := range collectorconfig.SortedKeys(extensions) {
+		if slices.Contains(enabled, id) {
+			wanted[id] = extensions[id]
+		} else {
+			addOverride(override, "extensions", id, extensions[id])
+			c.Notes = append(c.Notes, fmt.Sprintf("extensions.%s is not enabled in service.extensions, kept in configOverride", id))
+		}
+	}
+	if patch := coalescePatch(collectorconfig.Map(defaults, "extensions"), wanted); len(patch) > 0 {
+		valuesDefaults["extensions"] = patch
+	}
+
+	pipelines := components(service, "pipelines")
+	pipelineIDs := map[string][]string{}
+	for _, id := range collectorconfig.SortedKeys(pipelines) {
+		pipelineType := collectorconfig.ComponentType(id)
+		pipelineIDs[pipelineType] = append(pipelineIDs[pipelineType], id)
+	}
+	pipelineNames := map[string]string{}
+	for pipelineType, ids := range pipelineIDs {
+		for id, name := range componentNames(ids, "", "default") {
+			if id == pipelineType {
+				pipelineNames[id] = id + "/" + name
+			}
+		}
+	}
+	var valuesPipelines []any
+	for _, id := range collectorconfig.SortedKeys(pipelines) {
+		p := pipelines[id]
+		for _, key := range []string{"receivers", "exporters"} {
+			var refs []any
+			for _, ref := range collectorconfig.Strings(p, key) {
+				if newID, ok := renames[ref]; ok {
+					ref = newID
+				}
+				refs = append(refs, ref)
+			}
+			if refs != nil {
+				p[key] = refs
+			}
+		}
+		newID := id
+		if renamed, ok := pipelineNames[id]; ok {
+			newID = renamed
+			c.Renames = append(c.Renames, Rename{From: "service.pipelines." + id, To: "service.pipelines." + newID})
+		}
+		delete(pipelines, id)
+		pipelines[newID] = p
+		if reason := unmodelledPipeline(p); reason != "" {
+			addOverride(override, "service", "pipelines", map[string]any{newID: deepCopy(p)})
+			c.Notes = append(c.Notes, fmt.Sprintf("service.pipelines.%s %s, kept in configOverride", id, reason))
+			continue
+		}
+		pipelineType, name, _ := strings.Cut(newID, "/")
+		entry := map[string]any{"name": name, "type": pipelineType, "processors": deepCopy(p["processors"])}
+		var receiverRefs, exporterRefs []any
+		for _, ref := range collectorconfig.Strings(p, "receivers") {
+			receiverRefs = append(receiverRefs, strings.TrimPrefix(ref, collectorconfig.KafkaReceiverType+"/"))
+		}
+		for _, ref := range collectorconfig.Strings(p, "exporters") {
+			exporterRefs = append(exporterRefs, hecName(ref))
+		}
+		entry["receivers"], entry["exporters"] = receiverRefs, exporterRefs
+		valuesPipelines = append(valuesPipelines, entry)
+	}
+	if len(pipelines) > 0 {
+		renamed := make(map[string]any, len(pipelines))
+		for id, p := range pipelines {
+			renamed[id] = p
+		}
+		service["pipelines"] = renamed
+	}
+	for _, k := range collectorconfig.SortedKeys(service) {
+		if k != "extensions" && k != "pipelines" {
+			addOverride(override, "service", k, service[k])
+			c.Notes = append(c.Notes, fmt.Sprintf("service.%s is kept in configOverride", k))
+		}
+	}
+	for _, k := range collectorconfig.SortedKeys(raw) {
+		switch k {
+		case "receivers", "exporters", "processors", "extensions", "service":
+		default:
+			override[k] = raw[k]
+			c.Notes = append(c.Notes, fmt.Sprintf("%s is kept in configOverride", k))
+		}
+	}
+
+	for _, id := range collectorconfig.SortedKeys(renames) {
+		c.Renames = append(c.Renames, Rename{From: id, To: renames[id]})
+	}
+	slices.SortFunc(c.Renames, func(a, b Rename) int { return strings.Compare(a.From, b.From) })
+	for key, v := range map[string][]any{"kafkaReceivers": kafkaReceivers, "splunkExporters": splunkExporters, "pipelines": valuesPipelines} {
+		if len(v) > 0 {
+			c.Values[key] = v
+		}
+	}
+	if len(valuesDefaults) > 0 {
+		c.Values["defaults"] = valuesDefaults
+	}
+	if len(override) > 0 {
+		c.Values["configOverride"] = override
+	}
+	c.Expected = expectedConfig(raw, renames, exporterNames, receiverNames, enabled)
+	return c
+}
+
+// components returns the component configurations of a section, with empty configurations as empty maps.
+func components(raw map[string]any, section string) map[string]map[string]any {
+	out := map[string]map[string]any{}
+	for id, cfg := range collectorconfig.Map(raw, section) {
+		m, _ := cfg.(map[string]any)
+		if m == nil {
+			m = map[string]any{}
+		}
+		out[id] = m
+	}
+	if len(out) > 0 || raw[section] != nil {
+		converted := make(map[string]any, len(out))
+		for id, m := range out {
+			converted[id] = m
+		}
+		raw[section] = converted
+	}
+	return out
+}
+
+// componentNames returns the chart names of component IDs: the part after the type, or bare when the ID has none.
+// The name reserved for bare IDs is given to a suffixed ID only when no bare ID exists.
+func componentNames(ids []string, reserved, bare string) map[string]string {
+	taken := map[string]bool{}
+	hasBare := false
+	for _, id := range ids {
+		if _, name, ok := strings.Cut(id, "/"); ok && name != reserved {
+			taken[name] = true
+		} else if !ok {
+			hasBare = true
+		}
+	}
+	unique := func(name string) string {
+		candidate := name
+		for i := 2; taken[candidate]; i++ {
+			candidate = fmt.Sprintf("%s-%d", name, i)
+		}
+		taken[candidate] = true
+		return candidate
+	}
+	names := map[string]string{}
+	for _, id := range ids {
+		if !strings.Contains(id, "/") {
+			names[id] = unique(bare)
+		}
+	}
+	for _, id := range ids {
+		_, name, ok := strings.Cut(id, "/")
+		switch {
+		case !ok:
+		case name == reserved && hasBare:
+			names[id] = unique(name)
+		case name == reserved:
+			names[id] = name
+			taken[name] = true
+		default:
+			names[id] = name
+		}
+	}
+	return names
+}
+
+// unmodelledPipeline returns why the chart cannot generate a pipeline, or an empty string.
+func unmodelledPipeline(p map[string]any) string {
+	if len(collectorconfig.Strings(p, "processors")) == 0 {
+		return "has no processors, the chart would add defaults.pipelineProcessors"
+	}
+	for _, ref := range collectorconfig.Strings(p, "receivers") {
+		if collectorconfig.ComponentType(ref) != collectorconfig.KafkaReceiverType || !strings.Contains(ref, "/") {
+			return "uses receivers other than kafka"
+		}
+	}
+	for _, ref := range collectorconfig.Strings(p, "exporters") {
+		if collectorconfig.ComponentType(ref) != collectorconfig.HECExporterType {
+			return "uses exporters other than splunk_hec"
+		}
+	}
+	return ""
+}
+
+// hecName returns the splunkExporters name of an exporter ID generated by ExporterName.
+func hecName(id string) string {
+	if id == collectorconfig.HECExporterType {
+		return "primary"
+	}
+	return strings.TrimPrefix(id, collectorconfig.HECExporterType+"/")
+}
+
+// secretName returns a Kubernetes object name made of the given parts.
+func secretName(parts ...string) string {
+	name := strings.ToLower(strings.Join(append([]string{"soc4kafka"}, parts...), "-"))
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+func addOverride(override map[string]any, section, key string, v any) {
+	m, _ := override[section].(map[string]any)
+	if m == nil {
+		m = map[string]any{}
+		override[section] = m
+	}
+	if existing, ok := m[key].(map[string]any); ok {
+		if add, ok := v.(map[string]any); ok {
+			for k, item := range add {
+				existing[k] = item
+			}
+			return
+		}
+	}
+	m[key] = v
+}
+
+// sharedDefaults returns the part of the chart defaults of a component type that every configuration keeps: the
+// chart merges entries over the defaults with mustMergeOverwrite, so a default is dropped when a configuration
+// lacks it or sets it to an empty value, which cannot overwrite it.
+func sharedDefaults(defaults map[string]any, configs []map[string]any) map[string]any {
+	kept := map[string]any{}
+	for k, d := range defaults {
+		values := make([]map[string]any, 0, len(configs))
+		keep := true
+		for _, cfg := range configs {
+			v, ok := cfg[k]
+			if !ok {
+				keep = false
+				break
+			}
+			if _, isMap := d.(map[string]any); isMap {
+				m, isMap := v.(map[string]any)
+				if !isMap {
+					keep = false
+					break
+				}
+				values = append(values, m)
+			} else if isEmpty(v) && !reflect.DeepEqual(v, d) {
+				keep = false
+				break
+			}
+		}
+		if !keep {
+			continue
+		}
+		if m, isMap := d.(map[string]any); isMap {
+			if sub := sharedDefaults(m, values); len(sub) > 0 {
+				kept[k] = sub
+			}
+			continue
+		}
+		kept[k] = d
+	}
+	return kept
+}
+
+// coalescePatch returns the values which, coalesced over defaults, give want.
+func coalescePatch[V any](defaults map[string]any, want map[string]V) map[string]any {
+	patch := map[string]any{}
+	for k := range defaults {
+		if _, ok := want[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, w := range want {
+		var v any = w
+		d, ok := defaults[k]
+		if reflect.DeepEqual(d, v) {
+			continue
+		}
+		dm, dIsMap := d.(map[string]any)
+		wm, wIsMap := v.(map[string]any)
+		if ok && dIsMap && wIsMap {
+			if sub := coalescePatch(dm, wm); len(sub) > 0 {
+				patch[k] = sub
+			}
+			continue
+		}
+		patch[k] = v
+	}
+	return patch
+}
+
+// strip returns cfg without the settings equal to the shared defaults.
+func strip(cfg, shared map[string]any) map[string]any {
+	out := map[string]any{}
+	for k, v := range cfg {
+		d, ok := shared[k]
+		if !ok {
+			out[k] = v
+			continue
+		}
+		if reflect.DeepEqual(v, d) {
+			continue
+		}
+		vm, vIsMap := v.(map[string]any)
+		dm, dIsMap := d.(map[string]any)
+		if vIsMap && dIsMap {
+			if sub := strip(vm, dm); len(sub) > 0 {
+				out[k] = sub
+			}
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// expectedConfig returns the configuration the converted values must render: raw, as normalized by FromConfig,
+// with the renames applied, the credentials read from the chart environment variables and the extensions sorted.
+func expectedConfig(raw map[string]any, renames, exporterNames, receiverNames map[string]string, extensions []string) map[string]any {
+	expected := deepCopy(raw).(map[string]any)
+	for _, section := range []string{"receivers", "exporters"} {
+		m := collectorconfig.Map(expected, section)
+		for _, id := range collectorconfig.SortedKeys(m) {
+			cfg, _ := m[id].(map[string]any)
+			if name, ok := exporterNames[id]; ok && section == "exporters" {
+				cfg["token"] = "${" + TokenEnvVar(name) + "}"
+			}
+			if name, ok := receiverNames[id]; ok && section == "receivers" {
+				auth := collectorconfig.Map(cfg, "auth")
+				for _, mechanism := range authMechanisms {
+					if settings := collectorconfig.Map(auth, mechanism); settings != nil && settings["password"] != nil {
+						settings["password"] = "${" + PasswordEnvVar(ReceiverName(name), mechanism) + "}"
+					}
+				}
+			}
+			if newID, ok := renames[id]; ok {
+				delete(m, id)
+				m[newID] = cfg
+			}
+		}
+	}
+	service := collectorconfig.Map(raw, "service")
+	expectedService := deepCopy(service).(map[string]any)
+	if expectedService == nil {
+		expectedService = map[string]any{}
+	}
+	sorted := slices.Sorted(slices.Values(extensions))
+	expectedService["extensions"] = stringsToList(sorted)
+	if expectedService["pipelines"] == nil {
+		expectedService["pipelines"] = map[string]any{}
+	}
+	expected["service"] = expectedService
+	return expected
+}
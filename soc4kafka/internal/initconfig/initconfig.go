@@ -0,0 +1,204 @@
+// Package initconfig renders a ready to run SOC4Kafka collector configuration, together with an optional
+// systemd unit and environment file, from a small set of options.
+package initconfig
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+const (
+	// HECTokenEnvVar is the environment variable holding the Splunk HEC token when secrets are kept out of the config.
+	HECTokenEnvVar = "SPLUNK_HEC_TOKEN"
+	// KafkaPasswordEnvVar is the environment variable holding the Kafka password when secrets are kept out of the config.
+	KafkaPasswordEnvVar = "KAFKA_PASSWORD"
+)
+
+const (
+	AuthPlainText = "plain_text"
+	AuthSASL      = "sasl"
+)
+
+var supportedSASLMechanisms = []string{"PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512", "AWS_MSK_IAM_OAUTHBEARER"}
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"quote": quote,
+}).ParseFS(templatesFS, "templates/*.tmpl"))
+
+// Auth describes the Kafka receiver authentication block.
+type Auth struct {
+	// Type is either AuthPlainText or AuthSASL.
+	Type      string
+	Username  string
+	Password  string
+	Mechanism string
+}
+
+// KafkaTLS describes the TLS settings used to connect to the Kafka brokers.
+type KafkaTLS struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// Options are the inputs of the generated configuration.
+type Options struct {
+	Brokers  []string
+	Topics   []string
+	Encoding string
+	GroupID  string
+	Auth     *Auth
+	KafkaTLS *KafkaTLS
+
+	HECEndpoint           string
+	HECToken              string
+	HECInsecureSkipVerify bool
+	Index                 string
+	Source                string
+	Sourcetype            string
+
+	// SecretsFromEnv replaces the HEC token and the Kafka password in the config with environment variable
+	// references. The values are written to the environment file instead.
+	SecretsFromEnv bool
+}
+
+// SystemdOptions are the inputs of the generated systemd unit.
+type SystemdOptions struct {
+	BinaryPath  string
+	ConfigPath  string
+	EnvFilePath string
+	User        string
+}
+
+// Validate checks that all required options are set and have supported values.
+func (o *Options) Validate() error {
+	var errs []error
+	if len(o.Brokers) == 0 {
+		errs = append(errs, errors.New("at least one Kafka broker is required"))
+	}
+	for _, b := range o.Brokers {
+		if !strings.Contains(b, ":") {
+			errs = append(errs, fmt.Errorf("broker %q must be in the host:port format", b))
+		}
+	}
+	if len(o.Topics) == 0 {
+		errs = append(errs, errors.New("at least one Kafka topic is required"))
+	}
+	if o.Encoding == "" {
+		errs = append(errs, errors.New("message encoding is required"))
+	}
+	if o.HECEndpoint == "" {
+		errs = append(errs, errors.New("Splunk HEC endpoint is required"))
+	} else if u, err := url.Parse(o.HECEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("Splunk HEC endpoint %q must be an http(s) URL", o.HECEndpoint))
+	}
+	if o.HECToken == "" {
+		errs = append(errs, errors.New("Splunk HEC token is required"))
+	}
+	if o.Index == "" {
+		errs = append(errs, errors.New("Splunk index is required"))
+	}
+	if o.Auth != nil {
+		switch o.Auth.Type {
+		case AuthPlainText:
+			if o.Auth.Mechanism != "" {
+				errs = append(errs, errors.New("SASL mechanism can only be set with sasl authentication"))
+			}
+		case AuthSASL:
+			if !slices.Contains(supportedSASLMechanisms, o.Auth.Mechanism) {
+				errs = append(errs, fmt.Errorf("unsupported SASL mechanism %q, expected one of %s", o.Auth.Mechanism, strings.Join(supportedSASLMechanisms, ", ")))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unsupported authentication %q, expected %s or %s", o.Auth.Type, AuthPlainText, AuthSASL))
+		}
+		if o.Auth.Username == "" || o.Auth.Password == "" {
+			errs = append(errs, errors.New("Kafka authentication requires a username and a password"))
+		}
+	}
+	if o.KafkaTLS != nil && (o.KafkaTLS.CertFile == "") != (o.KafkaTLS.KeyFile == "") {
+		errs = append(errs, errors.New("Kafka TLS cert file and key file must be set together"))
+	}
+	return errors.Join(errs...)
+}
+
+// RenderConfig returns the collector configuration.
+func RenderConfig(o Options) ([]byte, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	if o.SecretsFromEnv {
+		o.HECToken = envRef(HECTokenEnvVar)
+		if o.Auth != nil {
+			a := *o.Auth
+			a.Password = envRef(KafkaPasswordEnvVar)
+			o.Auth = &a
+		}
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "config.yaml.tmpl", o); err != nil {
+		return nil, err
+	}
+	// Guard against producing a file the collector cannot load.
+	var check map[string]any
+	if err := yaml.Unmarshal(buf.Bytes(), &check); err != nil {
+		return nil, fmt.Errorf("generated config is not valid YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderEnvFile returns the environment file holding the secrets referenced by the configuration.
+func RenderEnvFile(o Options) []byte {
+	secrets := map[string]string{HECTokenEnvVar: o.HECToken}
+	if o.Auth != nil {
+		secrets[KafkaPasswordEnvVar] = o.Auth.Password
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := make([]collectorconfig.EnvFileVar, 0, len(names))
+	for _, name := range names {
+		vars = append(vars, collectorconfig.EnvFileVar{Name: name, Value: secrets[name]})
+	}
+	return collectorconfig.RenderEnvFile("Secrets referenced by the SOC4Kafka collector configuration", vars)
+}
+
+// RenderSystemdUnit returns a systemd service unit starting the collector with the generated configuration.
+func RenderSystemdUnit(o SystemdOptions) ([]byte, error) {
+	if o.BinaryPath == "" || o.ConfigPath == "" {
+		return nil, errors.New("systemd unit requires the collector binary path and the config path")
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "soc4kafka.service.tmpl", o); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func envRef(name string) string {
+	return "${" + name + "}"
+}
+
+// quote renders s as a double-quoted YAML scalar. JSON strings are valid YAML.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,149 @@
+package initconfig
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func baseOptions() Options {
+	return Options{
+		Brokers:     []string{"broker1:9092", "broker2:9092"},
+		Topics:      []string{"example-topic", "^logs-.*"},
+		Encoding:    "text",
+		HECEndpoint: "https://splunk-hec-endpoint:8088/services/collector",
+		HECToken:    "00000000-0000-0000-0000-000000000000",
+		Index:       "kafka_otel",
+		Source:      "my-kafka",
+		Sourcetype:  "kafka-otel",
+	}
+}
+
+func renderAndParse(t *testing.T, o Options) map[string]any {
+	cfg, err := RenderConfig(o)
+	require.NoError(t, err)
+	var parsed map[string]any
+	require.NoError(t, yaml.Unmarshal(cfg, &parsed))
+	return parsed
+}
+
+func lookup(t *testing.T, m map[string]any, path ...string) any {
+	var cur any = m
+	for _, p := range path {
+		node, ok := cur.(map[string]any)
+		require.True(t, ok, "expected a map at %q", p)
+		cur, ok = node[p]
+		require.True(t, ok, "missing key %q", p)
+	}
+	return cur
+}
+
+func TestRenderConfigBasic(t *testing.T) {
+	cfg := renderAndParse(t, baseOptions())
+
+	assert.Equal(t, []any{"broker1:9092", "broker2:9092"}, lookup(t, cfg, "receivers", "kafka", "brokers"))
+	assert.Equal(t, []any{"example-topic", "^logs-.*"}, lookup(t, cfg, "receivers", "kafka", "logs", "topics"))
+	assert.Equal(t, "text", lookup(t, cfg, "receivers", "kafka", "logs", "encoding"))
+	assert.NotContains(t, lookup(t, cfg, "receivers", "kafka"), "auth")
+	assert.NotContains(t, lookup(t, cfg, "receivers", "kafka"), "tls")
+
+	assert.Equal(t, []any{"system"}, lookup(t, cfg, "processors", "resourcedetection", "detectors"))
+	assert.Equal(t, "soc4kafka", lookup(t, cfg, "exporters", "splunk_hec", "splunk_app_name"))
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", lookup(t, cfg, "exporters", "splunk_hec", "token"))
+	assert.Equal(t, "kafka_otel", lookup(t, cfg, "exporters", "splunk_hec", "index"))
+	assert.Equal(t, false, lookup(t, cfg, "exporters", "splunk_hec", "tls", "insecure_skip_verify"))
+	assert.Equal(t, 10000, lookup(t, cfg, "exporters", "splunk_hec", "sending_queue", "queue_size"))
+	assert.Equal(t, 1000, lookup(t, cfg, "exporters", "splunk_hec", "sending_queue", "batch", "min_size"))
+	assert.Equal(t, []any{"resourcedetection"}, lookup(t, cfg, "service", "pipelines", "logs", "processors"))
+}
+
+func TestRenderConfigAuthTLSAndSecrets(t *testing.T) {
+	o := baseOptions()
+	o.GroupID = "soc4kafka-main"
+	o.Auth = &Auth{Type: AuthSASL, Username: "user", Password: "p@ss: \"word\"", Mechanism: "SCRAM-SHA-512"}
+	o.KafkaTLS = &KafkaTLS{CAFile: "/etc/ssl/kafka/ca.pem"}
+
+	cfg := renderAndParse(t, o)
+	assert.Equal(t, "soc4kafka-main", lookup(t, cfg, "receivers", "kafka", "group_id"))
+	assert.Equal(t, "p@ss: \"word\"", lookup(t, cfg, "receivers", "kafka", "auth", "sasl", "password"))
+	assert.Equal(t, "SCRAM-SHA-512", lookup(t, cfg, "receivers", "kafka", "auth", "sasl", "mechanism"))
+	assert.Equal(t, "/etc/ssl/kafka/ca.pem", lookup(t, cfg, "receivers", "kafka", "tls", "ca_file"))
+
+	o.SecretsFromEnv = true
+	cfg = renderAndParse(t, o)
+	assert.Equal(t, "${SPLUNK_HEC_TOKEN}", lookup(t, cfg, "exporters", "splunk_hec", "token"))
+	assert.Equal(t, "${KAFKA_PASSWORD}", lookup(t, cfg, "receivers", "kafka", "auth", "sasl", "password"))
+
+	env := string(RenderEnvFile(o))
+	assert.Contains(t, env, "SPLUNK_HEC_TOKEN=\"00000000-0000-0000-0000-000000000000\"\n")
+	assert.Contains(t, env, "KAFKA_PASSWORD=\"p@ss: \\\"word\\\"\"\n")
+}
+
+func TestRenderEnvFileSourcedByShell(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell to source the env file with")
+	}
+	password := "p@ss w'o\"rd $HOME `id` \\n; &|"
+	o := baseOptions()
+	o.Auth = &Auth{Type: AuthSASL, Username: "user", Password: password, Mechanism: "SCRAM-SHA-512"}
+	path := filepath.Join(t.TempDir(), "soc4kafka.env")
+	require.NoError(t, os.WriteFile(path, RenderEnvFile(o), 0o600))
+
+	out, err := exec.Command(sh, "-c", `set -a && . "$1" && printf %s "$KAFKA_PASSWORD"`, "sh", path).Output()
+	require.NoError(t, err)
+	assert.Equal(t, password, string(out))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(o *Options)
+		wantErr string
+	}{
+		{name: "valid", modify: func(*Options) {}},
+		{name: "missing brokers", modify: func(o *Options) { o.Brokers = nil }, wantErr: "at least one Kafka broker"},
+		{name: "broker without port", modify: func(o *Options) { o.Brokers = []string{"broker"} }, wantErr: "host:port"},
+		{name: "missing topics", modify: func(o *Options) { o.Topics = nil }, wantErr: "at least one Kafka topic"},
+		{name: "bad endpoint", modify: func(o *Options) { o.HECEndpoint = "splunk:8088" }, wantErr: "http(s) URL"},
+		{name: "missing token", modify: func(o *Options) { o.HECToken = "" }, wantErr: "HEC token is required"},
+		{name: "missing index", modify: func(o *Options) { o.Index = "" }, wantErr: "index is required"},
+		{name: "unknown auth", modify: func(o *Options) { o.Auth = &Auth{Type: "oauth", Username: "u", Password: "p"} }, wantErr: "unsupported authentication"},
+		{name: "bad mechanism", modify: func(o *Options) { o.Auth = &Auth{Type: AuthSASL, Username: "u", Password: "p", Mechanism: "GSSAPI"} }, wantErr: "unsupported SASL mechanism"},
+		{name: "auth without password", modify: func(o *Options) { o.Auth = &Auth{Type: AuthPlainText, Username: "u"} }, wantErr: "username and a password"},
+		{name: "cert without key", modify: func(o *Options) { o.KafkaTLS = &KafkaTLS{CertFile: "cert.pem"} }, wantErr: "must be set together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := baseOptions()
+			tt.modify(&o)
+			err := o.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit, err := RenderSystemdUnit(SystemdOptions{
+		BinaryPath:  "/opt/soc4kafka/otelcol_linux_amd64",
+		ConfigPath:  "/opt/soc4kafka/config.yaml",
+		EnvFilePath: "/opt/soc4kafka/soc4kafka.env",
+		User:        "soc4kafka",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(unit), "ExecStart=/opt/soc4kafka/otelcol_linux_amd64 --config /opt/soc4kafka/config.yaml\n")
+	assert.Contains(t, string(unit), "EnvironmentFile=/opt/soc4kafka/soc4kafka.env\n")
+	assert.Contains(t, string(unit), "User=soc4kafka\n")
+
+	_, err = RenderSystemdUnit(SystemdOptions{ConfigPath: "config.yaml"})
+	require.Error(t, err)
+}
@@ -0,0 +1,535 @@
+// Package kafkaclient connects to the Kafka cluster of a configured kafka receiver, using the same brokers,
+// authentication and TLS settings as the collector.
+package kafkaclient
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// ErrNotReported is returned when the brokers do not report authorized operations, e.g. without an authorizer.
+var ErrNotReported = errors.New("authorized operations not reported by the brokers")
+
+// newProducer creates the producer of a client, tests count the calls.
+var newProducer = kafka.NewProducer
+
+// ConfigMap translates the connection settings of a kafka receiver into librdkafka properties.
+// Environment variable references are expanded.
+func ConfigMap(r collectorconfig.KafkaReceiver) (*kafka.ConfigMap, error) {
+	if len(r.Brokers) == 0 {
+		return nil, fmt.Errorf("%s has no brokers", r.ID)
+	}
+	brokers := make([]string, 0, len(r.Brokers))
+	for _, b := range r.Brokers {
+		brokers = append(brokers, collectorconfig.ExpandEnv(b))
+	}
+	cm := kafka.ConfigMap{
+		"bootstrap.servers": strings.Join(brokers, ","),
+		"client.id":         "soc4kafka-cli",
+		// Errors are returned from the API calls, the librdkafka log lines only add noise to the CLI output.
+		"log_level": 0,
+	}
+
+	auth := collectorconfig.Map(r.Raw, "auth")
+	tls := collectorconfig.Map(r.Raw, "tls")
+	if tls == nil {
+		tls = collectorconfig.Map(auth, "tls")
+	}
+	useTLS := tls != nil && !boolValue(tls["insecure"])
+	if useTLS {
+		setIfPresent(cm, "ssl.ca.location", tls, "ca_file")
+		setIfPresent(cm, "ssl.ca.pem", tls, "ca_pem")
+		setIfPresent(cm, "ssl.certificate.location", tls, "cert_file")
+		setIfPresent(cm, "ssl.certificate.pem", tls, "cert_pem")
+		setIfPresent(cm, "ssl.key.location", tls, "key_file")
+		setIfPresent(cm, "ssl.key.pem", tls, "key_pem")
+		if boolValue(tls["insecure_skip_verify"]) {
+			cm["enable.ssl.certificate.verification"] = false
+		}
+	}
+
+	useSASL := true
+	switch {
+	case auth["sasl"] != nil:
+		sasl := collectorconfig.Map(auth, "sasl")
+		mechanism := collectorconfig.String(sasl, "mechanism")
+		switch mechanism {
+		case "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		default:
+			return nil, fmt.Errorf("%s: SASL mechanism %q is not supported by soc4kafka", r.ID, mechanism)
+		}
+		cm["sasl.mechanisms"] = mechanism
+		cm["sasl.username"] = collectorconfig.ExpandEnv(collectorconfig.String(sasl, "username"))
+		cm["sasl.password"] = collectorconfig.ExpandEnv(collectorconfig.String(sasl, "password"))
+	case auth["plain_text"] != nil:
+		plain := collectorconfig.Map(auth, "plain_text")
+		cm["sasl.mechanisms"] = "PLAIN"
+		cm["sasl.username"] = collectorconfig.ExpandEnv(collectorconfig.String(plain, "username"))
+		cm["sasl.password"] = collectorconfig.ExpandEnv(collectorconfig.String(plain, "password"))
+	case auth["kerberos"] != nil:
+		krb := collectorconfig.Map(auth, "kerberos")
+		if !boolValue(krb["use_keytab"]) {
+			return nil, fmt.Errorf("%s: only keytab based Kerberos authentication is supported by soc4kafka", r.ID)
+		}
+		cm["sasl.mechanisms"] = "GSSAPI"
+		cm["sasl.kerberos.service.name"] = collectorconfig.String(krb, "service_name")
+		cm["sasl.kerberos.keytab"] = collectorconfig.String(krb, "keytab_file")
+		principal := collectorconfig.String(krb, "username")
+		if realm := collectorconfig.String(krb, "realm"); realm != "" {
+			principal += "@" + realm
+		}
+		cm["sasl.kerberos.principal"] = principal
+	default:
+		useSASL = false
+	}
+
+	switch {
+	case useSASL && useTLS:
+		cm["security.protocol"] = "SASL_SSL"
+	case useSASL:
+		cm["security.protocol"] = "SASL_PLAINTEXT"
+	case useTLS:
+		cm["security.protocol"] = "SSL"
+	}
+	return &cm, nil
+}
+
+// Client wraps a Kafka admin client.
+type Client struct {
+	admin   *kafka.AdminClient
+	config  *kafka.ConfigMap
+	timeout time.Duration
+
+	// producerMu guards producer, Produce may be called concurrently.
+	producerMu sync.Mutex
+	producer   *kafka.Producer
+}
+
+// New creates a client for the cluster of the receiver. Requests time out after timeout.
+func New(r collectorconfig.KafkaReceiver, timeout time.Duration) (*Client, error) {
+	cm, err := ConfigMap(r)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := kafka.NewAdminClient(cm)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{admin: admin, config: cm, timeout: timeout}, nil
+}
+
+// Close releases the client.
+func (c *Client) Close() {
+	c.producerMu.Lock()
+	if c.producer != nil {
+		c.producer.Close()
+	}
+	c.producerMu.Unlock()
+	c.admin.Close()
+}
+
+// Topics returns the topics visible to the client with their partition count.
+func (c *Client) Topics(context.Context) (map[string]int, error) {
+	md, err := c.admin.GetMetadata(nil, true, int(c.timeout.Milliseconds()))
+	if err != nil {
+		return nil, err
+	}
+	topics := make(map[string]int, len(md.Topics))
+	for name, t := range md.Topics {
+		if t.Error.Code() != kafka.ErrNoError {
+			continue
+		}
+		topics[name] = len(t.Partitions)
+	}
+	return topics, nil
+}
+
+// TopicOperations returns the operations the client is authorized to perform on each topic.
+func (c *Client) TopicOperations(ctx context.Context, topics []string) (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	res, err := c.admin.DescribeTopics(ctx, kafka.NewTopicCollectionOfTopicNames(topics),
+		kafka.SetAdminOptionIncludeAuthorizedOperations(true))
+	if err != nil {
+		return nil, err
+	}
+	ops := make(map[string][]string, len(res.TopicDescriptions))
+	for _, d := range res.TopicDescriptions {
+		if d.Error.Code() != kafka.ErrNoError {
+			return nil, fmt.Errorf("topic %s: %w", d.Name, d.Error)
+		}
+		if len(d.AuthorizedOperations) == 0 {
+			return nil, ErrNotReported
+		}
+		ops[d.Name] = operationNames(d.AuthorizedOperations)
+	}
+	return ops, nil
+}
+
+// GroupOperations returns the operations the client is authorized to perform on the consumer group.
+func (c *Client) GroupOperations(ctx context.Context, group string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	res, err := c.admin.DescribeConsumerGroups(ctx, []string{group}, kafka.SetAdminOptionIncludeAuthorizedOperations(true))
+	if err != nil {
+		return nil, err
+	}
+	if len(res.ConsumerGroupDescriptions) != 1 {
+		return nil, fmt.Errorf("unexpected response describing group %s", group)
+	}
+	d := res.ConsumerGroupDescriptions[0]
+	if d.Error.Code() != kafka.ErrNoError {
+		return nil, fmt.Errorf("group %s: %w", group, d.Error)
+	}
+	if len(d.AuthorizedOperations) == 0 {
+		return nil, ErrNotReported
+	}
+	return operationNames(d.AuthorizedOperations), nil
+}
+
+func operationNames(ops []kafka.ACLOperation) []string {
+	names := make([]string, 0, len(ops))
+	for _, op := range ops {
+		names = append(names, op.String())
+	}
+	return names
+}
+
+func setIfPresent(cm kafka.ConfigMap, property string, m map[string]any, key string) {
+	if v := collectorconfig.String(m, key); v != "" {
+		cm[property] = collectorconfig.ExpandEnv(v)
+	}
+}
+
+func boolValue(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+// TopicPartition identifies a partition of a topic.
+type TopicPartition struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+}
+
+// NoOffset is returned for partitions without a committed offset.
+const NoOffset int64 = -1
+
+// Partitions returns every partition of the topics, in topic and partition order. Unknown topics are skipped.
+func Partitions(topics map[string]int, names []string) []TopicPartition {
+	var out []TopicPartition
+	for _, name := range names {
+		for p := 0; p < topics[name]; p++ {
+			out = append(out, TopicPartition{Topic: name, Partition: int32(p)})
+		}
+	}
+	return out
+}
+
+// EndOffsets returns the log end offset of each partition.
+func (c *Client) EndOffsets(ctx context.Context, partitions []TopicPartition) (map[TopicPartition]int64, error) {
+	return c.listOffsets(ctx, partitions, kafka.LatestOffsetSpec)
+}
+
+// StartOffsets returns the log start offset of each partition.
+func (c *Client) StartOffsets(ctx context.Context, partitions []TopicPartition) (map[TopicPartition]int64, error) {
+	return c.listOffsets(ctx, partitions, kafka.EarliestOffsetSpec)
+}
+
+// OffsetsForTime returns the offset of the first record of each partition with a timestamp at or after t,
+// NoOffset when the partition has no such record.
+func (c *Client) OffsetsForTime(ctx context.Context, partitions []TopicPartition, t time.Time) (map[TopicPartition]int64, error) {
+	return c.listOffsets(ctx, partitions, kafka.NewOffsetSpecForTimestamp(t.UnixMilli()))
+}
+
+func (c *Client) listOffsets(ctx context.Context, partitions []TopicPartition, spec kafka.OffsetSpec) (map[TopicPartition]int64, error) {
+	out := make(map[TopicPartition]int64, len(partitions))
+	if len(partitions) == 0 {
+		return out, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req := make(map[kafka.TopicPartition]kafka.OffsetSpec, len(partitions))
+	for _, tp := range partitions {
+		req[toKafka(tp)] = spec
+	}
+	res, err := c.admin.ListOffsets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for tp, info := range res.ResultInfos {
+		if info.Error.Code() != kafka.ErrNoError {
+			return nil, fmt.Errorf("%s[%d]: %w", *tp.Topic, tp.Partition, info.Error)
+		}
+		offset := int64(info.Offset)
+		if offset < 0 {
+			offset = NoOffset
+		}
+		out[fromKafka(tp)] = offset
+	}
+	return out, nil
+}
+
+// CommittedOffsets returns the offsets committed by the consumer group for the partitions, NoOffset when the group
+// did not commit an offset for a partition.
+func (c *Client) CommittedOffsets(ctx context.Context, group string, partitions []TopicPartition) (map[TopicPartition]int64, error) {
+	out := make(map[TopicPartition]int64, len(partitions))
+	if len(partitions) == 0 {
+		return out, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req := kafka.ConsumerGroupTopicPartitions{Group: group}
+	for _, tp := range partitions {
+		req.Partitions = append(req.Partitions, toKafka(tp))
+	}
+	res, err := c.admin.ListConsumerGroupOffsets(ctx, []kafka.ConsumerGroupTopicPartitions{req})
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range res.ConsumerGroupsTopicPartitions {
+		for _, tp := range g.Partitions {
+			if tp.Error != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", *tp.Topic, tp.Partition, tp.Error)
+			}
+			offset := int64(tp.Offset)
+			if offset < 0 {
+				offset = NoOffset
+			}
+			out[fromKafka(tp)] = offset
+		}
+	}
+	return out, nil
+}
+
+// CommitOffsets sets the committed offsets of the consumer group. Kafka rejects the request while the group has
+// active members.
+func (c *Client) CommitOffsets(ctx context.Context, group string, offsets map[TopicPartition]int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req := kafka.ConsumerGroupTopicPartitions{Group: group}
+	for tp, offset := range offsets {
+		ktp := toKafka(tp)
+		ktp.Offset = kafka.Offset(offset)
+		req.Partitions = append(req.Partitions, ktp)
+	}
+	res, err := c.admin.AlterConsumerGroupOffsets(ctx, []kafka.ConsumerGroupTopicPartitions{req})
+	if err != nil {
+		return err
+	}
+	for _, g := range res.ConsumerGroupsTopicPartitions {
+		for _, tp := range g.Partitions {
+			if tp.Error != nil {
+				return fmt.Errorf("%s[%d]: %w", *tp.Topic, tp.Partition, tp.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Member is an active member of a consumer group.
+type Member struct {
+	ClientID   string           `json:"client_id"`
+	ConsumerID string           `json:"consumer_id"`
+	Host       string           `json:"host"`
+	Assignment []TopicPartition `json:"assignment"`
+}
+
+// Group is the state of a consumer group.
+type Group struct {
+	ID      string   `json:"id"`
+	State   string   `json:"state"`
+	Members []Member `json:"members"`
+}
+
+// DescribeGroup returns the state and the members of the consumer group.
+func (c *Client) DescribeGroup(ctx context.Context, group string) (Group, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	res, err := c.admin.DescribeConsumerGroups(ctx, []string{group})
+	if err != nil {
+		return Group{}, err
+	}
+	if len(res.ConsumerGroupDescriptions) != 1 {
+		return Group{}, fmt.Errorf("unexpected response describing group %s", group)
+	}
+	d := res.ConsumerGroupDescriptions[0]
+	if d.Error.Code() != kafka.ErrNoError {
+		return Group{}, fmt.Errorf("group %s: %w", group, d.Error)
+	}
+	g := Group{ID: d.GroupID, State: d.State.String(), Members: []Member{}}
+	for _, m := range d.Members {
+		member := Member{ClientID: m.ClientID, ConsumerID: m.ConsumerID, Host: m.Host, Assignment: []TopicPartition{}}
+		for _, tp := range m.Assignment.TopicPartitions {
+			member.Assignment = append(member.Assignment, fromKafka(tp))
+		}
+		g.Members = append(g.Members, member)
+	}
+	return g, nil
+}
+
+func toKafka(tp TopicPartition) kafka.TopicPartition {
+	topic := tp.Topic
+	return kafka.TopicPartition{Topic: &topic, Partition: tp.Partition}
+}
+
+func fromKafka(tp kafka.TopicPartition) TopicPartition {
+	return TopicPartition{Topic: *tp.Topic, Partition: tp.Partition}
+}
+
+// Header is a header of a record.
+type Header struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Record is a consumed record.
+type Record struct {
+	TopicPartition
+	Offset    int64     `json:"offset"`
+	Timestamp time.Time `json:"timestamp"`
+	Key       []byte    `json:"key,omitempty"`
+	Value     []byte    `json:"value"`
+	Headers   []Header  `json:"headers,omitempty"`
+}
+
+// OffsetRange selects the records from Start, included, to End, excluded.
+type OffsetRange struct {
+	Start int64
+	End   int64
+}
+
+// Read consumes the records of the ranges, by partition and offset order. Partitions are assigned directly: no
+// consumer group is joined and no offset is committed. The records read so far are returned with ctx.Err() when
+// ctx is done first.
+func (c *Client) Read(ctx context.Context, ranges map[TopicPartition]OffsetRange) ([]Record, error) {
+	cm := kafka.ConfigMap{}
+	for k, v := range *c.config {
+		cm[k] = v
+	}
+	// librdkafka requires a group to fetch, it is never joined since partitions are assigned.
+	cm["group.id"] = "soc4kafka-cli"
+	cm["enable.auto.commit"] = false
+	cm["enable.partition.eof"] = true
+	consumer, err := kafka.NewConsumer(&cm)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	pending := map[TopicPartition]OffsetRange{}
+	var assignment []kafka.TopicPartition
+	for tp, r := range ranges {
+		if r.Start >= r.End {
+			continue
+		}
+		pending[tp] = r
+		ktp := toKafka(tp)
+		ktp.Offset = kafka.Offset(r.Start)
+		assignment = append(assignment, ktp)
+	}
+	if len(assignment) == 0 {
+		return nil, nil
+	}
+	if err := consumer.Assign(assignment); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for len(pending) > 0 {
+		if err := ctx.Err(); err != nil {
+			return sortRecords(records), err
+		}
+		switch ev := consumer.Poll(100).(type) {
+		case *kafka.Message:
+			tp := fromKafka(ev.TopicPartition)
+			r, ok := pending[tp]
+			offset := int64(ev.TopicPartition.Offset)
+			if !ok || offset >= r.End {
+				continue
+			}
+			rec := Record{TopicPartition: tp, Offset: offset, Timestamp: ev.Timestamp, Key: ev.Key, Value: ev.Value}
+			for _, h := range ev.Headers {
+				rec.Headers = append(rec.Headers, Header{Key: h.Key, Value: h.Value})
+			}
+			records = append(records, rec)
+			if offset+1 >= r.End {
+				delete(pending, tp)
+			}
+		case kafka.PartitionEOF:
+			// Offsets removed by compaction or transaction markers may end a partition before the end offset.
+			delete(pending, fromKafka(kafka.TopicPartition(ev)))
+		case kafka.Error:
+			if ev.IsFatal() || ev.Code() == kafka.ErrAllBrokersDown {
+				return sortRecords(records), ev
+			}
+		}
+	}
+	return sortRecords(records), nil
+}
+
+func sortRecords(records []Record) []Record {
+	slices.SortFunc(records, func(a, b Record) int {
+		if c := strings.Compare(a.Topic, b.Topic); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Partition, b.Partition); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+	return records
+}
+
+// Produce writes a record to a partition chosen by the default partitioner and waits for its delivery. The producer
+// is created on first use, shared by concurrent calls and released by Close.
+func (c *Client) Produce(ctx context.Context, topic string, key, value []byte) (TopicPartition, int64, error) {
+	producer, err := c.sharedProducer()
+	if err != nil {
+		return TopicPartition{}, 0, err
+	}
+	delivered := make(chan kafka.Event, 1)
+	m := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny}, Key: key, Value: value}
+	if err := producer.Produce(m, delivered); err != nil {
+		return TopicPartition{}, 0, err
+	}
+	select {
+	case <-ctx.Done():
+		return TopicPartition{}, 0, ctx.Err()
+	case ev := <-delivered:
+		m := ev.(*kafka.Message)
+		if m.TopicPartition.Error != nil {
+			return TopicPartition{}, 0, m.TopicPartition.Error
+		}
+		return fromKafka(m.TopicPartition), int64(m.TopicPartition.Offset), nil
+	}
+}
+
+func (c *Client) sharedProducer() (*kafka.Producer, error) {
+	c.producerMu.Lock()
+	defer c.producerMu.Unlock()
+	if c.producer != nil {
+		return c.producer, nil
+	}
+	cm := kafka.ConfigMap{}
+	for k, v := range *c.config {
+		cm[k] = v
+	}
+	cm["delivery.timeout.ms"] = int(c.timeout.Milliseconds())
+	p, err := newProducer(&cm)
+	if err != nil {
+		return nil, err
+	}
+	c.producer = p
+	return p, nil
+}
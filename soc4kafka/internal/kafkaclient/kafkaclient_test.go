@@ -0,0 +1,234 @@
+package kafkaclient
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkatest"
+)
+
+func receiver(t *testing.T, yaml string) collectorconfig.KafkaReceiver {
+	t.Helper()
+	cfg, err := collectorconfig.Parse([]byte(yaml))
+	require.NoError(t, err)
+	require.Len(t, cfg.KafkaReceivers, 1)
+	return cfg.KafkaReceivers[0]
+}
+
+func TestConfigMap(t *testing.T) {
+	t.Setenv("KAFKA_PASSWORD", "secret")
+	tests := []struct {
+		name     string
+		config   string
+		expected kafka.ConfigMap
+		err      string
+	}{
+		{
+			name: "plaintext",
+			config: `
+receivers:
+  kafka:
+    brokers: [ "a:9092", "b:9092" ]
+    logs: { topics: [ t ] }`,
+			expected: kafka.ConfigMap{"bootstrap.servers": "a:9092,b:9092"},
+		},
+		{
+			name: "sasl over tls",
+			config: `
+receivers:
+  kafka:
+    brokers: [ "a:9093" ]
+    tls:
+      ca_file: /etc/ca.pem
+      insecure_skip_verify: true
+    auth:
+      sasl:
+        mechanism: SCRAM-SHA-512
+        username: user
+        password: ${env:KAFKA_PASSWORD}`,
+			expected: kafka.ConfigMap{
+				"bootstrap.servers":                   "a:9093",
+				"security.protocol":                   "SASL_SSL",
+				"ssl.ca.location":                     "/etc/ca.pem",
+				"enable.ssl.certificate.verification": false,
+				"sasl.mechanisms":                     "SCRAM-SHA-512",
+				"sasl.username":                       "user",
+				"sasl.password":                       "secret",
+			},
+		},
+		{
+			name: "insecure tls and plain text auth",
+			config: `
+receivers:
+  kafka:
+    brokers: [ "a:9092" ]
+    tls: { insecure: true }
+    auth:
+      plain_text: { username: user, password: pass }`,
+			expected: kafka.ConfigMap{
+				"bootstrap.servers": "a:9092",
+				"security.protocol": "SASL_PLAINTEXT",
+				"sasl.mechanisms":   "PLAIN",
+				"sasl.username":     "user",
+				"sasl.password":     "pass",
+			},
+		},
+		{
+			name: "unsupported mechanism",
+			config: `
+receivers:
+  kafka:
+    brokers: [ "a:9092" ]
+    auth:
+      sasl: { mechanism: AWS_MSK_IAM_OAUTHBEARER }`,
+			err: `kafka: SASL mechanism "AWS_MSK_IAM_OAUTHBEARER" is not supported by soc4kafka`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm, err := ConfigMap(receiver(t, tt.config))
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			delete(*cm, "client.id")
+			delete(*cm, "log_level")
+			assert.Equal(t, tt.expected, *cm)
+		})
+	}
+}
+
+func TestMockCluster(t *testing.T) {
+	cluster := kafkatest.NewCluster(t, map[string]int{"logs": 2})
+	cluster.Produce("logs", 0, 5)
+	cluster.Commit("group", "logs", 0, 3)
+
+	c, err := New(collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{cluster.Brokers()}}, 10*time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	topics, err := c.Topics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, topics["logs"])
+
+	partitions := Partitions(map[string]int{"logs": 2, "other": 1}, []string{"logs", "missing"})
+	require.Equal(t, []TopicPartition{{Topic: "logs", Partition: 0}, {Topic: "logs", Partition: 1}}, partitions)
+
+	end, err := c.EndOffsets(context.Background(), partitions)
+	require.NoError(t, err)
+	assert.Equal(t, map[TopicPartition]int64{partitions[0]: 5, partitions[1]: 0}, end)
+
+	committed, err := c.CommittedOffsets(context.Background(), "group", partitions)
+	require.NoError(t, err)
+	assert.Equal(t, map[TopicPartition]int64{partitions[0]: 3, partitions[1]: NoOffset}, committed)
+
+	start, err := c.StartOffsets(context.Background(), partitions)
+	require.NoError(t, err)
+	assert.Equal(t, map[TopicPartition]int64{partitions[0]: 0, partitions[1]: 0}, start)
+
+	byTime, err := c.OffsetsForTime(context.Background(), partitions, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, map[TopicPartition]int64{partitions[0]: NoOffset, partitions[1]: NoOffset}, byTime)
+
+	require.NoError(t, c.CommitOffsets(context.Background(), "group", map[TopicPartition]int64{partitions[0]: 1}))
+	committed, err = c.CommittedOffsets(context.Background(), "group", partitions)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), committed[partitions[0]])
+}
+
+func TestRead(t *testing.T) {
+	cluster := kafkatest.NewCluster(t, map[string]int{"logs": 2})
+	cluster.Produce("logs", 0, 3)
+	cluster.Send("logs", 1, &kafka.Message{Value: []byte("hello"), Key: []byte("k"),
+		Headers: []kafka.Header{{Key: "index", Value: []byte("main")}}})
+
+	c, err := New(collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{cluster.Brokers()}}, 10*time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	records, err := c.Read(ctx, map[TopicPartition]OffsetRange{
+		{Topic: "logs", Partition: 0}: {Start: 1, End: 3},
+		{Topic: "logs", Partition: 1}: {Start: 0, End: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, []int64{1, 2, 0}, []int64{records[0].Offset, records[1].Offset, records[2].Offset})
+	assert.Equal(t, "event", string(records[0].Value))
+	last := records[2]
+	assert.Equal(t, TopicPartition{Topic: "logs", Partition: 1}, last.TopicPartition)
+	assert.Equal(t, "k", string(last.Key))
+	assert.Equal(t, []Header{{Key: "index", Value: []byte("main")}}, last.Headers)
+	assert.False(t, last.Timestamp.IsZero())
+
+	records, err = c.Read(ctx, map[TopicPartition]OffsetRange{{Topic: "logs", Partition: 1}: {Start: 1, End: 1}})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestProduce(t *testing.T) {
+	cluster := kafkatest.NewCluster(t, map[string]int{"logs": 1})
+	c, err := New(collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{cluster.Brokers()}}, 10*time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for i := range 2 {
+		tp, offset, err := c.Produce(ctx, "logs", []byte("k"), []byte("canary"))
+		require.NoError(t, err)
+		assert.Equal(t, TopicPartition{Topic: "logs", Partition: 0}, tp)
+		assert.Equal(t, int64(i), offset)
+	}
+	records, err := c.Read(ctx, map[TopicPartition]OffsetRange{{Topic: "logs", Partition: 0}: {Start: 0, End: 2}})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "canary", string(records[1].Value))
+}
+
+// Probes of a receiver with several topics produce concurrently through one client, which must create one producer.
+func TestProduceConcurrently(t *testing.T) {
+	topics := map[string]int{}
+	for i := range 8 {
+		topics[fmt.Sprintf("logs-%d", i)] = 1
+	}
+	cluster := kafkatest.NewCluster(t, topics)
+	var created atomic.Int32
+	newProducer = func(cm *kafka.ConfigMap) (*kafka.Producer, error) {
+		created.Add(1)
+		return kafka.NewProducer(cm)
+	}
+	t.Cleanup(func() { newProducer = kafka.NewProducer })
+
+	c, err := New(collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{cluster.Brokers()}}, 10*time.Second)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for topic := range topics {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, _, err := c.Produce(ctx, topic, nil, []byte("canary"))
+			assert.NoError(t, err)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	assert.Equal(t, int32(1), created.Load())
+}
@@ -0,0 +1,68 @@
+// Package kafkatest provides an in-process Kafka cluster for tests, backed by the librdkafka mock cluster.
+package kafkatest
+
+import (
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/require"
+)
+
+// Cluster is a single broker mock cluster closed at the end of the test.
+type Cluster struct {
+	t  *testing.T
+	mc *kafka.MockCluster
+}
+
+// NewCluster starts a mock cluster with the topics, given as name to partition count.
+func NewCluster(t *testing.T, topics map[string]int) *Cluster {
+	t.Helper()
+	mc, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	t.Cleanup(mc.Close)
+	for name, partitions := range topics {
+		require.NoError(t, mc.CreateTopic(name, partitions, 1))
+	}
+	return &Cluster{t: t, mc: mc}
+}
+
+// Brokers returns the bootstrap servers of the cluster.
+func (c *Cluster) Brokers() string {
+	return c.mc.BootstrapServers()
+}
+
+// Produce writes count records to a partition and waits for their delivery.
+func (c *Cluster) Produce(topic string, partition int32, count int) {
+	c.t.Helper()
+	messages := make([]*kafka.Message, count)
+	for i := range messages {
+		messages[i] = &kafka.Message{Value: []byte("event")}
+	}
+	c.Send(topic, partition, messages...)
+}
+
+// Send writes messages to a partition and waits for their delivery.
+func (c *Cluster) Send(topic string, partition int32, messages ...*kafka.Message) {
+	c.t.Helper()
+	p, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": c.Brokers()})
+	require.NoError(c.t, err)
+	defer p.Close()
+	delivered := make(chan kafka.Event, len(messages))
+	for _, m := range messages {
+		m.TopicPartition = kafka.TopicPartition{Topic: &topic, Partition: partition}
+		require.NoError(c.t, p.Produce(m, delivered))
+	}
+	for range messages {
+		require.NoError(c.t, (<-delivered).(*kafka.Message).TopicPartition.Error)
+	}
+}
+
+// Commit commits an offset of a partition for a consumer group.
+func (c *Cluster) Commit(group, topic string, partition int32, offset int64) {
+	c.t.Helper()
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{"bootstrap.servers": c.Brokers(), "group.id": group})
+	require.NoError(c.t, err)
+	defer consumer.Close()
+	_, err = consumer.CommitOffsets([]kafka.TopicPartition{{Topic: &topic, Partition: partition, Offset: kafka.Offset(offset)}})
+	require.NoError(c.t, err)
+}
@@ -0,0 +1,100 @@
+// Package lag computes the consumer lag of the consumer groups used by kafka receivers.
+package lag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+// Cluster is the subset of the Kafka admin API used to compute the lag.
+type Cluster interface {
+	Topics(ctx context.Context) (map[string]int, error)
+	EndOffsets(ctx context.Context, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	CommittedOffsets(ctx context.Context, group string, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	DescribeGroup(ctx context.Context, group string) (kafkaclient.Group, error)
+}
+
+// Partition is the lag of the consumer group on a single partition.
+type Partition struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	// Committed is the offset committed by the group, kafkaclient.NoOffset when the group did not commit yet.
+	Committed int64 `json:"committed"`
+	LogEnd    int64 `json:"log_end"`
+	// Lag is nil when the group did not commit an offset for the partition.
+	Lag *int64 `json:"lag"`
+	// Member is the consumer ID of the group member the partition is assigned to, empty when unassigned.
+	Member string `json:"member,omitempty"`
+	Host   string `json:"host,omitempty"`
+}
+
+// Report is the lag of the consumer group of a receiver.
+type Report struct {
+	Receiver string `json:"receiver"`
+	Group    string `json:"group"`
+	// State is the consumer group state, e.g. Stable or Empty, empty when the group could not be described.
+	State      string      `json:"state,omitempty"`
+	Members    int         `json:"members"`
+	TotalLag   int64       `json:"total_lag"`
+	Partitions []Partition `json:"partitions"`
+	// Warning explains partial results, e.g. when the brokers do not allow describing the group.
+	Warning string `json:"warning,omitempty"`
+}
+
+// Collect computes the lag of the consumer group of r on every partition of the topics it consumes.
+func Collect(ctx context.Context, cluster Cluster, r collectorconfig.KafkaReceiver) (Report, error) {
+	report := Report{Receiver: r.ID, Group: r.EffectiveGroupID(), Partitions: []Partition{}}
+
+	topics, err := cluster.Topics(ctx)
+	if err != nil {
+		return report, fmt.Errorf("fetching metadata: %w", err)
+	}
+	names, err := r.ResolveTopics(collectorconfig.SortedKeys(topics))
+	if err != nil {
+		return report, err
+	}
+	partitions := kafkaclient.Partitions(topics, names)
+	endOffsets, err := cluster.EndOffsets(ctx, partitions)
+	if err != nil {
+		return report, fmt.Errorf("listing log end offsets: %w", err)
+	}
+	committed, err := cluster.CommittedOffsets(ctx, report.Group, partitions)
+	if err != nil {
+		return report, fmt.Errorf("listing offsets of group %s: %w", report.Group, err)
+	}
+
+	assigned := map[kafkaclient.TopicPartition]kafkaclient.Member{}
+	group, err := cluster.DescribeGroup(ctx, report.Group)
+	if err != nil {
+		report.Warning = fmt.Sprintf("member assignment unavailable: %v", err)
+	} else {
+		report.State = group.State
+		report.Members = len(group.Members)
+		for _, m := range group.Members {
+			for _, tp := range m.Assignment {
+				assigned[tp] = m
+			}
+		}
+	}
+
+	for _, tp := range partitions {
+		p := Partition{Topic: tp.Topic, Partition: tp.Partition, Committed: kafkaclient.NoOffset, LogEnd: endOffsets[tp]}
+		if offset, ok := committed[tp]; ok {
+			p.Committed = offset
+		}
+		if p.Committed != kafkaclient.NoOffset {
+			lag := max(p.LogEnd-p.Committed, 0)
+			p.Lag = &lag
+			report.TotalLag += lag
+		}
+		if m, ok := assigned[tp]; ok {
+			p.Member = m.ConsumerID
+			p.Host = m.Host
+		}
+		report.Partitions = append(report.Partitions, p)
+	}
+	return report, nil
+}
@@ -0,0 +1,93 @@
+package lag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+type fakeCluster struct {
+	topics    map[string]int
+	end       map[kafkaclient.TopicPartition]int64
+	committed map[kafkaclient.TopicPartition]int64
+	group     kafkaclient.Group
+	groupErr  error
+}
+
+func (f *fakeCluster) Topics(context.Context) (map[string]int, error) { return f.topics, nil }
+
+func (f *fakeCluster) EndOffsets(context.Context, []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.end, nil
+}
+
+func (f *fakeCluster) CommittedOffsets(_ context.Context, _ string, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	out := map[kafkaclient.TopicPartition]int64{}
+	for _, tp := range partitions {
+		if offset, ok := f.committed[tp]; ok {
+			out[tp] = offset
+		} else {
+			out[tp] = kafkaclient.NoOffset
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCluster) DescribeGroup(context.Context, string) (kafkaclient.Group, error) {
+	return f.group, f.groupErr
+}
+
+func tp(topic string, partition int32) kafkaclient.TopicPartition {
+	return kafkaclient.TopicPartition{Topic: topic, Partition: partition}
+}
+
+func TestCollect(t *testing.T) {
+	cluster := &fakeCluster{
+		topics:    map[string]int{"logs-a": 2, "logs-b": 1, "logs-internal": 1, "other": 1},
+		end:       map[kafkaclient.TopicPartition]int64{tp("logs-a", 0): 100, tp("logs-a", 1): 50, tp("logs-b", 0): 10},
+		committed: map[kafkaclient.TopicPartition]int64{tp("logs-a", 0): 90, tp("logs-a", 1): 50},
+		group: kafkaclient.Group{ID: "soc4kafka", State: "Stable", Members: []kafkaclient.Member{
+			{ConsumerID: "consumer-1", Host: "/10.0.0.1", Assignment: []kafkaclient.TopicPartition{tp("logs-a", 0), tp("logs-a", 1)}},
+			{ConsumerID: "consumer-2", Host: "/10.0.0.2", Assignment: []kafkaclient.TopicPartition{tp("logs-b", 0)}},
+		}},
+	}
+	r := collectorconfig.KafkaReceiver{
+		ID:            "kafka/logs",
+		Topics:        []string{"^logs-.*"},
+		ExcludeTopics: []string{"^logs-internal$"},
+		GroupID:       "soc4kafka",
+	}
+
+	report, err := Collect(context.Background(), cluster, r)
+	require.NoError(t, err)
+	assert.Equal(t, "soc4kafka", report.Group)
+	assert.Equal(t, "Stable", report.State)
+	assert.Equal(t, 2, report.Members)
+	assert.Equal(t, int64(10), report.TotalLag)
+	require.Len(t, report.Partitions, 3)
+
+	lag := func(v int64) *int64 { return &v }
+	assert.Equal(t, Partition{Topic: "logs-a", Partition: 0, Committed: 90, LogEnd: 100, Lag: lag(10), Member: "consumer-1", Host: "/10.0.0.1"}, report.Partitions[0])
+	assert.Equal(t, lag(0), report.Partitions[1].Lag)
+	assert.Equal(t, Partition{Topic: "logs-b", Partition: 0, Committed: kafkaclient.NoOffset, LogEnd: 10, Member: "consumer-2", Host: "/10.0.0.2"}, report.Partitions[2])
+}
+
+func TestCollectWithoutGroupDescription(t *testing.T) {
+	cluster := &fakeCluster{
+		topics:    map[string]int{"logs": 1},
+		end:       map[kafkaclient.TopicPartition]int64{tp("logs", 0): 5},
+		committed: map[kafkaclient.TopicPartition]int64{tp("logs", 0): 2},
+		groupErr:  errors.New("not supported"),
+	}
+	report, err := Collect(context.Background(), cluster, collectorconfig.KafkaReceiver{ID: "kafka", Topics: []string{"logs"}})
+	require.NoError(t, err)
+	assert.Equal(t, collectorconfig.DefaultGroupID, report.Group)
+	assert.Equal(t, int64(3), report.TotalLag)
+	assert.Empty(t, report.State)
+	assert.Equal(t, "member assignment unavailable: not supported", report.Warning)
+}
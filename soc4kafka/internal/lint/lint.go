@@ -0,0 +1,319 @@
+// Package lint statically checks SOC4Kafka collector configurations for known misconfigurations.
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// Severity of a finding.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Rank orders severities so that a threshold can be applied.
+func (s Severity) Rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ParseSeverity converts a severity name.
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(s)); sev {
+	case SeverityInfo, SeverityWarning, SeverityError:
+		return sev, nil
+	}
+	return "", fmt.Errorf("unknown severity %q, expected info, warning or error", s)
+}
+
+// Finding is a single problem reported by a rule.
+type Finding struct {
+	Rule      string   `json:"rule"`
+	Severity  Severity `json:"severity"`
+	Component string   `json:"component,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Message   string   `json:"message"`
+}
+
+// Options tune the rules that depend on the deployment rather than on the configuration itself.
+type Options struct {
+	// Replicas is the number of collector instances running the configuration.
+	Replicas int
+	// Disabled lists rule IDs that are skipped.
+	Disabled []string
+}
+
+// Rule is a single check.
+type Rule struct {
+	ID          string
+	Name        string
+	Severity    Severity
+	Description string
+	check       func(cfg *collectorconfig.Config, opts Options, report func(component, path, message string))
+}
+
+// Rules returns all rules in ID order.
+func Rules() []Rule {
+	return rules
+}
+
+var rules = []Rule{
+	{
+		ID:          "SOC001",
+		Name:        "hec-metadata-header-not-extracted",
+		Severity:    SeverityError,
+		Description: "otel_attrs_to_hec_metadata references a kafka.header.* attribute that no receiver of the pipeline extracts",
+		check:       checkHECMetadataHeaders,
+	},
+	{
+		ID:          "SOC002",
+		Name:        "regex-topic-without-caret",
+		Severity:    SeverityWarning,
+		Description: "topic contains regex characters but does not start with ^, so it is subscribed as a literal topic name",
+		check:       checkRegexTopics,
+	},
+	{
+		ID:          "SOC003",
+		Name:        "exclude-topics-without-regex",
+		Severity:    SeverityWarning,
+		Description: "exclude_topics only applies to regex subscriptions, but the receiver has no topic starting with ^",
+		check:       checkExcludeTopics,
+	},
+	{
+		ID:          "SOC004",
+		Name:        "duplicate-consumer",
+		Severity:    SeverityError,
+		Description: "two receivers consume the same topic with the same group_id and split its partitions between them",
+		check:       checkDuplicateConsumers,
+	},
+	{
+		ID:          "SOC005",
+		Name:        "batch-size-close-to-queue-size",
+		Severity:    SeverityWarning,
+		Description: "sending_queue.batch.min_size is close to sending_queue.queue_size, which increases latency and reduces throughput",
+		check:       checkBatchSize,
+	},
+	{
+		ID:          "SOC006",
+		Name:        "insecure-skip-verify",
+		Severity:    SeverityWarning,
+		Description: "tls.insecure_skip_verify is enabled, certificates are not verified",
+		check:       checkInsecureSkipVerify,
+	},
+	{
+		ID:          "SOC007",
+		Name:        "missing-group-id-when-scaling",
+		Severity:    SeverityWarning,
+		Description: "the receiver relies on the default group_id while several collector instances run the configuration",
+		check:       checkGroupIDWhenScaling,
+	},
+}
+
+// batchToQueueRatio is the min_size/queue_size ratio above which SOC005 reports.
+const batchToQueueRatio = 0.5
+
+// Run applies all enabled rules to cfg and returns the findings ordered by severity, rule and component.
+func Run(cfg *collectorconfig.Config, opts Options) []Finding {
+	var findings []Finding
+	for _, r := range rules {
+		if slices.Contains(opts.Disabled, r.ID) || slices.Contains(opts.Disabled, r.Name) {
+			continue
+		}
+		r.check(cfg, opts, func(component, path, message string) {
+			findings = append(findings, Finding{
+				Rule:      r.ID,
+				Severity:  r.Severity,
+				Component: component,
+				Path:      path,
+				Message:   message,
+			})
+		})
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity.Rank() != b.Severity.Rank() {
+			return a.Severity.Rank() > b.Severity.Rank()
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Component < b.Component
+	})
+	return findings
+}
+
+func checkHECMetadataHeaders(cfg *collectorconfig.Config, _ Options, report func(string, string, string)) {
+	for _, e := range cfg.HECExporters {
+		receivers := receiversFeeding(cfg, e.ID)
+		for _, field := range collectorconfig.SortedKeys(e.HECMetadata) {
+			attr := e.HECMetadata[field]
+			header, ok := strings.CutPrefix(attr, collectorconfig.HeaderAttributePrefix)
+			if !ok {
+				continue
+			}
+			var missing []string
+			for _, r := range receivers {
+				if !r.ExtractHeaders || !slices.Contains(r.ExtractedHeaders, header) {
+					missing = append(missing, r.ID)
+				}
+			}
+			if len(missing) == 0 {
+				continue
+			}
+			report(e.ID, "exporters."+e.ID+".otel_attrs_to_hec_metadata."+field, fmt.Sprintf(
+				"%s is mapped from %q, but header %q is not listed in header_extraction.headers (with extract_headers: true) of %s",
+				field, attr, header, strings.Join(missing, ", ")))
+		}
+	}
+}
+
+// receiversFeeding returns the Kafka receivers sharing a pipeline with the exporter. When the exporter is not part of
+// any pipeline all Kafka receivers are returned.
+func receiversFeeding(cfg *collectorconfig.Config, exporterID string) []collectorconfig.KafkaReceiver {
+	pipelines := cfg.PipelinesWithExporter(exporterID)
+	if len(pipelines) == 0 {
+		return cfg.KafkaReceivers
+	}
+	var out []collectorconfig.KafkaReceiver
+	seen := map[string]bool{}
+	for _, p := range pipelines {
+		for _, id := range p.Receivers {
+			if r, ok := cfg.KafkaReceiver(id); ok && !seen[id] {
+				seen[id] = true
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+// regexChars are characters that cannot appear in a Kafka topic name but are common in regular expressions.
+// Dots are valid in topic names and therefore not considered.
+const regexChars = `*+?[](){}|\$`
+
+func checkRegexTopics(cfg *collectorconfig.Config, _ Options, report func(string, string, string)) {
+	for _, r := range cfg.KafkaReceivers {
+		for _, t := range r.Topics {
+			if !collectorconfig.IsRegexTopic(t) && strings.ContainsAny(t, regexChars) {
+				report(r.ID, "receivers."+r.ID+".logs.topics", fmt.Sprintf(
+					"topic %q looks like a regular expression; prefix it with ^ to subscribe by pattern", t))
+			}
+		}
+	}
+}
+
+func checkExcludeTopics(cfg *collectorconfig.Config, _ Options, report func(string, string, string)) {
+	for _, r := range cfg.KafkaReceivers {
+		if len(r.ExcludeTopics) == 0 || slices.ContainsFunc(r.Topics, collectorconfig.IsRegexTopic) {
+			continue
+		}
+		report(r.ID, "receivers."+r.ID+".logs.exclude_topics", fmt.Sprintf(
+			"exclude_topics %v has no effect because none of the topics %v is a regex", r.ExcludeTopics, r.Topics))
+	}
+}
+
+func checkDuplicateConsumers(cfg *collectorconfig.Config, _ Options, report func(string, string, string)) {
+	type key struct{ group, topic string }
+	consumers := map[key][]string{}
+	var keys []key
+	for _, r := range cfg.KafkaReceivers {
+		for _, t := range r.Topics {
+			k := key{r.EffectiveGroupID(), t}
+			if len(consumers[k]) == 0 {
+				keys = append(keys, k)
+			}
+			consumers[k] = append(consumers[k], r.ID)
+		}
+	}
+	for _, k := range keys {
+		ids := consumers[k]
+		if len(ids) < 2 {
+			continue
+		}
+		for _, id := range ids[1:] {
+			report(id, "receivers."+id+".group_id", fmt.Sprintf(
+				"receivers %s consume topic %q in the same consumer group %q; each receives only part of the partitions",
+				strings.Join(ids, ", "), k.topic, k.group))
+		}
+	}
+}
+
+func checkBatchSize(cfg *collectorconfig.Config, _ Options, report func(string, string, string)) {
+	for _, e := range cfg.HECExporters {
+		queue := collectorconfig.Map(e.Raw, "sending_queue")
+		queueSize, ok1 := number(queue["queue_size"])
+		minSize, ok2 := number(collectorconfig.Map(queue, "batch")["min_size"])
+		if !ok1 || !ok2 || queueSize <= 0 {
+			continue
+		}
+		if minSize/queueSize >= batchToQueueRatio {
+			report(e.ID, "exporters."+e.ID+".sending_queue.batch.min_size", fmt.Sprintf(
+				"batch.min_size %v is %.0f%% of queue_size %v; keep it well below the queue size", minSize, 100*minSize/queueSize, queueSize))
+		}
+	}
+}
+
+func checkInsecureSkipVerify(cfg *collectorconfig.Config, _ Options, report func(string, string, string)) {
+	for _, kind := range []string{"receivers", "exporters", "extensions"} {
+		components := collectorconfig.Map(cfg.Raw, kind)
+		for _, id := range collectorconfig.SortedKeys(components) {
+			walk(collectorconfig.Map(components, id), kind+"."+id, func(path string, m map[string]any) {
+				if v, _ := m["insecure_skip_verify"].(bool); v {
+					report(id, path+".insecure_skip_verify", "certificate verification is disabled; use a CA file instead")
+				}
+			})
+		}
+	}
+}
+
+func checkGroupIDWhenScaling(cfg *collectorconfig.Config, opts Options, report func(string, string, string)) {
+	if opts.Replicas <= 1 {
+		return
+	}
+	for _, r := range cfg.KafkaReceivers {
+		if r.GroupID == "" {
+			report(r.ID, "receivers."+r.ID+".group_id", fmt.Sprintf(
+				"group_id is not set while %d instances run this config; all instances join the default group %q, set an explicit group_id",
+				opts.Replicas, collectorconfig.DefaultGroupID))
+		}
+	}
+}
+
+func walk(m map[string]any, path string, fn func(path string, m map[string]any)) {
+	if m == nil {
+		return
+	}
+	fn(path, m)
+	for _, k := range collectorconfig.SortedKeys(m) {
+		if child, ok := m[k].(map[string]any); ok {
+			walk(child, path+"."+k, fn)
+		}
+	}
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,63 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+func load(t *testing.T, name string) *collectorconfig.Config {
+	cfg, err := collectorconfig.Load("testdata/" + name)
+	require.NoError(t, err)
+	return cfg
+}
+
+func rulesOf(findings []Finding) []string {
+	var ids []string
+	for _, f := range findings {
+		ids = append(ids, f.Rule)
+	}
+	return ids
+}
+
+func TestCleanConfig(t *testing.T) {
+	assert.Empty(t, Run(load(t, "clean.yaml"), Options{Replicas: 3}))
+}
+
+func TestMisconfiguredConfig(t *testing.T) {
+	findings := Run(load(t, "misconfigured.yaml"), Options{Replicas: 3})
+
+	assert.Equal(t, []string{
+		"SOC001", "SOC001", "SOC004",
+		"SOC002", "SOC003", "SOC005", "SOC006", "SOC006", "SOC007", "SOC007",
+	}, rulesOf(findings))
+
+	assert.Equal(t, Finding{
+		Rule:      "SOC001",
+		Severity:  SeverityError,
+		Component: "splunk_hec",
+		Path:      "exporters.splunk_hec.otel_attrs_to_hec_metadata.index",
+		Message:   `index is mapped from "kafka.header.index", but header "index" is not listed in header_extraction.headers (with extract_headers: true) of kafka/b`,
+	}, findings[0])
+	assert.Equal(t, "kafka/b", findings[2].Component)
+	assert.Contains(t, findings[2].Message, `consume topic "shared" in the same consumer group "otel-collector"`)
+	assert.Contains(t, findings[5].Message, "80%")
+}
+
+func TestOptions(t *testing.T) {
+	cfg := load(t, "misconfigured.yaml")
+
+	findings := Run(cfg, Options{Replicas: 1, Disabled: []string{"SOC001", "insecure-skip-verify"}})
+	assert.Equal(t, []string{"SOC004", "SOC002", "SOC003", "SOC005"}, rulesOf(findings))
+}
+
+func TestParseSeverity(t *testing.T) {
+	sev, err := ParseSeverity("Warning")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarning, sev)
+	_, err = ParseSeverity("fatal")
+	require.Error(t, err)
+}
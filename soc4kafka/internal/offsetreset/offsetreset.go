@@ -0,0 +1,205 @@
+// Package offsetreset plans and applies offset resets of the consumer group of a kafka receiver, e.g. to replay the
+// records of a time window after an outage.
+package offsetreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+// ErrActiveMembers is returned when the consumer group still has active members. The collectors consuming with the
+// group must be stopped before its offsets can be reset.
+var ErrActiveMembers = errors.New("the consumer group has active members, stop the collectors consuming with it first")
+
+// Cluster is the subset of the Kafka admin API used to reset offsets.
+type Cluster interface {
+	Topics(ctx context.Context) (map[string]int, error)
+	StartOffsets(ctx context.Context, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	EndOffsets(ctx context.Context, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	OffsetsForTime(ctx context.Context, partitions []kafkaclient.TopicPartition, t time.Time) (map[kafkaclient.TopicPartition]int64, error)
+	CommittedOffsets(ctx context.Context, group string, partitions []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error)
+	DescribeGroup(ctx context.Context, group string) (kafkaclient.Group, error)
+	CommitOffsets(ctx context.Context, group string, offsets map[kafkaclient.TopicPartition]int64) error
+}
+
+// TargetKind selects how the new offsets are computed.
+type TargetKind string
+
+const (
+	ToEarliest TargetKind = "earliest"
+	ToLatest   TargetKind = "latest"
+	ToOffset   TargetKind = "offset"
+	ToTime     TargetKind = "time"
+)
+
+// Target is the position the offsets are reset to.
+type Target struct {
+	Kind TargetKind
+	// Offset is the offset for ToOffset, clamped to the offsets available in each partition.
+	Offset int64
+	// Time is the timestamp for ToTime: the first record at or after it is consumed next.
+	Time time.Time
+}
+
+// String describes the target.
+func (t Target) String() string {
+	switch t.Kind {
+	case ToOffset:
+		return fmt.Sprintf("offset %d", t.Offset)
+	case ToTime:
+		return t.Time.UTC().Format(time.RFC3339)
+	default:
+		return string(t.Kind)
+	}
+}
+
+// Scope restricts the reset to a subset of the topics and partitions consumed by the receiver. Empty fields select
+// everything.
+type Scope struct {
+	Topics     []string
+	Partitions []int32
+}
+
+// Change is the planned reset of a partition.
+type Change struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	// Current is the committed offset, kafkaclient.NoOffset when the group did not commit an offset.
+	Current  int64 `json:"current"`
+	Target   int64 `json:"target"`
+	LogStart int64 `json:"log_start"`
+	LogEnd   int64 `json:"log_end"`
+	// Note explains adjustments of the requested target.
+	Note string `json:"note,omitempty"`
+}
+
+// Plan is the reset of the consumer group of a receiver.
+type Plan struct {
+	Receiver string   `json:"receiver"`
+	Group    string   `json:"group"`
+	Target   string   `json:"target"`
+	Changes  []Change `json:"changes"`
+	// Members is the number of active members of the group, -1 when the group could not be described.
+	Members int `json:"members"`
+	// Warning explains why the plan cannot be applied as is.
+	Warning string `json:"warning,omitempty"`
+}
+
+// NewPlan computes the new offsets of the partitions in scope.
+func NewPlan(ctx context.Context, cluster Cluster, r collectorconfig.KafkaReceiver, scope Scope, target Target) (Plan, error) {
+	plan := Plan{Receiver: r.ID, Group: r.EffectiveGroupID(), Target: target.String(), Changes: []Change{}}
+
+	topics, err := cluster.Topics(ctx)
+	if err != nil {
+		return plan, fmt.Errorf("fetching metadata: %w", err)
+	}
+	consumed, err := r.ResolveTopics(collectorconfig.SortedKeys(topics))
+	if err != nil {
+		return plan, err
+	}
+	selected := consumed
+	if len(scope.Topics) > 0 {
+		selected = nil
+		for _, t := range scope.Topics {
+			if !slices.Contains(consumed, t) {
+				return plan, fmt.Errorf("topic %s is not consumed by %s", t, r.ID)
+			}
+			if _, ok := topics[t]; !ok {
+				return plan, fmt.Errorf("topic %s does not exist", t)
+			}
+			selected = append(selected, t)
+		}
+	}
+	var partitions []kafkaclient.TopicPartition
+	for _, tp := range kafkaclient.Partitions(topics, selected) {
+		if len(scope.Partitions) == 0 || slices.Contains(scope.Partitions, tp.Partition) {
+			partitions = append(partitions, tp)
+		}
+	}
+	if len(partitions) == 0 {
+		return plan, errors.New("no partition matches the scope")
+	}
+
+	start, err := cluster.StartOffsets(ctx, partitions)
+	if err != nil {
+		return plan, fmt.Errorf("listing log start offsets: %w", err)
+	}
+	end, err := cluster.EndOffsets(ctx, partitions)
+	if err != nil {
+		return plan, fmt.Errorf("listing log end offsets: %w", err)
+	}
+	committed, err := cluster.CommittedOffsets(ctx, plan.Group, partitions)
+	if err != nil {
+		return plan, fmt.Errorf("listing offsets of group %s: %w", plan.Group, err)
+	}
+	var byTime map[kafkaclient.TopicPartition]int64
+	if target.Kind == ToTime {
+		if byTime, err = cluster.OffsetsForTime(ctx, partitions, target.Time); err != nil {
+			return plan, fmt.Errorf("looking up offsets for %s: %w", target, err)
+		}
+	}
+
+	for _, tp := range partitions {
+		c := Change{Topic: tp.Topic, Partition: tp.Partition, Current: kafkaclient.NoOffset, LogStart: start[tp], LogEnd: end[tp]}
+		if offset, ok := committed[tp]; ok {
+			c.Current = offset
+		}
+		switch target.Kind {
+		case ToEarliest:
+			c.Target = c.LogStart
+		case ToLatest:
+			c.Target = c.LogEnd
+		case ToOffset:
+			c.Target = target.Offset
+			if c.Target < c.LogStart {
+				c.Target, c.Note = c.LogStart, "below the log start offset, using the log start offset"
+			} else if c.Target > c.LogEnd {
+				c.Target, c.Note = c.LogEnd, "above the log end offset, using the log end offset"
+			}
+		case ToTime:
+			if offset, ok := byTime[tp]; ok && offset != kafkaclient.NoOffset {
+				c.Target = offset
+			} else {
+				c.Target, c.Note = c.LogEnd, "no record at or after the timestamp, using the log end offset"
+			}
+		default:
+			return plan, fmt.Errorf("unknown target %q", target.Kind)
+		}
+		plan.Changes = append(plan.Changes, c)
+	}
+
+	plan.Members = -1
+	group, err := cluster.DescribeGroup(ctx, plan.Group)
+	switch {
+	case err != nil:
+		plan.Warning = fmt.Sprintf("cannot verify that the group has no active members: %v", err)
+	case len(group.Members) > 0:
+		plan.Members = len(group.Members)
+		plan.Warning = fmt.Sprintf("%v: %d member(s) in state %s", ErrActiveMembers, len(group.Members), group.State)
+	default:
+		plan.Members = 0
+	}
+	return plan, nil
+}
+
+// Apply commits the planned offsets. It refuses when the group has active members or when they cannot be verified.
+func Apply(ctx context.Context, cluster Cluster, plan Plan) error {
+	group, err := cluster.DescribeGroup(ctx, plan.Group)
+	if err != nil {
+		return fmt.Errorf("cannot verify that group %s has no active members: %w", plan.Group, err)
+	}
+	if len(group.Members) > 0 {
+		return ErrActiveMembers
+	}
+	offsets := make(map[kafkaclient.TopicPartition]int64, len(plan.Changes))
+	for _, c := range plan.Changes {
+		offsets[kafkaclient.TopicPartition{Topic: c.Topic, Partition: c.Partition}] = c.Target
+	}
+	return cluster.CommitOffsets(ctx, plan.Group, offsets)
+}
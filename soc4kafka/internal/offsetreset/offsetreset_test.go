@@ -0,0 +1,154 @@
+package offsetreset
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+type fakeCluster struct {
+	topics    map[string]int
+	start     map[kafkaclient.TopicPartition]int64
+	end       map[kafkaclient.TopicPartition]int64
+	byTime    map[kafkaclient.TopicPartition]int64
+	committed map[kafkaclient.TopicPartition]int64
+	group     kafkaclient.Group
+	groupErr  error
+	commits   map[kafkaclient.TopicPartition]int64
+}
+
+func (f *fakeCluster) Topics(context.Context) (map[string]int, error) { return f.topics, nil }
+
+func (f *fakeCluster) StartOffsets(context.Context, []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.start, nil
+}
+
+func (f *fakeCluster) EndOffsets(context.Context, []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.end, nil
+}
+
+func (f *fakeCluster) OffsetsForTime(context.Context, []kafkaclient.TopicPartition, time.Time) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.byTime, nil
+}
+
+func (f *fakeCluster) CommittedOffsets(context.Context, string, []kafkaclient.TopicPartition) (map[kafkaclient.TopicPartition]int64, error) {
+	return f.committed, nil
+}
+
+func (f *fakeCluster) DescribeGroup(context.Context, string) (kafkaclient.Group, error) {
+	return f.group, f.groupErr
+}
+
+func (f *fakeCluster) CommitOffsets(_ context.Context, _ string, offsets map[kafkaclient.TopicPartition]int64) error {
+	f.commits = offsets
+	return nil
+}
+
+func tp(topic string, partition int32) kafkaclient.TopicPartition {
+	return kafkaclient.TopicPartition{Topic: topic, Partition: partition}
+}
+
+func newCluster() *fakeCluster {
+	return &fakeCluster{
+		topics:    map[string]int{"logs": 2, "other": 1},
+		start:     map[kafkaclient.TopicPartition]int64{tp("logs", 0): 10, tp("logs", 1): 0},
+		end:       map[kafkaclient.TopicPartition]int64{tp("logs", 0): 100, tp("logs", 1): 40},
+		byTime:    map[kafkaclient.TopicPartition]int64{tp("logs", 0): 55, tp("logs", 1): kafkaclient.NoOffset},
+		committed: map[kafkaclient.TopicPartition]int64{tp("logs", 0): 100},
+		group:     kafkaclient.Group{ID: "soc4kafka", State: "Empty", Members: []kafkaclient.Member{}},
+	}
+}
+
+var receiver = collectorconfig.KafkaReceiver{ID: "kafka/main", Topics: []string{"logs"}, GroupID: "soc4kafka"}
+
+func targets(plan Plan) []int64 {
+	var out []int64
+	for _, c := range plan.Changes {
+		out = append(out, c.Target)
+	}
+	return out
+}
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		target   Target
+		expected []int64
+		notes    []string
+	}{
+		{target: Target{Kind: ToEarliest}, expected: []int64{10, 0}, notes: []string{"", ""}},
+		{target: Target{Kind: ToLatest}, expected: []int64{100, 40}, notes: []string{"", ""}},
+		{
+			target:   Target{Kind: ToOffset, Offset: 50},
+			expected: []int64{50, 40},
+			notes:    []string{"", "above the log end offset, using the log end offset"},
+		},
+		{
+			target:   Target{Kind: ToOffset, Offset: 5},
+			expected: []int64{10, 5},
+			notes:    []string{"below the log start offset, using the log start offset", ""},
+		},
+		{
+			target:   Target{Kind: ToTime, Time: time.Date(2025, 1, 31, 22, 0, 0, 0, time.UTC)},
+			expected: []int64{55, 40},
+			notes:    []string{"", "no record at or after the timestamp, using the log end offset"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target.String(), func(t *testing.T) {
+			plan, err := NewPlan(context.Background(), newCluster(), receiver, Scope{}, tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, targets(plan))
+			assert.Equal(t, tt.notes, []string{plan.Changes[0].Note, plan.Changes[1].Note})
+			assert.Equal(t, int64(100), plan.Changes[0].Current)
+			assert.Equal(t, kafkaclient.NoOffset, plan.Changes[1].Current)
+			assert.Zero(t, plan.Members)
+			assert.Empty(t, plan.Warning)
+		})
+	}
+}
+
+func TestNewPlanScope(t *testing.T) {
+	plan, err := NewPlan(context.Background(), newCluster(), receiver, Scope{Topics: []string{"logs"}, Partitions: []int32{1}}, Target{Kind: ToEarliest})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, int32(1), plan.Changes[0].Partition)
+
+	_, err = NewPlan(context.Background(), newCluster(), receiver, Scope{Topics: []string{"other"}}, Target{Kind: ToEarliest})
+	assert.EqualError(t, err, "topic other is not consumed by kafka/main")
+
+	_, err = NewPlan(context.Background(), newCluster(), receiver, Scope{Partitions: []int32{7}}, Target{Kind: ToEarliest})
+	assert.EqualError(t, err, "no partition matches the scope")
+}
+
+func TestApply(t *testing.T) {
+	cluster := newCluster()
+	plan, err := NewPlan(context.Background(), cluster, receiver, Scope{}, Target{Kind: ToEarliest})
+	require.NoError(t, err)
+	require.NoError(t, Apply(context.Background(), cluster, plan))
+	assert.Equal(t, map[kafkaclient.TopicPartition]int64{tp("logs", 0): 10, tp("logs", 1): 0}, cluster.commits)
+}
+
+func TestApplyRefusesActiveMembers(t *testing.T) {
+	cluster := newCluster()
+	cluster.group = kafkaclient.Group{ID: "soc4kafka", State: "Stable", Members: []kafkaclient.Member{{ConsumerID: "consumer-1"}}}
+	plan, err := NewPlan(context.Background(), cluster, receiver, Scope{}, Target{Kind: ToEarliest})
+	require.NoError(t, err)
+	assert.Equal(t, 1, plan.Members)
+	assert.Contains(t, plan.Warning, "1 member(s) in state Stable")
+	assert.ErrorIs(t, Apply(context.Background(), cluster, plan), ErrActiveMembers)
+	assert.Nil(t, cluster.commits)
+
+	cluster.groupErr = errors.New("not authorized")
+	plan, err = NewPlan(context.Background(), cluster, receiver, Scope{}, Target{Kind: ToEarliest})
+	require.NoError(t, err)
+	assert.Equal(t, -1, plan.Members)
+	assert.EqualError(t, Apply(context.Background(), cluster, plan), "cannot verify that group soc4kafka has no active members: not authorized")
+	assert.Nil(t, cluster.commits)
+}
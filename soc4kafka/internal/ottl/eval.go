@@ -0,0 +1,454 @@
+package ottl
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log record with its resource and scope, the values being strings, int64, float64, bool, []byte,
+// []any and map[string]any as in pcommon.Value.AsRaw.
+type Record struct {
+	Resource        map[string]any
+	ScopeName       string
+	ScopeVersion    string
+	ScopeAttributes map[string]any
+	Attributes      map[string]any
+	Body            any
+	Time            time.Time
+	ObservedTime    time.Time
+	SeverityText    string
+	SeverityNumber  int64
+
+	// cache is the log.cache map, reset for each record by the processors.
+	cache map[string]any
+}
+
+// NewRecord returns a record with empty attribute maps.
+func NewRecord() *Record {
+	return &Record{Resource: map[string]any{}, ScopeAttributes: map[string]any{}, Attributes: map[string]any{}}
+}
+
+// Execute runs the statement on the record: its where clause is evaluated first, then the editor when the clause
+// holds. It reports whether the editor ran.
+func (s *Statement) Execute(r *Record) (bool, error) {
+	if s.where != nil {
+		ok, err := evalCondition(r, s.where)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, editors[s.editor](r, s.args)
+}
+
+// Eval evaluates the condition on the record.
+func (c *Condition) Eval(r *Record) (bool, error) {
+	return evalCondition(r, c.c)
+}
+
+// ResetCache clears the log.cache map, which lives as long as the processing of one record by a processor.
+func (r *Record) ResetCache() {
+	r.cache = nil
+}
+
+func evalCondition(r *Record, c condition) (bool, error) {
+	switch c := c.(type) {
+	case boolOp:
+		left, err := evalCondition(r, c.left)
+		if err != nil {
+			return false, err
+		}
+		// Both operands are short circuited, as in the collector.
+		if c.op == "and" && !left || c.op == "or" && left {
+			return left, nil
+		}
+		return evalCondition(r, c.right)
+	case notOp:
+		v, err := evalCondition(r, c.c)
+		return !v, err
+	case comparison:
+		left, err := eval(r, c.left)
+		if err != nil {
+			return false, err
+		}
+		right, err := eval(r, c.right)
+		if err != nil {
+			return false, err
+		}
+		return compare(c.op, left, right), nil
+	case valueCondition:
+		v, err := eval(r, c.e)
+		if err != nil {
+			return false, err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return false, fmt.Errorf("expected a boolean but got %s", typeName(v))
+		}
+		return b, nil
+	}
+	return false, fmt.Errorf("invalid condition %T", c)
+}
+
+func eval(r *Record, e expr) (any, error) {
+	switch e := e.(type) {
+	case literal:
+		return e.value, nil
+	case listExpr:
+		items := make([]any, 0, len(e.items))
+		for _, item := range e.items {
+			v, err := eval(r, item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case mapExpr:
+		m := make(map[string]any, len(e.keys))
+		for i, k := range e.keys {
+			v, err := eval(r, e.values[i])
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case call:
+		v, err := converters[e.name](r, e.args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.name, err)
+		}
+		return index(r, v, e.keys)
+	case path:
+		v, err := get(r, e)
+		if err != nil {
+			return nil, err
+		}
+		return index(r, v, e.keys)
+	}
+	return nil, fmt.Errorf("invalid expression %T", e)
+}
+
+func get(r *Record, p path) (any, error) {
+	switch p.context + "." + p.fields[0] {
+	case "log.body":
+		return r.Body, nil
+	case "log.attributes":
+		return r.Attributes, nil
+	case "log.cache":
+		if r.cache == nil {
+			r.cache = map[string]any{}
+		}
+		return r.cache, nil
+	case "log.time":
+		return r.Time, nil
+	case "log.observed_time":
+		return r.ObservedTime, nil
+	case "log.time_unix_nano":
+		return unixNano(r.Time), nil
+	case "log.observed_time_unix_nano":
+		return unixNano(r.ObservedTime), nil
+	case "log.severity_text":
+		return r.SeverityText, nil
+	case "log.severity_number":
+		return r.SeverityNumber, nil
+	case "resource.attributes":
+		return r.Resource, nil
+	case "scope.name":
+		return r.ScopeName, nil
+	case "scope.version":
+		return r.ScopeVersion, nil
+	case "scope.attributes":
+		return r.ScopeAttributes, nil
+	}
+	return nil, fmt.Errorf("unknown path %s.%s", p.context, p.fields[0])
+}
+
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// index resolves the keys of a path, a missing map key resolving to nil.
+func index(r *Record, v any, keys []expr) (any, error) {
+	for _, k := range keys {
+		key, err := eval(r, k)
+		if err != nil {
+			return nil, err
+		}
+		switch c := v.(type) {
+		case map[string]any:
+			s, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("map keys must be strings, got %s", typeName(key))
+			}
+			v = c[s]
+		case []any:
+			i, ok := key.(int64)
+			if !ok {
+				return nil, fmt.Errorf("slice indexes must be integers, got %s", typeName(key))
+			}
+			if i < 0 || int(i) >= len(c) {
+				return nil, fmt.Errorf("index %d out of bounds", i)
+			}
+			v = c[i]
+		case nil:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("type %s does not support keys", typeName(v))
+		}
+	}
+	return v, nil
+}
+
+// set stores a value at a path, creating the intermediate maps of its keys.
+func set(r *Record, p path, v any) error {
+	v = deepCopy(v)
+	if len(p.keys) > 0 {
+		root, err := get(r, p)
+		if err != nil {
+			return err
+		}
+		if root == nil && p.context+"."+p.fields[0] == "log.body" {
+			root = map[string]any{}
+			r.Body = root
+		}
+		return setKey(r, root, p.keys, v)
+	}
+	switch p.context + "." + p.fields[0] {
+	case "log.body":
+		r.Body = v
+	case "log.attributes", "resource.attributes", "scope.attributes", "log.cache":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s.%s must be a map, got %s", p.context, p.fields[0], typeName(v))
+		}
+		switch p.context {
+		case ResourceContext:
+			r.Resource = m
+		case ScopeContext:
+			r.ScopeAttributes = m
+		default:
+			if p.fields[0] == "cache" {
+				r.cache = m
+			} else {
+				r.Attributes = m
+			}
+		}
+	case "log.time", "log.observed_time":
+		t, ok := v.(time.Time)
+		if !ok {
+			return fmt.Errorf("%s.%s must be a time, got %s", p.context, p.fields[0], typeName(v))
+		}
+		if p.fields[0] == "time" {
+			r.Time = t
+		} else {
+			r.ObservedTime = t
+		}
+	case "log.time_unix_nano", "log.observed_time_unix_nano":
+		n, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("%s.%s must be an integer, got %s", p.context, p.fields[0], typeName(v))
+		}
+		if p.fields[0] == "time_unix_nano" {
+			r.Time = time.Unix(0, n).UTC()
+		} else {
+			r.ObservedTime = time.Unix(0, n).UTC()
+		}
+	case "log.severity_text", "scope.name", "scope.version":
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s.%s must be a string, got %s", p.context, p.fields[0], typeName(v))
+		}
+		switch p.fields[0] {
+		case "severity_text":
+			r.SeverityText = s
+		case "name":
+			r.ScopeName = s
+		default:
+			r.ScopeVersion = s
+		}
+	case "log.severity_number":
+		n, ok := v.(int64)
+		if !ok {
+			return fmt.Errorf("%s.%s must be an integer, got %s", p.context, p.fields[0], typeName(v))
+		}
+		r.SeverityNumber = n
+	default:
+		return fmt.Errorf("path %s.%s cannot be set", p.context, p.fields[0])
+	}
+	return nil
+}
+
+func setKey(r *Record, container any, keys []expr, v any) error {
+	key, err := eval(r, keys[0])
+	if err != nil {
+		return err
+	}
+	if _, ok := v.(time.Time); ok {
+		return errors.New("a time cannot be stored in a map")
+	}
+	switch c := container.(type) {
+	case map[string]any:
+		s, ok := key.(string)
+		if !ok {
+			return fmt.Errorf("map keys must be strings, got %s", typeName(key))
+		}
+		if len(keys) == 1 {
+			c[s] = v
+			return nil
+		}
+		if _, ok := c[s].(map[string]any); !ok && c[s] == nil {
+			c[s] = map[string]any{}
+		}
+		return setKey(r, c[s], keys[1:], v)
+	case []any:
+		i, ok := key.(int64)
+		if !ok || i < 0 || int(i) >= len(c) {
+			return fmt.Errorf("invalid slice index %v", key)
+		}
+		if len(keys) == 1 {
+			c[i] = v
+			return nil
+		}
+		return setKey(r, c[i], keys[1:], v)
+	}
+	return fmt.Errorf("type %s does not support keys", typeName(container))
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []any:
+		l := make([]any, len(v))
+		for i, item := range v {
+			l[i] = deepCopy(item)
+		}
+		return l
+	case []byte:
+		return append([]byte(nil), v...)
+	}
+	return v
+}
+
+// compare implements the OTTL comparison operators: numbers compare across integer and float, strings, booleans
+// and times compare with values of their type, and values of different types are never equal.
+func compare(op string, left, right any) bool {
+	if l, ok := toFloat(left); ok {
+		if r, ok := toFloat(right); ok {
+			return ordered(op, cmpFloat(l, r))
+		}
+	}
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			return ordered(op, strings.Compare(l, r))
+		}
+	case time.Time:
+		if r, ok := right.(time.Time); ok {
+			return ordered(op, l.Compare(r))
+		}
+	case bool:
+		if r, ok := right.(bool); ok && (op == "==" || op == "!=") {
+			return (l == r) == (op == "==")
+		}
+	}
+	if op == "==" || op == "!=" {
+		return reflect.DeepEqual(left, right) == (op == "==")
+	}
+	return false
+}
+
+func ordered(op string, c int) bool {
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v)
+	}
+	return 0, false
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return "string"
+	case int64:
+		return "int"
+	case float64:
+		return "double"
+	case bool:
+		return "bool"
+	case []byte:
+		return "bytes"
+	case []any:
+		return "slice"
+	case map[string]any:
+		return "map"
+	case time.Time:
+		return "time"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// String formats a value as the String converter does.
+func String(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return fmt.Sprintf("%x", v)
+	}
+	return jsonString(v)
+}
@@ -0,0 +1,659 @@
+package ottl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/strptime"
+)
+
+type editor func(r *Record, args []expr) error
+
+type converter func(r *Record, args []expr) (any, error)
+
+var editors map[string]editor
+
+var converters map[string]converter
+
+func init() {
+	editors = map[string]editor{
+		"set":                  editSet,
+		"delete_key":           editDeleteKey,
+		"delete_matching_keys": editDeleteMatchingKeys,
+		"keep_keys":            editKeepKeys,
+		"keep_matching_keys":   editKeepMatchingKeys,
+		"merge_maps":           editMergeMaps,
+		"replace_pattern":      editReplacePattern,
+		"replace_all_patterns": editReplaceAllPatterns,
+		"truncate_all":         editTruncateAll,
+	}
+	converters = map[string]converter{
+		"Concat":          convConcat,
+		"Double":          convDouble,
+		"ExtractPatterns": convExtractPatterns,
+		"Int":             convInt,
+		"IsBool":          isType("bool"),
+		"IsDouble":        isType("double"),
+		"IsInt":           isType("int"),
+		"IsList":          isType("slice"),
+		"IsMap":           isType("map"),
+		"IsMatch":         convIsMatch,
+		"IsString":        isType("string"),
+		"Len":             convLen,
+		"Now":             convNow,
+		"ParseJSON":       convParseJSON,
+		"Split":           convSplit,
+		"String":          convString,
+		"Substring":       convSubstring,
+		"Time":            convTime,
+		"ToLowerCase":     convCase(strings.ToLower),
+		"ToUpperCase":     convCase(strings.ToUpper),
+	}
+}
+
+// Editors returns the names of the editors the interpreter implements, sorted.
+func Editors() []string {
+	return slices.Sorted(maps.Keys(editors))
+}
+
+// Converters returns the names of the converters the interpreter implements, sorted.
+func Converters() []string {
+	return slices.Sorted(maps.Keys(converters))
+}
+
+func arity(args []expr, min, max int) error {
+	if len(args) < min || len(args) > max {
+		if min == max {
+			return fmt.Errorf("expected %d arguments, got %d", min, len(args))
+		}
+		return fmt.Errorf("expected %d to %d arguments, got %d", min, max, len(args))
+	}
+	return nil
+}
+
+func target(args []expr) (path, error) {
+	p, ok := args[0].(path)
+	if !ok {
+		return path{}, errors.New("the first argument must be a path")
+	}
+	return p, nil
+}
+
+func stringArg(r *Record, e expr) (string, error) {
+	v, err := eval(r, e)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expected string but got %s", typeName(v))
+	}
+	return s, nil
+}
+
+func intArg(r *Record, e expr) (int64, error) {
+	v, err := eval(r, e)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("expected int but got %s", typeName(v))
+	}
+	return n, nil
+}
+
+// mapTarget evaluates the first argument of the editors working on a map.
+func mapTarget(r *Record, args []expr) (map[string]any, error) {
+	p, err := target(args)
+	if err != nil {
+		return nil, err
+	}
+	v, err := eval(r, p)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected map but got %s", typeName(v))
+	}
+	return m, nil
+}
+
+var regexCache sync.Map
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+func regexArg(r *Record, e expr) (*regexp.Regexp, error) {
+	pattern, err := stringArg(r, e)
+	if err != nil {
+		return nil, err
+	}
+	return compile(pattern)
+}
+
+func editSet(r *Record, args []expr) error {
+	if err := arity(args, 2, 2); err != nil {
+		return err
+	}
+	p, err := target(args)
+	if err != nil {
+		return err
+	}
+	v, err := eval(r, args[1])
+	if err != nil || v == nil {
+		return err
+	}
+	return set(r, p, v)
+}
+
+func editDeleteKey(r *Record, args []expr) error {
+	if err := arity(args, 2, 2); err != nil {
+		return err
+	}
+	m, err := mapTarget(r, args)
+	if err != nil {
+		return err
+	}
+	key, err := stringArg(r, args[1])
+	if err != nil {
+		return err
+	}
+	delete(m, key)
+	return nil
+}
+
+func editDeleteMatchingKeys(r *Record, args []expr) error {
+	return filterKeys(r, args, false)
+}
+
+func editKeepMatchingKeys(r *Record, args []expr) error {
+	return filterKeys(r, args, true)
+}
+
+func filterKeys(r *Record, args []expr, keep bool) error {
+	if err := arity(args, 2, 2); err != nil {
+		return err
+	}
+	m, err := mapTarget(r, args)
+	if err != nil {
+		return err
+	}
+	re, err := regexArg(r, args[1])
+	if err != nil {
+		return err
+	}
+	for k := range m {
+		if re.MatchString(k) != keep {
+			delete(m, k)
+		}
+	}
+	return nil
+}
+
+func editKeepKeys(r *Record, args []expr) error {
+	if err := arity(args, 2, 2); err != nil {
+		return err
+	}
+	m, err := mapTarget(r, args)
+	if err != nil {
+		return err
+	}
+	v, err := eval(r, args[1])
+	if err != nil {
+		return err
+	}
+	keys, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("expected a list of keys but got %s", typeName(v))
+	}
+	keep := map[string]bool{}
+	for _, k := range keys {
+		keep[String(k)] = true
+	}
+	for k := range m {
+		if !keep[k] {
+			delete(m, k)
+		}
+	}
+	return nil
+}
+
+func editMergeMaps(r *Record, args []expr) error {
+	if err := arity(args, 3, 3); err != nil {
+		return err
+	}
+	m, err := mapTarget(r, args)
+	if err != nil {
+		return err
+	}
+	v, err := eval(r, args[1])
+	if err != nil {
+		return err
+	}
+	source, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("expected map but got %s", typeName(v))
+	}
+	strategy, err := stringArg(r, args[2])
+	if err != nil {
+		return err
+	}
+	if strategy != "insert" && strategy != "update" && strategy != "upsert" {
+		return fmt.Errorf("invalid merge strategy %q, expected insert, update or upsert", strategy)
+	}
+	for k, item := range source {
+		_, exists := m[k]
+		if strategy == "upsert" || strategy == "insert" && !exists || strategy == "update" && exists {
+			m[k] = deepCopy(item)
+		}
+	}
+	return nil
+}
+
+func editReplacePattern(r *Record, args []expr) error {
+	if err := arity(args, 3, 3); err != nil {
+		return err
+	}
+	p, err := target(args)
+	if err != nil {
+		return err
+	}
+	v, err := eval(r, p)
+	if err != nil {
+		return err
+	}
+	re, err := regexArg(r, args[1])
+	if err != nil {
+		return err
+	}
+	replacement, err := stringArg(r, args[2])
+	if err != nil {
+		return err
+	}
+	s, ok := v.(string)
+	if !ok || !re.MatchString(s) {
+		return nil
+	}
+	return set(r, p, re.ReplaceAllString(s, replacement))
+}
+
+func editReplaceAllPatterns(r *Record, args []expr) error {
+	if err := arity(args, 4, 4); err != nil {
+		return err
+	}
+	m, err := mapTarget(r, args)
+	if err != nil {
+		return err
+	}
+	mode, err := stringArg(r, args[1])
+	if err != nil {
+		return err
+	}
+	re, err := regexArg(r, args[2])
+	if err != nil {
+		return err
+	}
+	replacement, err := stringArg(r, args[3])
+	if err != nil {
+		return err
+	}
+	switch mode {
+	case "value":
+		for k, v := range m {
+			if s, ok := v.(string); ok {
+				m[k] = re.ReplaceAllString(s, replacement)
+			}
+		}
+	case "key":
+		// Keys are collected first, a renamed key must not be renamed again.
+		for _, k := range collectKeys(m) {
+			if renamed := re.ReplaceAllString(k, replacement); renamed != k {
+				m[renamed] = m[k]
+				delete(m, k)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid mode %q, expected key or value", mode)
+	}
+	return nil
+}
+
+func editTruncateAll(r *Record, args []expr) error {
+	if err := arity(args, 2, 2); err != nil {
+		return err
+	}
+	m, err := mapTarget(r, args)
+	if err != nil {
+		return err
+	}
+	limit, err := intArg(r, args[1])
+	if err != nil {
+		return err
+	}
+	if limit < 0 {
+		return fmt.Errorf("invalid limit %d", limit)
+	}
+	for k, v := range m {
+		if s, ok := v.(string); ok && int64(len(s)) > limit {
+			m[k] = s[:limit]
+		}
+	}
+	return nil
+}
+
+func convConcat(r *Record, args []expr) (any, error) {
+	if err := arity(args, 2, 2); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	values, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list but got %s", typeName(v))
+	}
+	delimiter, err := stringArg(r, args[1])
+	if err != nil {
+		return nil, err
+	}
+	parts := make([]string, 0, len(values))
+	for _, item := range values {
+		parts = append(parts, String(item))
+	}
+	return strings.Join(parts, delimiter), nil
+}
+
+func convDouble(r *Record, args []expr) (any, error) {
+	if err := arity(args, 1, 1); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case bool:
+		if v {
+			return 1.0, nil
+		}
+		return 0.0, nil
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+func convInt(r *Record, args []expr) (any, error) {
+	if err := arity(args, 1, 1); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		return int64(v), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func convString(r *Record, args []expr) (any, error) {
+	if err := arity(args, 1, 1); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return String(v), nil
+}
+
+func convExtractPatterns(r *Record, args []expr) (any, error) {
+	if err := arity(args, 2, 2); err != nil {
+		return nil, err
+	}
+	re, err := regexArg(r, args[1])
+	if err != nil {
+		return nil, err
+	}
+	named := false
+	for _, name := range re.SubexpNames() {
+		named = named || name != ""
+	}
+	if !named {
+		return nil, errors.New("at least 1 named capture group must be supplied in the given regex")
+	}
+	s, err := stringArg(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	matches := re.FindStringSubmatch(s)
+	for i, name := range re.SubexpNames() {
+		if matches != nil && name != "" {
+			out[name] = matches[i]
+		}
+	}
+	return out, nil
+}
+
+func convIsMatch(r *Record, args []expr) (any, error) {
+	if err := arity(args, 2, 2); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	re, err := regexArg(r, args[1])
+	if err != nil {
+		return nil, err
+	}
+	switch v.(type) {
+	case nil:
+		return false, nil
+	case map[string]any, []any, []byte:
+		return nil, fmt.Errorf("unsupported type %s", typeName(v))
+	}
+	return re.MatchString(String(v)), nil
+}
+
+func isType(name string) converter {
+	return func(r *Record, args []expr) (any, error) {
+		if err := arity(args, 1, 1); err != nil {
+			return nil, err
+		}
+		v, err := eval(r, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return typeName(v) == name, nil
+	}
+}
+
+func convLen(r *Record, args []expr) (any, error) {
+	if err := arity(args, 1, 1); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case string:
+		return int64(len(v)), nil
+	case []any:
+		return int64(len(v)), nil
+	case map[string]any:
+		return int64(len(v)), nil
+	case []byte:
+		return int64(len(v)), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", typeName(v))
+}
+
+func convNow(_ *Record, args []expr) (any, error) {
+	if err := arity(args, 0, 0); err != nil {
+		return nil, err
+	}
+	return time.Now(), nil
+}
+
+func convParseJSON(r *Record, args []expr) (any, error) {
+	if err := arity(args, 1, 1); err != nil {
+		return nil, err
+	}
+	s, err := stringArg(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, err
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+		return v, nil
+	}
+	return nil, fmt.Errorf("could not convert parsed value of type %s to a map or a slice", typeName(v))
+}
+
+func convSplit(r *Record, args []expr) (any, error) {
+	if err := arity(args, 2, 2); err != nil {
+		return nil, err
+	}
+	v, err := eval(r, args[0])
+	if err != nil || v == nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected string but got %s", typeName(v))
+	}
+	delimiter, err := stringArg(r, args[1])
+	if err != nil {
+		return nil, err
+	}
+	var out []any
+	for _, part := range strings.Split(s, delimiter) {
+		out = append(out, part)
+	}
+	return out, nil
+}
+
+func convSubstring(r *Record, args []expr) (any, error) {
+	if err := arity(args, 3, 3); err != nil {
+		return nil, err
+	}
+	s, err := stringArg(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	start, err := intArg(r, args[1])
+	if err != nil {
+		return nil, err
+	}
+	length, err := intArg(r, args[2])
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || length <= 0 || start+length > int64(len(s)) {
+		return nil, fmt.Errorf("invalid range for substring, start=%d, length=%d, source length=%d", start, length, len(s))
+	}
+	return s[start : start+length], nil
+}
+
+func convTime(r *Record, args []expr) (any, error) {
+	if err := arity(args, 2, 3); err != nil {
+		return nil, err
+	}
+	s, err := stringArg(r, args[0])
+	if err != nil {
+		return nil, err
+	}
+	format, err := stringArg(r, args[1])
+	if err != nil {
+		return nil, err
+	}
+	if format == "" {
+		return nil, errors.New("format cannot be nil")
+	}
+	loc := time.UTC
+	if len(args) == 3 {
+		name, err := stringArg(r, args[2])
+		if err != nil {
+			return nil, err
+		}
+		if loc, err = time.LoadLocation(name); err != nil {
+			return nil, err
+		}
+	}
+	return strptime.Parse(format, s, loc)
+}
+
+func convCase(f func(string) string) converter {
+	return func(r *Record, args []expr) (any, error) {
+		if err := arity(args, 1, 1); err != nil {
+			return nil, err
+		}
+		s, err := stringArg(r, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return f(s), nil
+	}
+}
+
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func collectKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
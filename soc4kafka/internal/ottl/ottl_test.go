@@ -0,0 +1,127 @@
+package ottl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, r *Record, statements ...string) {
+	t.Helper()
+	for _, s := range statements {
+		st, err := ParseStatement(s, "")
+		require.NoError(t, err, s)
+		_, err = st.Execute(r)
+		require.NoError(t, err, s)
+	}
+}
+
+func TestTimestampStatements(t *testing.T) {
+	r := NewRecord()
+	r.Body = "[2024-05-01 10:20:30] request done"
+	run(t, r,
+		`set(log.attributes["extracted_ts"], ExtractPatterns(log.body, "\\[(?P<timestamp>[^\\]]+)\\]"))`,
+		`set(log.time, Time(log.attributes["extracted_ts"]["timestamp"], "%Y-%m-%d %H:%M:%S", "Europe/Paris"))`,
+		`delete_key(log.attributes, "extracted_ts")`,
+	)
+	assert.Equal(t, time.Date(2024, 5, 1, 8, 20, 30, 0, time.UTC), r.Time.UTC())
+	assert.Empty(t, r.Attributes)
+
+	r = NewRecord()
+	r.Body = "no timestamp"
+	run(t, r, `set(log.attributes["extracted_ts"], ExtractPatterns(log.body, "\\[(?P<timestamp>[^\\]]+)\\]"))`)
+	assert.Equal(t, map[string]any{"extracted_ts": map[string]any{}}, r.Attributes)
+	st, err := ParseStatement(`set(log.time, Time(log.attributes["extracted_ts"]["timestamp"], "%Y", "UTC"))`, "")
+	require.NoError(t, err)
+	_, err = st.Execute(r)
+	require.EqualError(t, err, "Time: expected string but got nil")
+	assert.True(t, r.Time.IsZero())
+}
+
+func TestEditors(t *testing.T) {
+	r := NewRecord()
+	r.Body = `{"user": {"name": "ada", "id": 7}, "msg": "login from 10.0.0.1"}`
+	r.Resource["kafka.header.tenant"] = "acme"
+	run(t, r,
+		`merge_maps(log.attributes, ParseJSON(log.body), "upsert")`,
+		`set(log.attributes["user"]["name"], ToUpperCase(log.attributes["user"]["name"]))`,
+		`replace_pattern(log.attributes["msg"], "\\d+\\.\\d+\\.\\d+\\.\\d+", "<ip>")`,
+		`set(resource.attributes["com.splunk.index"], Concat(["tenant", resource.attributes["kafka.header.tenant"]], "_"))`,
+		`set(log.attributes["id"], Int(log.attributes["user"]["id"])) where log.attributes["user"]["id"] > 5`,
+		`set(log.attributes["skipped"], true) where log.attributes["user"]["id"] > 10 or log.body == nil`,
+		`delete_matching_keys(log.attributes, "^ms")`,
+		`set(log.severity_text, "INFO")`,
+	)
+	assert.Equal(t, map[string]any{"user": map[string]any{"name": "ADA", "id": 7.0}, "id": int64(7)}, r.Attributes)
+	assert.Equal(t, "tenant_acme", r.Resource["com.splunk.index"])
+	assert.Equal(t, "INFO", r.SeverityText)
+
+	r = NewRecord()
+	r.Attributes = map[string]any{"a": "x", "b": "y", "c": "z"}
+	run(t, r, `keep_keys(log.attributes, ["a", "b"])`, `replace_all_patterns(log.attributes, "key", "^a$", "renamed")`,
+		`truncate_all(log.attributes, 0)`)
+	assert.Equal(t, map[string]any{"renamed": "", "b": ""}, r.Attributes)
+}
+
+func TestImplicitContext(t *testing.T) {
+	st, err := ParseStatement(`set(attributes["from"], resource.attributes["host.name"])`, LogContext)
+	require.NoError(t, err)
+	r := NewRecord()
+	r.Resource["host.name"] = "collector-1"
+	_, err = st.Execute(r)
+	require.NoError(t, err)
+	assert.Equal(t, "collector-1", r.Attributes["from"])
+
+	_, err = ParseStatement(`set(attributes["from"], "x")`, "")
+	require.EqualError(t, err, "path attributes at offset 4 must start with a context such as log or resource")
+}
+
+func TestConditions(t *testing.T) {
+	r := NewRecord()
+	r.Body = "GET /health 200"
+	r.Attributes["status"] = int64(200)
+	for text, want := range map[string]bool{
+		`IsMatch(log.body, "/health")`:                            true,
+		`not IsMatch(log.body, "/health")`:                        false,
+		`log.attributes["status"] == 200.0`:                       true,
+		`log.attributes["status"] >= 500 or log.body == "x"`:      false,
+		`(log.attributes["status"] < 300) and IsString(log.body)`: true,
+		`log.attributes["missing"] == nil`:                        true,
+		`log.attributes["status"] == "200"`:                       false,
+	} {
+		c, err := ParseCondition(text, LogContext)
+		require.NoError(t, err, text)
+		got, err := c.Eval(r)
+		require.NoError(t, err, text)
+		assert.Equal(t, want, got, text)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for text, want := range map[string]string{
+		`set(log.attributes["a"], "b"`:                   "unexpected end of statement, expected \",\"",
+		`set(log.attributes["a"], "\d")`:                 `invalid string "\d" at offset 25: backslashes must be escaped as \\`,
+		`set(log.attributes["a"], "b") when x`:           `unexpected "when" at offset 30, expected where or the end of the statement`,
+		`Set(log.body, "x")`:                             `unexpected "Set" at offset 0, expected an editor such as set`,
+		`flatten(log.attributes)`:                        "editor flatten is not supported by soc4kafka",
+		`set(log.body, SHA256(log.body))`:                "converter SHA256 is not supported by soc4kafka",
+		`set(log.trace_id, "x")`:                         "path log.trace_id is not supported by soc4kafka",
+		`set(log.severity_number, SEVERITY_NUMBER_INFO)`: "enum SEVERITY_NUMBER_INFO is not supported by soc4kafka",
+		`set(target = log.body, value = "x")`:            "named argument target is not supported by soc4kafka",
+	} {
+		_, err := ParseStatement(text, "")
+		assert.EqualError(t, err, want, text)
+	}
+	_, err := ParseStatement("flatten(log.attributes)", "")
+	var unsupported *UnsupportedError
+	assert.ErrorAs(t, err, &unsupported)
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "1.5", String(1.5))
+	assert.Equal(t, "42", String(int64(42)))
+	assert.Equal(t, `{"a":[1,"b"]}`, String(map[string]any{"a": []any{int64(1), "b"}}))
+	assert.Equal(t, "", String(nil))
+}
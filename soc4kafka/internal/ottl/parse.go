@@ -0,0 +1,485 @@
+// Package ottl interprets the subset of the OpenTelemetry Transformation Language used by the transform and filter
+// processors of SOC4Kafka pipelines, so that their effect on a log record can be shown without running a collector.
+// Statements using functions or syntax outside of the subset fail to parse with an UnsupportedError.
+package ottl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// UnsupportedError reports valid OTTL that the interpreter does not implement.
+type UnsupportedError struct {
+	What string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.What + " is not supported by soc4kafka"
+}
+
+// Contexts of paths, the first segment of a path when it is explicit.
+const (
+	LogContext      = "log"
+	ResourceContext = "resource"
+	ScopeContext    = "scope"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lex(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"':
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			tokens = append(tokens, token{tokString, s[i : j+1], i})
+			i = j + 1
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, token{tokIdent, s[i:j], i})
+			i = j
+		case unicode.IsDigit(rune(c)) || c == '-' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1])):
+			j := i + 1
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, s[i:j], i})
+			i = j
+		default:
+			if i+1 < len(s) {
+				switch op := s[i : i+2]; op {
+				case "==", "!=", "<=", ">=":
+					tokens = append(tokens, token{tokPunct, op, i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("()[]{},.:<>=", rune(c)) {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			tokens = append(tokens, token{tokPunct, string(c), i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(s)}), nil
+}
+
+// expr is a value: a literal, a path, a converter call, a list or a map.
+type expr interface{}
+
+type literal struct{ value any }
+
+type path struct {
+	context string
+	fields  []string
+	keys    []expr
+}
+
+type call struct {
+	name string
+	args []expr
+	keys []expr
+}
+
+type listExpr struct{ items []expr }
+
+type mapExpr struct {
+	keys   []string
+	values []expr
+}
+
+// condition is a boolean expression.
+type condition interface{}
+
+type boolOp struct {
+	op          string // and, or
+	left, right condition
+}
+
+type notOp struct{ c condition }
+
+type comparison struct {
+	op          string
+	left, right expr
+}
+
+// valueCondition is a value used as a condition, a boolean literal or converter.
+type valueCondition struct{ e expr }
+
+type parser struct {
+	tokens []token
+	i      int
+	// context is applied to paths without an explicit context, empty when they must be explicit.
+	context string
+}
+
+func (p *parser) peek() token { return p.tokens[p.i] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); (t.kind == tokPunct || t.kind == tokIdent) && t.text == text {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected("expected " + strconv.Quote(text))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(hint string) error {
+	t := p.peek()
+	if t.kind == tokEOF {
+		return fmt.Errorf("unexpected end of statement, %s", hint)
+	}
+	return fmt.Errorf("unexpected %q at offset %d, %s", t.text, t.pos, hint)
+}
+
+// Statement is a parsed editor invocation with its optional where clause.
+type Statement struct {
+	Text   string
+	editor string
+	args   []expr
+	where  condition
+}
+
+// ParseStatement parses a statement. Paths without a context use ctx, they are rejected when ctx is empty.
+func ParseStatement(s, ctx string) (*Statement, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, context: ctx}
+	name := p.next()
+	if name.kind != tokIdent || !unicode.IsLower(rune(name.text[0])) {
+		p.i--
+		return nil, p.unexpected("expected an editor such as set")
+	}
+	if _, ok := editors[name.text]; !ok {
+		return nil, &UnsupportedError{What: "editor " + name.text}
+	}
+	st := &Statement{Text: s, editor: name.text}
+	if st.args, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if p.accept("where") {
+		if st.where, err = p.or(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("expected where or the end of the statement")
+	}
+	return st, nil
+}
+
+// Condition is a parsed boolean expression, as used by the filter processor and transform context conditions.
+type Condition struct {
+	Text string
+	c    condition
+}
+
+// ParseCondition parses a condition, see ParseStatement for ctx.
+func ParseCondition(s, ctx string) (*Condition, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, context: ctx}
+	c, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, p.unexpected("expected and, or or the end of the condition")
+	}
+	return &Condition{Text: s, c: c}, nil
+}
+
+func (p *parser) or() (condition, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = boolOp{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) and() (condition, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = boolOp{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) factor() (condition, error) {
+	if p.accept("not") {
+		c, err := p.factor()
+		return notOp{c}, err
+	}
+	if p.accept("(") {
+		c, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return c, p.expect(")")
+	}
+	left, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	switch t := p.peek(); t.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return comparison{op: t.text, left: left, right: right}, nil
+	}
+	return valueCondition{left}, nil
+}
+
+func (p *parser) arguments() ([]expr, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []expr
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		if p.peek().kind == tokIdent && p.tokens[p.i+1].text == "=" {
+			return nil, &UnsupportedError{What: "named argument " + p.peek().text}
+		}
+		arg, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			return args, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) value() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		s, err := strconv.Unquote(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s at offset %d: backslashes must be escaped as \\\\", t.text, t.pos)
+		}
+		return literal{s}, nil
+	case tokNumber:
+		if strings.Contains(t.text, ".") {
+			f, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s at offset %d", t.text, t.pos)
+			}
+			return literal{f}, nil
+		}
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at offset %d", t.text, t.pos)
+		}
+		return literal{n}, nil
+	case tokIdent:
+		switch t.text {
+		case "true", "false":
+			return literal{t.text == "true"}, nil
+		case "nil":
+			return literal{nil}, nil
+		}
+		if unicode.IsUpper(rune(t.text[0])) {
+			if !p.accept("(") {
+				return nil, &UnsupportedError{What: "enum " + t.text}
+			}
+			p.i--
+			if _, ok := converters[t.text]; !ok {
+				return nil, &UnsupportedError{What: "converter " + t.text}
+			}
+			args, err := p.arguments()
+			if err != nil {
+				return nil, err
+			}
+			keys, err := p.keys()
+			return call{name: t.text, args: args, keys: keys}, err
+		}
+		return p.path(t)
+	case tokPunct:
+		switch t.text {
+		case "[":
+			var l listExpr
+			for !p.accept("]") {
+				if len(l.items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				l.items = append(l.items, item)
+			}
+			return l, nil
+		case "{":
+			var m mapExpr
+			for !p.accept("}") {
+				if len(m.keys) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				k := p.next()
+				key, err := strconv.Unquote(k.text)
+				if k.kind != tokString || err != nil {
+					p.i--
+					return nil, p.unexpected("expected a string key")
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				v, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				m.keys, m.values = append(m.keys, key), append(m.values, v)
+			}
+			return m, nil
+		}
+	}
+	p.i--
+	return nil, p.unexpected("expected a value")
+}
+
+func (p *parser) path(first token) (expr, error) {
+	fields := []string{first.text}
+	for p.accept(".") {
+		t := p.next()
+		if t.kind != tokIdent {
+			p.i--
+			return nil, p.unexpected("expected a path segment")
+		}
+		fields = append(fields, t.text)
+	}
+	pa := path{context: p.context, fields: fields}
+	switch fields[0] {
+	case LogContext, ResourceContext, ScopeContext:
+		if len(fields) > 1 {
+			pa.context, pa.fields = fields[0], fields[1:]
+		}
+	}
+	if pa.context == "" {
+		return nil, fmt.Errorf("path %s at offset %d must start with a context such as log or resource", strings.Join(fields, "."), first.pos)
+	}
+	if !knownPath(pa.context, pa.fields) {
+		return nil, &UnsupportedError{What: "path " + pa.context + "." + strings.Join(pa.fields, ".")}
+	}
+	var err error
+	pa.keys, err = p.keys()
+	return pa, err
+}
+
+func (p *parser) keys() ([]expr, error) {
+	var keys []expr
+	for p.accept("[") {
+		k, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		switch k.(type) {
+		case literal, path, call:
+		default:
+			return nil, errors.New("keys must be strings, integers, paths or converters")
+		}
+		keys = append(keys, k)
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func knownPath(ctx string, fields []string) bool {
+	if len(fields) != 1 {
+		return false
+	}
+	switch ctx {
+	case LogContext:
+		switch fields[0] {
+		case "body", "attributes", "cache", "time", "observed_time", "time_unix_nano", "observed_time_unix_nano",
+			"severity_text", "severity_number":
+			return true
+		}
+	case ResourceContext:
+		return fields[0] == "attributes"
+	case ScopeContext:
+		switch fields[0] {
+		case "name", "version", "attributes":
+			return true
+		}
+	}
+	return false
+}
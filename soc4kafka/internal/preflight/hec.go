@@ -0,0 +1,185 @@
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+const (
+	defaultHealthPath = "/services/collector/health"
+	testEventBody     = "SOC4Kafka preflight check. Splunk HEC test event"
+)
+
+// HEC response codes, see https://docs.splunk.com/Documentation/Splunk/latest/Data/TroubleshootHTTPEventCollector
+const (
+	hecCodeSuccess        = 0
+	hecCodeTokenDisabled  = 1
+	hecCodeTokenRequired  = 2
+	hecCodeInvalidAuth    = 3
+	hecCodeInvalidToken   = 4
+	hecCodeNoData         = 5
+	hecCodeIncorrectIndex = 7
+)
+
+type hecResponse struct {
+	Text string `json:"text"`
+	Code int    `json:"code"`
+}
+
+// CheckHECExporter verifies the health endpoint, the token and, when enabled, the index permission of an exporter.
+func (c *Checker) CheckHECExporter(ctx context.Context, e collectorconfig.HECExporter) []Result {
+	var results []Result
+	add := func(check, target string, status Status, format string, args ...any) {
+		results = append(results, Result{Component: e.ID, Check: check, Target: target, Status: status, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	endpoint := collectorconfig.ExpandEnv(e.Endpoint)
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		add("endpoint", endpoint, StatusFail, "invalid endpoint URL")
+		return results
+	}
+	client, err := c.hecClient(e)
+	if err != nil {
+		add("endpoint", endpoint, StatusFail, "%v", err)
+		return results
+	}
+
+	healthPath := collectorconfig.String(e.Raw, "health_path")
+	if healthPath == "" {
+		healthPath = defaultHealthPath
+	}
+	healthURL := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: healthPath}).String()
+	status, resp, err := c.hecRequest(ctx, client, http.MethodGet, healthURL, "", nil)
+	switch {
+	case err != nil:
+		add("health", healthURL, StatusFail, "%v", err)
+		return results
+	case status == http.StatusOK:
+		add("health", healthURL, StatusPass, "%s", resp.Text)
+	default:
+		add("health", healthURL, StatusFail, "HTTP %d: %s", status, resp.Text)
+	}
+
+	token := collectorconfig.ExpandEnv(e.Token)
+	if token == "" {
+		add("token", endpoint, StatusFail, "token is empty, check the referenced environment variables")
+		return results
+	}
+	// An empty request is rejected with "No data" only after the token was accepted.
+	status, resp, err = c.hecRequest(ctx, client, http.MethodPost, endpoint, token, []byte{})
+	switch {
+	case err != nil:
+		add("token", endpoint, StatusFail, "%v", err)
+		return results
+	case resp.Code == hecCodeNoData || resp.Code == hecCodeSuccess:
+		add("token", endpoint, StatusPass, "token accepted")
+	case resp.Code == hecCodeTokenDisabled || resp.Code == hecCodeTokenRequired || resp.Code == hecCodeInvalidAuth || resp.Code == hecCodeInvalidToken:
+		add("token", endpoint, StatusFail, "token rejected: %s", resp.Text)
+		return results
+	default:
+		add("token", endpoint, StatusFail, "HTTP %d: %s", status, resp.Text)
+		return results
+	}
+
+	index := collectorconfig.ExpandEnv(e.Index)
+	if !c.SendTestEvent {
+		add("index", index, StatusSkip, "test event disabled")
+		return results
+	}
+	event := map[string]any{"event": testEventBody, "time": float64(time.Now().UnixMilli()) / 1000}
+	for field, value := range map[string]string{"index": index, "source": e.Source, "sourcetype": e.Sourcetype} {
+		if value = collectorconfig.ExpandEnv(value); value != "" {
+			event[field] = value
+		}
+	}
+	if host, err := os.Hostname(); err == nil {
+		event["host"] = host
+	}
+	body, _ := json.Marshal(event)
+	status, resp, err = c.hecRequest(ctx, client, http.MethodPost, endpoint, token, body)
+	target := index
+	if target == "" {
+		target = "(token default index)"
+	}
+	switch {
+	case err != nil:
+		add("index", target, StatusFail, "%v", err)
+	case status == http.StatusOK && resp.Code == hecCodeSuccess:
+		add("index", target, StatusPass, "test event accepted")
+	case resp.Code == hecCodeIncorrectIndex:
+		add("index", target, StatusFail, "the token is not allowed to write to the index or the index does not exist: %s", resp.Text)
+	default:
+		add("index", target, StatusFail, "HTTP %d: %s", status, resp.Text)
+	}
+	return results
+}
+
+func (c *Checker) hecClient(e collectorconfig.HECExporter) (*http.Client, error) {
+	tlsSettings := collectorconfig.Map(e.Raw, "tls")
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: boolValue(tlsSettings["insecure_skip_verify"]),
+	}
+	if caFile := collectorconfig.String(tlsSettings, "ca_file"); caFile != "" {
+		pem, err := os.ReadFile(collectorconfig.ExpandEnv(caFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading tls.ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls.ca_file %s contains no certificates", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return &http.Client{
+		Timeout:   c.timeout(),
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+func (c *Checker) hecRequest(ctx context.Context, client *http.Client, method, url, token string, body []byte) (int, hecResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return 0, hecResponse{}, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Splunk "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, hecResponse{}, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, hecResponse{}, err
+	}
+	hr := hecResponse{Code: -1}
+	if err := json.Unmarshal(data, &hr); err != nil {
+		hr.Text = string(bytes.TrimSpace(data))
+	}
+	return resp.StatusCode, hr, nil
+}
+
+func boolValue(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
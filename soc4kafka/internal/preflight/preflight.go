@@ -0,0 +1,261 @@
+// Package preflight verifies that the Kafka clusters and Splunk HEC endpoints referenced by a collector
+// configuration are reachable and that the configured credentials have the required permissions.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+// Status of a single check.
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusWarn Status = "WARN"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Component string `json:"component"`
+	Check     string `json:"check"`
+	Target    string `json:"target,omitempty"`
+	Status    Status `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// KafkaCluster is the subset of the Kafka admin API used by the checks.
+type KafkaCluster interface {
+	Topics(ctx context.Context) (map[string]int, error)
+	TopicOperations(ctx context.Context, topics []string) (map[string][]string, error)
+	GroupOperations(ctx context.Context, group string) ([]string, error)
+	Close()
+}
+
+// Checker runs the checks.
+type Checker struct {
+	Timeout time.Duration
+	// SendTestEvent posts a test event to every HEC exporter to verify the index permission.
+	SendTestEvent bool
+	// CheckACLs verifies the authorized operations on topics and consumer groups.
+	CheckACLs bool
+	// Dial opens TCP connections, defaults to net.Dialer.DialContext.
+	Dial func(ctx context.Context, network, address string) (net.Conn, error)
+	// NewKafkaCluster connects to the cluster of a receiver, defaults to kafkaclient.New.
+	NewKafkaCluster func(r collectorconfig.KafkaReceiver) (KafkaCluster, error)
+}
+
+// Run checks every kafka receiver and splunk_hec exporter of cfg.
+func (c *Checker) Run(ctx context.Context, cfg *collectorconfig.Config) []Result {
+	var results []Result
+	for _, r := range cfg.KafkaReceivers {
+		results = append(results, c.CheckKafkaReceiver(ctx, r)...)
+	}
+	for _, e := range cfg.HECExporters {
+		results = append(results, c.CheckHECExporter(ctx, e)...)
+	}
+	return results
+}
+
+// Failed reports whether any result failed.
+func Failed(results []Result) bool {
+	return slices.ContainsFunc(results, func(r Result) bool { return r.Status == StatusFail })
+}
+
+// CheckKafkaReceiver verifies broker reachability, the SASL/TLS handshake, the subscribed topics and the ACLs.
+func (c *Checker) CheckKafkaReceiver(ctx context.Context, r collectorconfig.KafkaReceiver) []Result {
+	var results []Result
+	add := func(check, target string, status Status, format string, args ...any) {
+		results = append(results, Result{Component: r.ID, Check: check, Target: target, Status: status, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	reachable := 0
+	for _, b := range r.Brokers {
+		addr := collectorconfig.ExpandEnv(b)
+		if err := c.dial(ctx, addr); err != nil {
+			add("broker reachable", addr, StatusFail, "%v", err)
+			continue
+		}
+		reachable++
+		add("broker reachable", addr, StatusPass, "TCP connection established")
+	}
+	if reachable == 0 {
+		add("handshake", "", StatusSkip, "no broker is reachable")
+		return results
+	}
+
+	cluster, err := c.newKafkaCluster(r)
+	if err != nil {
+		add("handshake", "", StatusFail, "%v", err)
+		return results
+	}
+	defer cluster.Close()
+
+	topics, err := cluster.Topics(ctx)
+	if err != nil {
+		add("handshake", "", StatusFail, "fetching metadata failed, check the auth and tls settings: %v", err)
+		return results
+	}
+	add("handshake", "", StatusPass, "%s, %d topics visible", securityDescription(r), len(topics))
+
+	excluded, err := collectorconfig.CompileTopicPatterns(r.ExcludeTopics)
+	if err != nil {
+		add("exclude_topics", "", StatusFail, "%v", err)
+	}
+	var consumed []string
+	for _, t := range r.Topics {
+		if !collectorconfig.IsRegexTopic(t) {
+			if partitions, ok := topics[t]; ok {
+				add("topic exists", t, StatusPass, "%d partitions", partitions)
+				consumed = append(consumed, t)
+			} else {
+				add("topic exists", t, StatusFail, "topic does not exist or is not visible to the configured user")
+			}
+			continue
+		}
+		re, err := regexp.Compile(t)
+		if err != nil {
+			add("regex matches", t, StatusFail, "invalid regular expression: %v", err)
+			continue
+		}
+		var matches []string
+		for _, name := range collectorconfig.SortedKeys(topics) {
+			if re.MatchString(name) && !excluded.Match(name) {
+				matches = append(matches, name)
+			}
+		}
+		if len(matches) == 0 {
+			add("regex matches", t, StatusWarn, "no existing topic matches, matching topics are picked up once created")
+			continue
+		}
+		add("regex matches", t, StatusPass, "%d topics: %s", len(matches), summarize(matches))
+		consumed = append(consumed, matches...)
+	}
+
+	if !c.CheckACLs {
+		return results
+	}
+	slices.Sort(consumed)
+	consumed = slices.Compact(consumed)
+	if len(consumed) > 0 {
+		ops, err := cluster.TopicOperations(ctx, consumed)
+		switch {
+		case errors.Is(err, kafkaclient.ErrNotReported):
+			add("topic ACLs", "", StatusSkip, "%v", err)
+		case err != nil:
+			add("topic ACLs", "", StatusFail, "%v", err)
+		default:
+			for _, t := range consumed {
+				if missing := missingOperations(ops[t], "DESCRIBE", "READ"); len(missing) > 0 {
+					add("topic ACLs", t, StatusFail, "missing %s permission", strings.Join(missing, ", "))
+				} else {
+					add("topic ACLs", t, StatusPass, "DESCRIBE and READ allowed")
+				}
+			}
+		}
+	}
+
+	group := r.EffectiveGroupID()
+	ops, err := cluster.GroupOperations(ctx, group)
+	switch {
+	case errors.Is(err, kafkaclient.ErrNotReported):
+		add("group ACLs", group, StatusSkip, "%v", err)
+	case err != nil:
+		add("group ACLs", group, StatusFail, "%v", err)
+	default:
+		if missing := missingOperations(ops, "READ"); len(missing) > 0 {
+			add("group ACLs", group, StatusFail, "missing %s permission", strings.Join(missing, ", "))
+		} else {
+			add("group ACLs", group, StatusPass, "READ allowed")
+		}
+	}
+	return results
+}
+
+func (c *Checker) dial(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+	dial := c.Dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (c *Checker) newKafkaCluster(r collectorconfig.KafkaReceiver) (KafkaCluster, error) {
+	if c.NewKafkaCluster != nil {
+		return c.NewKafkaCluster(r)
+	}
+	return kafkaclient.New(r, c.timeout())
+}
+
+func (c *Checker) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return c.Timeout
+}
+
+func securityDescription(r collectorconfig.KafkaReceiver) string {
+	auth := collectorconfig.Map(r.Raw, "auth")
+	var parts []string
+	for _, mechanism := range []string{"sasl", "plain_text", "kerberos"} {
+		if auth[mechanism] != nil {
+			parts = append(parts, mechanism+" authentication")
+		}
+	}
+	if r.Raw["tls"] != nil || auth["tls"] != nil {
+		parts = append(parts, "TLS")
+	}
+	if len(parts) == 0 {
+		return "plaintext connection"
+	}
+	return strings.Join(parts, " over ")
+}
+
+// missingOperations returns the required operations not covered by the authorized ones. ALL covers everything and
+// READ implies DESCRIBE.
+func missingOperations(authorized []string, required ...string) []string {
+	have := map[string]bool{}
+	for _, op := range authorized {
+		have[strings.ToUpper(op)] = true
+	}
+	if have["ALL"] {
+		return nil
+	}
+	if have["READ"] {
+		have["DESCRIBE"] = true
+	}
+	var missing []string
+	for _, op := range required {
+		if !have[op] {
+			missing = append(missing, op)
+		}
+	}
+	return missing
+}
+
+func summarize(names []string) string {
+	const max = 5
+	sort.Strings(names)
+	if len(names) <= max {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:max], ", "), len(names)-max)
+}
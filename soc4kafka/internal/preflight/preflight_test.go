@@ -0,0 +1,225 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/kafkaclient"
+)
+
+type fakeCluster struct {
+	topics   map[string]int
+	topicOps map[string][]string
+	groupOps []string
+	err      error
+}
+
+func (f *fakeCluster) Topics(context.Context) (map[string]int, error) { return f.topics, f.err }
+
+func (f *fakeCluster) TopicOperations(context.Context, []string) (map[string][]string, error) {
+	if f.topicOps == nil {
+		return nil, kafkaclient.ErrNotReported
+	}
+	return f.topicOps, nil
+}
+
+func (f *fakeCluster) GroupOperations(context.Context, string) ([]string, error) {
+	if f.groupOps == nil {
+		return nil, kafkaclient.ErrNotReported
+	}
+	return f.groupOps, nil
+}
+
+func (f *fakeCluster) Close() {}
+
+func dialOK(context.Context, string, string) (net.Conn, error) {
+	client, server := net.Pipe()
+	_ = server.Close()
+	return client, nil
+}
+
+func checks(results []Result) map[string]Status {
+	out := map[string]Status{}
+	for _, r := range results {
+		out[r.Check+" "+r.Target] = r.Status
+	}
+	return out
+}
+
+func TestCheckKafkaReceiver(t *testing.T) {
+	cluster := &fakeCluster{
+		topics:   map[string]int{"app": 3, "logs-a": 1, "logs-b": 1, "logs-internal": 1},
+		topicOps: map[string][]string{"app": {"READ"}, "logs-a": {"ALL"}, "logs-b": {"DESCRIBE"}},
+		groupOps: []string{"DESCRIBE"},
+	}
+	c := &Checker{
+		CheckACLs:       true,
+		Dial:            dialOK,
+		NewKafkaCluster: func(collectorconfig.KafkaReceiver) (KafkaCluster, error) { return cluster, nil },
+	}
+	r := collectorconfig.KafkaReceiver{
+		ID:            "kafka/logs",
+		Brokers:       []string{"broker:9092"},
+		Topics:        []string{"app", "missing", "^logs-.*", "^metrics-.*"},
+		ExcludeTopics: []string{"^logs-internal$"},
+		GroupID:       "soc4kafka",
+	}
+
+	results := c.CheckKafkaReceiver(context.Background(), r)
+	assert.Equal(t, map[string]Status{
+		"broker reachable broker:9092": StatusPass,
+		"handshake ":                   StatusPass,
+		"topic exists app":             StatusPass,
+		"topic exists missing":         StatusFail,
+		"regex matches ^logs-.*":       StatusPass,
+		"regex matches ^metrics-.*":    StatusWarn,
+		"topic ACLs app":               StatusPass,
+		"topic ACLs logs-a":            StatusPass,
+		"topic ACLs logs-b":            StatusFail,
+		"group ACLs soc4kafka":         StatusFail,
+	}, checks(results))
+	assert.Equal(t, "2 topics: logs-a, logs-b", results[4].Detail)
+	assert.True(t, Failed(results))
+
+	cluster.topicOps, cluster.groupOps = nil, nil
+	results = c.CheckKafkaReceiver(context.Background(), r)
+	assert.Equal(t, StatusSkip, checks(results)["topic ACLs "])
+	assert.Equal(t, StatusSkip, checks(results)["group ACLs soc4kafka"])
+}
+
+func TestCheckKafkaReceiverUnreachable(t *testing.T) {
+	c := &Checker{
+		Timeout: time.Second,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	results := c.CheckKafkaReceiver(context.Background(), collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{"broker:9092"}})
+	assert.Equal(t, []Result{
+		{Component: "kafka", Check: "broker reachable", Target: "broker:9092", Status: StatusFail, Detail: "connection refused"},
+		{Component: "kafka", Check: "handshake", Status: StatusSkip, Detail: "no broker is reachable"},
+	}, results)
+}
+
+func TestCheckKafkaReceiverHandshakeFailure(t *testing.T) {
+	c := &Checker{
+		Dial: dialOK,
+		NewKafkaCluster: func(collectorconfig.KafkaReceiver) (KafkaCluster, error) {
+			return &fakeCluster{err: errors.New("SASL authentication error")}, nil
+		},
+	}
+	results := c.CheckKafkaReceiver(context.Background(), collectorconfig.KafkaReceiver{ID: "kafka", Brokers: []string{"broker:9092"}})
+	require.Len(t, results, 2)
+	assert.Equal(t, StatusFail, results[1].Status)
+	assert.Contains(t, results[1].Detail, "SASL authentication error")
+}
+
+// fakeHEC emulates the responses of the Splunk HEC endpoint for a single token and index.
+func fakeHEC(t *testing.T, token, index string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == defaultHealthPath {
+			_, _ = io.WriteString(w, `{"text":"HEC is healthy","code":17}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Splunk "+token {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"text":"Invalid token","code":4}`)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if len(body) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"text":"No data","code":5}`)
+			return
+		}
+		var event map[string]any
+		require.NoError(t, json.Unmarshal(body, &event))
+		if event["index"] != index {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"text":"Incorrect index","code":7,"invalid-event-number":0}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"text":"Success","code":0}`)
+	}))
+}
+
+func TestCheckHECExporter(t *testing.T) {
+	srv := fakeHEC(t, "valid-token", "kafka")
+	defer srv.Close()
+	t.Setenv("SOC4KAFKA_TEST_TOKEN", "valid-token")
+
+	tests := []struct {
+		name     string
+		exporter collectorconfig.HECExporter
+		sendTest bool
+		expected []Status
+	}{
+		{
+			name:     "valid",
+			exporter: collectorconfig.HECExporter{Token: "${SOC4KAFKA_TEST_TOKEN}", Index: "kafka"},
+			sendTest: true,
+			expected: []Status{StatusPass, StatusPass, StatusPass},
+		},
+		{
+			name:     "invalid token",
+			exporter: collectorconfig.HECExporter{Token: "wrong", Index: "kafka"},
+			sendTest: true,
+			expected: []Status{StatusPass, StatusFail},
+		},
+		{
+			name:     "empty token",
+			exporter: collectorconfig.HECExporter{Token: "${SOC4KAFKA_TEST_UNSET}"},
+			expected: []Status{StatusPass, StatusFail},
+		},
+		{
+			name:     "index not allowed",
+			exporter: collectorconfig.HECExporter{Token: "valid-token", Index: "main"},
+			sendTest: true,
+			expected: []Status{StatusPass, StatusPass, StatusFail},
+		},
+		{
+			name:     "test event disabled",
+			exporter: collectorconfig.HECExporter{Token: "valid-token", Index: "main"},
+			expected: []Status{StatusPass, StatusPass, StatusSkip},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.exporter.ID = "splunk_hec"
+			tt.exporter.Endpoint = srv.URL + "/services/collector"
+			c := &Checker{SendTestEvent: tt.sendTest}
+			var statuses []Status
+			for _, r := range c.CheckHECExporter(context.Background(), tt.exporter) {
+				statuses = append(statuses, r.Status)
+			}
+			assert.Equal(t, tt.expected, statuses)
+		})
+	}
+}
+
+func TestCheckHECExporterUnreachable(t *testing.T) {
+	srv := fakeHEC(t, "token", "")
+	srv.Close()
+	c := &Checker{Timeout: time.Second}
+	results := c.CheckHECExporter(context.Background(), collectorconfig.HECExporter{ID: "splunk_hec", Endpoint: srv.URL + "/services/collector", Token: "token"})
+	require.Len(t, results, 1)
+	assert.Equal(t, "health", results[0].Check)
+	assert.Equal(t, StatusFail, results[0].Status)
+}
+
+func TestMissingOperations(t *testing.T) {
+	assert.Empty(t, missingOperations([]string{"ALL"}, "DESCRIBE", "READ"))
+	assert.Empty(t, missingOperations([]string{"read"}, "DESCRIBE", "READ"))
+	assert.Equal(t, []string{"READ"}, missingOperations([]string{"DESCRIBE", "WRITE"}, "DESCRIBE", "READ"))
+}
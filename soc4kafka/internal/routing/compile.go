@@ -0,0 +1,352 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/helmvalues"
+)
+
+// HEC metadata attributes read by the splunk_hec exporter by default (otel_attrs_to_hec_metadata).
+const (
+	IndexAttribute      = "com.splunk.index"
+	SourcetypeAttribute = "com.splunk.sourcetype"
+	SourceAttribute     = "com.splunk.source"
+)
+
+const defaultGroupPrefix = "soc4kafka"
+
+// Group is a set of routes sharing a destination. Each group is consumed by one receiver and one pipeline; the
+// pipelines of an exporter share it, and the groups whose destination differs from the exporter settings set the
+// HEC metadata attributes with a transform processor.
+type Group struct {
+	Name       string   `json:"name"`
+	Exporter   string   `json:"exporter"`
+	Index      string   `json:"index,omitempty"`
+	Sourcetype string   `json:"sourcetype,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	Topics     []string `json:"topics"`
+	// ExcludeTopics are the topics routed to other groups that match a regex topic of the group.
+	ExcludeTopics []string `json:"exclude_topics,omitempty"`
+	GroupID       string   `json:"group_id"`
+	// Statements set the HEC metadata attributes that differ from the exporter settings.
+	Statements []string `json:"statements,omitempty"`
+}
+
+// Processor returns the ID of the transform processor of the group, empty when it needs none.
+func (g Group) Processor() string {
+	if len(g.Statements) == 0 {
+		return ""
+	}
+	return "transform/route_" + g.Name
+}
+
+// Result is a compiled routing table.
+type Result struct {
+	Groups []Group `json:"groups"`
+	// Warnings are the potential issues that could not be verified, such as regexes of different groups that may
+	// match the same topic.
+	Warnings []string `json:"warnings,omitempty"`
+
+	table *Table
+}
+
+type destination struct{ exporter, index, sourcetype, source string }
+
+// Compile groups the routes by destination and checks that no topic is routed twice. When existing topics are
+// given, each one must be consumed by at most one group, otherwise regexes of different groups are only reported
+// as warnings since their overlap cannot be decided.
+func Compile(t *Table, existing []string) (*Result, error) {
+	var errs []error
+	fail := func(r Route, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", lineOf(r), fmt.Sprintf(format, args...)))
+	}
+	if len(t.Defaults.Brokers) == 0 {
+		errs = append(errs, errors.New("defaults.brokers is required"))
+	}
+	if len(t.Exporters) == 0 {
+		errs = append(errs, errors.New("at least one exporter is required"))
+	}
+	for _, name := range collectorconfig.SortedKeys(t.Exporters) {
+		if collectorconfig.String(t.Exporters[name], "endpoint") == "" {
+			errs = append(errs, fmt.Errorf("exporter %s: endpoint is required", name))
+		}
+	}
+	defaultExporter := t.Defaults.Exporter
+	if _, ok := t.Exporters["primary"]; ok && defaultExporter == "" {
+		defaultExporter = "primary"
+	} else if defaultExporter == "" && len(t.Exporters) == 1 {
+		defaultExporter = collectorconfig.SortedKeys(t.Exporters)[0]
+	}
+
+	res := &Result{table: t}
+	groups := map[destination]*Group{}
+	var order []destination
+	seen := map[string]Route{}
+	for _, r := range t.Routes {
+		if r.Topic == "" {
+			fail(r, "topic is required")
+			continue
+		}
+		if previous, ok := seen[r.Topic]; ok {
+			fail(r, "topic %s is already routed at %s", r.Topic, lineOf(previous))
+			continue
+		}
+		seen[r.Topic] = r
+		if collectorconfig.IsRegexTopic(r.Topic) {
+			if _, err := regexp.Compile(r.Topic); err != nil {
+				fail(r, "invalid regex: %v", err)
+				continue
+			}
+		} else if strings.ContainsAny(r.Topic, `*?[]()|\+{}$`) {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("%s: topic %s contains regex characters but does not start with ^, it is routed as an exact topic name", lineOf(r), r.Topic))
+		}
+		d := destination{
+			exporter:   or(r.Exporter, defaultExporter),
+			index:      or(r.Index, t.Defaults.Index),
+			sourcetype: or(r.Sourcetype, t.Defaults.Sourcetype),
+			source:     or(r.Source, t.Defaults.Source),
+		}
+		if d.exporter == "" {
+			fail(r, "exporter is required when the table defines several exporters, none named primary, and no defaults.exporter")
+			continue
+		}
+		if _, ok := t.Exporters[d.exporter]; !ok {
+			fail(r, "exporter %s is not defined", d.exporter)
+			continue
+		}
+		g, ok := groups[d]
+		if !ok {
+			g = &Group{Exporter: d.exporter, Index: d.index, Sourcetype: d.sourcetype, Source: d.source}
+			groups[d] = g
+			order = append(order, d)
+		}
+		g.Topics = append(g.Topics, r.Topic)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	names := map[string]bool{}
+	prefix := or(t.Defaults.GroupPrefix, defaultGroupPrefix)
+	for _, d := range order {
+		g := groups[d]
+		g.Name = uniqueName(names, d)
+		g.GroupID = prefix + "-" + g.Name
+		exporter := t.Exporters[d.exporter]
+		for _, f := range []struct{ attribute, value, exporterDefault string }{
+			{IndexAttribute, d.index, or(collectorconfig.String(exporter, "index"), t.Defaults.Index)},
+			{SourcetypeAttribute, d.sourcetype, or(collectorconfig.String(exporter, "sourcetype"), t.Defaults.Sourcetype)},
+			{SourceAttribute, d.source, or(collectorconfig.String(exporter, "source"), t.Defaults.Source)},
+		} {
+			if f.value != "" && f.value != f.exporterDefault {
+				g.Statements = append(g.Statements, fmt.Sprintf("set(resource.attributes[%q], %q)", f.attribute, f.value))
+			}
+		}
+		res.Groups = append(res.Groups, *g)
+	}
+	res.exclude()
+	if err := res.check(existing); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// exclude adds the exact topics of each group to the exclude_topics of the other groups with a regex matching them.
+// The receiver compiles exclude_topics entries as unanchored regexes, so each topic is excluded with an anchored and
+// quoted regex: audit-special alone would also exclude audit-special-eu.
+func (res *Result) exclude() {
+	for i := range res.Groups {
+		g := &res.Groups[i]
+		for _, pattern := range g.Topics {
+			if !collectorconfig.IsRegexTopic(pattern) {
+				continue
+			}
+			re := regexp.MustCompile(pattern)
+			for j, other := range res.Groups {
+				if i == j {
+					continue
+				}
+				for _, topic := range other.Topics {
+					if collectorconfig.IsRegexTopic(topic) || !re.MatchString(topic) {
+						continue
+					}
+					if exclude := "^" + regexp.QuoteMeta(topic) + "$"; !slices.Contains(g.ExcludeTopics, exclude) {
+						g.ExcludeTopics = append(g.ExcludeTopics, exclude)
+					}
+				}
+			}
+		}
+	}
+}
+
+// check verifies that each existing topic is consumed by one group at most, or warns about the regexes of
+// different groups when the topics are unknown.
+func (res *Result) check(existing []string) error {
+	if existing == nil {
+		var regexGroups []string
+		for _, g := range res.Groups {
+			for _, topic := range g.Topics {
+				if collectorconfig.IsRegexTopic(topic) {
+					regexGroups = append(regexGroups, g.Name)
+					break
+				}
+			}
+		}
+		if len(regexGroups) > 1 {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("the regexes of routes %s may match the same topics, pass the existing topics to verify it",
+				strings.Join(regexGroups, ", ")))
+		}
+		return nil
+	}
+	consumers := map[string][]string{}
+	for _, g := range res.Groups {
+		r := collectorconfig.KafkaReceiver{Topics: g.Topics, ExcludeTopics: g.ExcludeTopics}
+		topics, err := r.ResolveTopics(existing)
+		if err != nil {
+			return err
+		}
+		for _, topic := range topics {
+			consumers[topic] = append(consumers[topic], g.Name)
+		}
+	}
+	var errs []error
+	for _, topic := range collectorconfig.SortedKeys(consumers) {
+		if len(consumers[topic]) > 1 {
+			errs = append(errs, fmt.Errorf("topic %s is routed twice, by routes %s", topic, strings.Join(consumers[topic], " and ")))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// uniqueName names a group after its index, adding the sourcetype, the source and a counter as needed.
+func uniqueName(taken map[string]bool, d destination) string {
+	candidates := []string{
+		sanitize(or(d.index, "default")),
+		sanitize(or(d.index, "default") + "_" + d.sourcetype),
+		sanitize(or(d.index, "default") + "_" + d.sourcetype + "_" + d.source),
+	}
+	for _, c := range candidates {
+		if !taken[c] {
+			taken[c] = true
+			return c
+		}
+	}
+	for n := 2; ; n++ {
+		if c := fmt.Sprintf("%s_%d", candidates[2], n); !taken[c] {
+			taken[c] = true
+			return c
+		}
+	}
+}
+
+var unsafeName = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+func sanitize(s string) string {
+	return strings.Trim(unsafeName.ReplaceAllString(strings.ToLower(s), "_"), "_")
+}
+
+// Values returns the Helm values of the compiled table, as YAML.
+func (res *Result) Values() ([]byte, error) {
+	t := res.table
+	type logs struct {
+		Topics        []string `yaml:"topics"`
+		ExcludeTopics []string `yaml:"exclude_topics,omitempty"`
+		Encoding      string   `yaml:"encoding,omitempty"`
+	}
+	type receiver struct {
+		Name    string   `yaml:"name"`
+		Brokers []string `yaml:"brokers"`
+		Logs    logs     `yaml:"logs"`
+		GroupID string   `yaml:"group_id"`
+	}
+	type pipeline struct {
+		Name       string   `yaml:"name"`
+		Type       string   `yaml:"type"`
+		Receivers  []string `yaml:"receivers"`
+		Processors []string `yaml:"processors,omitempty"`
+		Exporters  []string `yaml:"exporters"`
+	}
+	var doc struct {
+		KafkaReceivers  []receiver       `yaml:"kafkaReceivers"`
+		SplunkExporters []map[string]any `yaml:"splunkExporters"`
+		Pipelines       []pipeline       `yaml:"pipelines"`
+		Defaults        map[string]any   `yaml:"defaults,omitempty"`
+	}
+
+	used := map[string]bool{}
+	processors := map[string]any{}
+	pipelineProcessors := collectorconfig.Strings(collectorconfig.Map(helmvalues.Defaults(), "defaults"), "pipelineProcessors")
+	for _, g := range res.Groups {
+		used[g.Exporter] = true
+		doc.KafkaReceivers = append(doc.KafkaReceivers, receiver{
+			Name: g.Name, Brokers: t.Defaults.Brokers, GroupID: g.GroupID,
+			Logs: logs{Topics: g.Topics, ExcludeTopics: g.ExcludeTopics, Encoding: t.Defaults.Encoding},
+		})
+		p := pipeline{Name: g.Name, Type: "logs", Receivers: []string{g.Name}, Exporters: []string{g.Exporter}}
+		if id := g.Processor(); id != "" {
+			processors[id] = map[string]any{"error_mode": "ignore", "log_statements": g.Statements}
+			p.Processors = append(append([]string{}, pipelineProcessors...), id)
+		}
+		doc.Pipelines = append(doc.Pipelines, p)
+	}
+	for _, name := range collectorconfig.SortedKeys(t.Exporters) {
+		if !used[name] {
+			continue
+		}
+		e := map[string]any{"name": name}
+		for k, v := range t.Exporters[name] {
+			e[k] = v
+		}
+		for key, value := range map[string]string{"index": t.Defaults.Index, "sourcetype": t.Defaults.Sourcetype, "source": t.Defaults.Source} {
+			if _, ok := e[key]; !ok && value != "" {
+				e[key] = value
+			}
+		}
+		doc.SplunkExporters = append(doc.SplunkExporters, e)
+	}
+	if len(processors) > 0 {
+		doc.Defaults = map[string]any{"processors": processors}
+	}
+	return yaml.Marshal(doc)
+}
+
+// Config returns the collector configuration of the compiled table, rendered from its Helm values with the chart
+// defaults.
+func (res *Result) Config() ([]byte, error) {
+	data, err := res.Values()
+	if err != nil {
+		return nil, err
+	}
+	values, err := helmvalues.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := helmvalues.Config(values)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(cfg)
+}
+
+func lineOf(r Route) string {
+	if r.Line > 0 {
+		return fmt.Sprintf("line %d", r.Line)
+	}
+	return fmt.Sprintf("route %q", r.Topic)
+}
+
+func or(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
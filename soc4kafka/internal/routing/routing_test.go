@@ -0,0 +1,199 @@
+package routing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+const table = `defaults:
+  brokers: ["kafka:9092"]
+  encoding: text
+  index: kafka
+  sourcetype: kafka:logs
+exporters:
+  primary:
+    endpoint: https://splunk:8088/services/collector
+  security:
+    endpoint: https://splunk-sec:8088/services/collector
+    index: security
+routes:
+  - topic: app-logs
+    index: app
+  - topic: app-events
+    index: app
+  - topic: ^audit-.*
+    exporter: security
+  - topic: audit-special
+    index: app
+  - topic: payments
+  - topic: ^orders-.*
+    index: orders
+    sourcetype: orders:json
+`
+
+func compile(t *testing.T, data string, existing []string) (*Result, error) {
+	t.Helper()
+	table, err := ParseYAML([]byte(data))
+	require.NoError(t, err)
+	return Compile(table, existing)
+}
+
+func TestCompile(t *testing.T) {
+	res, err := compile(t, table, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Group{
+		{Name: "app", Exporter: "primary", Index: "app", Sourcetype: "kafka:logs",
+			Topics: []string{"app-logs", "app-events", "audit-special"}, GroupID: "soc4kafka-app",
+			Statements: []string{`set(resource.attributes["com.splunk.index"], "app")`}},
+		{Name: "kafka", Exporter: "security", Index: "kafka", Sourcetype: "kafka:logs",
+			Topics: []string{"^audit-.*"}, ExcludeTopics: []string{"^audit-special$"}, GroupID: "soc4kafka-kafka",
+			Statements: []string{`set(resource.attributes["com.splunk.index"], "kafka")`}},
+		{Name: "kafka_kafka_logs", Exporter: "primary", Index: "kafka", Sourcetype: "kafka:logs",
+			Topics: []string{"payments"}, GroupID: "soc4kafka-kafka_kafka_logs"},
+		{Name: "orders", Exporter: "primary", Index: "orders", Sourcetype: "orders:json",
+			Topics: []string{"^orders-.*"}, GroupID: "soc4kafka-orders",
+			Statements: []string{
+				`set(resource.attributes["com.splunk.index"], "orders")`,
+				`set(resource.attributes["com.splunk.sourcetype"], "orders:json")`,
+			}},
+	}, res.Groups)
+	assert.Equal(t, []string{"the regexes of routes kafka, orders may match the same topics, pass the existing topics to verify it"}, res.Warnings)
+	assert.Equal(t, "transform/route_app", res.Groups[0].Processor())
+	assert.Empty(t, res.Groups[2].Processor())
+}
+
+func TestCompileErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		routes string
+		want   []string
+	}{
+		"duplicate topic": {
+			routes: "  - topic: a\n  - topic: b\n  - topic: a\n",
+			want:   []string{"line 15: topic a is already routed at line 13"},
+		},
+		"invalid regex": {
+			routes: "  - topic: ^a(\n",
+			want:   []string{"line 13: invalid regex: "},
+		},
+		"undefined exporter": {
+			routes: "  - topic: a\n    exporter: missing\n",
+			want:   []string{"line 13: exporter missing is not defined"},
+		},
+		"missing topic": {
+			routes: "  - index: a\n",
+			want:   []string{"line 13: topic is required"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := strings.SplitAfter(table, "routes:\n")[0] + tc.routes
+			_, err := compile(t, data, nil)
+			require.Error(t, err)
+			for _, want := range tc.want {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+
+	_, err := compile(t, "exporters:\n  a:\n    index: x\n  b:\n    endpoint: https://b\nroutes:\n  - topic: t\n", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defaults.brokers is required")
+	assert.Contains(t, err.Error(), "exporter a: endpoint is required")
+	assert.Contains(t, err.Error(), "line 7: exporter is required when the table defines several exporters")
+}
+
+func TestCompileExistingTopics(t *testing.T) {
+	res, err := compile(t, table, []string{"app-logs", "audit-1", "audit-special", "orders-eu", "payments"})
+	require.NoError(t, err)
+	assert.Empty(t, res.Warnings)
+
+	overlapping := strings.Replace(table, "^orders-.*", "^.*t-.*", 1)
+	_, err = compile(t, overlapping, []string{"audit-special", "orders-eu"})
+	require.NoError(t, err)
+	_, err = compile(t, overlapping, []string{"audit-1", "orders-eu"})
+	require.EqualError(t, err, "topic audit-1 is routed twice, by routes kafka and orders")
+}
+
+// An exact topic excluded from a regex route must not exclude the topics it is a prefix of.
+func TestCompileExcludesExactTopicsOnly(t *testing.T) {
+	existing := []string{"audit-1", "audit-special", "audit-special-eu"}
+	res, err := compile(t, table, existing)
+	require.NoError(t, err)
+	require.Equal(t, "kafka", res.Groups[1].Name)
+	r := collectorconfig.KafkaReceiver{Topics: res.Groups[1].Topics, ExcludeTopics: res.Groups[1].ExcludeTopics}
+	topics, err := r.ResolveTopics(existing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audit-1", "audit-special-eu"}, topics)
+}
+
+func TestCompileWarnsAboutUnanchoredRegex(t *testing.T) {
+	res, err := compile(t, strings.SplitAfter(table, "routes:\n")[0]+"  - topic: logs-.*\n", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"line 13: topic logs-.* contains regex characters but does not start with ^, it is routed as an exact topic name"}, res.Warnings)
+}
+
+func TestLoadCSV(t *testing.T) {
+	dir := t.TempDir()
+	defaults := filepath.Join(dir, "defaults.yaml")
+	require.NoError(t, os.WriteFile(defaults, []byte(strings.SplitAfter(table, "routes:\n")[0]), 0o600))
+	routes := filepath.Join(dir, "routes.csv")
+	require.NoError(t, os.WriteFile(routes, []byte("topic,index,exporter\n# audit\n^audit-.*,,security\napp-logs, app\n"), 0o600))
+
+	base, err := Load(defaults, nil)
+	require.NoError(t, err)
+	loaded, err := Load(routes, base)
+	require.NoError(t, err)
+	assert.Equal(t, []Route{
+		{Topic: "^audit-.*", Exporter: "security", Line: 3},
+		{Topic: "app-logs", Index: "app", Line: 4},
+	}, loaded.Routes)
+	assert.Equal(t, "kafka", loaded.Defaults.Index)
+
+	_, err = ParseCSV(strings.NewReader("name,index\n"))
+	require.EqualError(t, err, `unknown column "name", expected topic, index, sourcetype, source, exporter`)
+	_, err = ParseCSV(strings.NewReader("index\n"))
+	require.EqualError(t, err, "missing column topic")
+}
+
+func TestValuesRenderConfig(t *testing.T) {
+	res, err := compile(t, table, nil)
+	require.NoError(t, err)
+	data, err := res.Config()
+	require.NoError(t, err)
+	cfg, err := collectorconfig.Parse(data)
+	require.NoError(t, err)
+
+	receiver, ok := cfg.KafkaReceiver("kafka/kafka")
+	require.True(t, ok)
+	assert.Equal(t, []string{"^audit-.*"}, receiver.Topics)
+	assert.Equal(t, []string{"^audit-special$"}, receiver.ExcludeTopics)
+	assert.Equal(t, "soc4kafka-kafka", receiver.EffectiveGroupID())
+
+	pipelines := map[string]collectorconfig.Pipeline{}
+	for _, p := range cfg.Pipelines {
+		pipelines[p.ID] = p
+	}
+	assert.Equal(t, []string{"splunk_hec/security"}, pipelines["logs/kafka"].Exporters)
+	assert.Equal(t, []string{"resourcedetection", "transform/route_orders"}, pipelines["logs/orders"].Processors)
+	security, ok := cfg.HECExporter("splunk_hec/security")
+	require.True(t, ok)
+	assert.Equal(t, "security", security.Index)
+	primary, ok := cfg.HECExporter("splunk_hec")
+	require.True(t, ok)
+	assert.Equal(t, "kafka", primary.Index)
+	assert.Equal(t, "${SPLUNK_HEC_TOKEN_PRIMARY}", primary.Token)
+
+	values, err := res.Values()
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, yaml.Unmarshal(values, &doc))
+	assert.Len(t, doc["kafkaReceivers"], 4)
+	assert.Len(t, doc["splunkExporters"], 2)
+}
@@ -0,0 +1,188 @@
+// Package routing compiles a routing table, mapping Kafka topics to Splunk indexes, sourcetypes and sources, into
+// the Helm values or the collector configuration consuming them with as few components as possible.
+package routing
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Table is a routing table.
+type Table struct {
+	Defaults Defaults `yaml:"defaults"`
+	// Exporters are the HEC endpoints by name, each holding splunk_hec exporter settings such as endpoint and tls.
+	// Tokens are read from environment variables, see helmvalues.TokenEnvVar.
+	Exporters map[string]map[string]any `yaml:"exporters"`
+	Routes    []Route                   `yaml:"routes"`
+}
+
+// Defaults apply to the routes leaving a field empty.
+type Defaults struct {
+	Brokers  []string `yaml:"brokers"`
+	Encoding string   `yaml:"encoding"`
+	// GroupPrefix prefixes the consumer group of each receiver, soc4kafka when empty.
+	GroupPrefix string `yaml:"group_prefix"`
+	Index       string `yaml:"index"`
+	Sourcetype  string `yaml:"sourcetype"`
+	Source      string `yaml:"source"`
+	// Exporter is the exporter of routes without one. When empty, the exporter named primary or the only exporter.
+	Exporter string `yaml:"exporter"`
+}
+
+// Route maps a topic, or the topics matching a regex starting with ^, to a destination.
+type Route struct {
+	Topic      string `yaml:"topic"`
+	Index      string `yaml:"index"`
+	Sourcetype string `yaml:"sourcetype"`
+	Source     string `yaml:"source"`
+	Exporter   string `yaml:"exporter"`
+	// Line is the line of the route in the table file, for error messages.
+	Line int `yaml:"-"`
+}
+
+// csvColumns are the columns of CSV tables, topic is required.
+var csvColumns = []string{"topic", "index", "sourcetype", "source", "exporter"}
+
+// Load reads a routing table, in CSV when the file name ends with .csv and in YAML otherwise. CSV tables only hold
+// routes: their defaults and exporters come from a YAML table passed as base, which may be nil.
+func Load(path string, base *Table) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	t := &Table{}
+	if base != nil {
+		*t = *base
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		routes, err := ParseCSV(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		t.Routes = append(slices.Clone(t.Routes), routes...)
+		return t, nil
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := ParseYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return merge(t, parsed), nil
+}
+
+// ParseYAML parses a YAML routing table.
+func ParseYAML(data []byte) (*Table, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	t := &Table{}
+	if len(doc.Content) == 0 {
+		return t, nil
+	}
+	if err := doc.Decode(t); err != nil {
+		return nil, err
+	}
+	// Record the line of each route for error messages.
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "routes" {
+			for j, item := range root.Content[i+1].Content {
+				if j < len(t.Routes) {
+					t.Routes[j].Line = item.Line
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// ParseCSV parses the routes of a CSV table. The first row names the columns, lines starting with # are comments.
+func ParseCSV(r io.Reader) ([]Route, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("empty table")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(csvColumns, name) {
+			return nil, fmt.Errorf("unknown column %q, expected %s", name, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["topic"]; !ok {
+		return nil, errors.New("missing column topic")
+	}
+	var routes []Route
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return routes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		routes = append(routes, Route{
+			Topic: field("topic"), Index: field("index"), Sourcetype: field("sourcetype"), Source: field("source"),
+			Exporter: field("exporter"), Line: line,
+		})
+	}
+}
+
+// merge returns base with the fields set in t replacing its own, and the routes of both.
+func merge(base, t *Table) *Table {
+	out := *base
+	d := &out.Defaults
+	if len(t.Defaults.Brokers) > 0 {
+		d.Brokers = t.Defaults.Brokers
+	}
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&d.Encoding, t.Defaults.Encoding}, {&d.GroupPrefix, t.Defaults.GroupPrefix}, {&d.Index, t.Defaults.Index},
+		{&d.Sourcetype, t.Defaults.Sourcetype}, {&d.Source, t.Defaults.Source}, {&d.Exporter, t.Defaults.Exporter},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if len(t.Exporters) > 0 {
+		out.Exporters = map[string]map[string]any{}
+		for name, e := range base.Exporters {
+			out.Exporters[name] = e
+		}
+		for name, e := range t.Exporters {
+			out.Exporters[name] = e
+		}
+	}
+	out.Routes = append(slices.Clone(base.Routes), t.Routes...)
+	return &out
+}
@@ -0,0 +1,232 @@
+package sc4kafka
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/timestamp"
+)
+
+// Connector settings of the timestamp extraction.
+const (
+	EnableTimestampExtraction = "enable.timestamp.extraction"
+	TimestampRegex            = "timestamp.regex"
+	TimestampFormat           = "timestamp.format"
+	TimestampTimezone         = "timestamp.timezone"
+)
+
+// javaShortIDs are the three letter IDs accepted by java.util.TimeZone, see ZoneId.SHORT_IDS. EST, MST and HST
+// are fixed offsets in Java.
+var javaShortIDs = map[string]string{
+	"ACT": "Australia/Darwin", "AET": "Australia/Sydney", "AGT": "America/Argentina/Buenos_Aires",
+	"ART": "Africa/Cairo", "AST": "America/Anchorage", "BET": "America/Sao_Paulo", "BST": "Asia/Dhaka",
+	"CAT": "Africa/Harare", "CNT": "America/St_Johns", "CST": "America/Chicago", "CTT": "Asia/Shanghai",
+	"EAT": "Africa/Addis_Ababa", "ECT": "Europe/Paris", "IET": "America/Indiana/Indianapolis", "IST": "Asia/Kolkata",
+	"JST": "Asia/Tokyo", "MIT": "Pacific/Apia", "NET": "Asia/Yerevan", "NST": "Pacific/Auckland",
+	"PLT": "Asia/Karachi", "PNT": "America/Phoenix", "PRT": "America/Puerto_Rico", "PST": "America/Los_Angeles",
+	"SST": "Pacific/Guadalcanal", "VST": "Asia/Ho_Chi_Minh", "EST": "Etc/GMT+5", "MST": "Etc/GMT+7",
+	"HST": "Etc/GMT+10",
+}
+
+// Timezone translates a Java time zone ID to an IANA name loadable by the collector. Custom IDs such as GMT+02:00
+// are only supported for whole hours, as Etc/GMT-2.
+func Timezone(id string) (Translation, error) {
+	switch {
+	case id == "":
+		return Translation{Value: "UTC"}, nil
+	case javaShortIDs[id] != "":
+		return Translation{Value: javaShortIDs[id],
+			Warnings: []string{fmt.Sprintf("the Java short ID %s is translated to %s", id, javaShortIDs[id])}}, nil
+	}
+	for _, prefix := range []string{"GMT", "UTC", "UT"} {
+		offset, ok := strings.CutPrefix(id, prefix)
+		if !ok || offset == "" || offset[0] != '+' && offset[0] != '-' {
+			continue
+		}
+		hours, minutes, _ := strings.Cut(offset[1:], ":")
+		if !strings.Contains(offset, ":") && len(hours) == 4 {
+			hours, minutes = hours[:2], hours[2:]
+		}
+		h, err := strconv.Atoi(hours)
+		if err != nil || h > 14 || minutes != "" && minutes != "00" {
+			return Translation{}, &UnsupportedError{What: TimestampTimezone, Value: id,
+				Problems: []string{"only custom offsets in whole hours can be mapped to an IANA zone"}}
+		}
+		if h == 0 {
+			return Translation{Value: "UTC"}, nil
+		}
+		// The sign of the Etc/GMT zones is inverted.
+		sign := "-"
+		if offset[0] == '-' {
+			sign = "+"
+		}
+		return Translation{Value: fmt.Sprintf("Etc/GMT%s%d", sign, h)}, nil
+	}
+	if _, err := time.LoadLocation(id); err != nil {
+		return Translation{}, &UnsupportedError{What: TimestampTimezone, Value: id, Problems: []string{err.Error()}}
+	}
+	return Translation{Value: id}, nil
+}
+
+// LoadConnectorConfig reads the settings of a connector, either a Kafka Connect REST API payload in JSON, with the
+// settings under "config" or at the top level, or a properties file.
+func LoadConnectorConfig(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := ParseConnectorConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// ParseConnectorConfig parses the settings of a connector, see LoadConnectorConfig.
+func ParseConnectorConfig(data []byte) (map[string]string, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var payload map[string]any
+		if err := json.Unmarshal(trimmed, &payload); err != nil {
+			return nil, err
+		}
+		if config, ok := payload["config"].(map[string]any); ok {
+			payload = config
+		}
+		settings := map[string]string{}
+		for k, v := range payload {
+			if s, ok := v.(string); ok {
+				settings[k] = s
+			} else {
+				settings[k] = fmt.Sprint(v)
+			}
+		}
+		return settings, nil
+	}
+	return parseProperties(data)
+}
+
+// parseProperties parses a Java properties file: key=value or key: value lines, # and ! comments, backslash escapes
+// and line continuations.
+func parseProperties(data []byte) (map[string]string, error) {
+	settings := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var logical string
+	for scanner.Scan() {
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if logical == "" && (line == "" || line[0] == '#' || line[0] == '!') {
+			continue
+		}
+		if trailing := len(line) - len(strings.TrimRight(line, `\`)); trailing%2 == 1 {
+			logical += line[:len(line)-1]
+			continue
+		}
+		logical += line
+		key, value := splitProperty(logical)
+		settings[unescapeProperty(key)] = unescapeProperty(value)
+		logical = ""
+	}
+	if logical != "" {
+		key, value := splitProperty(logical)
+		settings[unescapeProperty(key)] = unescapeProperty(value)
+	}
+	return settings, scanner.Err()
+}
+
+func splitProperty(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '=', ':', ' ', '\t':
+			key, rest := line[:i], strings.TrimLeft(line[i:], " \t\f")
+			if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+				rest = strings.TrimLeft(rest[1:], " \t\f")
+			}
+			return key, rest
+		}
+	}
+	return line, ""
+}
+
+func unescapeProperty(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 <= len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 32); err == nil {
+					b.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			b.WriteByte('u')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// Conversion is the timestamp extraction translated from a connector.
+type Conversion struct {
+	Extraction timestamp.Extraction `json:"-"`
+	Regex      Translation          `json:"regex"`
+	Format     Translation          `json:"format"`
+	Timezone   Translation          `json:"timezone"`
+}
+
+// Warnings returns the warnings of all the translated settings.
+func (c Conversion) Warnings() []string {
+	var warnings []string
+	for _, t := range []Translation{c.Regex, c.Format, c.Timezone} {
+		warnings = append(warnings, t.Warnings...)
+	}
+	return warnings
+}
+
+// ConvertTimestamp translates the timestamp settings of a connector. The errors of all the settings are joined.
+func ConvertTimestamp(settings map[string]string) (Conversion, error) {
+	var c Conversion
+	if enabled, ok := settings[EnableTimestampExtraction]; ok && enabled != "true" {
+		return c, fmt.Errorf("%s is %q, the connector does not extract timestamps", EnableTimestampExtraction, enabled)
+	}
+	var errs []error
+	var err error
+	if c.Regex, err = Regex(settings[TimestampRegex], TimeGroup, "timestamp"); err != nil {
+		errs = append(errs, err)
+	} else if !strings.Contains(c.Regex.Value, "(?P<timestamp>") {
+		errs = append(errs, fmt.Errorf("%s %q has no named group %s", TimestampRegex, settings[TimestampRegex], TimeGroup))
+	}
+	if c.Format, err = DateFormat(settings[TimestampFormat]); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Timezone, err = Timezone(settings[TimestampTimezone]); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return c, errors.Join(errs...)
+	}
+	c.Extraction = timestamp.Extraction{Pattern: c.Regex.Value, Format: c.Format.Value, Timezone: c.Timezone.Value}
+	return c, c.Extraction.Validate()
+}
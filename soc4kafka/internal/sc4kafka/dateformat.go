@@ -0,0 +1,193 @@
+// Package sc4kafka translates the timestamp extraction settings of Splunk Connect for Kafka (SC4Kafka) connectors,
+// written for Java, to the transform processor used by SOC4Kafka: SimpleDateFormat patterns to strptime formats,
+// Java regular expressions to RE2 and Java time zone IDs to IANA names.
+package sc4kafka
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/strptime"
+)
+
+// Translation is the result of a translation with the caveats of the translated value.
+type Translation struct {
+	Value    string   `json:"value"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// UnsupportedError lists the constructs of a Java value that have no equivalent.
+type UnsupportedError struct {
+	// What is the translated setting, e.g. timestamp.format.
+	What     string
+	Value    string
+	Problems []string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s %q cannot be translated: %s", e.What, e.Value, strings.Join(e.Problems, "; "))
+}
+
+// DateFormat translates a SimpleDateFormat pattern, e.g. yyyy-MM-dd'T'HH:mm:ss.SSSZ, to a strptime format, e.g.
+// %Y-%m-%dT%H:%M:%S.%L%z.
+func DateFormat(pattern string) (Translation, error) {
+	var (
+		out      strings.Builder
+		t        Translation
+		problems []string
+	)
+	if pattern == "" {
+		return t, &UnsupportedError{What: "timestamp.format", Value: pattern,
+			Problems: []string{"the format is empty, epoch timestamps cannot be parsed by the Time function"}}
+	}
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == '\'':
+			// Quoted text, '' is a single quote inside and outside quoted text.
+			if i+1 < len(pattern) && pattern[i+1] == '\'' {
+				out.WriteString("'")
+				i += 2
+				continue
+			}
+			end := i + 1
+			for {
+				next := strings.IndexByte(pattern[end:], '\'')
+				if next < 0 {
+					return t, &UnsupportedError{What: "timestamp.format", Value: pattern, Problems: []string{"unterminated quote"}}
+				}
+				out.WriteString(strings.ReplaceAll(pattern[end:end+next], "%", "%%"))
+				end += next + 1
+				if end < len(pattern) && pattern[end] == '\'' {
+					out.WriteString("'")
+					end++
+					continue
+				}
+				break
+			}
+			i = end
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			n := 1
+			for i+n < len(pattern) && pattern[i+n] == c {
+				n++
+			}
+			directive, warning, err := dateField(c, n)
+			if err != "" {
+				problems = append(problems, err)
+			}
+			if warning != "" {
+				t.Warnings = append(t.Warnings, warning)
+			}
+			if c == 'S' && (i == 0 || pattern[i-1] != '.' && pattern[i-1] != ',') {
+				problems = append(problems, "fractional seconds (S) must follow a '.' or ','")
+			}
+			out.WriteString(directive)
+			i += n
+		case c == '%':
+			out.WriteString("%%")
+			i++
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	t.Value = out.String()
+	if len(problems) == 0 {
+		if _, err := strptime.Layout(t.Value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return t, &UnsupportedError{What: "timestamp.format", Value: pattern, Problems: problems}
+	}
+	return t, nil
+}
+
+// dateField returns the strptime directive of a run of n pattern letters c.
+func dateField(c byte, n int) (directive, warning, problem string) {
+	run := strings.Repeat(string(c), n)
+	switch c {
+	case 'y':
+		if n == 2 {
+			return "%y", "", ""
+		}
+		return "%Y", "", ""
+	case 'M', 'L':
+		switch {
+		case n >= 4:
+			return "%B", "", ""
+		case n == 3:
+			return "%b", "", ""
+		case n == 2:
+			return "%m", "", ""
+		}
+		return "%q", "", ""
+	case 'd':
+		if n == 1 {
+			return "%g", "", ""
+		}
+		return "%d", "", ""
+	case 'H':
+		return "%H", "", ""
+	case 'h':
+		if n == 1 {
+			return "%l", "", ""
+		}
+		return "%I", "", ""
+	case 'm':
+		if n == 1 {
+			return "%M", "m is translated to %M, which requires two digit minutes", ""
+		}
+		return "%M", "", ""
+	case 's':
+		if n == 1 {
+			return "%S", "s is translated to %S, which requires two digit seconds", ""
+		}
+		return "%S", "", ""
+	case 'S':
+		switch {
+		case n <= 3:
+			return "%L", "", ""
+		case n <= 6:
+			return "%f", fmt.Sprintf("SimpleDateFormat reads %s as a number of milliseconds, %%f reads the digits as a fraction of a second", run), ""
+		}
+		return "%s", fmt.Sprintf("SimpleDateFormat reads %s as a number of milliseconds, %%s reads the digits as a fraction of a second", run), ""
+	case 'E':
+		if n >= 4 {
+			return "%A", "", ""
+		}
+		return "%a", "", ""
+	case 'a':
+		return "%p", "", ""
+	case 'z':
+		if n >= 4 {
+			return "", "", "long time zone names (zzzz) are not supported"
+		}
+		return "%Z", "zone abbreviations are only resolved when the timezone uses them, others are read as UTC", ""
+	case 'Z':
+		return "%z", "", ""
+	case 'X':
+		switch n {
+		case 1:
+			return "%i", "", ""
+		case 2:
+			return "%z", "", ""
+		}
+		return "%j", "", ""
+	case 'G', 'Y', 'w', 'W', 'D', 'F', 'u', 'k', 'K':
+		return "", "", fmt.Sprintf("pattern letter %c (%s) is not supported", c, javaFields[c])
+	}
+	return "", "", fmt.Sprintf("unknown pattern letter %c", c)
+}
+
+var javaFields = map[byte]string{
+	'G': "era",
+	'Y': "week year",
+	'w': "week in year",
+	'W': "week in month",
+	'D': "day in year",
+	'F': "day of week in month",
+	'u': "day number of week",
+	'k': "hour 1-24",
+	'K': "hour 0-11",
+}
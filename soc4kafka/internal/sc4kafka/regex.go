@@ -0,0 +1,312 @@
+package sc4kafka
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TimeGroup is the named group holding the timestamp in SC4Kafka regexes, renamed to the timestamp group expected
+// by the generated statements.
+const TimeGroup = "time"
+
+// Character classes of Java without an RE2 escape, written as class contents.
+const (
+	horizontalSpace = `\t \x{A0}\x{1680}\x{180E}\x{2000}-\x{200A}\x{202F}\x{205F}\x{3000}`
+	verticalSpace   = `\n\x0B\f\r\x{85}\x{2028}\x{2029}`
+)
+
+// posixClasses maps the Java POSIX property names to RE2 ASCII classes.
+var posixClasses = map[string]string{
+	"Lower": "lower", "Upper": "upper", "ASCII": "ascii", "Alpha": "alpha", "Digit": "digit", "Alnum": "alnum",
+	"Punct": "punct", "Graph": "graph", "Print": "print", "Blank": "blank", "Cntrl": "cntrl", "XDigit": "xdigit",
+	"Space": "space",
+}
+
+// Regex translates a Java regular expression to RE2, renaming the named group from to to. Constructs RE2 does not
+// support, such as lookarounds, backreferences, atomic groups and possessive quantifiers, are reported together in an
+// *UnsupportedError.
+func Regex(pattern, from, to string) (Translation, error) {
+	var (
+		out      strings.Builder
+		t        Translation
+		problems []string
+		inClass  bool
+		// classStart is the offset of the first member of the current class, where ] is a literal.
+		classStart int
+	)
+	unsupported := func(offset int, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("%s at offset %d", fmt.Sprintf(format, args...), offset))
+	}
+	// class writes a class given as contents, wrapped in brackets outside classes.
+	class := func(offset int, contents string, negated bool) {
+		switch {
+		case inClass && negated:
+			unsupported(offset, "negated class escape inside a character class")
+		case inClass:
+			out.WriteString(contents)
+		case negated:
+			out.WriteString("[^" + contents + "]")
+		default:
+			out.WriteString("[" + contents + "]")
+		}
+	}
+
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(pattern) {
+				unsupported(i, "trailing backslash")
+				i++
+				continue
+			}
+			e := pattern[i+1]
+			switch {
+			case e == 'Q':
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					out.WriteString(regexp.QuoteMeta(pattern[i+2:]))
+					i = len(pattern)
+					continue
+				}
+				out.WriteString(regexp.QuoteMeta(pattern[i+2 : i+2+end]))
+				i += end + 4
+				continue
+			case e >= '1' && e <= '9' && !inClass:
+				unsupported(i, `backreference \%c`, e)
+			case e == 'k':
+				unsupported(i, `named backreference \k`)
+			case e == 'G':
+				unsupported(i, `end of previous match \G`)
+			case e == 'X' || e == 'N':
+				unsupported(i, `\%c`, e)
+			case e == 'b' && strings.HasPrefix(pattern[i+2:], "{g}"):
+				unsupported(i, `grapheme boundary \b{g}`)
+				i += 3
+			case e == 'Z':
+				out.WriteString(`\z`)
+				t.Warnings = append(t.Warnings, `\Z is translated to \z, which does not match before a final line terminator`)
+			case e == 'h' || e == 'H':
+				class(i, horizontalSpace, e == 'H')
+			case e == 'v' || e == 'V':
+				class(i, verticalSpace, e == 'V')
+			case e == 'R':
+				if inClass {
+					unsupported(i, `\R inside a character class`)
+				} else {
+					out.WriteString(`(?:\r\n|[` + verticalSpace + `])`)
+				}
+			case e == 'e':
+				out.WriteString(`\x1B`)
+			case e == 'c' && i+2 < len(pattern):
+				fmt.Fprintf(&out, `\x%02X`, pattern[i+2]^64)
+				i += 3
+				continue
+			case e == '0':
+				// Octal escape: \0n, \0nn or \0mnn with m <= 3.
+				end := i + 2
+				for end < len(pattern) && end < i+5 && pattern[end] >= '0' && pattern[end] <= '7' {
+					end++
+				}
+				if end-i == 5 && pattern[i+2] > '3' {
+					end--
+				}
+				value, err := strconv.ParseUint(pattern[i+2:end], 8, 8)
+				if err != nil {
+					unsupported(i, "invalid octal escape")
+				}
+				fmt.Fprintf(&out, `\x%02X`, value)
+				i = end
+				continue
+			case e == 'u':
+				if i+6 > len(pattern) {
+					unsupported(i, `invalid \u escape`)
+					i = len(pattern)
+					continue
+				}
+				fmt.Fprintf(&out, `\x{%s}`, pattern[i+2:i+6])
+				i += 6
+				continue
+			case e == 'p' || e == 'P':
+				name, length := propertyName(pattern[i+2:])
+				translated, ok := property(name)
+				if !ok {
+					unsupported(i, `unsupported property \%c{%s}`, e, name)
+				} else if strings.HasPrefix(translated, "[:") {
+					class(i, translated, e == 'P')
+				} else {
+					fmt.Fprintf(&out, `\%c{%s}`, e, translated)
+				}
+				i += 2 + length
+				continue
+			default:
+				out.WriteString(pattern[i : i+2])
+			}
+			i += 2
+		case inClass:
+			switch {
+			case c == '[':
+				unsupported(i, "nested character class (union)")
+			case c == '&' && strings.HasPrefix(pattern[i:], "&&"):
+				unsupported(i, "character class intersection &&")
+				i++
+			case c == ']' && i > classStart:
+				inClass = false
+			}
+			out.WriteByte(c)
+			i++
+		case c == '[':
+			inClass = true
+			out.WriteByte(c)
+			i++
+			if i < len(pattern) && pattern[i] == '^' {
+				out.WriteByte('^')
+				i++
+			}
+			classStart = i
+		case c == '(' && strings.HasPrefix(pattern[i:], "(?"):
+			i = group(pattern, i, from, to, &out, &t, unsupported)
+		case c == '*' || c == '+' || c == '?':
+			out.WriteByte(c)
+			i++
+			if i < len(pattern) && pattern[i] == '+' {
+				unsupported(i-1, "possessive quantifier %c+", c)
+				i++
+			}
+		case c == '{':
+			end := strings.IndexByte(pattern[i:], '}')
+			if m := repetition.FindStringSubmatch(pattern[i:]); m != nil && end >= 0 {
+				for _, bound := range m[1:] {
+					if n, err := strconv.Atoi(bound); err == nil && n > 1000 {
+						unsupported(i, "repetition count %d above the RE2 limit of 1000", n)
+					}
+				}
+				out.WriteString(pattern[i : i+end+1])
+				i += end + 1
+				if i < len(pattern) && pattern[i] == '+' {
+					unsupported(i-end-1, "possessive quantifier")
+					i++
+				}
+				continue
+			}
+			out.WriteByte(c)
+			i++
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	t.Value = out.String()
+	if len(problems) == 0 {
+		if _, err := regexp.Compile(t.Value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return t, &UnsupportedError{What: "timestamp.regex", Value: pattern, Problems: problems}
+	}
+	return t, nil
+}
+
+var repetition = regexp.MustCompile(`^\{(\d+)(?:,(\d*))?\}`)
+
+// group translates the group opening at pattern[i], a "(?" construct, and returns the offset after it.
+func group(pattern string, i int, from, to string, out *strings.Builder, t *Translation, unsupported func(int, string, ...any)) int {
+	rest := pattern[i+2:]
+	switch {
+	case strings.HasPrefix(rest, "<=") || strings.HasPrefix(rest, "<!"):
+		unsupported(i, "lookbehind (?%s", rest[:2])
+		out.WriteString("(?:")
+		return i + 4
+	case strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "!"):
+		unsupported(i, "lookahead (?%s", rest[:1])
+		out.WriteString("(?:")
+		return i + 3
+	case strings.HasPrefix(rest, ">"):
+		unsupported(i, "atomic group (?>")
+		out.WriteString("(?:")
+		return i + 3
+	case strings.HasPrefix(rest, "<"):
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			unsupported(i, "unterminated group name")
+			return len(pattern)
+		}
+		name := rest[1:end]
+		if name == from {
+			name = to
+		}
+		out.WriteString("(?P<" + name + ">")
+		return i + 2 + end + 1
+	}
+	// Inline flags, (?flags) or (?flags:...).
+	end := strings.IndexAny(rest, ":)")
+	if end < 0 {
+		unsupported(i, "unterminated group")
+		return len(pattern)
+	}
+	var flags strings.Builder
+	for _, f := range rest[:end] {
+		switch f {
+		case 'i', 'm', 's', '-':
+			flags.WriteRune(f)
+		case 'd':
+			// UNIX_LINES: RE2 only treats \n as a line terminator anyway.
+		case 'u':
+			t.Warnings = append(t.Warnings, "flag u is dropped, RE2 case folding is always Unicode aware")
+		case 'U':
+			t.Warnings = append(t.Warnings, `flag U is dropped, \w, \d, \s and \b only match ASCII in RE2`)
+		case 'x':
+			unsupported(i, "comments mode (?x)")
+		default:
+			unsupported(i, "flag %c", f)
+		}
+	}
+	f := strings.TrimSuffix(flags.String(), "-")
+	switch {
+	case rest[end] == ':':
+		out.WriteString("(?" + f + ":")
+	case f != "":
+		out.WriteString("(?" + f + ")")
+	}
+	return i + 2 + end + 1
+}
+
+// propertyName returns the name of a \p escape, either {name} or a single letter, and the length it spans.
+func propertyName(s string) (string, int) {
+	if strings.HasPrefix(s, "{") {
+		if end := strings.IndexByte(s, '}'); end >= 0 {
+			return s[1:end], end + 1
+		}
+		return s, len(s)
+	}
+	if s == "" {
+		return "", 0
+	}
+	return s[:1], 1
+}
+
+// property translates a Java property name to an RE2 one, or to a POSIX class starting with "[:".
+func property(name string) (string, bool) {
+	if posix, ok := posixClasses[name]; ok {
+		return "[:" + posix + ":]", true
+	}
+	for _, prefix := range []string{"Is", "general_category=", "gc=", "script=", "sc="} {
+		if trimmed, ok := strings.CutPrefix(name, prefix); ok {
+			name = trimmed
+			break
+		}
+	}
+	if _, ok := unicode.Categories[name]; ok {
+		return name, true
+	}
+	for script := range unicode.Scripts {
+		if strings.EqualFold(script, name) {
+			return script, true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,188 @@
+package sc4kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/strptime"
+)
+
+// Each sample is the reference time as formatted by SimpleDateFormat with the pattern: parsing it with the
+// translated format and formatting the result again must give back the sample.
+func TestDateFormatRoundTrip(t *testing.T) {
+	plus2 := time.FixedZone("", 2*3600)
+	minus7 := time.FixedZone("", -7*3600)
+	for _, tc := range []struct {
+		java, strptime, sample string
+		time                   time.Time
+	}{
+		{"yyyy-MM-dd'T'HH:mm:ss.SSSZ", "%Y-%m-%dT%H:%M:%S.%L%z", "2024-05-01T10:20:30.123-0700", time.Date(2024, 5, 1, 10, 20, 30, 123e6, minus7)},
+		{"yyyy-MM-dd'T'HH:mm:ss.SSSXXX", "%Y-%m-%dT%H:%M:%S.%L%j", "2024-05-01T10:20:30.123+02:00", time.Date(2024, 5, 1, 10, 20, 30, 123e6, plus2)},
+		{"yyyy-MM-dd HH:mm:ss", "%Y-%m-%d %H:%M:%S", "2024-05-01 10:20:30", time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)},
+		{"yyyy-MM-dd HH:mm:ss,SSS", "%Y-%m-%d %H:%M:%S,%L", "2024-05-01 10:20:30,123", time.Date(2024, 5, 1, 10, 20, 30, 123e6, time.UTC)},
+		{"dd/MMM/yyyy:HH:mm:ss Z", "%d/%b/%Y:%H:%M:%S %z", "01/May/2024:10:20:30 +0200", time.Date(2024, 5, 1, 10, 20, 30, 0, plus2)},
+		{"EEE MMM dd HH:mm:ss yyyy", "%a %b %d %H:%M:%S %Y", "Wed May 01 10:20:30 2024", time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)},
+		{"MM/dd/yyyy hh:mm:ss a", "%m/%d/%Y %I:%M:%S %p", "05/01/2024 10:20:30 PM", time.Date(2024, 5, 1, 22, 20, 30, 0, time.UTC)},
+		{"yyyyMMdd'T'HHmmss", "%Y%m%dT%H%M%S", "20240501T102030", time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)},
+		{"EEEE, d MMMM yy HH:mm", "%A, %g %B %y %H:%M", "Wednesday, 1 May 24 10:20", time.Date(2024, 5, 1, 10, 20, 0, 0, time.UTC)},
+		{"'Date:' yyyy.MM.dd 'at' HH:mm:ss", "Date: %Y.%m.%d at %H:%M:%S", "Date: 2024.05.01 at 10:20:30", time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)},
+		{"yyyy-MM-dd'T'HH:mm:ss''SSS", "", "", time.Time{}},
+	} {
+		translated, err := DateFormat(tc.java)
+		if tc.strptime == "" {
+			assert.Error(t, err, tc.java)
+			continue
+		}
+		require.NoError(t, err, tc.java)
+		assert.Equal(t, tc.strptime, translated.Value, tc.java)
+
+		parsed, err := strptime.Parse(translated.Value, tc.sample, time.UTC)
+		require.NoError(t, err, tc.java)
+		assert.True(t, parsed.Equal(tc.time), "%s: %s != %s", tc.java, parsed, tc.time)
+		layout, err := strptime.Layout(translated.Value)
+		require.NoError(t, err)
+		assert.Equal(t, tc.sample, parsed.Format(layout), tc.java)
+	}
+}
+
+func TestDateFormatProblems(t *testing.T) {
+	translated, err := DateFormat("yyyy-MM-dd HH:mm:ss.SSSSSS")
+	require.NoError(t, err)
+	assert.Equal(t, "%Y-%m-%d %H:%M:%S.%f", translated.Value)
+	assert.Len(t, translated.Warnings, 1)
+
+	_, err = DateFormat("YYYY-ww kk:mm zzzz")
+	assert.EqualError(t, err, `timestamp.format "YYYY-ww kk:mm zzzz" cannot be translated: pattern letter Y (week year) is not supported; `+
+		"pattern letter w (week in year) is not supported; pattern letter k (hour 1-24) is not supported; "+
+		"long time zone names (zzzz) are not supported")
+	_, err = DateFormat("HH:mm:ssSSS")
+	assert.ErrorContains(t, err, "must follow a '.' or ','")
+	_, err = DateFormat("'Monday' yyyy")
+	assert.ErrorContains(t, err, "Go layout element")
+	_, err = DateFormat("")
+	assert.ErrorContains(t, err, "epoch")
+	_, err = DateFormat("yyyy 'unterminated")
+	assert.ErrorContains(t, err, "unterminated quote")
+}
+
+func TestRegex(t *testing.T) {
+	for java, want := range map[string]string{
+		`\[(?<time>\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\]`: `\[(?P<timestamp>\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})\]`,
+		`"time":\s*"(?<time>.*?)"`:                         `"time":\s*"(?P<timestamp>.*?)"`,
+		`(?i)ts=(?<time>\S+)(?:\h|$)`:                      `(?i)ts=(?P<timestamp>\S+)(?:[` + horizontalSpace + `]|$)`,
+		`(?iu:at) (?<time>\p{Digit}+)\p{IsLatin}*`:         `(?i:at) (?P<timestamp>[[:digit:]]+)\p{Latin}*`,
+		`\Q[time]\E (?<time>[^\p{Space}]+)\Z`:              `\[time\] (?P<timestamp>[^[:space:]]+)\z`,
+		`(?<time>\d+)\u00e9\0101\e\cA`:                     `(?P<timestamp>\d+)\x{00e9}\x41\x1B\x01`,
+		`(?d)(?<time>[]a-z]+)\R`:                           `(?P<timestamp>[]a-z]+)(?:\r\n|[` + verticalSpace + `])`,
+		`(?<time>\d{2,4}).{1,3}?`:                          `(?P<timestamp>\d{2,4}).{1,3}?`,
+	} {
+		translated, err := Regex(java, TimeGroup, "timestamp")
+		require.NoError(t, err, java)
+		assert.Equal(t, want, translated.Value, java)
+	}
+
+	translated, err := Regex(`(?U)\w+\Z`, TimeGroup, "timestamp")
+	require.NoError(t, err)
+	assert.Len(t, translated.Warnings, 2)
+}
+
+func TestRegexUnsupported(t *testing.T) {
+	for java, problem := range map[string]string{
+		`(?<=at )(?<time>\d+)`:    "lookbehind (?<= at offset 0",
+		`(?<time>\d+)(?!ms)`:      "lookahead (?! at offset 12",
+		`(?<time>\d+) \1`:         `backreference \1 at offset 13`,
+		`(?<q>')(?<time>.*)\k<q>`: `named backreference \k`,
+		`(?>\d+)`:                 "atomic group (?> at offset 0",
+		`\d++`:                    "possessive quantifier ++ at offset 2",
+		`\d{2}+`:                  "possessive quantifier at offset 2",
+		`[a-z&&[^aeiou]]`:         "character class intersection && at offset 4",
+		`(?x) \d+ # digits`:       "comments mode (?x) at offset 0",
+		`\d{1001}`:                "repetition count 1001 above the RE2 limit of 1000",
+		`\p{InGreek}`:             `unsupported property \p{InGreek}`,
+		`[\H]`:                    "negated class escape inside a character class",
+		`\Gfoo`:                   `end of previous match \G`,
+	} {
+		_, err := Regex(java, TimeGroup, "timestamp")
+		var unsupported *UnsupportedError
+		require.ErrorAs(t, err, &unsupported, java)
+		assert.Contains(t, unsupported.Error(), problem, java)
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	for java, want := range map[string]string{
+		"":                 "UTC",
+		"UTC":              "UTC",
+		"America/New_York": "America/New_York",
+		"PST":              "America/Los_Angeles",
+		"EST":              "Etc/GMT+5",
+		"GMT+02:00":        "Etc/GMT-2",
+		"GMT-0500":         "Etc/GMT+5",
+		"UTC+0":            "UTC",
+	} {
+		translated, err := Timezone(java)
+		require.NoError(t, err, java)
+		assert.Equal(t, want, translated.Value, java)
+		_, err = time.LoadLocation(translated.Value)
+		assert.NoError(t, err, java)
+	}
+	_, err := Timezone("GMT+05:30")
+	assert.ErrorContains(t, err, "whole hours")
+	_, err = Timezone("Mars/Olympus")
+	assert.Error(t, err)
+}
+
+func TestParseConnectorConfig(t *testing.T) {
+	settings, err := ParseConnectorConfig([]byte(`{
+  "name": "kafka-connect-splunk",
+  "config": {
+    "tasks.max": 3,
+    "enable.timestamp.extraction": "true",
+    "timestamp.regex": "\\\"time\\\":\\s*\\\"(?<time>.*?)\"",
+    "timestamp.format": "yyyy-MM-dd'T'HH:mm:ss.SSSZ"
+  }
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, `\"time\":\s*\"(?<time>.*?)"`, settings[TimestampRegex])
+	assert.Equal(t, "3", settings["tasks.max"])
+
+	settings, err = ParseConnectorConfig([]byte(`# SC4Kafka
+name=kafka-connect-splunk
+enable.timestamp.extraction = true
+timestamp.regex: \\[(?<time>[^\\]]+)\\] \
+    \\u00e9
+timestamp.format=yyyy-MM-dd HH:mm:ss
+timestamp.timezone   Europe/Paris
+`))
+	require.NoError(t, err)
+	assert.Equal(t, `\[(?<time>[^\]]+)\] \u00e9`, settings[TimestampRegex])
+	assert.Equal(t, "yyyy-MM-dd HH:mm:ss", settings[TimestampFormat])
+	assert.Equal(t, "Europe/Paris", settings[TimestampTimezone])
+}
+
+func TestConvertTimestamp(t *testing.T) {
+	c, err := ConvertTimestamp(map[string]string{
+		EnableTimestampExtraction: "true",
+		TimestampRegex:            `\[(?<time>[^\]]+)\]`,
+		TimestampFormat:           "yyyy-MM-dd HH:mm:ss",
+		TimestampTimezone:         "ECT",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `\[(?P<timestamp>[^\]]+)\]`, c.Extraction.Pattern)
+	assert.Equal(t, "%Y-%m-%d %H:%M:%S", c.Extraction.Format)
+	assert.Equal(t, "Europe/Paris", c.Extraction.Timezone)
+	assert.Len(t, c.Warnings(), 1)
+
+	_, err = ConvertTimestamp(map[string]string{TimestampRegex: `(?<ts>\d+)(?=x)`, TimestampFormat: "yyyy", TimestampTimezone: "GMT+05:30"})
+	assert.ErrorContains(t, err, "lookahead")
+	assert.ErrorContains(t, err, "whole hours")
+
+	_, err = ConvertTimestamp(map[string]string{TimestampRegex: `(?<ts>\d+)`, TimestampFormat: "yyyy"})
+	assert.ErrorContains(t, err, "has no named group time")
+
+	_, err = ConvertTimestamp(map[string]string{EnableTimestampExtraction: "false"})
+	assert.ErrorContains(t, err, "does not extract timestamps")
+}
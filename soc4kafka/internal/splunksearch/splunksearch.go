@@ -0,0 +1,166 @@
+// Package splunksearch runs searches through the search job REST API of the Splunk management port, the same flow
+// as the functional tests: the job is created, its status is polled until it is done and its results are read.
+package splunksearch
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client runs search jobs as a user authenticated with basic auth.
+type Client struct {
+	// BaseURL of the management port, e.g. https://splunk:8089.
+	BaseURL  string
+	User     string
+	Password string
+	// HTTPClient defaults to http.DefaultClient, see NewHTTPClient for self-signed certificates.
+	HTTPClient *http.Client
+	// PollInterval between two status requests, defaults to one second.
+	PollInterval time.Duration
+}
+
+// NewHTTPClient returns a client for the management port, which usually has a self-signed certificate.
+func NewHTTPClient(insecureSkipVerify bool, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify}},
+	}
+}
+
+// Events runs query between earliest and latest, Splunk time modifiers such as -15m or now, and returns its events.
+func (c *Client) Events(ctx context.Context, query, earliest, latest string) ([]map[string]any, error) {
+	return c.search(ctx, query, earliest, latest, "events")
+}
+
+// Results runs query like Events and returns its results, the rows of a transforming search.
+func (c *Client) Results(ctx context.Context, query, earliest, latest string) ([]map[string]any, error) {
+	return c.search(ctx, query, earliest, latest, "results")
+}
+
+func (c *Client) search(ctx context.Context, query, earliest, latest, endpoint string) ([]map[string]any, error) {
+	sid, err := c.postJob(ctx, query, earliest, latest)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.waitForJob(ctx, sid); err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Results []map[string]any `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/services/search/v2/jobs/"+url.PathEscape(sid)+"/"+endpoint+"?output_mode=json&count=0", nil, &resp); err != nil {
+		return nil, fmt.Errorf("reading the %s of search job %s: %w", endpoint, sid, err)
+	}
+	return resp.Results, nil
+}
+
+func (c *Client) postJob(ctx context.Context, query, earliest, latest string) (string, error) {
+	if !strings.HasPrefix(strings.TrimSpace(query), "search ") && !strings.HasPrefix(strings.TrimSpace(query), "|") {
+		query = "search " + query
+	}
+	data := url.Values{}
+	data.Set("search", query)
+	data.Set("earliest_time", earliest)
+	data.Set("latest_time", latest)
+	var resp struct {
+		SID string `json:"sid"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/services/search/v2/jobs?output_mode=json", data, &resp); err != nil {
+		return "", fmt.Errorf("creating the search job: %w", err)
+	}
+	if resp.SID == "" {
+		return "", fmt.Errorf("creating the search job: the response has no sid")
+	}
+	return resp.SID, nil
+}
+
+func (c *Client) waitForJob(ctx context.Context, sid string) error {
+	interval := c.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		var resp struct {
+			Entry []struct {
+				Content struct {
+					IsDone        bool   `json:"isDone"`
+					IsFailed      bool   `json:"isFailed"`
+					DispatchState string `json:"dispatchState"`
+				} `json:"content"`
+			} `json:"entry"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/services/search/v2/jobs/"+url.PathEscape(sid)+"?output_mode=json", nil, &resp); err != nil {
+			return fmt.Errorf("checking search job %s: %w", sid, err)
+		}
+		if len(resp.Entry) == 0 {
+			return fmt.Errorf("checking search job %s: the response has no entry", sid)
+		}
+		if content := resp.Entry[0].Content; content.IsFailed {
+			return fmt.Errorf("search job %s failed in state %s", sid, content.DispatchState)
+		} else if content.IsDone {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("search job %s did not complete: %w", sid, ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, form url.Values, out any) error {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.User, c.Password)
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, messages(data))
+	}
+	return json.Unmarshal(data, out)
+}
+
+// messages extracts the text of the messages of an error response, or returns the body.
+func messages(data []byte) string {
+	var resp struct {
+		Messages []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil || len(resp.Messages) == 0 {
+		return strings.TrimSpace(string(data))
+	}
+	texts := make([]string, len(resp.Messages))
+	for i, m := range resp.Messages {
+		texts[i] = m.Text
+	}
+	return strings.Join(texts, "; ")
+}
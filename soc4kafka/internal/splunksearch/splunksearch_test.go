@@ -0,0 +1,42 @@
+package splunksearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/splunktest"
+)
+
+func TestResults(t *testing.T) {
+	server := splunktest.NewServer(t)
+	indexed := time.Unix(1714558830, 0)
+	server.Index("canary abc", indexed)
+	server.Index("other", indexed)
+	c := &Client{BaseURL: server.URL + "/", User: splunktest.User, Password: splunktest.Password,
+		HTTPClient: NewHTTPClient(true, 5*time.Second), PollInterval: time.Millisecond}
+
+	results, err := c.Results(context.Background(), `index=* "abc" | head 1`, "-15m", "now")
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"_raw": "canary abc", "_indextime": "1714558830"}}, results)
+	assert.Equal(t, []string{`search index=* "abc" | head 1`}, server.Queries)
+
+	results, err = c.Events(context.Background(), `| search "missing"`, "-15m", "now")
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, `| search "missing"`, server.Queries[1])
+}
+
+func TestErrors(t *testing.T) {
+	server := splunktest.NewServer(t)
+	c := &Client{BaseURL: server.URL, User: splunktest.User, Password: "wrong", HTTPClient: NewHTTPClient(true, 5*time.Second)}
+	_, err := c.Results(context.Background(), "index=*", "-15m", "now")
+	require.EqualError(t, err, "creating the search job: 401 Unauthorized: call not properly authenticated")
+
+	c = &Client{BaseURL: server.URL, User: splunktest.User, Password: splunktest.Password, HTTPClient: NewHTTPClient(false, 5*time.Second)}
+	_, err = c.Results(context.Background(), "index=*", "-15m", "now")
+	require.ErrorContains(t, err, "certificate")
+}
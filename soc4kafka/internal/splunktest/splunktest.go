@@ -0,0 +1,123 @@
+// Package splunktest provides a stand-in for the search job API of the Splunk management port, for tests.
+package splunktest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// User and Password accepted by the server.
+const (
+	User     = "admin"
+	Password = "changeme"
+)
+
+// Server is a TLS server closed at the end of the test. A search matches the indexed events whose raw text
+// contains every quoted term of the query; a job reports done on its second status request.
+type Server struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []map[string]any
+	jobs   map[string]*job
+	// Queries received by the server, in order.
+	Queries []string
+	// Feed, when set, is called on each new search job and returns raw events to index before running it.
+	Feed func() []string
+}
+
+type job struct {
+	query  string
+	checks int
+}
+
+var quotedTerm = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+
+// NewServer starts a server without events.
+func NewServer(t *testing.T) *Server {
+	s := &Server{jobs: map[string]*job{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /services/search/v2/jobs", s.create)
+	mux.HandleFunc("GET /services/search/v2/jobs/{sid}", s.status)
+	mux.HandleFunc("GET /services/search/v2/jobs/{sid}/{endpoint}", s.results)
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != User || password != Password {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Index adds an event with its index time, both exposed as the _raw and _indextime fields.
+func (s *Server) Index(raw string, indexTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, map[string]any{"_raw": raw, "_indextime": strconv.FormatInt(indexTime.Unix(), 10)})
+}
+
+func (s *Server) search(query string) []map[string]any {
+	out := []map[string]any{}
+	for _, e := range s.events {
+		matched := true
+		for _, m := range quotedTerm.FindAllStringSubmatch(query, -1) {
+			matched = matched && strings.Contains(e["_raw"].(string), m[1])
+		}
+		if matched {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Feed != nil {
+		for _, raw := range s.Feed() {
+			s.events = append(s.events, map[string]any{"_raw": raw, "_indextime": strconv.FormatInt(time.Now().Unix(), 10)})
+		}
+	}
+	query := r.FormValue("search")
+	s.Queries = append(s.Queries, query)
+	sid := "job" + strconv.Itoa(len(s.Queries))
+	s.jobs[sid] = &job{query: query}
+	reply(w, map[string]any{"sid": sid})
+}
+
+func (s *Server) status(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[r.PathValue("sid")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	j.checks++
+	reply(w, map[string]any{"entry": []any{map[string]any{"content": map[string]any{"isDone": j.checks > 1, "dispatchState": "RUNNING"}}}})
+}
+
+func (s *Server) results(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[r.PathValue("sid")]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	reply(w, map[string]any{"results": s.search(j.query)})
+}
+
+func reply(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
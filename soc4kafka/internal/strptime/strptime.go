@@ -0,0 +1,113 @@
+// Package strptime converts the strptime-style formats accepted by the OTTL Time function to Go time layouts, with
+// the directives supported by the collector (see the ctimefmt package of opentelemetry-collector-contrib).
+//
+// The components module has a copy, components/internal/strptime, whose tests fail when the two diverge.
+package strptime
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// directives maps each supported directive to its Go layout element.
+var directives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'o': "_1",
+	'q': "1",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'd': "02",
+	'e': "_2",
+	'g': "2",
+	'a': "Mon",
+	'A': "Monday",
+	'H': "15",
+	'l': "3",
+	'I': "03",
+	'p': "PM",
+	'P': "pm",
+	'M': "04",
+	'S': "05",
+	'L': "999",
+	'f': "999999",
+	's': "999999999",
+	'Z': "MST",
+	'z': "Z0700",
+	'w': "-070000",
+	'i': "-07",
+	'j': "-07:00",
+	'k': "-07:00:00",
+	'D': "01/02/2006",
+	'x': "01/02/2006",
+	'F': "2006-01-02",
+	'T': "15:04:05",
+	'X': "15:04:05",
+	'r': "03:04:05 pm",
+	'R': "15:04",
+	'n': "\n",
+	't': "\t",
+	'%': "%",
+	'c': "Mon Jan 02 15:04:05 2006",
+}
+
+// goElements are the Go layout elements that literal text must not contain, since Go would parse them as values.
+var goElements = []string{
+	"January", "Jan", "Monday", "Mon", "MST", "PM", "pm", "2006", "01", "02", "03", "04", "05", "06", "15", "_2",
+	"-07", "Z07", "1", "2", "3", "4", "5", "6", "7",
+}
+
+// fractional are the directives of fractional seconds. Like the collector, which rejects them otherwise, they must
+// follow a '.' or ',' separating them from the seconds: Go only parses fractional seconds there.
+var fractional = "Lfs"
+
+// Layout returns the Go time layout of a strptime format. Unsupported directives, fractional seconds that do not follow
+// '.' or ',', and literal text that Go would interpret as a layout element, e.g. the digits or "Mon" in "Monitor",
+// are errors.
+func Layout(format string) (string, error) {
+	var layout strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			literal := format[i:]
+			if next := strings.IndexByte(literal, '%'); next >= 0 {
+				literal = literal[:next]
+			}
+			for _, element := range goElements {
+				if strings.Contains(literal, element) {
+					return "", fmt.Errorf("literal %q would be parsed as the Go layout element %q", literal, element)
+				}
+			}
+			layout.WriteString(literal)
+			i += len(literal) - 1
+			continue
+		}
+		if i+1 == len(format) {
+			return "", fmt.Errorf("format %q ends with a lone %%", format)
+		}
+		element, ok := directives[format[i+1]]
+		if !ok {
+			return "", fmt.Errorf("unsupported directive %%%c", format[i+1])
+		}
+		if strings.IndexByte(fractional, format[i+1]) >= 0 {
+			if l := layout.String(); l == "" || (l[len(l)-1] != '.' && l[len(l)-1] != ',') {
+				return "", fmt.Errorf("fractional seconds directive %%%c must follow '.' or ',', e.g. %%S.%%%c", format[i+1], format[i+1])
+			}
+		}
+		layout.WriteString(element)
+		i++
+	}
+	return layout.String(), nil
+}
+
+// Parse parses value with a strptime format, in loc when the value has no zone.
+func Parse(format, value string, loc *time.Location) (time.Time, error) {
+	layout, err := Layout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
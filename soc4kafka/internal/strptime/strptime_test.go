@@ -0,0 +1,60 @@
+package strptime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayout(t *testing.T) {
+	for format, want := range map[string]string{
+		"%Y-%m-%d %H:%M:%S":       "2006-01-02 15:04:05",
+		"%d/%b/%Y:%H:%M:%S %z":    "02/Jan/2006:15:04:05 Z0700",
+		"%Y-%m-%dT%H:%M:%S.%f%j":  "2006-01-02T15:04:05.999999-07:00",
+		"%a %b %e %I:%M:%S %p %Y": "Mon Jan _2 03:04:05 PM 2006",
+		"%F %T,%L":                "2006-01-02 15:04:05,999",
+		"%H:%M:%S.%s":             "15:04:05.999999999",
+		"%s":                      "",
+		"%Y%m%d%H%M%S%L":          "",
+		"100%% %D":                "",
+	} {
+		layout, err := Layout(format)
+		if want == "" {
+			assert.Error(t, err, format)
+			continue
+		}
+		require.NoError(t, err, format)
+		assert.Equal(t, want, layout, format)
+	}
+
+	_, err := Layout("%Y-%m-%d %Q")
+	assert.EqualError(t, err, "unsupported directive %Q")
+	_, err = Layout("%Y-%m-%d %")
+	assert.Error(t, err)
+	_, err = Layout("%H:%M:%S%f")
+	assert.EqualError(t, err, "fractional seconds directive %f must follow '.' or ',', e.g. %S.%f")
+	_, err = Layout("Mon %d")
+	assert.EqualError(t, err, `literal "Mon " would be parsed as the Go layout element "Mon"`)
+}
+
+func TestParse(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	got, err := Parse("%d/%b/%Y:%H:%M:%S %z", "10/Oct/2000:13:55:36 -0700", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2000, 10, 10, 20, 55, 36, 0, time.UTC)))
+
+	got, err = Parse("%Y-%m-%d %H:%M:%S", "2020-01-01 12:00:00", paris)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2020, 1, 1, 11, 0, 0, 0, time.UTC)))
+
+	got, err = Parse("%Y-%m-%d %H:%M:%S,%s", "2020-01-01 12:00:00,123456789", time.UTC)
+	require.NoError(t, err)
+	assert.True(t, got.Equal(time.Date(2020, 1, 1, 12, 0, 0, 123456789, time.UTC)))
+
+	_, err = Parse("%Y-%m-%d", "2020-01-01 12:00", time.UTC)
+	assert.Error(t, err)
+}
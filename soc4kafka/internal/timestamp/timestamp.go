@@ -0,0 +1,136 @@
+// Package timestamp builds the transform processor statements setting the time of a record from a timestamp in its
+// body, as described in docs/extracting_additional_data.md, and runs the extraction locally on sample messages.
+package timestamp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/strptime"
+)
+
+// DefaultProcessor is the ID of the generated transform processor.
+const DefaultProcessor = "transform/timestamp"
+
+// helperAttribute holds the captures of the pattern between the statements.
+const helperAttribute = "extracted_ts"
+
+// Extraction describes how the timestamp is found in the body and parsed.
+type Extraction struct {
+	// Pattern is a Go regular expression with a named capturing group timestamp, written as is: the escaping for
+	// OTTL and YAML is added by Statements and Processor.
+	Pattern string
+	// Format is the strptime-style format of the captured timestamp, e.g. %Y-%m-%d %H:%M:%S.
+	Format string
+	// Timezone is the IANA name of the location of timestamps without a zone, UTC when empty.
+	Timezone string
+}
+
+// Validate checks the pattern, the format and the timezone.
+func (x Extraction) Validate() error {
+	re, err := regexp.Compile(x.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if !slices.Contains(re.SubexpNames(), "timestamp") {
+		return errors.New("the pattern has no named capturing group timestamp, e.g. (?P<timestamp>...)")
+	}
+	if _, err := strptime.Layout(x.Format); err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+	if _, err := time.LoadLocation(x.timezone()); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	return nil
+}
+
+func (x Extraction) timezone() string {
+	if x.Timezone == "" {
+		return "UTC"
+	}
+	return x.Timezone
+}
+
+// Statements returns the three OTTL log statements: capture the timestamp in a helper attribute, set the record
+// time from it and delete the helper attribute.
+func (x Extraction) Statements() []string {
+	attr := fmt.Sprintf("log.attributes[%s]", quote(helperAttribute))
+	return []string{
+		fmt.Sprintf("set(%s, ExtractPatterns(log.body, %s))", attr, quote(x.Pattern)),
+		fmt.Sprintf("set(log.time, Time(%s[%s], %s, %s))", attr, quote("timestamp"), quote(x.Format), quote(x.timezone())),
+		fmt.Sprintf("delete_key(log.attributes, %s)", quote(helperAttribute)),
+	}
+}
+
+// Processor returns the YAML definition of a transform processor running the statements, indented to be pasted
+// under the processors section.
+func (x Extraction) Processor(id string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n  error_mode: ignore\n  log_statements:\n", id)
+	for _, s := range x.Statements() {
+		fmt.Fprintf(&b, "    - %s\n", yamlScalar(s))
+	}
+	return b.String()
+}
+
+// quote returns s as an OTTL string literal.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// yamlScalar returns s as a plain YAML scalar when it reads back unchanged, as in the documentation examples, and
+// single quoted otherwise, e.g. when the pattern contains ": " or " #".
+func yamlScalar(s string) string {
+	var decoded []string
+	if err := yaml.Unmarshal([]byte("- "+s), &decoded); err == nil && len(decoded) == 1 && decoded[0] == s {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Result is the outcome of the extraction on a sample message.
+type Result struct {
+	Sample string `json:"sample"`
+	// Extracted is the text captured by the timestamp group.
+	Extracted string     `json:"extracted,omitempty"`
+	Time      *time.Time `json:"time,omitempty"`
+	// Error explains why the record time would not be set from the message.
+	Error string `json:"error,omitempty"`
+}
+
+// Test runs the extraction on samples the way the statements do in the collector.
+func (x Extraction) Test(samples []string) ([]Result, error) {
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	re := regexp.MustCompile(x.Pattern)
+	group := re.SubexpIndex("timestamp")
+	loc, _ := time.LoadLocation(x.timezone())
+	results := make([]Result, 0, len(samples))
+	for _, sample := range samples {
+		r := Result{Sample: sample}
+		match := re.FindStringSubmatch(sample)
+		switch {
+		case match == nil:
+			r.Error = "the pattern does not match, the record time is not set"
+		case match[group] == "" && re.FindStringSubmatchIndex(sample)[2*group] < 0:
+			r.Error = "the timestamp group did not participate in the match, the record time is not set"
+		default:
+			r.Extracted = match[group]
+			t, err := strptime.Parse(x.Format, r.Extracted, loc)
+			if err != nil {
+				r.Error = fmt.Sprintf("cannot parse %q with %q: %v", r.Extracted, x.Format, err)
+				break
+			}
+			r.Time = &t
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
@@ -0,0 +1,73 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+var bracketed = Extraction{
+	Pattern: `\[(?P<timestamp>[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2})\]`,
+	Format:  "%Y-%m-%d %H:%M:%S",
+}
+
+// The statements must match the example of docs/extracting_additional_data.md.
+func TestProcessor(t *testing.T) {
+	assert.Equal(t, `transform/timestamp:
+  error_mode: ignore
+  log_statements:
+    - set(log.attributes["extracted_ts"], ExtractPatterns(log.body, "\\[(?P<timestamp>[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2})\\]"))
+    - set(log.time, Time(log.attributes["extracted_ts"]["timestamp"], "%Y-%m-%d %H:%M:%S", "UTC"))
+    - delete_key(log.attributes, "extracted_ts")
+`, bracketed.Processor(DefaultProcessor))
+}
+
+func TestProcessorQuoting(t *testing.T) {
+	x := Extraction{Pattern: `time: "(?P<timestamp>[^"]+)" #'`, Format: "%Y-%m-%dT%H:%M:%S%j", Timezone: "Europe/Paris"}
+	require.NoError(t, x.Validate())
+	var decoded map[string]struct {
+		LogStatements []string `yaml:"log_statements"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(x.Processor("transform")), &decoded))
+	assert.Equal(t, x.Statements(), decoded["transform"].LogStatements)
+	assert.Equal(t, `set(log.attributes["extracted_ts"], ExtractPatterns(log.body, "time: \"(?P<timestamp>[^\"]+)\" #'"))`, x.Statements()[0])
+	assert.Contains(t, x.Statements()[1], `"Europe/Paris"`)
+}
+
+func TestValidate(t *testing.T) {
+	assert.ErrorContains(t, Extraction{Pattern: `(\d+`, Format: "%Y"}.Validate(), "invalid pattern")
+	assert.ErrorContains(t, Extraction{Pattern: `(?P<ts>\d+)`, Format: "%Y"}.Validate(), "no named capturing group timestamp")
+	assert.ErrorContains(t, Extraction{Pattern: `(?P<timestamp>\d+)`, Format: "%Q"}.Validate(), "invalid format")
+	assert.ErrorContains(t, Extraction{Pattern: `(?P<timestamp>\d+)`, Format: "%Y", Timezone: "Mars/Base"}.Validate(), "invalid timezone")
+}
+
+func TestTest(t *testing.T) {
+	x := bracketed
+	x.Timezone = "America/New_York"
+	results, err := x.Test([]string{
+		"[2020-01-01 12:00:00] This event should have a custom timestamp!",
+		"2020-01-01 12:00:00 without brackets",
+		"[2020-02-30 12:00:00] invalid date",
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.NotNil(t, results[0].Time)
+	assert.Equal(t, "2020-01-01 12:00:00", results[0].Extracted)
+	assert.True(t, results[0].Time.Equal(time.Date(2020, 1, 1, 17, 0, 0, 0, time.UTC)))
+	assert.Empty(t, results[0].Error)
+
+	assert.Nil(t, results[1].Time)
+	assert.Equal(t, "the pattern does not match, the record time is not set", results[1].Error)
+
+	assert.Nil(t, results[2].Time)
+	assert.Equal(t, "2020-02-30 12:00:00", results[2].Extracted)
+	assert.Contains(t, results[2].Error, "day out of range")
+
+	results, err = Extraction{Pattern: `(?P<timestamp>\d+)?x`, Format: "%Y"}.Test([]string{"x"})
+	require.NoError(t, err)
+	assert.Contains(t, results[0].Error, "did not participate")
+}
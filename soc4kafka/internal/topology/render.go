@@ -0,0 +1,301 @@
+package topology
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteText writes a human readable description of the topology.
+func (t *Topology) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, p := range t.Pipelines {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "%s\n", p.ID)
+		for _, r := range p.Receivers {
+			if len(r.Topics) > 0 {
+				fmt.Fprintf(tw, "  topics\t%s\n", topicsLabel(r))
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", r.Kind, componentLabel(r))
+		}
+		var processors []string
+		for _, c := range p.Processors {
+			processors = append(processors, componentLabel(c))
+		}
+		if len(processors) > 0 {
+			fmt.Fprintf(tw, "  processors\t%s\n", strings.Join(processors, " -> "))
+		}
+		for _, e := range p.Exporters {
+			line := componentLabel(e)
+			if e.Destination != nil {
+				line += " -> " + destinationLabel(*e.Destination, " ")
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", e.Kind, line)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(t.Issues) > 0 {
+		fmt.Fprintf(w, "\nIssues:\n")
+		for _, i := range t.Issues {
+			fmt.Fprintf(w, "  %-8s  %s\n", i.Kind, i.Message())
+		}
+	}
+	return nil
+}
+
+// WriteDOT writes the topology as a Graphviz digraph.
+func (t *Topology) WriteDOT(w io.Writer) error {
+	g := t.graph()
+	fmt.Fprintf(w, "digraph soc4kafka {\n  rankdir=LR;\n  node [shape=box, fontname=\"Helvetica\"];\n")
+	writeNode := func(indent string, n node) {
+		attrs := []string{"label=" + dotQuote(strings.Join(n.lines, "\n"))}
+		switch n.shape {
+		case shapeTopic:
+			attrs = append(attrs, "shape=ellipse")
+		case shapeDestination:
+			attrs = append(attrs, "shape=note")
+		}
+		switch n.class {
+		case Dangling:
+			attrs = append(attrs, "style=dashed", "color=red", "fontcolor=red")
+		case Unused:
+			attrs = append(attrs, "style=dashed", "color=gray", "fontcolor=gray")
+		}
+		fmt.Fprintf(w, "%s%s [%s];\n", indent, dotQuote(n.id), strings.Join(attrs, ", "))
+	}
+	for _, n := range g.nodes {
+		if n.cluster == "" {
+			writeNode("  ", n)
+		}
+	}
+	for i, c := range g.clusters {
+		fmt.Fprintf(w, "  subgraph cluster_%d {\n    label=%s;\n    style=rounded;\n", i, dotQuote(c))
+		for _, n := range g.nodes {
+			if n.cluster == c {
+				writeNode("    ", n)
+			}
+		}
+		fmt.Fprintf(w, "  }\n")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  %s -> %s;\n", dotQuote(e[0]), dotQuote(e[1]))
+	}
+	_, err := fmt.Fprintf(w, "}\n")
+	return err
+}
+
+// WriteMermaid writes the topology as a Mermaid flowchart.
+func (t *Topology) WriteMermaid(w io.Writer) error {
+	g := t.graph()
+	ids := map[string]string{}
+	for i, n := range g.nodes {
+		ids[n.id] = fmt.Sprintf("n%d", i)
+	}
+	fmt.Fprintf(w, "flowchart LR\n")
+	writeNode := func(indent string, n node) {
+		label := mermaidQuote(strings.Join(n.lines, "<br/>"))
+		switch n.shape {
+		case shapeTopic:
+			fmt.Fprintf(w, "%s%s([%s])\n", indent, ids[n.id], label)
+		case shapeDestination:
+			fmt.Fprintf(w, "%s%s[/%s/]\n", indent, ids[n.id], label)
+		default:
+			fmt.Fprintf(w, "%s%s[%s]\n", indent, ids[n.id], label)
+		}
+	}
+	for _, n := range g.nodes {
+		if n.cluster == "" {
+			writeNode("  ", n)
+		}
+	}
+	for i, c := range g.clusters {
+		fmt.Fprintf(w, "  subgraph p%d [%s]\n", i, mermaidQuote(c))
+		for _, n := range g.nodes {
+			if n.cluster == c {
+				writeNode("    ", n)
+			}
+		}
+		fmt.Fprintf(w, "  end\n")
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(w, "  %s --> %s\n", ids[e[0]], ids[e[1]])
+	}
+	classes := map[IssueKind][]string{}
+	for _, n := range g.nodes {
+		if n.class != "" {
+			classes[n.class] = append(classes[n.class], ids[n.id])
+		}
+	}
+	fmt.Fprintf(w, "  classDef dangling stroke:#d00,color:#d00,stroke-dasharray:5 5\n")
+	fmt.Fprintf(w, "  classDef unused stroke:#999,color:#999,stroke-dasharray:5 5\n")
+	for _, kind := range []IssueKind{Dangling, Unused} {
+		if len(classes[kind]) > 0 {
+			fmt.Fprintf(w, "  class %s %s\n", strings.Join(classes[kind], ","), kind)
+		}
+	}
+	return nil
+}
+
+type shape int
+
+const (
+	shapeComponent shape = iota
+	shapeTopic
+	shapeDestination
+)
+
+type node struct {
+	id    string
+	lines []string
+	shape shape
+	// class marks dangling references and unused components.
+	class IssueKind
+	// cluster is the pipeline grouping the node, processors are drawn per pipeline since their order differs.
+	cluster string
+}
+
+type graph struct {
+	nodes    []node
+	edges    [][2]string
+	clusters []string
+}
+
+func (g *graph) add(n node) string {
+	for _, existing := range g.nodes {
+		if existing.id == n.id {
+			return n.id
+		}
+	}
+	g.nodes = append(g.nodes, n)
+	return n.id
+}
+
+func (g *graph) connect(from, to string) {
+	for _, e := range g.edges {
+		if e == [2]string{from, to} {
+			return
+		}
+	}
+	g.edges = append(g.edges, [2]string{from, to})
+}
+
+// graph lays out topics, receivers, processors, exporters and destinations as nodes. Receivers and exporters are
+// shared between pipelines, topics are shared between receivers.
+func (t *Topology) graph() *graph {
+	g := &graph{}
+	componentNode := func(c Component) node {
+		n := node{id: string(c.Kind) + ":" + c.ID, lines: []string{c.ID}}
+		if c.GroupID != "" {
+			n.lines = append(n.lines, "group: "+c.GroupID)
+		}
+		if !c.Defined {
+			n.lines[0] += " (undefined)"
+			n.class = Dangling
+		}
+		return n
+	}
+	for _, p := range t.Pipelines {
+		g.clusters = append(g.clusters, p.ID)
+		var heads []string
+		for _, r := range p.Receivers {
+			id := g.add(componentNode(r))
+			for _, topic := range r.Topics {
+				topicNode := node{id: "topic:" + topic, lines: []string{topic}, shape: shapeTopic}
+				if strings.HasPrefix(topic, "^") && len(r.ExcludeTopics) > 0 {
+					// Exclusions only apply to the regex topics of the receiver declaring them.
+					topicNode.id = "topic:" + r.ID + ":" + topic
+					topicNode.lines = append(topicNode.lines, "excluding "+strings.Join(r.ExcludeTopics, ", "))
+				}
+				g.connect(g.add(topicNode), id)
+			}
+			heads = append(heads, id)
+		}
+		for _, c := range p.Processors {
+			n := componentNode(c)
+			n.id = p.ID + ":" + n.id
+			n.cluster = p.ID
+			id := g.add(n)
+			for _, h := range heads {
+				g.connect(h, id)
+			}
+			heads = []string{id}
+		}
+		for _, e := range p.Exporters {
+			id := g.add(componentNode(e))
+			for _, h := range heads {
+				g.connect(h, id)
+			}
+			if e.Destination != nil {
+				g.connect(id, g.add(node{
+					id:    "destination:" + e.ID,
+					lines: strings.Split(destinationLabel(*e.Destination, "\n"), "\n"),
+					shape: shapeDestination,
+				}))
+			}
+		}
+	}
+	for _, kind := range []Kind{KindReceiver, KindProcessor, KindExporter, KindConnector, KindExtension} {
+		for _, id := range t.Unused[kind] {
+			g.add(node{id: string(kind) + ":" + id, lines: []string{id, "unused " + string(kind)}, class: Unused})
+		}
+	}
+	return g
+}
+
+func componentLabel(c Component) string {
+	label := c.ID
+	if c.GroupID != "" {
+		label += " (group " + c.GroupID + ")"
+	}
+	if !c.Defined {
+		label += " (undefined)"
+	}
+	return label
+}
+
+func topicsLabel(c Component) string {
+	label := strings.Join(c.Topics, ", ")
+	if len(c.ExcludeTopics) > 0 {
+		label += " excluding " + strings.Join(c.ExcludeTopics, ", ")
+	}
+	return label
+}
+
+func destinationLabel(d Destination, sep string) string {
+	var parts []string
+	for _, field := range []struct{ name, value string }{
+		{"index", d.Index}, {"source", d.Source}, {"sourcetype", d.Sourcetype},
+	} {
+		value := field.value
+		if value == "" {
+			value = "(token default)"
+		}
+		if attr, ok := d.FromAttributes[field.name]; ok {
+			value += " (" + attr + " when set)"
+		}
+		parts = append(parts, field.name+"="+value)
+	}
+	// Other HEC fields mapped from attributes, e.g. host.
+	var extra []string
+	for field, attr := range d.FromAttributes {
+		if field != "index" && field != "source" && field != "sourcetype" {
+			extra = append(extra, field+"="+attr)
+		}
+	}
+	sort.Strings(extra)
+	return strings.Join(append(parts, extra...), sep)
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,172 @@
+// Package topology describes how records flow through the pipelines of a collector configuration, from the Kafka
+// topics to the Splunk indexes, and reports components that are defined but unused or referenced but undefined.
+package topology
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// Kind is the kind of a component.
+type Kind string
+
+const (
+	KindReceiver  Kind = "receiver"
+	KindProcessor Kind = "processor"
+	KindExporter  Kind = "exporter"
+	KindConnector Kind = "connector"
+	KindExtension Kind = "extension"
+)
+
+// IssueKind is the kind of a topology issue.
+type IssueKind string
+
+const (
+	// Unused components are defined but not referenced by any pipeline, or by service.extensions for extensions.
+	Unused IssueKind = "unused"
+	// Dangling references name a component that is not defined.
+	Dangling IssueKind = "dangling"
+)
+
+// Component is a component referenced by a pipeline.
+type Component struct {
+	ID   string `json:"id"`
+	Kind Kind   `json:"kind"`
+	// Defined is false for dangling references.
+	Defined bool `json:"defined"`
+
+	// Topics, ExcludeTopics and GroupID are set for kafka receivers.
+	Topics        []string `json:"topics,omitempty"`
+	ExcludeTopics []string `json:"exclude_topics,omitempty"`
+	GroupID       string   `json:"group_id,omitempty"`
+
+	// Destination is set for splunk_hec exporters.
+	Destination *Destination `json:"destination,omitempty"`
+}
+
+// Destination is where a splunk_hec exporter sends the records.
+type Destination struct {
+	Endpoint   string `json:"endpoint,omitempty"`
+	Index      string `json:"index,omitempty"`
+	Source     string `json:"source,omitempty"`
+	Sourcetype string `json:"sourcetype,omitempty"`
+	// FromAttributes maps HEC fields to the record attributes overriding them (otel_attrs_to_hec_metadata).
+	FromAttributes map[string]string `json:"from_attributes,omitempty"`
+}
+
+// Pipeline is a service pipeline with its components in order.
+type Pipeline struct {
+	ID         string      `json:"id"`
+	Receivers  []Component `json:"receivers"`
+	Processors []Component `json:"processors"`
+	Exporters  []Component `json:"exporters"`
+}
+
+// Issue is an unused component or a dangling reference.
+type Issue struct {
+	Kind      IssueKind `json:"kind"`
+	Component string    `json:"component"`
+	// ComponentKind is the kind of the component, or the kind of the reference for dangling references.
+	ComponentKind Kind `json:"component_kind"`
+	// Pipeline is the pipeline holding a dangling reference, empty for service.extensions and unused components.
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+// Message describes the issue.
+func (i Issue) Message() string {
+	if i.Kind == Unused {
+		if i.ComponentKind == KindExtension {
+			return fmt.Sprintf("extension %s is not enabled in service.extensions", i.Component)
+		}
+		return fmt.Sprintf("%s %s is not used by any pipeline", i.ComponentKind, i.Component)
+	}
+	where := "service.extensions"
+	if i.Pipeline != "" {
+		where = "pipeline " + i.Pipeline
+	}
+	return fmt.Sprintf("%s references %s %s, which is not defined", where, i.ComponentKind, i.Component)
+}
+
+// Topology is the topology of a collector configuration.
+type Topology struct {
+	Pipelines []Pipeline `json:"pipelines"`
+	// Unused lists the components that are defined but not used, by kind.
+	Unused map[Kind][]string `json:"unused"`
+	Issues []Issue           `json:"issues"`
+}
+
+// Build computes the topology of cfg.
+func Build(cfg *collectorconfig.Config) *Topology {
+	defined := map[Kind]map[string]any{}
+	for kind, section := range map[Kind]string{
+		KindReceiver: "receivers", KindProcessor: "processors", KindExporter: "exporters",
+		KindConnector: "connectors", KindExtension: "extensions",
+	} {
+		defined[kind] = collectorconfig.Map(cfg.Raw, section)
+	}
+	used := map[Kind]map[string]bool{}
+	for kind := range defined {
+		used[kind] = map[string]bool{}
+	}
+
+	t := &Topology{Pipelines: []Pipeline{}, Unused: map[Kind][]string{}, Issues: []Issue{}}
+	resolve := func(pipeline, id string, kind Kind) Component {
+		if _, ok := defined[KindConnector][id]; ok && kind != KindProcessor {
+			used[KindConnector][id] = true
+			return Component{ID: id, Kind: KindConnector, Defined: true}
+		}
+		c := Component{ID: id, Kind: kind}
+		if _, c.Defined = defined[kind][id]; !c.Defined {
+			t.Issues = append(t.Issues, Issue{Kind: Dangling, Component: id, ComponentKind: kind, Pipeline: pipeline})
+			return c
+		}
+		used[kind][id] = true
+		if r, ok := cfg.KafkaReceiver(id); ok && kind == KindReceiver {
+			c.Topics, c.ExcludeTopics, c.GroupID = r.Topics, r.ExcludeTopics, r.EffectiveGroupID()
+		}
+		if e, ok := cfg.HECExporter(id); ok && kind == KindExporter {
+			c.Destination = &Destination{
+				Endpoint: e.Endpoint, Index: e.Index, Source: e.Source, Sourcetype: e.Sourcetype, FromAttributes: e.HECMetadata,
+			}
+		}
+		return c
+	}
+
+	for _, p := range cfg.Pipelines {
+		pipeline := Pipeline{ID: p.ID, Receivers: []Component{}, Processors: []Component{}, Exporters: []Component{}}
+		for _, id := range p.Receivers {
+			pipeline.Receivers = append(pipeline.Receivers, resolve(p.ID, id, KindReceiver))
+		}
+		for _, id := range p.Processors {
+			pipeline.Processors = append(pipeline.Processors, resolve(p.ID, id, KindProcessor))
+		}
+		for _, id := range p.Exporters {
+			pipeline.Exporters = append(pipeline.Exporters, resolve(p.ID, id, KindExporter))
+		}
+		t.Pipelines = append(t.Pipelines, pipeline)
+	}
+	for _, id := range collectorconfig.Strings(collectorconfig.Map(cfg.Raw, "service"), "extensions") {
+		if _, ok := defined[KindExtension][id]; !ok {
+			t.Issues = append(t.Issues, Issue{Kind: Dangling, Component: id, ComponentKind: KindExtension})
+			continue
+		}
+		used[KindExtension][id] = true
+	}
+
+	for _, kind := range []Kind{KindReceiver, KindProcessor, KindExporter, KindConnector, KindExtension} {
+		for _, id := range collectorconfig.SortedKeys(defined[kind]) {
+			if used[kind][id] {
+				continue
+			}
+			t.Unused[kind] = append(t.Unused[kind], id)
+			t.Issues = append(t.Issues, Issue{Kind: Unused, Component: id, ComponentKind: kind})
+		}
+	}
+	// Dangling references break the collector start, list them first.
+	sort.SliceStable(t.Issues, func(i, j int) bool {
+		return t.Issues[i].Kind == Dangling && t.Issues[j].Kind != Dangling
+	})
+	return t
+}
@@ -0,0 +1,100 @@
+package topology
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func load(t *testing.T) *Topology {
+	t.Helper()
+	cfg, err := collectorconfig.Load("testdata/config.yaml")
+	require.NoError(t, err)
+	return Build(cfg)
+}
+
+func TestBuild(t *testing.T) {
+	topo := load(t)
+	require.Len(t, topo.Pipelines, 2)
+
+	app := topo.Pipelines[0]
+	assert.Equal(t, "logs/app", app.ID)
+	require.Len(t, app.Receivers, 2)
+	assert.Equal(t, []string{"^logs-.*"}, app.Receivers[1].Topics)
+	assert.Equal(t, []string{"^logs-internal$"}, app.Receivers[1].ExcludeTopics)
+	assert.Equal(t, &Destination{
+		Endpoint:       "https://splunk:8088/services/collector",
+		Index:          "kafka",
+		Source:         "soc4kafka",
+		Sourcetype:     "otel:logs",
+		FromAttributes: map[string]string{"index": "kafka.header.index"},
+	}, app.Exporters[0].Destination)
+
+	assert.Equal(t, map[Kind][]string{
+		KindReceiver:  {"kafka/legacy"},
+		KindProcessor: {"batch"},
+		KindExtension: {"pprof"},
+	}, topo.Unused)
+	assert.Equal(t, []Issue{
+		{Kind: Dangling, Component: "transform/missing", ComponentKind: KindProcessor, Pipeline: "logs/archive"},
+		{Kind: Dangling, Component: "splunk_hec/missing", ComponentKind: KindExporter, Pipeline: "logs/archive"},
+		{Kind: Dangling, Component: "file_storage", ComponentKind: KindExtension},
+		{Kind: Unused, Component: "kafka/legacy", ComponentKind: KindReceiver},
+		{Kind: Unused, Component: "batch", ComponentKind: KindProcessor},
+		{Kind: Unused, Component: "pprof", ComponentKind: KindExtension},
+	}, topo.Issues)
+}
+
+func TestConnectors(t *testing.T) {
+	cfg, err := collectorconfig.Parse([]byte(`
+receivers:
+  kafka: { logs: { topics: [ logs ] } }
+connectors:
+  routing: {}
+exporters:
+  splunk_hec: {}
+service:
+  pipelines:
+    logs/in:
+      receivers: [ kafka ]
+      exporters: [ routing ]
+    logs/out:
+      receivers: [ routing ]
+      exporters: [ splunk_hec ]
+`))
+	require.NoError(t, err)
+	topo := Build(cfg)
+	assert.Empty(t, topo.Issues)
+	assert.Equal(t, KindConnector, topo.Pipelines[0].Exporters[0].Kind)
+	assert.Equal(t, KindConnector, topo.Pipelines[1].Receivers[0].Kind)
+}
+
+func TestRender(t *testing.T) {
+	topo := load(t)
+	for file, write := range map[string]func(*bytes.Buffer) error{
+		"config.txt": func(b *bytes.Buffer) error { return topo.WriteText(b) },
+		"config.dot": func(b *bytes.Buffer) error { return topo.WriteDOT(b) },
+		"config.mmd": func(b *bytes.Buffer) error { return topo.WriteMermaid(b) },
+	} {
+		t.Run(file, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, write(&b))
+			golden := filepath.Join("testdata", file)
+			if *update {
+				require.NoError(t, os.WriteFile(golden, b.Bytes(), 0o644))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), b.String())
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package upgrade
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// UpgradeNode is Upgrade on a parsed YAML document. The rewrites are applied to the node tree in place, so the
+// comments and key order of the settings left alone are kept. Added settings follow the existing ones of their map, and
+// a map moved as a whole, e.g. auth.tls to tls, keeps its comments and order.
+func UpgradeNode(doc *yaml.Node, from, to string) ([]Rewrite, error) {
+	if doc.Kind == 0 {
+		*doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	var raw map[string]any
+	if err := doc.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	rewrites, err := Upgrade(raw, from, to)
+	if err != nil || len(rewrites) == 0 {
+		return rewrites, err
+	}
+	root := doc
+	if doc.Kind == yaml.DocumentNode {
+		root = doc.Content[0]
+	}
+	a := applier{}
+	if err := a.index(root); err != nil {
+		return nil, err
+	}
+	return rewrites, a.apply(root, raw)
+}
+
+// applier updates a node tree to the value its rules rewrote.
+type applier struct {
+	// originals are the collections of the tree before the rewrites, reused when a rule moved one unchanged.
+	originals []original
+}
+
+type original struct {
+	node  *yaml.Node
+	value any
+}
+
+func (a *applier) index(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	a.originals = append(a.originals, original{node: node, value: value})
+	for _, child := range node.Content {
+		if err := a.index(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply updates node to value, leaving the parts that did not change untouched.
+func (a *applier) apply(node *yaml.Node, value any) error {
+	var current any
+	if err := node.Decode(&current); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(current, value) {
+		return nil
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+		var content []*yaml.Node
+		kept := map[string]bool{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			child, ok := v[key]
+			if !ok {
+				continue
+			}
+			if err := a.apply(node.Content[i+1], child); err != nil {
+				return err
+			}
+			content = append(content, node.Content[i], node.Content[i+1])
+			kept[key] = true
+		}
+		for _, key := range collectorconfig.SortedKeys(v) {
+			if kept[key] {
+				continue
+			}
+			child, err := a.node(v[key])
+			if err != nil {
+				return err
+			}
+			content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		node.Content = content
+		return nil
+	case []any:
+		if node.Kind != yaml.SequenceNode || len(node.Content) != len(v) {
+			break
+		}
+		for i, child := range v {
+			if err := a.apply(node.Content[i], child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	replacement, err := a.node(value)
+	if err != nil {
+		return err
+	}
+	replacement.HeadComment, replacement.LineComment, replacement.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = *replacement
+	return nil
+}
+
+// node returns a node of value: a copy of an original collection with that value, or a new one.
+func (a *applier) node(value any) (*yaml.Node, error) {
+	for _, o := range a.originals {
+		if reflect.DeepEqual(o.value, value) {
+			return deepCopy(o.node), nil
+		}
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func deepCopy(node *yaml.Node) *yaml.Node {
+	out := *node
+	out.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		out.Content[i] = deepCopy(child)
+	}
+	return &out
+}
@@ -0,0 +1,454 @@
+// Package upgrade rewrites collector configurations written for older splunk-otel-collector releases into the form
+// expected by the release pinned by the Helm chart.
+package upgrade
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/collectorconfig"
+)
+
+// Rewrite is a change made, or recommended, by a rule.
+type Rewrite struct {
+	Rule    string `json:"rule"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	// Explanation says what changed and why.
+	Explanation string `json:"explanation"`
+	// Advisory is set when the change is outside of the configuration, e.g. on the collector command line.
+	Advisory bool `json:"advisory,omitempty"`
+}
+
+// Rule rewrites one setting that changed in a release.
+type Rule struct {
+	Name string
+	// Version is the release expecting the new form. Configurations written for this release or a later one are
+	// left alone.
+	Version     string
+	Description string
+	rewrite     func(raw map[string]any, report func(path, explanation string))
+	advisory    bool
+}
+
+// Rules returns all rules in version order.
+func Rules() []Rule {
+	return rules
+}
+
+var rules = []Rule{
+	{
+		Name:        "telemetry-metrics-address",
+		Version:     "0.111.0",
+		Description: "service.telemetry.metrics.address is replaced by a Prometheus pull reader",
+		rewrite:     rewriteTelemetryAddress,
+	},
+	{
+		Name:        "kafka-auth-tls",
+		Version:     "0.122.0",
+		Description: "the TLS settings of the kafka receiver moved from auth.tls to tls",
+		rewrite:     rewriteAuthTLS,
+	},
+	{
+		Name:        "exporter-batcher",
+		Version:     "0.123.0",
+		Description: "the batcher settings of the exporters moved to sending_queue.batch",
+		rewrite:     rewriteBatcher,
+	},
+	{
+		Name:        "kafka-signal-settings",
+		Version:     "0.124.0",
+		Description: "the topic and encoding of the kafka receiver are set per signal, e.g. logs.topic",
+		rewrite:     rewriteSignalSettings,
+	},
+	{
+		Name:        "sending-queue-block-on-overflow",
+		Version:     "0.126.0",
+		Description: "sending_queue.blocking is renamed block_on_overflow",
+		rewrite:     rewriteBlocking,
+	},
+	{
+		Name:        "kafka-franz-go-gate",
+		Version:     "0.141.0",
+		Description: "regex topics no longer need the receiver.kafkareceiver.UseFranzGo feature gate",
+		rewrite:     reportFranzGoGate,
+		advisory:    true,
+	},
+	{
+		Name:        "kafka-topics-list",
+		Version:     "0.142.0",
+		Description: "the kafka receiver subscribes to a list of topics: topic becomes topics and exclude_topic exclude_topics",
+		rewrite:     rewriteTopicsList,
+	},
+	{
+		Name:        "batch-processor",
+		Version:     "0.155.0",
+		Description: "splunk_hec exporters batch in sending_queue.batch instead of a pipeline batch processor",
+		rewrite:     rewriteBatchProcessor,
+	},
+}
+
+// franzGoGate is the feature gate enabling the franz-go client, required by regex topics before it became the
+// default client.
+const franzGoGate = "receiver.kafkareceiver.UseFranzGo"
+
+// signals are the pipeline types with their own settings in the kafka receiver.
+var signals = []string{"logs", "metrics", "traces"}
+
+// Upgrade rewrites raw in place with the rules of the releases after from, up to and including to. An empty from
+// applies every rule whose old form is found.
+func Upgrade(raw map[string]any, from, to string) ([]Rewrite, error) {
+	var rewrites []Rewrite
+	for _, r := range rules {
+		if from != "" {
+			newer, err := newerThan(r.Version, from)
+			if err != nil {
+				return nil, err
+			}
+			if !newer {
+				continue
+			}
+		}
+		older, err := newerThan(r.Version, to)
+		if err != nil {
+			return nil, err
+		}
+		if older {
+			continue
+		}
+		r.rewrite(raw, func(path, explanation string) {
+			rewrites = append(rewrites, Rewrite{Rule: r.Name, Version: r.Version, Path: path, Explanation: explanation, Advisory: r.advisory})
+		})
+	}
+	return rewrites, nil
+}
+
+// newerThan reports whether version a is strictly newer than version b.
+func newerThan(a, b string) (bool, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return false, err
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return false, err
+	}
+	return slices.Compare(va, vb) > 0, nil
+}
+
+func parseVersion(s string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q, expected major.minor.patch", s)
+	}
+	version := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q, expected major.minor.patch", s)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+// kafkaReceivers returns the kafka receivers of raw by ID.
+func kafkaReceivers(raw map[string]any) map[string]map[string]any {
+	out := map[string]map[string]any{}
+	receivers := collectorconfig.Map(raw, "receivers")
+	for _, id := range collectorconfig.SortedKeys(receivers) {
+		if m := collectorconfig.Map(receivers, id); m != nil && collectorconfig.ComponentType(id) == collectorconfig.KafkaReceiverType {
+			out[id] = m
+		}
+	}
+	return out
+}
+
+// submap returns the map under key, creating it when missing.
+func submap(m map[string]any, key string) map[string]any {
+	sub := collectorconfig.Map(m, key)
+	if sub == nil {
+		sub = map[string]any{}
+		m[key] = sub
+	}
+	return sub
+}
+
+func rewriteTelemetryAddress(raw map[string]any, report func(path, explanation string)) {
+	metrics := collectorconfig.Map(collectorconfig.Map(collectorconfig.Map(raw, "service"), "telemetry"), "metrics")
+	address, ok := metrics["address"].(string)
+	if !ok {
+		return
+	}
+	host, port, err := net.SplitHostPort(address)
+	n, portErr := strconv.Atoi(port)
+	if err != nil || portErr != nil {
+		report("service.telemetry.metrics.address", fmt.Sprintf("address %q is not host:port, replace it by a readers entry by hand", address))
+		return
+	}
+	delete(metrics, "address")
+	readers, _ := metrics["readers"].([]any)
+	metrics["readers"] = append(readers, map[string]any{"pull": map[string]any{"exporter": map[string]any{
+		"prometheus": map[string]any{"host": host, "port": n},
+	}}})
+	report("service.telemetry.metrics.address", fmt.Sprintf(
+		"address %s is removed, the internal metrics are served by a readers entry pulling from a Prometheus exporter on the same host and port", address))
+}
+
+func rewriteAuthTLS(raw map[string]any, report func(path, explanation string)) {
+	for id, r := range kafkaReceivers(raw) {
+		auth := collectorconfig.Map(r, "auth")
+		tls, ok := auth["tls"]
+		if !ok {
+			continue
+		}
+		path := "receivers." + id + ".auth.tls"
+		delete(auth, "tls")
+		if len(auth) == 0 {
+			delete(r, "auth")
+		}
+		if _, exists := r["tls"]; exists {
+			report(path, "auth.tls is removed, tls is already set and is kept")
+			continue
+		}
+		r["tls"] = tls
+		report(path, "auth.tls is moved to tls, the receiver no longer reads TLS settings under auth")
+	}
+}
+
+// batcherSettings maps the settings of the exporter batcher to the ones of sending_queue.batch.
+var batcherSettings = map[string]string{
+	"flush_timeout":  "flush_timeout",
+	"min_size_items": "min_size",
+	"max_size_items": "max_size",
+	"min_size":       "min_size",
+	"max_size":       "max_size",
+	"sizer":          "sizer",
+}
+
+func rewriteBatcher(raw map[string]any, report func(path, explanation string)) {
+	exporters := collectorconfig.Map(raw, "exporters")
+	for _, id := range collectorconfig.SortedKeys(exporters) {
+		e := collectorconfig.Map(exporters, id)
+		batcher, ok := e["batcher"].(map[string]any)
+		if !ok {
+			continue
+		}
+		path := "exporters." + id + ".batcher"
+		delete(e, "batcher")
+		if enabled, ok := batcher["enabled"].(bool); ok && !enabled {
+			report(path, "batcher is removed, it was disabled")
+			continue
+		}
+		queue := submap(e, "sending_queue")
+		if _, exists := queue["batch"]; exists {
+			report(path, "batcher is removed, sending_queue.batch is already set and is kept")
+			continue
+		}
+		batch := map[string]any{}
+		for _, key := range collectorconfig.SortedKeys(batcher) {
+			if name, ok := batcherSettings[key]; ok {
+				batch[name] = batcher[key]
+			}
+		}
+		// The batcher counted items unless told otherwise, the batch of the queue uses the sizer of the queue.
+		if _, ok := batch["sizer"]; !ok {
+			batch["sizer"] = "items"
+		}
+		queue["batch"] = batch
+		report(path, "batcher is moved to sending_queue.batch, min_size_items and max_size_items become min_size and max_size counted in items")
+	}
+}
+
+// receiverSignals returns the pipeline types using a receiver, logs when no pipeline uses it.
+func receiverSignals(raw map[string]any, id string) []string {
+	var out []string
+	for _, p := range collectorconfig.FromMap(raw).Pipelines {
+		signal := collectorconfig.ComponentType(p.ID)
+		if slices.Contains(p.Receivers, id) && slices.Contains(signals, signal) && !slices.Contains(out, signal) {
+			out = append(out, signal)
+		}
+	}
+	if len(out) == 0 {
+		return []string{"logs"}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func rewriteSignalSettings(raw map[string]any, report func(path, explanation string)) {
+	for _, id := range collectorconfig.SortedKeys(kafkaReceivers(raw)) {
+		r := kafkaReceivers(raw)[id]
+		for _, key := range []string{"topic", "encoding"} {
+			v, ok := r[key]
+			if !ok {
+				continue
+			}
+			delete(r, key)
+			var moved []string
+			for _, signal := range receiverSignals(raw, id) {
+				settings := submap(r, signal)
+				if _, exists := settings[key]; exists {
+					continue
+				}
+				settings[key] = v
+				moved = append(moved, signal+"."+key)
+			}
+			path := "receivers." + id + "." + key
+			if len(moved) == 0 {
+				report(path, fmt.Sprintf("%s is removed, every signal of the receiver already sets its own", key))
+				continue
+			}
+			report(path, fmt.Sprintf("%s is moved to %s, the signals consumed by the pipelines of the receiver", key, strings.Join(moved, " and ")))
+		}
+	}
+}
+
+func rewriteBlocking(raw map[string]any, report func(path, explanation string)) {
+	exporters := collectorconfig.Map(raw, "exporters")
+	for _, id := range collectorconfig.SortedKeys(exporters) {
+		queue := collectorconfig.Map(collectorconfig.Map(exporters, id), "sending_queue")
+		blocking, ok := queue["blocking"]
+		if !ok {
+			continue
+		}
+		delete(queue, "blocking")
+		path := "exporters." + id + ".sending_queue.blocking"
+		if _, exists := queue["block_on_overflow"]; exists {
+			report(path, "blocking is removed, block_on_overflow is already set and is kept")
+			continue
+		}
+		queue["block_on_overflow"] = blocking
+		report(path, "blocking is renamed block_on_overflow")
+	}
+}
+
+func rewriteTopicsList(raw map[string]any, report func(path, explanation string)) {
+	for _, id := range collectorconfig.SortedKeys(kafkaReceivers(raw)) {
+		r := kafkaReceivers(raw)[id]
+		for _, signal := range signals {
+			settings := collectorconfig.Map(r, signal)
+			for _, key := range []string{"topic", "exclude_topic"} {
+				v, ok := settings[key]
+				if !ok {
+					continue
+				}
+				delete(settings, key)
+				path := "receivers." + id + "." + signal + "." + key
+				if _, exists := settings[key+"s"]; exists {
+					report(path, fmt.Sprintf("%s is removed, %ss is already set and is kept", key, key))
+					continue
+				}
+				list, isList := v.([]any)
+				if !isList {
+					list = []any{v}
+				}
+				settings[key+"s"] = list
+				report(path, fmt.Sprintf("%s is replaced by the list %ss", key, key))
+			}
+		}
+	}
+}
+
+func reportFranzGoGate(raw map[string]any, report func(path, explanation string)) {
+	for _, r := range collectorconfig.FromMap(raw).KafkaReceivers {
+		var topics []string
+		for _, signal := range signals {
+			topics = append(topics, collectorconfig.Strings(collectorconfig.Map(r.Raw, signal), "topics")...)
+			topics = append(topics, collectorconfig.Strings(collectorconfig.Map(r.Raw, signal), "topic")...)
+		}
+		if slices.ContainsFunc(topics, collectorconfig.IsRegexTopic) {
+			report("receivers."+r.ID, fmt.Sprintf(
+				"regex topics no longer need --feature-gates=%s, the franz-go client is the default: remove the gate from the collector command line", franzGoGate))
+		}
+	}
+}
+
+// batchProcessorDefaults are the settings of the batch processor when unset.
+var batchProcessorDefaults = map[string]any{"send_batch_size": 8192, "timeout": "200ms"}
+
+func rewriteBatchProcessor(raw map[string]any, report func(path, explanation string)) {
+	processors := collectorconfig.Map(raw, "processors")
+	pipelines := collectorconfig.Map(collectorconfig.Map(raw, "service"), "pipelines")
+	exporters := collectorconfig.Map(raw, "exporters")
+	batched := map[string]string{}
+	for _, id := range collectorconfig.SortedKeys(pipelines) {
+		p := collectorconfig.Map(pipelines, id)
+		pipelineExporters := collectorconfig.Strings(p, "exporters")
+		if len(pipelineExporters) == 0 || slices.ContainsFunc(pipelineExporters, func(e string) bool {
+			return collectorconfig.ComponentType(e) != collectorconfig.HECExporterType
+		}) {
+			continue
+		}
+		var kept []any
+		var removed []string
+		for _, processor := range collectorconfig.Strings(p, "processors") {
+			if collectorconfig.ComponentType(processor) == "batch" {
+				removed = append(removed, processor)
+				for _, e := range pipelineExporters {
+					if _, ok := batched[e]; !ok {
+						batched[e] = processor
+					}
+				}
+				continue
+			}
+			kept = append(kept, processor)
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		p["processors"] = kept
+		if kept == nil {
+			delete(p, "processors")
+		}
+		report("service.pipelines."+id+".processors", fmt.Sprintf(
+			"%s is removed, the splunk_hec exporters of the pipeline batch in sending_queue.batch", strings.Join(removed, ", ")))
+	}
+	for _, id := range collectorconfig.SortedKeys(batched) {
+		e := collectorconfig.Map(exporters, id)
+		if e == nil {
+			continue
+		}
+		queue := submap(e, "sending_queue")
+		if _, exists := queue["batch"]; exists {
+			continue
+		}
+		settings := collectorconfig.Map(processors, batched[id])
+		batch := map[string]any{"sizer": "items"}
+		for key, name := range map[string]string{"send_batch_size": "min_size", "send_batch_max_size": "max_size", "timeout": "flush_timeout"} {
+			if v, ok := settings[key]; ok {
+				batch[name] = v
+			} else if v, ok := batchProcessorDefaults[key]; ok {
+				batch[name] = v
+			}
+		}
+		queue["batch"] = batch
+		if _, ok := queue["enabled"]; !ok {
+			queue["enabled"] = true
+		}
+		report("exporters."+id+".sending_queue.batch", fmt.Sprintf(
+			"batch is set from processors.%s: send_batch_size, send_batch_max_size and timeout become min_size, max_size and flush_timeout", batched[id]))
+	}
+	for _, id := range collectorconfig.SortedKeys(processors) {
+		if collectorconfig.ComponentType(id) != "batch" || processorUsed(pipelines, id) {
+			continue
+		}
+		delete(processors, id)
+		report("processors."+id, "the processor is removed, no pipeline uses it anymore")
+	}
+	if processors != nil && len(processors) == 0 {
+		delete(raw, "processors")
+	}
+}
+
+func processorUsed(pipelines map[string]any, id string) bool {
+	for name := range pipelines {
+		if slices.Contains(collectorconfig.Strings(collectorconfig.Map(pipelines, name), "processors"), id) {
+			return true
+		}
+	}
+	return false
+}
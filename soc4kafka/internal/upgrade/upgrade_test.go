@@ -0,0 +1,256 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// oldConfig is written for a collector before 0.111.0.
+const oldConfig = `
+receivers:
+  kafka/main:
+    brokers: [kafka:9093]
+    topic: ^app-.*
+    encoding: text
+    auth:
+      tls:
+        insecure: false
+        ca_file: /etc/kafka/ca.pem
+  kafka/otlp:
+    brokers: [kafka:9093]
+    logs:
+      topic: otlp
+      exclude_topic: ignored
+processors:
+  batch:
+    send_batch_size: 500
+    timeout: 1s
+  resourcedetection:
+    detectors: [system]
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    sending_queue:
+      blocking: true
+  splunk_hec/audit:
+    endpoint: https://splunk:8088/services/collector
+    batcher:
+      enabled: true
+      min_size_items: 100
+      flush_timeout: 5s
+service:
+  telemetry:
+    metrics:
+      address: 0.0.0.0:8888
+  pipelines:
+    logs:
+      receivers: [kafka/main]
+      processors: [resourcedetection, batch]
+      exporters: [splunk_hec]
+    logs/otlp:
+      receivers: [kafka/otlp]
+      exporters: [splunk_hec/audit]
+`
+
+func parse(t *testing.T, doc string) map[string]any {
+	t.Helper()
+	var raw map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(doc), &raw))
+	return raw
+}
+
+func TestUpgrade(t *testing.T) {
+	raw := parse(t, oldConfig)
+	rewrites, err := Upgrade(raw, "", "0.155.0")
+	require.NoError(t, err)
+
+	var rules, paths []string
+	for _, r := range rewrites {
+		rules = append(rules, r.Rule)
+		paths = append(paths, r.Path)
+	}
+	assert.Equal(t, []string{
+		"telemetry-metrics-address", "kafka-auth-tls", "exporter-batcher", "kafka-signal-settings", "kafka-signal-settings",
+		"sending-queue-block-on-overflow", "kafka-franz-go-gate", "kafka-topics-list", "kafka-topics-list", "kafka-topics-list",
+		"batch-processor", "batch-processor", "batch-processor",
+	}, rules)
+	assert.Equal(t, []string{
+		"service.telemetry.metrics.address", "receivers.kafka/main.auth.tls", "exporters.splunk_hec/audit.batcher",
+		"receivers.kafka/main.topic", "receivers.kafka/main.encoding", "exporters.splunk_hec.sending_queue.blocking",
+		"receivers.kafka/main", "receivers.kafka/main.logs.topic", "receivers.kafka/otlp.logs.topic",
+		"receivers.kafka/otlp.logs.exclude_topic", "service.pipelines.logs.processors", "exporters.splunk_hec.sending_queue.batch", "processors.batch",
+	}, paths)
+	assert.True(t, rewrites[6].Advisory)
+	assert.Equal(t, "topic is moved to logs.topic, the signals consumed by the pipelines of the receiver", rewrites[3].Explanation)
+
+	assert.Equal(t, parse(t, `
+receivers:
+  kafka/main:
+    brokers: [kafka:9093]
+    logs:
+      topics: [^app-.*]
+      encoding: text
+    tls:
+      insecure: false
+      ca_file: /etc/kafka/ca.pem
+  kafka/otlp:
+    brokers: [kafka:9093]
+    logs:
+      topics: [otlp]
+      exclude_topics: [ignored]
+processors:
+  resourcedetection:
+    detectors: [system]
+exporters:
+  splunk_hec:
+    endpoint: https://splunk:8088/services/collector
+    sending_queue:
+      enabled: true
+      block_on_overflow: true
+      batch:
+        sizer: items
+        min_size: 500
+        flush_timeout: 1s
+  splunk_hec/audit:
+    endpoint: https://splunk:8088/services/collector
+    sending_queue:
+      batch:
+        sizer: items
+        min_size: 100
+        flush_timeout: 5s
+service:
+  telemetry:
+    metrics:
+      readers:
+        - pull:
+            exporter:
+              prometheus:
+                host: 0.0.0.0
+                port: 8888
+  pipelines:
+    logs:
+      receivers: [kafka/main]
+      processors: [resourcedetection]
+      exporters: [splunk_hec]
+    logs/otlp:
+      receivers: [kafka/otlp]
+      exporters: [splunk_hec/audit]
+`), raw)
+
+	// An upgraded configuration is left alone.
+	rewrites, err = Upgrade(raw, "", "0.155.0")
+	require.NoError(t, err)
+	assert.Len(t, rewrites, 1)
+	assert.True(t, rewrites[0].Advisory)
+}
+
+func TestUpgradeFrom(t *testing.T) {
+	raw := parse(t, oldConfig)
+	rewrites, err := Upgrade(raw, "0.126.0", "0.155.0")
+	require.NoError(t, err)
+	for _, r := range rewrites {
+		assert.Contains(t, []string{"kafka-topics-list", "kafka-franz-go-gate", "batch-processor"}, r.Rule)
+	}
+	// The rules of 0.126.0 and before are skipped, the top level topic is left for the collector to reject.
+	assert.Equal(t, "^app-.*", raw["receivers"].(map[string]any)["kafka/main"].(map[string]any)["topic"])
+
+	_, err = Upgrade(raw, "latest", "0.155.0")
+	assert.EqualError(t, err, `invalid version "latest", expected major.minor.patch`)
+}
+
+func TestSignalSettingsFollowPipelines(t *testing.T) {
+	raw := parse(t, `
+receivers:
+  kafka:
+    topic: spans
+    encoding: otlp_proto
+service:
+  pipelines:
+    traces:
+      receivers: [kafka]
+      exporters: [debug]
+    metrics:
+      receivers: [kafka]
+      exporters: [debug]
+`)
+	rewrites, err := Upgrade(raw, "0.123.0", "0.124.0")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 2)
+	assert.Equal(t, "encoding is moved to metrics.encoding and traces.encoding, the signals consumed by the pipelines of the receiver", rewrites[1].Explanation)
+	assert.Equal(t, map[string]any{
+		"metrics": map[string]any{"topic": "spans", "encoding": "otlp_proto"},
+		"traces":  map[string]any{"topic": "spans", "encoding": "otlp_proto"},
+	}, raw["receivers"].(map[string]any)["kafka"])
+}
+
+func TestRulesInVersionOrder(t *testing.T) {
+	for i := 1; i < len(Rules()); i++ {
+		newer, err := newerThan(Rules()[i-1].Version, Rules()[i].Version)
+		require.NoError(t, err)
+		assert.False(t, newer, Rules()[i].Name)
+	}
+}
+
+func TestUpgradeNode(t *testing.T) {
+	var doc yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`# Collector of the audit topics.
+service:
+  pipelines:
+    logs:
+      receivers: [kafka]
+      exporters: [splunk_hec]
+receivers:
+  kafka:
+    # Brokers of the production cluster.
+    brokers: [kafka:9093]
+    auth:
+      tls:
+        insecure: false # verify the brokers
+        ca_file: /etc/kafka/ca.pem
+    logs:
+      topic: audit # the only topic
+exporters:
+  splunk_hec:
+    token: ${env:SPLUNK_HEC_TOKEN}
+    sending_queue:
+      # Wait instead of dropping data.
+      blocking: true
+`), &doc))
+	rewrites, err := UpgradeNode(&doc, "", "0.155.0")
+	require.NoError(t, err)
+	require.Len(t, rewrites, 3)
+
+	out, err := yaml.Marshal(&doc)
+	require.NoError(t, err)
+	assert.Equal(t, `# Collector of the audit topics.
+service:
+    pipelines:
+        logs:
+            receivers: [kafka]
+            exporters: [splunk_hec]
+receivers:
+    kafka:
+        # Brokers of the production cluster.
+        brokers: ['kafka:9093']
+        logs:
+            topics:
+                - audit
+        tls:
+            insecure: false # verify the brokers
+            ca_file: /etc/kafka/ca.pem
+exporters:
+    splunk_hec:
+        token: ${env:SPLUNK_HEC_TOKEN}
+        sending_queue:
+            block_on_overflow: true
+`, string(out))
+
+	// An empty document is an empty configuration.
+	doc = yaml.Node{}
+	rewrites, err = UpgradeNode(&doc, "", "0.155.0")
+	require.NoError(t, err)
+	assert.Empty(t, rewrites)
+}
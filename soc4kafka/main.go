@@ -0,0 +1,12 @@
+// Command soc4kafka is a helper CLI for operating the Splunk OpenTelemetry Collector for Kafka (SOC4Kafka).
+package main
+
+import (
+	"os"
+
+	"github.com/splunk/splunk-opentelemetry-collector-for-kafka/soc4kafka/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
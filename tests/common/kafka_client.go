@@ -96,6 +96,46 @@ func checkKafkaTopicExists(t *testing.T, topicName string) bool {
 
 func SendMessageToKafkaTopic(t *testing.T, topicName string, message string, headers ...kafka.Header) {
 	t.Logf("Adding message to Kafka topic: %s\n", topicName)
+	produceMessage(t, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topicName, Partition: kafka.PartitionAny},
+		Value:          []byte(message),
+		Headers:        headers,
+	})
+}
+
+// SendMessageWithTimestampToKafkaTopic produces a message with an explicit record timestamp. The broker keeps it
+// unless the topic uses LogAppendTime.
+func SendMessageWithTimestampToKafkaTopic(t *testing.T, topicName string, message string, timestamp time.Time) {
+	t.Logf("Adding message with timestamp %s to Kafka topic: %s\n", timestamp.Format(time.RFC3339Nano), topicName)
+	produceMessage(t, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topicName, Partition: kafka.PartitionAny},
+		Value:          []byte(message),
+		Timestamp:      timestamp,
+	})
+}
+
+// SendMessageWithKeyToKafkaTopic produces a message with the given record key, which may be binary.
+func SendMessageWithKeyToKafkaTopic(t *testing.T, topicName string, message string, key []byte) {
+	t.Logf("Adding message with key %q to Kafka topic: %s\n", key, topicName)
+	produceMessage(t, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topicName, Partition: kafka.PartitionAny},
+		Key:            key,
+		Value:          []byte(message),
+	})
+}
+
+// SendMessageWithKeyAndTimestampToKafkaTopic produces a message with both a record key and a record timestamp.
+func SendMessageWithKeyAndTimestampToKafkaTopic(t *testing.T, topicName string, message string, key []byte, timestamp time.Time) {
+	t.Logf("Adding message with key %q and timestamp %s to Kafka topic: %s\n", key, timestamp.Format(time.RFC3339Nano), topicName)
+	produceMessage(t, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topicName, Partition: kafka.PartitionAny},
+		Key:            key,
+		Value:          []byte(message),
+		Timestamp:      timestamp,
+	})
+}
+
+func produceMessage(t *testing.T, message *kafka.Message) {
 	// Create a new producer
 	producer, err := kafka.NewProducer(&kafka.ConfigMap{
 		"bootstrap.servers": GetConfigVariable("KAFKA_BROKER_ADDRESS"),
@@ -105,11 +145,7 @@ func SendMessageToKafkaTopic(t *testing.T, topicName string, message string, hea
 
 	// Produce a message to the topic
 	deliveryChan := make(chan kafka.Event)
-	err = producer.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &topicName, Partition: kafka.PartitionAny},
-		Value:          []byte(message),
-		Headers:        headers,
-	}, deliveryChan)
+	err = producer.Produce(message, deliveryChan)
 
 	require.NoError(t, err, "Failed to produce message")
 
@@ -1,7 +1,9 @@
 package functional_tests
 
 import (
+	"encoding/base64"
 	"fmt"
+	"strconv"
 	"testing"
 	"tests/common"
 	"time"
@@ -17,7 +19,11 @@ func Test_Functions(t *testing.T) {
 	t.Run("scenario with multiple topics", testScenarioWithMultipleTopic)
 	t.Run("scenario with custom headers", testScenarioWithCustomHeaders)
 	t.Run("scenario with timestamp extraction", testScenarioTimestampExtraction)
+	t.Run("scenario with kafka record timestamp", testScenarioKafkaRecordTimestamp)
+	t.Run("scenario with kafka record key", testScenarioKafkaRecordKey)
+	t.Run("scenario with kafka record metadata", testScenarioKafkaRecordMetadata)
 	t.Run("scenario with regex topics", testScenarioRegexTopicMatchingUsingFranzGoFeatureGate)
+	t.Run("scenario with kafka routing", testScenarioKafkaRouting)
 }
 
 func testBasicScenarioWithSingleTopic(t *testing.T) {
@@ -272,6 +278,193 @@ func testScenarioTimestampExtraction(t *testing.T) {
 	defer common.StopOTelKafkaConnector(t, connectorHandler)
 }
 
+func testScenarioKafkaRecordTimestamp(t *testing.T) {
+	t.Logf("Running tests for kafka record timestamp")
+	sourceTimestamp := time.Now().Format("20060102150405")
+	topicName := "kafka-record-timestamp"
+	index := "kafka"
+	sourcetype := "otel-kafka-timestamp-test"
+	source := "otel-" + sourceTimestamp
+	configFileTemplate := "kafka_timestamp_test.yaml.tmpl"
+	extractPattern := "(?P<timestamp>[0-9]{4}-[0-9]{2}-[0-9]{2} [0-9]{2}:[0-9]{2}:[0-9]{2})"
+	goFormatStr := "2006-01-02 15:04:05"
+	otelFormatStr := "%Y-%m-%d %H:%M:%S"
+	bodyTimestampStr := "2020-01-01 12:00:00"
+	bodyTimestamp, err := time.Parse(goFormatStr, bodyTimestampStr)
+	require.NoError(t, err, "Error parsing timestamp")
+	// Recent enough for the record to stay within the retention of the topic, with the millisecond precision of Kafka.
+	recordTimestamp := time.Now().Add(-48 * time.Hour).Truncate(time.Millisecond).UTC()
+	recordEvent := "This event should have the timestamp of its kafka record!"
+	bodyEvent := "[" + bodyTimestampStr + "]" + " This event should keep the timestamp of its body!"
+
+	common.AddKafkaTopic(t, topicName, 1, 1)
+
+	replacements := map[string]any{
+		"KafkaBrokerAddress": common.GetConfigVariable("KAFKA_BROKER_ADDRESS"),
+		"KafkaTopicName":     topicName,
+		"SplunkHECToken":     common.GetConfigVariable("HEC_TOKEN"),
+		"SplunkHECEndpoint":  fmt.Sprintf("https://%s:8088/services/collector", common.GetConfigVariable("HOST")),
+		"Source":             source,
+		"Index":              index,
+		"Sourcetype":         sourcetype,
+		"ExtractPattern":     "\\\\[" + extractPattern + "\\\\]",
+		"FormatStr":          otelFormatStr,
+	}
+
+	configFileName := common.PrepareConfigFile(t, configFileTemplate, replacements, common.ConfigFilesDir)
+	connectorHandler := common.StartOTelKafkaConnector(t, configFileName, common.ConfigFilesDir)
+	defer common.StopOTelKafkaConnector(t, connectorHandler)
+
+	common.SendMessageWithTimestampToKafkaTopic(t, topicName, recordEvent, recordTimestamp)
+	common.SendMessageWithTimestampToKafkaTopic(t, topicName, bodyEvent, recordTimestamp)
+
+	searchQuery := common.EventSearchQueryString + "index=" + index + " sourcetype=" + sourcetype + " source=" +
+		source
+	startTime := "2019-12-31T00:00:00"
+	require.Eventually(t, func() bool {
+		events := common.GetEventsFromSplunk(t, searchQuery, startTime)
+		if len(events) < 2 {
+			return false
+		}
+		t.Logf(" =========>  Events received: %d", len(events))
+		assert.Equal(t, 2, len(events), "Expected two events for topic %s, but got %d", topicName, len(events))
+
+		expected := map[string]time.Time{
+			recordEvent: recordTimestamp,
+			bodyEvent:   bodyTimestamp,
+		}
+		for _, e := range events {
+			rawEvent := e.(map[string]interface{})["_raw"].(string)
+			want, ok := expected[rawEvent]
+			if !assert.True(t, ok, "Unexpected event %q", rawEvent) {
+				continue
+			}
+			eventTimeStr := e.(map[string]interface{})["_time"].(string)
+			eventTime, err := time.Parse(time.RFC3339, eventTimeStr)
+			require.NoError(t, err, "Error parsing event time from event")
+			assert.True(t, want.Equal(eventTime), "Event time of %q is %s, expected %s", rawEvent, eventTime, want)
+		}
+
+		return true
+	}, common.TestCaseDuration, common.TestCaseTick, "Search query: \n\"%s\"\n returned NO events for topic %s", searchQuery, topicName)
+}
+
+func testScenarioKafkaRecordKey(t *testing.T) {
+	t.Logf("Running tests for kafka record key")
+	topicName := "kafka-record-key"
+	index := "kafka"
+	sourcetype := "otel-kafka-key-test"
+	source := "otel-" + time.Now().Format("20060102150405")
+	configFileTemplate := "kafka_key_test.yaml.tmpl"
+	textKey := "web-1"
+	// Not valid UTF-8, so the string encoding attaches it base64-encoded.
+	binaryKey := []byte{0xff, 0x00, 0x01}
+	textEvent := "This event should have the host of its text key!"
+	binaryEvent := "This event should have its binary key base64-encoded!"
+
+	common.AddKafkaTopic(t, topicName, 1, 1)
+
+	replacements := map[string]any{
+		"KafkaBrokerAddress": common.GetConfigVariable("KAFKA_BROKER_ADDRESS"),
+		"KafkaTopicName":     topicName,
+		"SplunkHECToken":     common.GetConfigVariable("HEC_TOKEN"),
+		"SplunkHECEndpoint":  fmt.Sprintf("https://%s:8088/services/collector", common.GetConfigVariable("HOST")),
+		"Source":             source,
+		"Index":              index,
+		"Sourcetype":         sourcetype,
+	}
+
+	configFileName := common.PrepareConfigFile(t, configFileTemplate, replacements, common.ConfigFilesDir)
+	connectorHandler := common.StartOTelKafkaConnector(t, configFileName, common.ConfigFilesDir)
+	defer common.StopOTelKafkaConnector(t, connectorHandler)
+
+	common.SendMessageWithKeyToKafkaTopic(t, topicName, textEvent, []byte(textKey))
+	common.SendMessageWithKeyToKafkaTopic(t, topicName, binaryEvent, binaryKey)
+
+	searchQuery := common.EventSearchQueryString + "index=" + index + " sourcetype=" + sourcetype + " source=" +
+		source
+	startTime := "-1m@m"
+	require.Eventually(t, func() bool {
+		events := common.GetEventsFromSplunk(t, searchQuery, startTime)
+		if len(events) < 2 {
+			return false
+		}
+		t.Logf(" =========>  Events received: %d", len(events))
+		assert.Equal(t, 2, len(events), "Expected two events for topic %s, but got %d", topicName, len(events))
+
+		expected := map[string]string{
+			textEvent:   textKey,
+			binaryEvent: base64.StdEncoding.EncodeToString(binaryKey),
+		}
+		for _, e := range events {
+			event := e.(map[string]interface{})
+			rawEvent := event["_raw"].(string)
+			want, ok := expected[rawEvent]
+			if !assert.True(t, ok, "Unexpected event %q", rawEvent) {
+				continue
+			}
+			assert.Equal(t, want, event["kafka.key"], "Key of %q does not match", rawEvent)
+			assert.Equal(t, want, event["host"], "Host of %q does not match", rawEvent)
+		}
+
+		return true
+	}, common.TestCaseDuration, common.TestCaseTick, "Search query: \n\"%s\"\n returned NO events for topic %s", searchQuery, topicName)
+}
+
+// testScenarioKafkaRecordMetadata checks which record metadata the kafkametadata processor gets from the receiver
+// into Splunk: all of it, the key and timestamp included.
+func testScenarioKafkaRecordMetadata(t *testing.T) {
+	t.Logf("Running tests for kafka record metadata")
+	topicName := "kafka-record-metadata"
+	index := "kafka"
+	sourcetype := "otel-kafka-metadata-test"
+	source := "otel-" + time.Now().Format("20060102150405")
+	configFileTemplate := "kafka_metadata_test.yaml.tmpl"
+	key := "customer-7"
+	recordTimestamp := time.Now().Add(-time.Hour).Truncate(time.Millisecond).UTC()
+	event := "This event should have the metadata of its kafka record!"
+
+	common.AddKafkaTopic(t, topicName, 1, 1)
+
+	replacements := map[string]any{
+		"KafkaBrokerAddress": common.GetConfigVariable("KAFKA_BROKER_ADDRESS"),
+		"KafkaTopicName":     topicName,
+		"SplunkHECToken":     common.GetConfigVariable("HEC_TOKEN"),
+		"SplunkHECEndpoint":  fmt.Sprintf("https://%s:8088/services/collector", common.GetConfigVariable("HOST")),
+		"Source":             source,
+		"Index":              index,
+		"Sourcetype":         sourcetype,
+	}
+
+	configFileName := common.PrepareConfigFile(t, configFileTemplate, replacements, common.ConfigFilesDir)
+	connectorHandler := common.StartOTelKafkaConnector(t, configFileName, common.ConfigFilesDir)
+	defer common.StopOTelKafkaConnector(t, connectorHandler)
+
+	common.SendMessageWithKeyAndTimestampToKafkaTopic(t, topicName, event, []byte(key), recordTimestamp)
+
+	searchQuery := common.EventSearchQueryString + "index=" + index + " sourcetype=" + sourcetype + " source=" +
+		source + " kafka_topic=" + topicName
+	startTime := "-1d@d"
+	require.Eventually(t, func() bool {
+		events := common.GetEventsFromSplunk(t, searchQuery, startTime)
+		if len(events) < 1 {
+			return false
+		}
+		t.Logf(" =========>  Events received: %d", len(events))
+		assert.Equal(t, 1, len(events), "Expected one event for topic %s, but got %d", topicName, len(events))
+
+		fields := events[0].(map[string]interface{})
+		assert.Equal(t, event, fields["_raw"], "Expected event body does not match")
+		assert.Equal(t, topicName, fields["kafka_topic"], "Expected topic does not match")
+		assert.Equal(t, "0", fields["kafka_partition"], "Expected partition does not match")
+		// The offset depends on the records left in the topic by earlier runs.
+		assert.Contains(t, fields, "kafka_offset", "Expected offset is missing")
+		assert.Equal(t, key, fields["kafka_key"], "Expected key does not match")
+		assert.Equal(t, strconv.FormatInt(recordTimestamp.UnixMilli(), 10), fields["kafka_timestamp"], "Expected timestamp does not match")
+		return true
+	}, common.TestCaseDuration, common.TestCaseTick, "Search query: \n\"%s\"\n returned NO events for topic %s", searchQuery, topicName)
+}
+
 func testScenarioRegexTopicMatchingUsingFranzGoFeatureGate(t *testing.T) {
 	t.Logf("Running tests for regex matching")
 	regexTopic1 := "regex-topic1"
@@ -320,3 +513,67 @@ func testScenarioRegexTopicMatchingUsingFranzGoFeatureGate(t *testing.T) {
 	}, common.TestCaseDuration, common.TestCaseTick, "Search query: \n\"%s\"\n failed", searchQuery)
 	defer common.StopOTelKafkaConnector(t, connectorHandler)
 }
+
+func testScenarioKafkaRouting(t *testing.T) {
+	t.Logf("Running tests for routing from a single receiver")
+	topicName1 := "routing-topic1"
+	topicName2 := "routing-topic2"
+	regexExpression := "^routing-topic[0-9]"
+	tenant := "acme"
+	event := "Hello, Kafka from "
+	index := "kafka"
+	sourcetype := "otel-routing-test"
+	sourcePrefix := "otel-routing"
+	source := sourcePrefix + "-default"
+	source1 := sourcePrefix + "-1"
+	source2 := sourcePrefix + "-" + tenant
+	configFileTemplate := "kafka_routing_test.yaml.tmpl"
+
+	// the receiver subscribes with a regex, so the topics have to be created before starting the connector
+	common.AddKafkaTopic(t, topicName1, 1, 1)
+	common.AddKafkaTopic(t, topicName2, 1, 1)
+
+	replacements := map[string]any{
+		"KafkaBrokerAddress":  common.GetConfigVariable("KAFKA_BROKER_ADDRESS"),
+		"KafkaRegexTopicName": regexExpression,
+		"KafkaTopicName1":     topicName1,
+		"KafkaTopicName2":     topicName2,
+		"Tenant":              tenant,
+		"SplunkHECToken":      common.GetConfigVariable("HEC_TOKEN"),
+		"SplunkHECEndpoint":   fmt.Sprintf("https://%s:8088/services/collector", common.GetConfigVariable("HOST")),
+		"Source":              source,
+		"Source1":             source1,
+		"Source2":             source2,
+		"Index":               index,
+		"Sourcetype":          sourcetype,
+	}
+
+	configFileName := common.PrepareConfigFile(t, configFileTemplate, replacements, common.ConfigFilesDir)
+	connectorHandler := common.StartOTelKafkaConnector(t, configFileName, common.ConfigFilesDir)
+	defer common.StopOTelKafkaConnector(t, connectorHandler)
+
+	common.SendMessageToKafkaTopic(t, topicName1, event+topicName1)
+	common.SendMessageToKafkaTopic(t, topicName2, event+topicName2+" for "+tenant, kafka.Header{Key: "tenant", Value: []byte(tenant)})
+	common.SendMessageToKafkaTopic(t, topicName2, event+topicName2)
+
+	// check events in Splunk: every message gets the source of the route it matches, the last one the default
+	searchQuery := common.EventSearchQueryString + "index=" + index + " sourcetype=" + sourcetype + " source=" + sourcePrefix + "*"
+	startTime := "-1m@m"
+	require.Eventually(t, func() bool {
+		events := common.GetEventsFromSplunk(t, searchQuery, startTime)
+		t.Logf(" =========>  Events received: %d", len(events))
+		if len(events) < 3 {
+			return false
+		}
+		sources := map[string]any{}
+		for _, e := range events {
+			sources[e.(map[string]interface{})["_raw"].(string)] = e.(map[string]interface{})["source"]
+		}
+		assert.Equal(t, map[string]any{
+			event + topicName1:                    source1,
+			event + topicName2 + " for " + tenant: source2,
+			event + topicName2:                    source,
+		}, sources)
+		return true
+	}, common.TestCaseDuration, common.TestCaseTick, "Search query: \n\"%s\"\n returned NO events for topics %s, %s", searchQuery, topicName1, topicName2)
+}
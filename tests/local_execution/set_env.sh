@@ -4,4 +4,6 @@ export CI_SPLUNK_HOST=
 export CI_SPLUNK_MGMT_PORT=8089
 export CI_SPLUNK_HEC_TOKEN=
 export CI_KAFKA_BROKER_ADDRESS=
+# From the repository root, build the SOC4Kafka distribution with distribution/build.sh tests/otelcol_darwin_arm64,
+# or download the splunk-otel-collector release binary into tests/.
 export CI_OTEL_BINARY_FILE="otelcol_darwin_arm64"
\ No newline at end of file